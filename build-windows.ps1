@@ -55,7 +55,9 @@ $env:GOMAXPROCS = $cores
 
 # 执行多线程编译
 Write-ColorOutput "执行多线程编译（$cores 线程）..." "Yellow"
-go build -p $cores -ldflags="-H windowsgui -w -s" -tags bundled -o "build\windows\$AppName.exe" .
+# 当前 git 提交，写入程序便于定位问题对应的构建
+$commit = git rev-parse --short HEAD 2>$null
+go build -p $cores -ldflags="-H windowsgui -w -s -X claude-k2-installer/internal/version.Version=$Version -X claude-k2-installer/internal/version.Commit=$commit" -tags bundled -o "build\windows\$AppName.exe" .
 
 if ($LASTEXITCODE -eq 0) {
     Write-ColorOutput "✓ 编译成功" "Green"
@@ -87,6 +89,11 @@ try {
     }
 }
 
+# 生成校验文件，自动更新时用它校验下载的发布包；与其他平台的 SHA256SUMS 合并后上传到 Release
+$hash = (Get-FileHash -Algorithm SHA256 "build\$zipName").Hash.ToLower()
+Set-Content -Path "build\SHA256SUMS" -Value "$hash  $zipName" -Encoding ascii
+Write-ColorOutput "✓ 校验文件: build\SHA256SUMS（发布时与发布包一起上传）" "Green"
+
 # 显示构建结果
 Write-ColorOutput "构建完成！" "Green"
 
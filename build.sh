@@ -14,6 +14,8 @@ echo -e "${GREEN}开始构建 Claude Code + K2 Installer...${NC}"
 # 设置应用名称
 APP_NAME="ClaudeK2Installer"
 VERSION="1.0.0"
+# 当前 git 提交，写入程序便于定位问题对应的构建
+COMMIT=$(git rev-parse --short HEAD 2>/dev/null)
 
 # 检测当前系统
 OS=$(uname -s)
@@ -51,10 +53,10 @@ case "$OS" in
         
         if [ "$ARCH" = "arm64" ]; then
             echo -e "${BLUE}构建 Apple Silicon 版本...${NC}"
-            go build -p $CORES -ldflags="-w -s" -tags bundled -o "build/macos/${APP_NAME}" .
+            go build -p $CORES -ldflags="-w -s -X claude-k2-installer/internal/version.Version=${VERSION} -X claude-k2-installer/internal/version.Commit=${COMMIT}" -tags bundled -o "build/macos/${APP_NAME}" .
         else
             echo -e "${BLUE}构建 Intel 版本...${NC}"
-            go build -p $CORES -ldflags="-w -s" -tags bundled -o "build/macos/${APP_NAME}" .
+            go build -p $CORES -ldflags="-w -s -X claude-k2-installer/internal/version.Version=${VERSION} -X claude-k2-installer/internal/version.Commit=${COMMIT}" -tags bundled -o "build/macos/${APP_NAME}" .
         fi
         
         if [ $? -eq 0 ]; then
@@ -69,7 +71,7 @@ case "$OS" in
         # Linux 构建
         echo -e "${GREEN}构建 Linux 版本（使用 $CORES 线程）...${NC}"
         mkdir -p build/linux
-        go build -p $CORES -ldflags="-w -s" -tags bundled -o "build/linux/${APP_NAME}" .
+        go build -p $CORES -ldflags="-w -s -X claude-k2-installer/internal/version.Version=${VERSION} -X claude-k2-installer/internal/version.Commit=${COMMIT}" -tags bundled -o "build/linux/${APP_NAME}" .
         
         if [ $? -eq 0 ]; then
             echo -e "${GREEN}✓ Linux 版本构建成功${NC}"
@@ -85,7 +87,7 @@ case "$OS" in
         mkdir -p build/windows
         
         # Windows 特殊处理：添加 -H windowsgui 隐藏控制台窗口
-        GOMAXPROCS=$CORES go build -p $CORES -ldflags="-H windowsgui -w -s" -tags bundled -o "build/windows/${APP_NAME}.exe" .
+        GOMAXPROCS=$CORES go build -p $CORES -ldflags="-H windowsgui -w -s -X claude-k2-installer/internal/version.Version=${VERSION} -X claude-k2-installer/internal/version.Commit=${COMMIT}" -tags bundled -o "build/windows/${APP_NAME}.exe" .
         
         if [ $? -eq 0 ]; then
             echo -e "${GREEN}✓ Windows 版本构建成功${NC}"
@@ -174,6 +176,15 @@ case "$OS" in
         ;;
 esac
 
+# 生成校验文件，自动更新时用它校验下载的发布包
+# 各平台分别构建时，把所有发布包放到同一个 build 目录后重新运行本段，或合并各平台的 SHA256SUMS 后上传到 Release
+if command -v sha256sum &> /dev/null; then
+    sha256sum ${APP_NAME}-${VERSION}-*.zip ${APP_NAME}-${VERSION}-*.tar.gz 2>/dev/null > SHA256SUMS
+else
+    shasum -a 256 ${APP_NAME}-${VERSION}-*.zip ${APP_NAME}-${VERSION}-*.tar.gz 2>/dev/null > SHA256SUMS
+fi
+echo -e "${GREEN}✓ 校验文件: build/SHA256SUMS（发布时与发布包一起上传）${NC}"
+
 cd ..
 
 echo -e "${GREEN}构建完成！${NC}"
@@ -36,6 +36,24 @@ func IsActivated() bool {
 	return info != nil && Validate(info.Code)
 }
 
+// Current 返回已保存的有效激活信息，未激活时返回 nil
+func Current() *ActivationInfo {
+	info, err := loadActivation()
+	if err != nil || info == nil || !Validate(info.Code) {
+		return nil
+	}
+	return info
+}
+
+// MaskedCode 返回只显示前两段的激活码，如 CK2025-1A2B-****-****，用于界面显示
+func (info *ActivationInfo) MaskedCode() string {
+	parts := strings.Split(info.Code, "-")
+	for i := 2; i < len(parts); i++ {
+		parts[i] = strings.Repeat("*", len(parts[i]))
+	}
+	return strings.Join(parts, "-")
+}
+
 func Validate(code string) bool {
 	// 去除空格和转换为大写
 	code = strings.ToUpper(strings.ReplaceAll(code, " ", ""))
@@ -0,0 +1,214 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"claude-k2-installer/internal/installer"
+)
+
+// Options 无界面模式的参数
+type Options struct {
+	APIKey          string
+	RPM             string
+	Provider        string // 服务商名称，为空时使用默认服务商
+	CustomBaseURL   string // 服务商为自定义网关时的接口地址
+	UseSystemConfig bool   // 是否永久设置环境变量
+	JSON            bool   // 以 JSON 事件流输出进度，每行一个事件
+	DryRun          bool   // 模拟运行，只报告将执行的操作
+	NodeVersion     string // 需要安装 Node.js 时安装的版本
+	MinNodeVersion  int    // 要求的最低 Node.js 主版本，为 0 时使用默认值
+	GitVersion      string // Windows 上需要安装 Git 时安装的版本
+	MinGitVersion   string // 要求的最低 Git 版本，为空时使用默认值
+	NPMSudo         bool   // npm 全局目录不可写时使用 sudo，而不是改用 ~/.npm-global
+	ClaudeVersion   string // 安装的 Claude Code 版本，为空时安装 latest
+	DownloadRetries int    // 每个镜像下载失败后的重试次数
+	OfflineDir      string // 离线安装包目录，为空时联网下载
+	SkipNode        bool   // 跳过检测和安装 Node.js
+	SkipGit         bool   // 跳过检测和安装 Git
+	NodeMirror      string // 自定义 Node.js 下载镜像
+	NPMRegistry     string // 安装 Claude Code 使用的 npm 镜像
+	Proxy           string // 下载和 npm 安装使用的 HTTP 代理
+	SlowNetwork     bool   // 慢速网络模式，放宽下载超时
+	ForceIPv4       bool   // 只使用 IPv4 连接
+	EnvOnly         bool   // 仅配置环境变量，不修改 ~/.claude.json
+	ConfigStrategy  string // 永久环境变量的写入位置：shell、settings 或 both，为空时为 shell
+	LogPolicy       installer.LogPolicy
+}
+
+// Run 不启动 GUI，直接执行安装和配置流程，返回进程退出码
+func Run(opts Options) int {
+	provider := installer.DefaultProvider()
+	if opts.Provider == installer.CustomProviderName {
+		p, err := installer.NewCustomProvider(opts.CustomBaseURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 2
+		}
+		provider = p
+	} else if opts.Provider != "" {
+		p, ok := installer.FindProvider(opts.Provider)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "❌ 未知的服务商: %s\n", opts.Provider)
+			return 2
+		}
+		provider = p
+	}
+
+	// 去掉粘贴时带上的空白和引号，格式可疑时只提醒不阻止
+	opts.APIKey = installer.NormalizeAPIKey(opts.APIKey)
+	if err := provider.CheckAPIKeyFormat(opts.APIKey); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ 密钥格式看起来不对，仍继续配置: %v\n", err)
+	}
+
+	switch opts.LogPolicy.Retention {
+	case "", installer.LogRetentionOff, installer.LogRetentionSession, installer.LogRetentionDays, installer.LogRetentionForever:
+	default:
+		fmt.Fprintf(os.Stderr, "❌ 未知的日志留存方式: %s\n", opts.LogPolicy.Retention)
+		return 2
+	}
+
+	if err := installer.ValidateMinNodeVersion(opts.MinNodeVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+	if opts.NodeVersion != "" {
+		if err := installer.ValidateNodeTargetVersion(opts.NodeVersion, opts.MinNodeVersion); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 2
+		}
+	}
+
+	if err := installer.ValidateMinGitVersion(opts.MinGitVersion); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+	if opts.GitVersion != "" {
+		if err := installer.ValidateGitTargetVersion(opts.GitVersion, opts.MinGitVersion); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 2
+		}
+	}
+
+	if err := installer.ValidateConfigStrategy(opts.ConfigStrategy); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 2
+	}
+
+	for _, mirror := range []string{opts.NodeMirror, opts.NPMRegistry} {
+		if mirror == "" {
+			continue
+		}
+		if err := installer.ValidateMirrorURL(mirror); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 2
+		}
+	}
+	if opts.Proxy != "" {
+		if err := installer.ValidateProxyURL(opts.Proxy); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			return 2
+		}
+	}
+
+	inst := installer.New()
+	inst.DryRun = opts.DryRun
+	if opts.ClaudeVersion != "" {
+		inst.ClaudeCodeVersion = opts.ClaudeVersion
+	}
+	if opts.NPMSudo {
+		inst.NPMStrategy = installer.NPMStrategySudo
+	}
+	if opts.NodeVersion != "" {
+		inst.NodeVersion = opts.NodeVersion
+	}
+	if opts.MinNodeVersion > 0 {
+		inst.MinNodeVersion = opts.MinNodeVersion
+	}
+	if opts.GitVersion != "" {
+		inst.GitVersion = opts.GitVersion
+	}
+	if opts.MinGitVersion != "" {
+		inst.MinGitVersion = opts.MinGitVersion
+	}
+	inst.DownloadRetries = opts.DownloadRetries
+	inst.OfflineDir = opts.OfflineDir
+	inst.WriteClaudeJSON = !opts.EnvOnly
+	inst.ConfigStrategy = installer.ConfigStrategy(opts.ConfigStrategy)
+	inst.SkipNode = opts.SkipNode
+	inst.SkipGit = opts.SkipGit
+	inst.NodeMirror = opts.NodeMirror
+	inst.NPMRegistry = opts.NPMRegistry
+	inst.Proxy = opts.Proxy
+	inst.ForceIPv4 = opts.ForceIPv4
+	if opts.SlowNetwork {
+		inst.UseSlowNetwork()
+	}
+	inst.SetLogPolicy(opts.LogPolicy)
+	defer inst.CloseLog()
+	if opts.JSON {
+		inst.SetProgressJSON(os.Stdout)
+	}
+	// 安装期间 Ctrl+C 时取消安装，正在等待的步骤立即结束
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		if _, ok := <-interrupts; ok {
+			fmt.Fprintln(os.Stderr, "⏹️ 正在取消安装...")
+			inst.Cancel()
+		}
+	}()
+
+	// 依次安装和配置，JSON 模式下进度和最后的安装报告都以 JSON 事件输出
+	report := inst.RunFull(provider, opts.APIKey, opts.RPM, opts.UseSystemConfig, func(update installer.ProgressUpdate) {
+		if opts.JSON {
+			return
+		}
+		if update.Error != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", update.Error)
+			return
+		}
+		printUpdate(update)
+	})
+	signal.Stop(interrupts)
+	close(interrupts)
+
+	if !opts.JSON {
+		printReport(report)
+	}
+	if !report.Success {
+		return 1
+	}
+
+	if !opts.JSON {
+		if opts.DryRun {
+			fmt.Println("🔍 " + inst.DryRunSummary())
+		} else {
+			fmt.Println("✅ 安装和配置全部完成！")
+			fmt.Println("在当前终端启用 K2: " + inst.GetActivationCommand(opts.UseSystemConfig))
+		}
+	}
+	return 0
+}
+
+// printReport 输出安装报告的摘要：各组件的结果、用时和警告数量
+func printReport(report *installer.InstallReport) {
+	icons := map[string]string{installer.ComponentOK: "✅", installer.ComponentFailed: "❌", installer.ComponentSkipped: "⏭️"}
+	fmt.Printf("📋 安装报告（用时 %s）\n", report.Duration)
+	for _, component := range report.Components {
+		fmt.Printf("   %s %s %s\n", icons[component.Status], component.Name, component.Version)
+	}
+	if len(report.Warnings) > 0 {
+		fmt.Printf("   ⚠️ %d 条警告，详见日志\n", len(report.Warnings))
+	}
+}
+
+// printUpdate 输出一条进度更新，日志消息原样输出，进度消息带百分比
+func printUpdate(update installer.ProgressUpdate) {
+	if update.Percent < 0 {
+		fmt.Println(update.Message)
+		return
+	}
+	fmt.Printf("[%3.0f%%] %s\n", update.Percent*100, update.Message)
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"claude-k2-installer/internal/installer"
+	"claude-k2-installer/internal/ui"
+)
+
+// LoadConfigFile 读取配置文件并应用到命令行没有显式指定的参数（explicit 为已指定的参数名），
+// 校验通过后输出应用了哪些设置；JSON 模式下输出到标准错误，不混入事件流
+func (o *Options) LoadConfigFile(path string, explicit map[string]bool) error {
+	config, err := ui.LoadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	applied, err := o.ApplyConfig(config, explicit)
+	if err != nil {
+		return fmt.Errorf("配置文件 %s 校验失败:\n%v", path, err)
+	}
+
+	var out io.Writer = os.Stdout
+	if o.JSON {
+		out = os.Stderr
+	}
+	if len(applied) == 0 {
+		fmt.Fprintf(out, "📄 配置文件 %s 中没有需要应用的设置\n", path)
+		return nil
+	}
+	fmt.Fprintf(out, "📄 已应用配置文件 %s 中的设置:\n", path)
+	for _, line := range applied {
+		fmt.Fprintf(out, "   • %s\n", line)
+	}
+	return nil
+}
+
+// ApplyConfig 校验配置中的设置，并填充命令行没有显式指定的参数，返回应用的设置说明
+// 任一设置无效时不修改参数，返回全部无效设置
+func (o *Options) ApplyConfig(config *ui.AppConfig, explicit map[string]bool) ([]string, error) {
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	setString := func(flagName, label string, value string, target *string) {
+		value = strings.TrimSpace(value)
+		if value == "" || explicit[flagName] {
+			return
+		}
+		*target = value
+		applied = append(applied, fmt.Sprintf("%s: %s", label, value))
+	}
+	setBool := func(flagName, label string, value bool, target *bool) {
+		if !value || explicit[flagName] {
+			return
+		}
+		*target = true
+		applied = append(applied, label)
+	}
+
+	if config.APIKey != "" && !explicit["api-key"] {
+		o.APIKey = config.APIKey
+		applied = append(applied, "API Key: 已设置")
+	}
+	setString("rpm", "速率限制 RPM", config.RPM, &o.RPM)
+	if config.Provider != "" && !explicit["provider"] {
+		o.Provider = config.Provider
+		o.CustomBaseURL = config.CustomBaseURL
+		if config.Provider == installer.CustomProviderName {
+			applied = append(applied, fmt.Sprintf("服务商: %s（%s）", config.Provider, config.CustomBaseURL))
+		} else {
+			applied = append(applied, fmt.Sprintf("服务商: %s", config.Provider))
+		}
+	}
+	setString("node-version", "Node.js 版本", config.NodeVersion, &o.NodeVersion)
+	if config.MinNodeVersion > 0 && !explicit["min-node-version"] {
+		o.MinNodeVersion = config.MinNodeVersion
+		applied = append(applied, fmt.Sprintf("最低 Node.js 版本: v%d", config.MinNodeVersion))
+	}
+	setString("git-version", "Git 版本", config.GitVersion, &o.GitVersion)
+	setString("min-git-version", "最低 Git 版本", config.MinGitVersion, &o.MinGitVersion)
+	setString("claude-version", "Claude Code 版本", config.ClaudeVersion, &o.ClaudeVersion)
+	setBool("npm-sudo", "npm 全局目录无写权限时使用 sudo", config.NPMStrategy == string(installer.NPMStrategySudo), &o.NPMSudo)
+	setString("offline-dir", "离线安装包目录", config.OfflineDir, &o.OfflineDir)
+	setString("node-mirror", "Node.js 镜像", config.NodeMirror, &o.NodeMirror)
+	setString("npm-registry", "npm 镜像", config.NPMRegistry, &o.NPMRegistry)
+	setString("proxy", "代理", config.Proxy, &o.Proxy)
+	setBool("slow-network", "慢速网络模式", config.SlowNetwork, &o.SlowNetwork)
+	setBool("force-ipv4", "只使用 IPv4 连接", config.ForceIPv4, &o.ForceIPv4)
+	setBool("env-only", "仅配置环境变量", config.EnvOnly, &o.EnvOnly)
+	setString("config-strategy", "环境变量写入位置", config.ConfigStrategy, &o.ConfigStrategy)
+	setBool("skip-node", "跳过 Node.js", config.SkipNode, &o.SkipNode)
+	setBool("skip-git", "跳过 Git", config.SkipGit, &o.SkipGit)
+	return applied, nil
+}
+
+// validateConfig 检查配置中已填写的设置，返回全部无效设置
+func validateConfig(config *ui.AppConfig) error {
+	var errs []error
+	if rpm := strings.TrimSpace(config.RPM); rpm != "" {
+		if n, err := strconv.Atoi(rpm); err != nil || n <= 0 {
+			errs = append(errs, fmt.Errorf("rpm: 速率限制必须是正整数: %q", config.RPM))
+		}
+	}
+	switch config.Provider {
+	case "":
+	case installer.CustomProviderName:
+		if _, err := installer.NewCustomProvider(config.CustomBaseURL); err != nil {
+			errs = append(errs, fmt.Errorf("custom_base_url: %v", err))
+		}
+	default:
+		if _, ok := installer.FindProvider(config.Provider); !ok {
+			errs = append(errs, fmt.Errorf("provider: 未知的服务商: %s", config.Provider))
+		}
+	}
+	if err := installer.ValidateMinNodeVersion(config.MinNodeVersion); err != nil {
+		errs = append(errs, fmt.Errorf("min_node_version: %v", err))
+	}
+	if version := strings.TrimSpace(config.NodeVersion); version != "" {
+		if err := installer.ValidateNodeTargetVersion(version, config.MinNodeVersion); err != nil {
+			errs = append(errs, fmt.Errorf("node_version: %v", err))
+		}
+	}
+	if err := installer.ValidateMinGitVersion(config.MinGitVersion); err != nil {
+		errs = append(errs, fmt.Errorf("min_git_version: %v", err))
+	}
+	if version := strings.TrimSpace(config.GitVersion); version != "" {
+		if err := installer.ValidateGitTargetVersion(version, config.MinGitVersion); err != nil {
+			errs = append(errs, fmt.Errorf("git_version: %v", err))
+		}
+	}
+	if version := strings.TrimSpace(config.ClaudeVersion); version != "" {
+		if err := installer.ValidateClaudeCodeVersion(strings.TrimPrefix(version, "v")); err != nil {
+			errs = append(errs, fmt.Errorf("claude_code_version: %v", err))
+		}
+	}
+	if err := installer.ValidateConfigStrategy(config.ConfigStrategy); err != nil {
+		errs = append(errs, fmt.Errorf("config_strategy: %v", err))
+	}
+	switch installer.NPMStrategy(config.NPMStrategy) {
+	case "", installer.NPMStrategyUserPrefix, installer.NPMStrategySudo:
+	default:
+		errs = append(errs, fmt.Errorf("npm_strategy: 未知的 npm 安装方式: %s，应为 %s 或 %s",
+			config.NPMStrategy, installer.NPMStrategyUserPrefix, installer.NPMStrategySudo))
+	}
+	if config.NodeMirror != "" {
+		if err := installer.ValidateMirrorURL(config.NodeMirror); err != nil {
+			errs = append(errs, fmt.Errorf("node_mirror: %v", err))
+		}
+	}
+	if config.NPMRegistry != "" {
+		if err := installer.ValidateMirrorURL(config.NPMRegistry); err != nil {
+			errs = append(errs, fmt.Errorf("npm_registry: %v", err))
+		}
+	}
+	if config.Proxy != "" {
+		if err := installer.ValidateProxyURL(config.Proxy); err != nil {
+			errs = append(errs, fmt.Errorf("proxy: %v", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,482 @@
+package i18n
+
+// enUS 英文文案
+var enUS = map[string]string{
+	// 主界面
+	"app.title":    "Claude Code + K2 Setup Tool",
+	"app.subtitle": "Install and configure Claude Code with Kimi K2 in one click",
+	"app.wechat":   "🤖 WeChat: ruan11223344 — join the group to learn about the latest AI together (click to copy)",
+
+	"status.ready":     "Ready",
+	"log.placeholder":  "Install logs will appear here...",
+	"log.copied_title": "Copied",
+	"log.copied":       "The log has been copied to the clipboard and is ready to paste.",
+
+	"label.provider":           "Provider:",
+	"label.language":           "Language:",
+	"apikey.placeholder":       "Enter your API Key",
+	"button.get_api_key":       "🔑 Get an API Key",
+	"button.scan":              "📱 Scan",
+	"qr.api_key_title":         "Get an API Key on your phone",
+	"button.restore_config":    "🔄 Restore Claude config",
+	"rpm.info":                 "Free: 3 | ¥50: 200 | ¥100: 500 | ¥500+: 5000",
+	"rpm.delay":                "⏱ %d ms between requests (written to CLAUDE_REQUEST_DELAY_MS)",
+	"rpm.paid_tier":            "ℹ️ %d RPM is above the free tier of %d RPM and needs a balance of at least %s, otherwise you will see frequent 429 errors",
+	"rpm.above_max":            "ℹ️ %d RPM is above the highest published tier of %s (%s: %d RPM) and may trigger 429 errors",
+	"rpm.desc":                 "* Rate limits depend on your Kimi balance; top up at least ¥50 for smooth use",
+	"button.charge":            "💳 Open Kimi top-up page",
+	"qr.charge_title":          "Top up on your phone",
+	"label.rpm":                "Rate limit (RPM):",
+	"check.system_config":      "Set K2 environment variables permanently (recommended — writes .bashrc/.zshrc/Windows env)",
+	"check.dry_run":            "Dry run",
+	"help.env_var":             "✓ Checked: permanent (written to config files)  ✗ Unchecked: current process only",
+	"help.node_version":        "Only used when Node.js is not found, e.g. 22.11.0 (ignored on Linux when using system packages)",
+	"check.npm_sudo":           "Use sudo when the npm global directory is not writable (Linux only, defaults to ~/.npm-global)",
+	"label.terminal":           "Terminal app:",
+	"advanced.title":           "Advanced options",
+	"label.node_version":       "Node.js version:",
+	"label.claude_version":     "Claude Code version:",
+	"label.project_dir":        "Project folder:",
+	"project.placeholder":      "Folder to open Claude Code in; empty for your home folder",
+	"label.proxy":              "HTTP proxy:",
+	"check.env_only":           "Configure environment variables only (leave ~/.claude.json and Claude Code's onboarding and project state untouched)",
+	"label.config_strategy":    "Write variables to:",
+	"config_strategy.shell":    "Shell profiles (.bashrc/.zshrc/Windows environment)",
+	"config_strategy.settings": "~/.claude/settings.json (works when launched from the GUI)",
+	"config_strategy.both":     "Both",
+	"check.force_ipv4":         "Use IPv4 only (check this if your IPv6 is broken and downloads hang)",
+	"check.slow_network":       "Slow network mode (allow downloads up to 60 minutes, treat as stalled only after 2 minutes without data)",
+	"button.clear_cache":       "Clear cache",
+	"cache.size":               "Download cache: %.1f MB",
+	"cache.size_unknown":       "Download cache: size unknown",
+	"cache.confirm":            "Delete the cached Node.js and Git installers? They will be downloaded again on the next install.",
+	"error.clear_cache":        "Failed to clear the cache: %v",
+	"proxy.placeholder":        "e.g. http://127.0.0.1:7890; empty to use the system proxy variables",
+	"label.offline_dir":        "Offline bundle folder:",
+	"offline.placeholder":      "Leave empty to download",
+	"button.browse":            "Browse...",
+	"help.offline_dir":         "Put the Node.js installer (e.g. node-v22.11.0-x64.msi), the Git installer (Windows) and the anthropic-ai-claude-code-<version>.tgz from npm pack in this folder, optionally with a SHASUMS256.txt",
+	"label.appearance":         "Appearance:",
+	"appearance.system":        "Follow system",
+	"appearance.light":         "Light",
+	"appearance.dark":          "Dark",
+	"label.ui_scale":           "UI scale:",
+	"check.high_contrast":      "High contrast mode (for low vision or bright environments)",
+	"button.install":           "Install",
+	"button.configure_only":    "Configure API only",
+	"button.tutorial":          "Tutorial",
+	"button.log_policy":        "Logs & privacy",
+	"button.uninstall":         "Uninstall",
+	"button.test_connection":   "Test connection",
+	"button.verify":            "Check environment",
+	"button.open_claude":       "Open Claude Code",
+	"button.reinstall":         "Reinstall",
+	"button.update_claude":     "Update Claude Code",
+	"button.retry_update":      "Retry update",
+	"button.export_logs":       "Export logs",
+	"button.copy_logs":         "Copy logs",
+	"button.diagnostics":       "Diagnostics report",
+	"report.title":             "Install report (took %s)",
+	"report.components":        "Components:",
+	"report.ok":                "installed",
+	"report.failed":            "missing or too old",
+	"report.skipped":           "skipped",
+	"report.config_files":      "Config files written:",
+	"report.env_vars":          "Environment variables set (%s):",
+	"report.permanent":         "permanent",
+	"report.temporary":         "current terminal only",
+	"report.warnings":          "Warnings (%d):",
+	"report.error":             "Failure: %s",
+	"report.export":            "Export logs and report",
+	"button.open_config_dir":   "Open config folder",
+	"button.copy":              "Copy",
+	"button.ok":                "OK",
+	"button.close":             "Close",
+	"button.cancel":            "Cancel",
+	"section.config":           "Configuration",
+	"section.progress":         "Progress",
+	"section.logs":             "Install log",
+	"language.restart":         "The language change takes effect after restarting the app.",
+
+	// 步骤卡片
+	"steps.title":          "Install steps",
+	"steps.subtitle":       "This tool completes the following steps automatically:",
+	"steps.check_system":   "1. Check the system",
+	"steps.install_node":   "2. Install Node.js (if missing)",
+	"steps.install_git":    "3. Install Git (if missing)",
+	"steps.install_claude": "4. Install the Claude Code CLI",
+	"steps.configure_api":  "5. Configure the Kimi K2 API",
+	"steps.verify":         "6. Verify the environment",
+	"steps.skipped":        "%s (skipped)",
+	"steps.skip":           "Skip",
+	"env.detecting":        "Detecting installed components...",
+	"env.not_installed":    "not installed",
+	"env.too_old":          "%s (too old)",
+	"env.detected":         "Detected: Node.js %s · Git %s · Claude Code %s",
+	"env.ready":            "Everything is installed; only the API needs to be configured.",
+	"env.last_install":     "Last successful install: %s (%s)\nNode.js %s · Git %s · Claude Code %s",
+	"log.skip_install":     "Complete environment detected, skipping install and configuring the API...",
+	"log.configuring":      "Configuring K2 API...",
+
+	// 安装流程
+	"error.api_key_required":         "Please enter your Kimi K2 API Key",
+	"confirm.api_key_title":          "Confirm API Key",
+	"confirm.api_key_format":         "The key format looks wrong. Continue anyway?\n\n%v",
+	"error.rpm_not_number":           "The rate limit must be a number",
+	"error.install_panic":            "An error occurred during installation: %v",
+	"status.install_failed":          "Installation failed",
+	"status.error":                   "Error: %v",
+	"status.configuring":             "Configuring K2 API...",
+	"status.install_ok_api_failed":   "⚠️ Installed, but API configuration failed",
+	"status.all_done":                "✅ Installation and configuration complete!",
+	"status.api_failed":              "⚠️ API configuration failed",
+	"error.api_failed":               "API configuration failed: %v",
+	"status.api_done":                "✅ API configured!",
+	"dialog.api_done_title":          "Configured",
+	"dialog.api_done":                "The API is configured!\n\nClick \"Open Claude Code\" to get started.",
+	"dialog.install_failed_title":    "Installation failed",
+	"dialog.retry":                   "%v\n\nYou can retry from \"%s\"; completed steps will not run again.",
+	"button.retry_step":              "Retry this step",
+	"status.retrying":                "Retrying %s...",
+	"status.install_done":            "✅ Installation complete!",
+	"status.installed":               "✅ Installed — open Claude Code to get started",
+	"status.updating":                "Updating Claude Code...",
+	"status.update_failed":           "⚠️ Claude Code update failed",
+	"dialog.update_done":             "Update complete",
+	"dialog.update_failed":           "Update failed",
+	"change_key.title":               "Change API Key",
+	"button.change_key":              "Change API Key",
+	"change_key.hint":                "Enter the new API key. The old K2 configuration (config files and environment variables) is removed and rewritten with the new key, then verified. No components are reinstalled.",
+	"change_key.placeholder":         "New API key",
+	"change_key.retry":               "Enter again",
+	"status.changing_key":            "Changing API key...",
+	"status.change_key_failed":       "⚠️ Failed to change the API key",
+	"status.key_changed":             "✅ API key changed",
+	"dialog.change_key_failed":       "Failed to change API key",
+	"dialog.key_changed":             "API key changed",
+	"dialog.key_changed_relaunch":    "The new API key is configured and verified.\n\nClaude Code windows that are already open still use the old key. Open Claude Code again now?",
+	"dialog.diagnostics_saved_title": "Diagnostics saved",
+	"dialog.diagnostics_saved":       "The diagnostics report was saved to:\n%s\n\nKeys in the report are masked. Please attach this file when reporting a problem.",
+	"error.diagnostics":              "Failed to create the diagnostics report: %v",
+	"dialog.install_done_title":      "Installed",
+	"dialog.install_done":            "Claude Code + K2 has been installed!\n\nClick \"Open Claude Code\" to get started.",
+	"complete.hint_permanent":        "Terminals that are already open must run this command first, otherwise claude keeps the old config:",
+	"complete.hint_settings":         "The configuration was written to ~/.claude/settings.json, which Claude Code reads on startup. Just run this in any terminal:",
+	"complete.hint_temp":             "Environment variables were not set permanently. Run this command in a terminal to enable K2 and start Claude Code:",
+	"dryrun.nothing":                 "All components are installed; nothing to change.",
+	"dryrun.components":              "The following components would be installed or changed:\n\n• ",
+	"dryrun.title":                   "Dry run complete",
+	"dryrun.footer":                  "\n\nSee the install log for details. Uncheck \"Dry run\" and click \"Install\" again to install for real.",
+
+	// shell 配置修改预览
+	"shell_preview.title":       "Confirm config file changes",
+	"shell_preview.hint":        "K2 environment variables will be written to the files below (- removed lines, + added lines, API Key masked). Cancel and uncheck the permanent option to use a temporary script instead.",
+	"shell_preview.confirm":     "Write changes",
+	"shell_preview.create":      "Create %s",
+	"shell_preview.replace":     "Replace the existing K2 block in %s",
+	"shell_preview.append":      "Append to %s",
+	"shell_preview.read_failed": "Skip %s (read failed: %v)",
+
+	// 自动更新
+	"update.available":         "🎉 A new version is available: v%s (current v%s)",
+	"update.install":           "Update now",
+	"update.notes":             "Release notes",
+	"update.downloading_title": "Updating",
+	"update.downloading":       "Downloading %s ...",
+	"update.done_title":        "Update complete",
+	"update.done":              "Updated to v%s. The new version takes effect after a restart. Restart now?",
+
+	// 管理员权限
+	"elevation.title":                "Administrator privileges needed",
+	"elevation.hint":                 "Installing the components below needs administrator privileges. Watch for the password prompt during the install:",
+	"elevation.hint_windows":         "This program is not running as administrator, and installing the components below needs administrator privileges. The install may only fail after the download finishes. Relaunching as administrator is recommended:",
+	"elevation.relaunch":             "Relaunch as administrator",
+	"elevation.continue":             "Continue anyway",
+	"remedy.network":                 "The installers could not be downloaded. Check your network connection; if you need a proxy to reach the internet, enter it under Advanced options → HTTP proxy and try again.",
+	"remedy.open_proxy":              "Set proxy",
+	"remedy.elevation":               "This step needs administrator rights. Enter your login password when prompted, or run this program with sudo from a terminal and try again.",
+	"error.user_cancelled":           "You cancelled the authorization, so the installation did not finish.",
+	"remedy.user_cancelled":          "You cancelled the password prompt. The downloaded installer has been kept; click retry and enter your login password when prompted to continue without downloading again.",
+	"remedy.elevation_win":           "This step needs administrator rights and the program is not running as administrator. Restart it as administrator and install again.",
+	"remedy.npm":                     "The npm global directory is not writable. Change its owner or run as administrator, then try again.",
+	"remedy.npm_win":                 "The npm global directory is not writable. Restart as administrator and install again.",
+	"remedy.git_too_old":             "The installed Git is too old for some of Claude Code's git operations, and the automatic install did not provide a newer version. Install a newer Git from git-scm.com (on Linux, use your distribution's updates or a third-party repository such as IUS), or check Skip next to the Git step and manage Git yourself.",
+	"remedy.node_too_old":            "The installed Node.js is older than Claude Code requires and the automatic upgrade did not succeed. Install a newer version from nodejs.org (or switch to one in nvm or another version manager), or uncheck Skip next to the Node.js step and try again.",
+	"node_upgrade.title":             "Node.js is too old",
+	"node_upgrade.message":           "Found Node.js %s, which is older than the v%d Claude Code requires.\n\nIt will be upgraded to v%s during installation.",
+	"node_upgrade.message_skipped":   "Found Node.js %s, which is older than the v%d Claude Code requires.\n\nThe Node.js step is set to Skip, so Claude Code will not run after installation unless Node.js is upgraded. Upgrade to v%s?",
+	"node_upgrade.upgrade":           "Upgrade Node.js",
+	"node_upgrade.keep":              "Continue without upgrading",
+	"remedy.antivirus":               "Windows Defender or another antivirus likely quarantined or blocked the downloaded installer (it was retried once automatically). Restore and allow the file in Protection history, or temporarily turn off real-time protection or add %TEMP% and ~/.claude-k2-installer to the exclusions, then retry. Installers come from official mirrors and the Git installer's signature has been verified.",
+	"remedy.open_protection_history": "Open Protection history",
+	"button.manual_guide":            "Manual install guide",
+	"manual_guide.title":             "Manual Install Guide",
+	"manual_guide.generating":        "Checking your system and network to generate the guide...",
+	"manual_guide.hint":              "These steps are tailored to your system and the step that failed; follow them in order to finish the installation. The API key is replaced by a placeholder, so the guide is safe to export or share.",
+	"manual_guide.copy":              "Copy",
+	"manual_guide.copied":            "The manual install guide has been copied to the clipboard",
+	"manual_guide.export":            "Export",
+	"manual_guide.saved":             "Manual install guide saved to:\n%s",
+	"manual_guide.suggest":           "Automatic installation has failed %d times in a row. You can generate a manual install guide and finish the installation yourself with steps for your system.",
+	"error.manual_guide":             "Failed to save the manual install guide: %v",
+	"remedy.untrusted":               "The downloaded installer is not signed by its official publisher. The mirror may have served a tampered file, so it was not run. Try again later or set a proxy to use another download source.",
+	"remedy.npm_sudo":                "The npm global directory is owned by root and not writable by the current user. You can install into it with sudo (asks for your password).",
+	"remedy.retry_sudo":              "Retry with sudo",
+	"remedy.api_key":                 "The provider rejected this API key. Make sure you copied the whole key and it has not been deleted; create a new key and enter it again if needed.",
+	"remedy.balance":                 "Your account balance or quota is used up. Top up to keep using it.",
+	"remedy.rate_limited":            "The connection works, but requests are too frequent (429).\n\nThe rate limit depends on how much you have topped up: the free tier allows only 3 RPM, and at least ¥50 is needed for normal use.",
+	"remedy.recharge":                "Open top-up page",
+
+	// 激活
+	"activation.hint":    "Enter your activation code to get started. The format is CK2025-XXXX-XXXX-XXXX. Activation is saved on this computer, so you only need to enter it once.",
+	"activation.invalid": "Invalid activation code. Please check that you entered it completely",
+	"activation.samples": "Sample activation codes (dev mode, click to fill in):",
+	"activation.status":  "🔑 Activated: %s (%s)",
+	"button.activate":    "Activate",
+	"error.activation":   "Failed to save activation: %v",
+
+	// ANTHROPIC_* 环境变量冲突
+	"env_conflict.title":  "Conflicting environment variables found",
+	"env_conflict.hint":   "The ANTHROPIC_* definitions below were not written by this tool and may override the K2 configuration, sending Claude Code to another service or breaking authentication. Cleaning up comments out these lines in your config files and removes the matching environment variables.",
+	"env_conflict.clean":  "Clean up and continue",
+	"env_conflict.ignore": "Ignore and continue",
+
+	// 检测环境
+	"verify.title":     "Environment check",
+	"verify.running":   "Checking the environment...",
+	"verify.healthy":   "✅ Everything is in place. Claude Code is ready to use in a new terminal.",
+	"verify.unhealthy": "⚠️ The environment is incomplete. Run the installer again to fix the failed items below.",
+	"verify.source":    "Environment variables read from: %s",
+
+	// 其他对话框
+	"link.copied_title":  "Link copied",
+	"link.copied":        "Could not open the browser. The link was copied to the clipboard:\n%s",
+	"error.restore":      "Failed to restore config: %v",
+	"dialog.success":     "Success",
+	"restore.done":       "✅ Claude Code config has been restored!",
+	"error.project_dir":  "Project folder does not exist: %s",
+	"error.open_claude":  "Could not open Claude Code: %v",
+	"open.started":       "Claude Code has started!\nEnvironment variables are set to the K2 API.",
+	"error.unsupported":  "Unsupported operating system or the terminal could not be started",
+	"launch.no_terminal": "No terminal emulator found (gnome-terminal, konsole, xterm, ...). Open a terminal and run:",
+	"wechat.title":       "Join the WeChat group",
+	"wechat.header":      "## WeChat ID copied to the clipboard\n",
+	"wechat.body":        "**WeChat ID**: ruan11223344\n\nScan the QR code to join the group, or search for the WeChat ID to add a friend.\nJoin us to learn about the latest AI together!",
+
+	// 安装器步骤名
+	"step.check_system":   "Check system",
+	"step.check_node":     "Detect Node.js",
+	"step.install_node":   "Install Node.js",
+	"step.check_git":      "Detect Git",
+	"step.install_git":    "Install Git",
+	"step.check_npm":      "Detect npm",
+	"step.install_claude": "Install Claude Code",
+	"step.verify":         "Verify installation",
+
+	// Installer step progress
+	"step_progress.running":      "%s...",
+	"step_progress.done":         "%s: done",
+	"step_progress.already_done": "%s: already done, skipped",
+	"step_progress.not_passed":   "%s: not passed, continuing",
+	"step_progress.failed":       "%s failed: %v",
+	"step_progress.all_done":     "All components installed!",
+
+	// 使用教程
+	"tutorial.title":     "Tutorial",
+	"tutorial.prev":      "Back",
+	"tutorial.next":      "Next",
+	"tutorial.preview":   "Image preview",
+	"tutorial.zoom_tip":  "💡 Click the image to enlarge it",
+	"tutorial.zoom_fit":  "Fit to window",
+	"tutorial.scan_open": "📱 Open on your phone",
+	"tutorial.welcome":   "Welcome to the Claude Code + K2 Setup Tool",
+	"tutorial.welcome_body": `This tool installs and configures Claude Code with the Kimi K2 model in one click.
+
+Features:
+• Detects and installs the required dependencies (Node.js, Git)
+• Installs the Claude Code CLI in one click
+• Configures the Kimi K2 API automatically
+• No complicated commands to type
+
+Click "Next" to learn more.`,
+	"tutorial.claude_code": "What is Claude Code?",
+	"tutorial.claude_code_body": `Claude Code is Anthropic's official AI coding assistant.
+
+Highlights:
+• Powered by Claude models
+• Supports many programming languages
+• Understands your project context
+• Offers smart completions and refactoring suggestions
+
+With the Kimi K2 model you get a more cost-effective experience.`,
+	"tutorial.k2": "About the Kimi K2 model",
+	"tutorial.k2_body": `Kimi K2 is the new-generation large language model from Moonshot AI.
+
+Highlights:
+• A very large model with 1T parameters
+• Capabilities between Claude 3.7 and Claude 4
+• Provides a Claude-compatible API
+• Excellent value for money
+
+New accounts get ¥15 of credit; topping up ¥50 is enough for regular use.`,
+	"tutorial.get_key": "Get a Kimi API Key",
+	"tutorial.get_key_body": `To use the Kimi K2 model you need an API Key:
+
+1. Visit https://platform.moonshot.cn/console/account
+2. Sign up or log in
+3. Top up at least ¥50 (to avoid RPM limits)
+4. Create a new key on the API Key page
+5. Copy the key that starts with sk
+
+Paste the API Key into this tool and it will be configured automatically.`,
+	"tutorial.charge": "Important: top up first",
+	"tutorial.charge_body": `⚠️ Important: top up before you start!
+
+Free account limits:
+• Only 3 requests per minute (RPM)
+• Not enough for normal Claude Code use
+• Frequent 429 errors
+
+Recommended:
+• Top up at least ¥50 for smooth use
+• Your RPM limit rises to 200 after topping up
+• This keeps the tool working reliably`,
+	"tutorial.charge_button": "Top up",
+	"tutorial.key_page":      "Step 1: Open the API Key page",
+	"tutorial.key_page_body": `After logging in to the Kimi platform, click "API Key 管理" (API Keys) in the left menu.
+
+In the top-right corner, click "新建 API Key" (New API Key), as shown by the red arrow below.`,
+	"tutorial.key_page_button": "Open the API Key page",
+	"tutorial.create_key":      "Step 2: Create a new API Key",
+	"tutorial.create_key_body": `In the dialog that opens:
+
+1. Enter a name for the API Key (the default is fine)
+2. Choose a project (default is "default")
+3. Click "确定" (OK) to create it
+
+Note: make sure you have topped up first, otherwise the key will not work.`,
+	"tutorial.save_key": "Step 3: Save your API Key",
+	"tutorial.save_key_body": `⚠️ Important: copy and save your API Key right away!
+
+• The key is shown only once
+• You cannot view it again after closing the dialog
+• Keep the key somewhere safe
+
+Copy the full key starting with sk- and paste it into the API Key field of this tool.`,
+	"tutorial.first_run": "Step 4: Run Claude Code for the first time",
+	"tutorial.first_run_body": `When Claude Code starts, it detects your custom API Key:
+
+1. It shows the detected API Key (as in the image)
+2. It asks whether to use this API Key
+3. Press the ↑ key to select "1. Yes"
+4. Then press Enter to continue
+
+⚠️ Important:
+• Make sure to choose "Yes" to use the detected K2 API
+• The Kimi K2 API has already been configured for you
+• If you choose No, the configured K2 service will not be used`,
+	"tutorial.usage": "After installation",
+	"tutorial.usage_body": `After installation you can:
+
+1. Run 'claude' in a terminal to start Claude Code
+2. Use Claude Code for AI-assisted programming
+3. Enjoy the value of the K2 model
+
+Common commands:
+• claude - start interactive mode
+• claude --help - show help
+• claude --version - show the version
+
+Enjoy!`,
+	"tutorial.faq":           "FAQ",
+	"tutorial.faq_body":      "Look here first when something goes wrong. Click a question to see the answer.",
+	"faq.search":             "Search, e.g. 429 or command not found",
+	"faq.no_match":           "No matching question. Export the logs from the main window and contact the author with them.",
+	"faq.429":                "What does 429 / too many requests mean?",
+	"faq.429_answer":         "429 means you hit the provider's rate limit. Kimi's RPM depends on your total top-up: the free tier allows only 3 RPM, and at least ¥50 is needed for normal use. After topping up, set the matching RPM in the main window and configure again.",
+	"faq.not_found":          "The terminal says claude: command not found",
+	"faq.not_found_answer":   "Claude Code is installed in the npm global directory, but that directory is not on the current terminal's PATH. Close and reopen the terminal; if it is still missing, click the button below to check Claude Code and PATH.",
+	"faq.not_applied":        "Claude Code still asks me to log in to Anthropic",
+	"faq.not_applied_answer": "Terminals that were already open do not pick up new environment variables. Copy the activation command below and run it in the terminal, or reopen the terminal and run claude again.",
+	"faq.change_key":         "How do I change the API key?",
+	"faq.change_key_answer":  "Enter the new key in the API key field of the main window, click Test connection to make sure it works, then click Configure API only. Installed components are not installed again. If the installed state is shown, click Reinstall and then Install.",
+	"faq.401":                "401 / invalid API key",
+	"faq.401_answer":         "The provider rejected this API key. Make sure you copied the whole key starting with sk- and that it was not deleted in the console; create a new key and configure again if needed.",
+	"faq.balance":            "Insufficient balance or quota used up",
+	"faq.balance_answer":     "Your account balance is used up. Top up and keep using it; no need to configure again.",
+	"faq.do_recharge":        "💳 Open top-up page",
+	"faq.do_api_keys":        "🔑 Open API key page",
+	"faq.do_verify":          "Check environment",
+	"faq.do_copy_command":    "Copy activation command",
+
+	// Profiles
+	"profile.placeholder":      "Choose a saved profile",
+	"profile.save":             "Save as profile",
+	"profile.label":            "Profile:",
+	"profile.name":             "Name",
+	"profile.name_placeholder": "e.g. Personal Kimi, Work DeepSeek",
+	"profile.save_title":       "Save Profile",
+	"profile.delete_title":     "Delete Profile",
+	"profile.select_first":     "Select a profile to delete first",
+	"profile.delete_confirm":   "Delete profile \"%s\"?",
+	"button.save":              "Save",
+	"button.delete":            "Delete",
+	"error.profile_name":       "Profile name cannot be empty",
+	"error.profile_save":       "Failed to save profile: %v",
+	"error.profile_delete":     "Failed to delete profile: %v",
+
+	// Connection test
+	"connection.testing":            "Testing connection...",
+	"connection.rate_limited":       "⚠️ Connection test hit the rate limit",
+	"connection.rate_limited_title": "Rate Limited",
+	"connection.failed":             "❌ Connection test failed",
+	"connection.failed_title":       "Connection Test Failed",
+	"connection.success":            "✅ Connection test passed",
+	"connection.success_title":      "Connection Test Passed",
+	"connection.success_message":    "Claude Code got a valid response through %s. You're ready to go.",
+	"button.retest":                 "Test Again",
+	"error.api_key_empty":           "Please enter an API key",
+
+	// Uninstall
+	"uninstall.plan":         "The following will be removed:\n\n• %s\n\nNode.js and Git will be kept.",
+	"uninstall.remove_data":  "Also remove install logs, environment snapshots and the download cache (~/.claude-k2-installer)",
+	"uninstall.title":        "Uninstall Claude Code",
+	"uninstall.running":      "Uninstalling...",
+	"uninstall.incomplete":   "⚠️ Uninstall incomplete",
+	"uninstall.done":         "✅ Uninstall complete",
+	"uninstall.done_title":   "Uninstall Complete",
+	"uninstall.done_message": "Claude Code has been uninstalled and the K2 configuration removed.\n\nReopen your terminal for the environment variable changes to take effect.",
+	"error.uninstall":        "Uninstall failed: %v",
+
+	// Logs & privacy
+	"log_policy.off":        "Don't save to disk (logs only shown in the window)",
+	"log_policy.session":    "This session only (deleted on exit)",
+	"log_policy.days":       "Keep for a number of days",
+	"log_policy.forever":    "Keep forever",
+	"log_policy.full_args":  "Log full command arguments (may include sensitive data such as the API key)",
+	"log_policy.intro":      "Install logs and environment snapshots are saved in the .claude-k2-installer folder in your home directory to help troubleshoot problems.",
+	"log_policy.first_run":  "%s\nDefault policy: %s.\nYou can change it here, or later with the \"Logs & privacy\" button.",
+	"log_policy.retention":  "Log retention",
+	"log_policy.days_label": "Days to keep:",
+	"log_policy.updated":    "Log policy updated: %s",
+	"button.use_default":    "Use Default",
+	"error.log_days":        "Days to keep must be a positive integer",
+
+	// QR codes
+	"qr.scan_heading": "## Scan the QR code with your phone\n",
+	"qr.scan_body":    "Finish in your phone's browser after scanning\n\n%s",
+	"error.qr_code":   "Failed to generate QR code: %v",
+
+	// Config folder and log export
+	"config_folder.copied_title": "Path Copied",
+	"config_folder.copied":       "Could not open the file manager. The config folder path was copied to the clipboard:\n%s",
+	"config_folder.files":        "Config files written",
+	"export_log.done_title":      "Export Complete",
+	"export_log.done":            "Log saved to:\n%s\n\nPlease attach this file when reporting a problem.",
+	"error.home_dir":             "Failed to get home directory: %v",
+	"error.export_log":           "Failed to export log: %v",
+
+	// Terminal
+	"terminal.auto":         "Auto-detect",
+	"error.launcher_script": "Failed to write launcher script: %v",
+}
@@ -0,0 +1,100 @@
+// Package i18n 界面和日志文案的多语言支持
+//
+// 文案按消息 ID 存放在各语言的表中，通过 T 按当前语言取出。
+// 当前语言缺少的消息回退到简体中文，仍缺少时返回消息 ID 本身。
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Locale 语言代码
+type Locale string
+
+const (
+	ZhCN Locale = "zh-CN"
+	EnUS Locale = "en-US"
+)
+
+// DefaultLocale 默认语言
+const DefaultLocale = ZhCN
+
+// EnvVar 指定界面语言的环境变量，优先于界面中保存的选择
+const EnvVar = "CLAUDE_K2_LANG"
+
+// Locales 支持的语言，按界面中的显示顺序排列
+var Locales = []Locale{ZhCN, EnUS}
+
+var tables = map[Locale]map[string]string{
+	ZhCN: zhCN,
+	EnUS: enUS,
+}
+
+var (
+	mu      sync.RWMutex
+	current = DefaultLocale
+)
+
+// SetLocale 设置当前语言，不支持的语言忽略
+func SetLocale(locale Locale) {
+	if _, ok := tables[locale]; !ok {
+		return
+	}
+	mu.Lock()
+	current = locale
+	mu.Unlock()
+}
+
+// Current 返回当前语言
+func Current() Locale {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Parse 解析语言代码，兼容 en、en_US.UTF-8、zh-cn 等写法
+func Parse(value string) (Locale, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if idx := strings.IndexAny(value, ".@"); idx >= 0 {
+		value = value[:idx]
+	}
+	switch {
+	case strings.HasPrefix(value, "zh"):
+		return ZhCN, true
+	case strings.HasPrefix(value, "en"):
+		return EnUS, true
+	}
+	return "", false
+}
+
+// FromEnv 返回环境变量 CLAUDE_K2_LANG 指定的语言
+func FromEnv() (Locale, bool) {
+	return Parse(os.Getenv(EnvVar))
+}
+
+// DisplayName 语言在选择框中显示的名称
+func (l Locale) DisplayName() string {
+	switch l {
+	case EnUS:
+		return "English"
+	default:
+		return "简体中文"
+	}
+}
+
+// T 按当前语言返回消息 ID 对应的文案，有参数时按 fmt.Sprintf 格式化
+func T(id string, args ...any) string {
+	text, ok := tables[Current()][id]
+	if !ok {
+		if text, ok = zhCN[id]; !ok {
+			text = id
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
@@ -0,0 +1,52 @@
+package i18n
+
+import "testing"
+
+func TestTablesHaveSameKeys(t *testing.T) {
+	for id := range zhCN {
+		if _, ok := enUS[id]; !ok {
+			t.Errorf("en-US is missing message %q", id)
+		}
+	}
+	for id := range enUS {
+		if _, ok := zhCN[id]; !ok {
+			t.Errorf("zh-CN is missing message %q", id)
+		}
+	}
+}
+
+func TestTFallsBack(t *testing.T) {
+	SetLocale(EnUS)
+	defer SetLocale(DefaultLocale)
+
+	if got := T("button.install"); got != "Install" {
+		t.Errorf("T(button.install) = %q, want %q", got, "Install")
+	}
+	if got := T("env.too_old", "v16.0.0"); got != "v16.0.0 (too old)" {
+		t.Errorf("T(env.too_old) = %q", got)
+	}
+	if got := T("no.such.message"); got != "no.such.message" {
+		t.Errorf("unknown message should return its ID, got %q", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Locale
+		ok    bool
+	}{
+		{"en", EnUS, true},
+		{"en_US.UTF-8", EnUS, true},
+		{"zh-cn", ZhCN, true},
+		{"zh_CN.UTF-8", ZhCN, true},
+		{"", "", false},
+		{"fr_FR", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %q, %v; want %q, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+package i18n
+
+// zhCN 简体中文文案
+var zhCN = map[string]string{
+	// 主界面
+	"app.title":    "Claude Code + K2 环境集成工具",
+	"app.subtitle": "一键安装配置 Claude Code 和 Kimi K2 开发环境",
+	"app.wechat":   "🤖 加微信: ruan11223344 进群分享最新AI知识，一起学习进步 (点击复制)",
+
+	"status.ready":     "准备就绪",
+	"log.placeholder":  "安装日志将显示在这里...",
+	"log.copied_title": "复制成功",
+	"log.copied":       "日志已复制到剪贴板，可以直接粘贴发送。",
+
+	"label.provider":           "服务商:",
+	"label.language":           "语言:",
+	"apikey.placeholder":       "请输入API Key",
+	"button.get_api_key":       "🔑 点击获取 API Key",
+	"button.scan":              "📱 扫码",
+	"qr.api_key_title":         "手机获取 API Key",
+	"button.restore_config":    "🔄 恢复Claude配置",
+	"rpm.info":                 "免费: 3 | ¥50: 200 | ¥100: 500 | ¥500+: 5000",
+	"rpm.delay":                "⏱ 请求间隔 %d 毫秒（写入 CLAUDE_REQUEST_DELAY_MS）",
+	"rpm.paid_tier":            "ℹ️ %d RPM 超过免费额度 %d RPM，需要账户充值达到 %s 档位，否则会频繁出现 429 错误",
+	"rpm.above_max":            "ℹ️ %d RPM 超过 %s 公布的最高档位（%s: %d RPM），超出部分可能触发 429 错误",
+	"rpm.desc":                 "* 速率限制基于Kimi充值额度，实测最少充值50元才不会影响使用",
+	"button.charge":            "💳 打开Kimi充值链接",
+	"qr.charge_title":          "手机充值",
+	"label.rpm":                "速率限制 (RPM):",
+	"check.system_config":      "永久设置K2环境变量（推荐 - 写入.bashrc/.zshrc/Windows环境变量）",
+	"check.dry_run":            "模拟运行",
+	"help.env_var":             "✓ 勾选：永久设置（写入配置文件）  ✗ 不勾选：仅当前进程",
+	"help.node_version":        "仅在未检测到 Node.js 时使用，例如 22.11.0（Linux 使用系统软件源时不生效）",
+	"check.npm_sudo":           "npm 全局目录无写权限时使用 sudo 安装（仅 Linux，默认改用 ~/.npm-global）",
+	"label.terminal":           "终端应用:",
+	"advanced.title":           "高级选项",
+	"label.node_version":       "Node.js 版本:",
+	"label.claude_version":     "Claude Code 版本:",
+	"label.project_dir":        "项目目录:",
+	"project.placeholder":      "打开 Claude Code 时进入的目录，留空为用户目录",
+	"label.proxy":              "HTTP 代理:",
+	"check.env_only":           "仅配置环境变量（不修改 ~/.claude.json，保留 Claude Code 的引导和项目状态）",
+	"label.config_strategy":    "环境变量写入:",
+	"config_strategy.shell":    "shell 配置文件（.bashrc/.zshrc/Windows 环境变量）",
+	"config_strategy.settings": "~/.claude/settings.json（从图形界面启动也能生效）",
+	"config_strategy.both":     "两者都写入",
+	"check.force_ipv4":         "只使用 IPv4 连接（IPv6 网络不通、下载经常卡住时勾选）",
+	"check.slow_network":       "慢速网络模式（下载超时放宽到 60 分钟，2 分钟无数据才判定停滞）",
+	"button.clear_cache":       "清除缓存",
+	"cache.size":               "下载缓存: %.1f MB",
+	"cache.size_unknown":       "下载缓存: 无法统计大小",
+	"cache.confirm":            "删除已缓存的 Node.js 和 Git 安装包？下次安装时会重新下载。",
+	"error.clear_cache":        "清除缓存失败: %v",
+	"proxy.placeholder":        "如 http://127.0.0.1:7890，留空时使用系统代理环境变量",
+	"label.offline_dir":        "离线安装包目录:",
+	"offline.placeholder":      "留空则联网下载",
+	"button.browse":            "选择...",
+	"help.offline_dir":         "目录中放入 Node.js 安装包（如 node-v22.11.0-x64.msi）、Git 安装包（Windows）和 npm pack 生成的 anthropic-ai-claude-code-<版本>.tgz，可附带 SHASUMS256.txt 校验",
+	"label.appearance":         "外观:",
+	"appearance.system":        "跟随系统",
+	"appearance.light":         "浅色",
+	"appearance.dark":          "深色",
+	"label.ui_scale":           "界面缩放:",
+	"check.high_contrast":      "高对比度模式（适合低视力或强光环境）",
+	"button.install":           "开始安装",
+	"button.configure_only":    "仅配置 API",
+	"button.tutorial":          "查看教程",
+	"button.log_policy":        "日志与隐私",
+	"button.uninstall":         "卸载",
+	"button.test_connection":   "测试连接",
+	"button.verify":            "检测环境",
+	"button.open_claude":       "打开 Claude Code",
+	"button.reinstall":         "重新安装",
+	"button.update_claude":     "更新 Claude Code",
+	"button.retry_update":      "重试更新",
+	"button.export_logs":       "导出日志",
+	"button.copy_logs":         "复制日志",
+	"button.diagnostics":       "生成诊断报告",
+	"report.title":             "安装报告（用时 %s）",
+	"report.components":        "组件:",
+	"report.ok":                "已安装",
+	"report.failed":            "未安装或版本过低",
+	"report.skipped":           "已跳过",
+	"report.config_files":      "写入的配置文件:",
+	"report.env_vars":          "设置的环境变量（%s）:",
+	"report.permanent":         "永久生效",
+	"report.temporary":         "仅当前终端",
+	"report.warnings":          "警告（%d 条）:",
+	"report.error":             "失败原因: %s",
+	"report.export":            "导出日志和报告",
+	"button.open_config_dir":   "打开配置目录",
+	"button.copy":              "复制",
+	"button.ok":                "确定",
+	"button.close":             "关闭",
+	"button.cancel":            "取消",
+	"section.config":           "配置信息",
+	"section.progress":         "安装进度",
+	"section.logs":             "安装日志",
+	"language.restart":         "语言设置将在重新启动程序后生效。",
+
+	// 步骤卡片
+	"steps.title":          "安装步骤",
+	"steps.subtitle":       "本工具将自动完成以下步骤：",
+	"steps.check_system":   "1. 检查系统环境",
+	"steps.install_node":   "2. 自动安装 Node.js (如未安装)",
+	"steps.install_git":    "3. 自动安装 Git (如未安装)",
+	"steps.install_claude": "4. 安装 Claude Code CLI 工具",
+	"steps.configure_api":  "5. 配置 Kimi K2 API",
+	"steps.verify":         "6. 验证环境配置",
+	"steps.skipped":        "%s（已跳过）",
+	"steps.skip":           "跳过",
+	"env.detecting":        "正在检测已安装的组件...",
+	"env.not_installed":    "未安装",
+	"env.too_old":          "%s（版本过低）",
+	"env.detected":         "已检测到：Node.js %s · Git %s · Claude Code %s",
+	"env.ready":            "环境已完整安装，只需配置 API 即可使用。",
+	"env.last_install":     "上次安装成功：%s（%s）\nNode.js %s · Git %s · Claude Code %s",
+	"log.skip_install":     "已检测到完整环境，跳过安装，直接配置 API...",
+	"log.configuring":      "配置 K2 API...",
+
+	// 安装流程
+	"error.api_key_required":         "请输入 Kimi K2 API Key",
+	"confirm.api_key_title":          "确认 API Key",
+	"confirm.api_key_format":         "密钥格式看起来不对，仍要继续吗？\n\n%v",
+	"error.rpm_not_number":           "速率限制必须是数字",
+	"error.install_panic":            "安装过程中发生错误: %v",
+	"status.install_failed":          "安装失败",
+	"status.error":                   "错误: %v",
+	"status.configuring":             "配置 K2 API...",
+	"status.install_ok_api_failed":   "⚠️ 安装完成，但 API 配置失败",
+	"status.all_done":                "✅ 安装和配置全部完成！",
+	"status.api_failed":              "⚠️ API 配置失败",
+	"error.api_failed":               "API 配置失败: %v",
+	"status.api_done":                "✅ API 配置完成！",
+	"dialog.api_done_title":          "配置完成",
+	"dialog.api_done":                "API 已配置完成！\n\n点击「打开 Claude Code」按钮开始使用。",
+	"dialog.install_failed_title":    "安装失败",
+	"dialog.retry":                   "%v\n\n可以从「%s」重试，已完成的步骤不会重复执行。",
+	"button.retry_step":              "重试此步骤",
+	"status.retrying":                "正在重试%s...",
+	"status.install_done":            "✅ 安装完成！",
+	"status.installed":               "✅ 已安装，可直接打开 Claude Code",
+	"status.updating":                "正在更新 Claude Code...",
+	"status.update_failed":           "⚠️ Claude Code 更新失败",
+	"dialog.update_done":             "更新完成",
+	"dialog.update_failed":           "更新失败",
+	"change_key.title":               "更换 API Key",
+	"button.change_key":              "更换 API Key",
+	"change_key.hint":                "输入新的 API Key。将删除旧的 K2 配置（配置文件、环境变量）并用新的 Key 重新配置和验证，不会重新安装任何组件。",
+	"change_key.placeholder":         "新的 API Key",
+	"change_key.retry":               "重新输入",
+	"status.changing_key":            "正在更换 API Key...",
+	"status.change_key_failed":       "⚠️ 更换 API Key 失败",
+	"status.key_changed":             "✅ API Key 已更换",
+	"dialog.change_key_failed":       "更换 API Key 失败",
+	"dialog.key_changed":             "API Key 已更换",
+	"dialog.key_changed_relaunch":    "新的 API Key 已配置并验证通过。\n\n已打开的 Claude Code 仍在使用旧的 Key，是否现在重新打开 Claude Code？",
+	"dialog.diagnostics_saved_title": "诊断报告已保存",
+	"dialog.diagnostics_saved":       "诊断报告已保存到：\n%s\n\n报告中的密钥已隐藏，反馈问题时请附上此文件。",
+	"error.diagnostics":              "生成诊断报告失败: %v",
+	"dialog.install_done_title":      "安装完成",
+	"dialog.install_done":            "Claude Code + K2 环境已成功安装！\n\n点击「打开 Claude Code」按钮开始使用。",
+	"complete.hint_permanent":        "已打开的终端需要先执行以下命令，否则 claude 仍会使用原来的配置：",
+	"complete.hint_settings":         "配置已写入 ~/.claude/settings.json，Claude Code 启动时自动读取，在任意终端中直接运行：",
+	"complete.hint_temp":             "未永久设置环境变量，在终端中执行以下命令启用 K2 并启动 Claude Code：",
+	"dryrun.nothing":                 "所有组件均已安装，无需改动。",
+	"dryrun.components":              "以下组件将被安装或修改：\n\n• ",
+	"dryrun.title":                   "模拟运行完成",
+	"dryrun.footer":                  "\n\n详细操作见安装日志。取消勾选「模拟运行」后再次点击「开始安装」即可正式安装。",
+
+	// shell 配置修改预览
+	"shell_preview.title":       "确认修改配置文件",
+	"shell_preview.hint":        "将在以下文件中写入 K2 环境变量（- 为删除的行，+ 为新增的行，API Key 已打码）。取消后可以去掉「永久设置」勾选，改用临时脚本。",
+	"shell_preview.confirm":     "确认写入",
+	"shell_preview.create":      "新建 %s",
+	"shell_preview.replace":     "替换 %s 中已有的 K2 配置",
+	"shell_preview.append":      "追加到 %s",
+	"shell_preview.read_failed": "跳过 %s（读取失败: %v）",
+
+	// 自动更新
+	"update.available":         "🎉 有新版本可用: v%s（当前 v%s）",
+	"update.install":           "立即更新",
+	"update.notes":             "更新说明",
+	"update.downloading_title": "正在更新",
+	"update.downloading":       "正在下载 %s ...",
+	"update.done_title":        "更新完成",
+	"update.done":              "已更新到 v%s，重新启动后生效。是否立即重新启动？",
+
+	// 管理员权限
+	"elevation.title":                "需要管理员权限",
+	"elevation.hint":                 "以下组件的安装需要管理员权限，安装过程中会弹出密码输入框，请留意并输入密码：",
+	"elevation.hint_windows":         "当前程序没有以管理员身份运行，以下组件的安装需要管理员权限，可能在下载完成后才失败。建议以管理员身份重新启动：",
+	"elevation.relaunch":             "以管理员身份重新启动",
+	"elevation.continue":             "仍然继续",
+	"remedy.network":                 "无法从下载服务器获取安装包。请检查网络连接；公司网络或需要代理才能访问外网时，在「高级选项 → HTTP 代理」中填写代理地址后重试。",
+	"remedy.open_proxy":              "设置代理",
+	"remedy.elevation":               "这一步需要管理员权限。请在弹出密码框时输入登录密码，或在终端中用 sudo 运行本程序后重试。",
+	"error.user_cancelled":           "您取消了授权，安装未完成",
+	"remedy.user_cancelled":          "您在密码框中点击了取消。已下载的安装包已保留，点击重试后在弹出的密码框中输入登录密码即可继续，无需重新下载。",
+	"remedy.elevation_win":           "这一步需要管理员权限，当前程序没有以管理员身份运行。请以管理员身份重新启动后再安装。",
+	"remedy.npm":                     "npm 全局目录没有写入权限。请修改 npm 全局目录的所有者，或以管理员身份运行后重试。",
+	"remedy.npm_win":                 "npm 全局目录没有写入权限。请以管理员身份重新启动后再安装。",
+	"remedy.git_too_old":             "已安装的 Git 版本过低，Claude Code 的部分 git 操作无法使用，自动安装也没有得到新版本。请从 git-scm.com 安装新版本 Git（Linux 上可使用发行版的软件源或 IUS 等第三方源），或勾选 Git 步骤旁的「跳过」自行管理。",
+	"remedy.node_too_old":            "已安装的 Node.js 低于 Claude Code 要求的版本，自动升级没有成功。请从 nodejs.org 安装新版本（使用 nvm 等版本管理器时切换到新版本），或取消 Node.js 步骤旁的「跳过」后重试。",
+	"node_upgrade.title":             "Node.js 版本过低",
+	"node_upgrade.message":           "检测到 Node.js %s，低于 Claude Code 要求的 v%d。\n\n安装时将升级到 v%s。",
+	"node_upgrade.message_skipped":   "检测到 Node.js %s，低于 Claude Code 要求的 v%d。\n\nNode.js 步骤已设为跳过，不升级的话安装后 Claude Code 将无法运行。是否升级到 v%s？",
+	"node_upgrade.upgrade":           "升级 Node.js",
+	"node_upgrade.keep":              "不升级，继续",
+	"remedy.antivirus":               "Windows Defender 或其他杀毒软件可能隔离或阻止了下载的安装程序（程序已自动重试一次）。请在「保护历史记录」中还原并允许该文件，或临时关闭实时保护、将 %TEMP% 和 ~/.claude-k2-installer 添加到排除项后重试。安装包来自官方镜像，Git 安装包已校验数字签名。",
+	"remedy.open_protection_history": "打开保护历史记录",
+	"button.manual_guide":            "生成手动安装指南",
+	"manual_guide.title":             "手动安装指南",
+	"manual_guide.generating":        "正在检测系统和网络，生成手动安装指南...",
+	"manual_guide.hint":              "以下步骤根据您的系统和失败的步骤生成，按顺序执行即可完成安装。API Key 已用占位符代替，可以放心导出或发给他人协助。",
+	"manual_guide.copy":              "复制",
+	"manual_guide.copied":            "手动安装指南已复制到剪贴板",
+	"manual_guide.export":            "导出",
+	"manual_guide.saved":             "手动安装指南已保存到:\n%s",
+	"manual_guide.suggest":           "自动安装已连续失败 %d 次。可以生成手动安装指南，按照适用于您系统的步骤自行完成安装。",
+	"error.manual_guide":             "保存手动安装指南失败: %v",
+	"remedy.untrusted":               "下载的安装包没有官方数字签名，镜像可能提供了被篡改的文件，程序已拒绝运行。请稍后重试，或设置代理改用其他下载源。",
+	"remedy.npm_sudo":                "npm 全局目录属于 root，当前用户没有写入权限。可以使用 sudo 安装到该目录（需要输入密码）。",
+	"remedy.retry_sudo":              "使用 sudo 重试",
+	"remedy.api_key":                 "服务商拒绝了这个 API Key。请确认复制了完整的密钥且没有被删除，必要时创建新的密钥后重新填写。",
+	"remedy.balance":                 "账户余额不足或额度已用完，充值后即可继续使用。",
+	"remedy.rate_limited":            "连接正常，但请求过于频繁 (429)。\n\n速率限制由充值额度决定，免费额度只有 3 RPM，实测至少充值 50 元才不影响使用。",
+	"remedy.recharge":                "打开充值页面",
+
+	// 激活
+	"activation.hint":    "首次使用请输入激活码，格式为 CK2025-XXXX-XXXX-XXXX。激活信息保存在本机，之后启动不再需要输入。",
+	"activation.invalid": "激活码无效，请检查是否完整输入",
+	"activation.samples": "示例激活码（开发模式，点击填入）:",
+	"activation.status":  "🔑 已激活: %s（%s）",
+	"button.activate":    "激活",
+	"error.activation":   "保存激活信息失败: %v",
+
+	// ANTHROPIC_* 环境变量冲突
+	"env_conflict.title":  "发现冲突的环境变量",
+	"env_conflict.hint":   "以下 ANTHROPIC_* 定义不是本工具写入的，可能覆盖 K2 配置，导致 Claude Code 连接到其他服务或认证失败。清理会注释掉配置文件中的这些行并移除对应的环境变量。",
+	"env_conflict.clean":  "先清理再继续",
+	"env_conflict.ignore": "忽略并继续",
+
+	// 检测环境
+	"verify.title":     "环境检测结果",
+	"verify.running":   "正在检测环境...",
+	"verify.healthy":   "✅ 环境正常，新打开的终端可以直接使用 Claude Code。",
+	"verify.unhealthy": "⚠️ 环境不完整，可以重新运行安装修复以下未通过的项目。",
+	"verify.source":    "环境变量来源: %s",
+
+	// 其他对话框
+	"link.copied_title":  "链接已复制",
+	"link.copied":        "无法自动打开浏览器，链接已复制到剪贴板:\n%s",
+	"error.restore":      "恢复配置失败: %v",
+	"dialog.success":     "成功",
+	"restore.done":       "✅ Claude Code 配置已恢复到初始状态！",
+	"error.project_dir":  "项目目录不存在: %s",
+	"error.open_claude":  "无法打开 Claude Code: %v",
+	"open.started":       "Claude Code 已启动！\n环境变量已自动设置为K2 API。",
+	"error.unsupported":  "不支持的操作系统或无法启动终端",
+	"launch.no_terminal": "未找到可用的终端模拟器（gnome-terminal、konsole、xterm 等），请打开终端执行：",
+	"wechat.title":       "加微信进群",
+	"wechat.header":      "## 微信号已复制到剪贴板\n",
+	"wechat.body":        "**微信号**: ruan11223344\n\n可以扫描二维码直接进群，或搜索微信号添加好友\n进群分享最新AI知识，一起学习进步！",
+
+	// 安装器步骤名
+	"step.check_system":   "检查系统环境",
+	"step.check_node":     "检测 Node.js",
+	"step.install_node":   "安装 Node.js",
+	"step.check_git":      "检测 Git",
+	"step.install_git":    "安装 Git",
+	"step.check_npm":      "检测 npm",
+	"step.install_claude": "安装 Claude Code",
+	"step.verify":         "验证安装",
+
+	// 安装器步骤进度
+	"step_progress.running":      "正在%s...",
+	"step_progress.done":         "%s完成",
+	"step_progress.already_done": "%s已完成，跳过",
+	"step_progress.not_passed":   "%s未通过，继续安装",
+	"step_progress.failed":       "%s失败: %v",
+	"step_progress.all_done":     "所有组件安装完成！",
+
+	// 使用教程
+	"tutorial.title":     "使用教程",
+	"tutorial.prev":      "上一步",
+	"tutorial.next":      "下一步",
+	"tutorial.preview":   "图片预览",
+	"tutorial.zoom_tip":  "💡 点击图片可放大查看",
+	"tutorial.zoom_fit":  "适应窗口",
+	"tutorial.scan_open": "📱 手机扫码打开",
+	"tutorial.welcome":   "欢迎使用 Claude Code + K2 集成工具",
+	"tutorial.welcome_body": `本工具将帮助你一键安装和配置 Claude Code 与 Kimi K2 大模型环境。
+
+主要功能：
+• 自动检测并安装必要的依赖（Node.js、Git）
+• 一键安装 Claude Code CLI 工具
+• 自动配置 Kimi K2 API
+• 无需手动输入复杂命令
+
+点击"下一步"继续了解详细信息。`,
+	"tutorial.claude_code": "什么是 Claude Code？",
+	"tutorial.claude_code_body": `Claude Code 是 Anthropic 官方推出的 AI 编程助手工具。
+
+特点：
+• 使用强大的 Claude 模型
+• 支持多种编程语言
+• 可以理解项目上下文
+• 提供智能代码补全和重构建议
+
+通过集成 Kimi K2 模型，可以获得更高性价比的使用体验。`,
+	"tutorial.k2": "Kimi K2 模型介绍",
+	"tutorial.k2_body": `Kimi K2 是月之暗面推出的新一代大语言模型。
+
+技术特性：
+• 1T 参数量的超大模型
+• 能力介于 Claude 3.7 和 Claude 4 之间
+• 提供兼容 Claude API 的接口
+• 性价比极高
+
+注册即送 15 元额度，充值 50 元即可正常使用。`,
+	"tutorial.get_key": "获取 Kimi API Key",
+	"tutorial.get_key_body": `要使用 Kimi K2 模型，需要先获取 API Key：
+
+1. 访问 https://platform.moonshot.cn/console/account
+2. 注册或登录账号
+3. 充值至少 50 元（避免 RPM 限制）
+4. 在 API Key 管理页面创建新的 Key
+5. 复制 sk 开头的密钥
+
+将获取的 API Key 填入本工具即可自动配置。`,
+	"tutorial.charge": "重要提醒：请先充值",
+	"tutorial.charge_body": `⚠️ 重要提醒：使用前请先充值！
+
+免费账户限制：
+• RPM（每分钟请求数）仅为 3 次
+• 无法满足 Claude Code 正常使用需求
+• 会频繁出现 429 错误
+
+建议操作：
+• 实测最少充值 50 元才不会影响使用
+• 充值后 RPM 限制将提升至 200
+• 确保工具能够正常使用`,
+	"tutorial.charge_button": "前往充值",
+	"tutorial.key_page":      "步骤1：进入 API Key 管理页面",
+	"tutorial.key_page_body": `登录 Kimi 平台后，点击左侧菜单的"API Key 管理"。
+
+在页面右上角，点击"新建 API Key"按钮（如下图红色箭头所示）。`,
+	"tutorial.key_page_button": "打开 API Key 管理页面",
+	"tutorial.create_key":      "步骤2：创建新的 API Key",
+	"tutorial.create_key_body": `在弹出的对话框中：
+
+1. 输入 API Key 名称（如：这里使用默认）
+2. 选择项目（默认为 default）
+3. 点击"确定"按钮创建
+
+注意：创建前请确保已经充值，否则无法正常使用。`,
+	"tutorial.save_key": "步骤3：保存你的 API Key",
+	"tutorial.save_key_body": `⚠️ 重要：请立即复制并保存你的 API Key！
+
+• 密钥只会显示一次
+• 关闭对话框后将无法再次查看
+• 请将密钥保存在安全的地方
+
+复制 sk- 开头的完整密钥，然后将其粘贴到本工具的 API Key 输入框中。`,
+	"tutorial.first_run": "步骤4：首次运行 Claude Code",
+	"tutorial.first_run_body": `打开 Claude Code 后，会检测到你的自定义 API Key：
+
+1. 系统会显示检测到的 API Key（如图所示）
+2. 询问是否使用此 API Key
+3. 这里要按↑键盘，选择 "1. Yes"
+4. 然后按回车键继续
+
+⚠️ 重要提醒：
+• 一定要选择 "Yes" 使用检测到的 K2 API
+• 系统已经为你配置好了 Kimi K2 的 API
+• 如果选择 No，将无法使用配置好的 K2 服务`,
+	"tutorial.usage": "安装完成后的使用",
+	"tutorial.usage_body": `安装完成后，你可以：
+
+1. 在终端运行 'claude' 命令启动 Claude Code
+2. 使用 Claude Code 进行 AI 辅助编程
+3. 享受 K2 模型带来的高性价比体验
+
+常用命令：
+• claude - 启动交互模式
+• claude --help - 查看帮助信息
+• claude --version - 查看版本
+
+祝你使用愉快！`,
+	"tutorial.faq":           "常见问题",
+	"tutorial.faq_body":      "遇到问题时先在这里查找，点击问题展开答案。",
+	"faq.search":             "搜索问题，如 429、command not found",
+	"faq.no_match":           "没有找到相关问题。可以在主界面导出日志，附上日志联系作者。",
+	"faq.429":                "提示 429 / 请求过于频繁怎么办？",
+	"faq.429_answer":         "429 表示触发了服务商的速率限制。Kimi 的 RPM 由累计充值额度决定，免费额度只有 3 RPM，实测至少充值 50 元才不影响使用。充值后把主界面的速率限制改为对应的 RPM，重新配置即可。",
+	"faq.not_found":          "终端提示 claude: command not found",
+	"faq.not_found_answer":   "Claude Code 安装在 npm 全局目录中，但该目录不在当前终端的 PATH 里。请关闭并重新打开终端；仍然找不到时，点击下方按钮检测环境，查看 Claude Code 和 PATH 的状态。",
+	"faq.not_applied":        "打开 Claude Code 后仍要求登录 Anthropic 账号",
+	"faq.not_applied_answer": "已打开的终端不会读取新写入的环境变量。复制下方的启用命令在终端中执行，或重新打开终端后再运行 claude。",
+	"faq.change_key":         "如何更换 API Key？",
+	"faq.change_key_answer":  "在主界面的 API Key 输入框中填写新的密钥，先点击「测试连接」确认可用，再点击「仅配置 API」重新写入配置，已安装的组件不会重复安装。显示已安装状态时，先点击「重新安装」，再点击「开始安装」。",
+	"faq.401":                "提示 401 / API Key 无效",
+	"faq.401_answer":         "服务商拒绝了这个 API Key。请确认复制了以 sk- 开头的完整密钥，且密钥没有在控制台中被删除；必要时创建新的密钥后重新配置。",
+	"faq.balance":            "提示余额不足或额度已用完",
+	"faq.balance_answer":     "账户余额已用完，充值后即可继续使用，无需重新配置。",
+	"faq.do_recharge":        "💳 打开充值页面",
+	"faq.do_api_keys":        "🔑 打开 API Key 管理页面",
+	"faq.do_verify":          "检测环境",
+	"faq.do_copy_command":    "复制启用命令",
+
+	// 配置档
+	"profile.placeholder":      "选择已保存的配置档",
+	"profile.save":             "保存为配置档",
+	"profile.label":            "配置档:",
+	"profile.name":             "名称",
+	"profile.name_placeholder": "例如：个人 Kimi、公司 DeepSeek",
+	"profile.save_title":       "保存配置档",
+	"profile.delete_title":     "删除配置档",
+	"profile.select_first":     "请先选择要删除的配置档",
+	"profile.delete_confirm":   "确定删除配置档「%s」吗？",
+	"button.save":              "保存",
+	"button.delete":            "删除",
+	"error.profile_name":       "配置档名称不能为空",
+	"error.profile_save":       "保存配置档失败: %v",
+	"error.profile_delete":     "删除配置档失败: %v",
+
+	// 测试连接
+	"connection.testing":            "正在测试连接...",
+	"connection.rate_limited":       "⚠️ 测试连接触发速率限制",
+	"connection.rate_limited_title": "触发速率限制",
+	"connection.failed":             "❌ 测试连接失败",
+	"connection.failed_title":       "测试连接失败",
+	"connection.success":            "✅ 测试连接成功",
+	"connection.success_title":      "测试连接成功",
+	"connection.success_message":    "Claude Code 已通过 %s 正常返回结果，可以开始使用了。",
+	"button.retest":                 "重新测试",
+	"error.api_key_empty":           "请输入 API Key",
+
+	// 卸载
+	"uninstall.plan":         "将删除以下内容：\n\n• %s\n\nNode.js 和 Git 会保留。",
+	"uninstall.remove_data":  "同时删除安装日志、环境快照和下载缓存（~/.claude-k2-installer）",
+	"uninstall.title":        "卸载 Claude Code",
+	"uninstall.running":      "正在卸载...",
+	"uninstall.incomplete":   "⚠️ 卸载未完成",
+	"uninstall.done":         "✅ 卸载完成",
+	"uninstall.done_title":   "卸载完成",
+	"uninstall.done_message": "Claude Code 已卸载，K2 配置已清除。\n\n请重新打开终端使环境变量变更生效。",
+	"error.uninstall":        "卸载失败: %v",
+
+	// 日志与隐私
+	"log_policy.off":        "不落盘（日志只在窗口中显示）",
+	"log_policy.session":    "仅本次会话（退出时删除）",
+	"log_policy.days":       "留存指定天数",
+	"log_policy.forever":    "永久保存",
+	"log_policy.full_args":  "记录命令完整参数（可能包含 API Key 等敏感信息）",
+	"log_policy.intro":      "安装日志和环境快照保存在用户目录下的 .claude-k2-installer 文件夹中，便于排查问题。",
+	"log_policy.first_run":  "%s\n默认策略：%s。\n你可以在这里调整，之后也可以通过「日志与隐私」按钮修改。",
+	"log_policy.retention":  "日志留存",
+	"log_policy.days_label": "留存天数:",
+	"log_policy.updated":    "日志策略已更新：%s",
+	"button.use_default":    "使用默认",
+	"error.log_days":        "留存天数必须是正整数",
+
+	// 二维码
+	"qr.scan_heading": "## 请使用手机扫描二维码\n",
+	"qr.scan_body":    "扫码后在手机浏览器中完成操作\n\n%s",
+	"error.qr_code":   "生成二维码失败: %v",
+
+	// 配置目录与日志导出
+	"config_folder.copied_title": "路径已复制",
+	"config_folder.copied":       "无法打开文件管理器，配置目录已复制到剪贴板:\n%s",
+	"config_folder.files":        "已写入的配置文件",
+	"export_log.done_title":      "导出成功",
+	"export_log.done":            "日志已保存到：\n%s\n\n反馈问题时请发送此文件。",
+	"error.home_dir":             "获取用户目录失败: %v",
+	"error.export_log":           "导出日志失败: %v",
+
+	// 终端
+	"terminal.auto":         "自动检测",
+	"error.launcher_script": "写入启动脚本失败: %v",
+}
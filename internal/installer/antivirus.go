@@ -0,0 +1,71 @@
+package installer
+
+import (
+	"fmt"
+	"time"
+)
+
+// antivirusRetryDelay 疑似被杀毒软件拦截后重试前的等待时间，杀毒软件扫描完成后文件锁通常会释放
+var antivirusRetryDelay = 5 * time.Second
+
+// antivirusExitCodes Windows 安装脚本中常见的被杀毒软件或 SmartScreen 拦截时的退出码及原因
+var antivirusExitCodes = map[int]string{
+	// ERROR_ACCESS_DENIED：文件正在被扫描或已被锁定
+	5: "被拒绝访问",
+	// ERROR_VIRUS_INFECTED、ERROR_VIRUS_DELETED
+	225: "被识别为病毒或潜在的垃圾软件",
+	226: "被识别为病毒并已删除",
+	// ERROR_ACCESS_DISABLED_BY_POLICY
+	1260: "被组策略或 SmartScreen 阻止运行",
+	// ERROR_INSTALL_PACKAGE_OPEN_FAILED：msiexec 无法打开安装包
+	1619: "无法打开，文件可能已被隔离",
+	// 安装脚本发现安装包下载后消失
+	scriptInstallerMissingExit: "下载后被删除，可能已被隔离",
+}
+
+// antivirusOutputKeywords 安装脚本输出中表明被杀毒软件拦截的文字，包括中文系统的提示
+var antivirusOutputKeywords = []string{
+	"access is denied",
+	"拒绝访问",
+	"contains a virus",
+	"potentially unwanted software",
+	"病毒",
+	"潜在的垃圾软件",
+	"smartscreen",
+	"quarantined by antivirus",
+}
+
+// antivirusBlockReason 判断 Windows 安装脚本的失败是否像是被杀毒软件或 SmartScreen 拦截，返回原因
+func antivirusBlockReason(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	if reason, ok := antivirusExitCodes[exitCode(err)]; ok {
+		return reason, true
+	}
+	if commandOutputContains(err, antivirusOutputKeywords...) {
+		return "被拒绝运行", true
+	}
+	return "", false
+}
+
+// runInstallerScript 执行 Windows 安装脚本，疑似被杀毒软件拦截时等待片刻，
+// 调用 prepareRetry 重新准备经过校验的安装包后重试一次；仍被拦截时返回 ErrBlockedByAntivirus
+func (i *Installer) runInstallerScript(component string, run func() error, prepareRetry func()) error {
+	err := run()
+	reason, blocked := antivirusBlockReason(err)
+	if !blocked {
+		return err
+	}
+
+	i.addLog(fmt.Sprintf("🛡️ %s 安装程序%s，可能被杀毒软件或 SmartScreen 拦截，%d 秒后重试一次...", component, reason, int(antivirusRetryDelay.Seconds())))
+	time.Sleep(antivirusRetryDelay)
+	prepareRetry()
+
+	err = run()
+	if reason, blocked := antivirusBlockReason(err); blocked {
+		i.addLog("🛡️ 杀毒软件可能拦截了安装程序，请临时关闭实时保护或将安装包添加到信任列表后重试")
+		return fmt.Errorf("%w: %s 安装程序%s", ErrBlockedByAntivirus, component, reason)
+	}
+	return err
+}
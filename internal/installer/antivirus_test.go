@@ -0,0 +1,64 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAntivirusBlockReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		blocked bool
+	}{
+		{"nil", nil, false},
+		{"installer missing", &CommandError{ExitCode: scriptInstallerMissingExit}, true},
+		{"virus infected", &CommandError{ExitCode: 225}, true},
+		{"wrapped exit code", fmt.Errorf("Git 安装失败: %w", &CommandError{ExitCode: 1619}), true},
+		{"access denied output", &CommandError{ExitCode: 1, Tail: "拒绝访问。"}, true},
+		{"msi fatal error", &CommandError{ExitCode: 1603}, false},
+		{"network error", &CommandError{ExitCode: 1, Tail: "network error"}, false},
+		{"not a command error", errors.New("exit 5"), false},
+	}
+
+	for _, tt := range tests {
+		if reason, blocked := antivirusBlockReason(tt.err); blocked != tt.blocked {
+			t.Errorf("%s: antivirusBlockReason(%v) = %q, %v, want blocked=%v", tt.name, tt.err, reason, blocked, tt.blocked)
+		}
+	}
+}
+
+func TestRunInstallerScriptRetriesOnce(t *testing.T) {
+	defer func(delay time.Duration) { antivirusRetryDelay = delay }(antivirusRetryDelay)
+	antivirusRetryDelay = 0
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	blocked := &CommandError{ExitCode: 5, Tail: "Access is denied."}
+
+	runs, prepared := 0, 0
+	err := i.runInstallerScript("Git", func() error {
+		runs++
+		if runs == 1 {
+			return blocked
+		}
+		return nil
+	}, func() { prepared++ })
+	if err != nil || runs != 2 || prepared != 1 {
+		t.Errorf("expected one retry to succeed, got err=%v runs=%d prepared=%d", err, runs, prepared)
+	}
+
+	runs = 0
+	err = i.runInstallerScript("Git", func() error { runs++; return blocked }, func() {})
+	if !errors.Is(err, ErrBlockedByAntivirus) || runs != 2 {
+		t.Errorf("expected ErrBlockedByAntivirus after one retry, got err=%v runs=%d", err, runs)
+	}
+
+	runs = 0
+	other := errors.New("Git 安装失败")
+	if err := i.runInstallerScript("Git", func() error { runs++; return other }, func() {}); err != other || runs != 1 {
+		t.Errorf("unrelated failures should not be retried, got err=%v runs=%d", err, runs)
+	}
+}
@@ -0,0 +1,106 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// k2EnvNames 本工具配置 API 时设置的环境变量，不含 API Key 本身
+var k2EnvNames = []string{"ANTHROPIC_BASE_URL", "CLAUDE_REQUEST_DELAY_MS", "CLAUDE_MAX_CONCURRENT_REQUESTS"}
+
+// StartChangeAPIKey 在后台更换 API Key，返回进度 channel，结束时关闭；
+// 失败时在关闭前发送一条带 Error 的更新。上一个操作仍在进行时返回错误
+func (i *Installer) StartChangeAPIKey(provider Provider, apiKey, rpm string, useSystemConfig bool) (<-chan ProgressUpdate, error) {
+	updates, err := i.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer i.endOperation()
+		if err := i.ChangeAPIKey(provider, apiKey, rpm, useSystemConfig); err != nil {
+			i.sendError(err)
+		}
+	}()
+	return updates, nil
+}
+
+// ChangeAPIKey 已安装好环境时更换 API Key：删除之前写入的 K2 配置，用新的 Key 重新配置，
+// 再测试一次连接确认新的 Key 可用。只修改配置，不重新安装任何组件
+func (i *Installer) ChangeAPIKey(provider Provider, apiKey, rpm string, useSystemConfig bool) error {
+	if apiKey == "" {
+		return errors.New("请先输入新的 API Key")
+	}
+	i.ensureClaudeOnPath()
+	if !i.CheckEnvironment().ClaudeOK {
+		return errors.New("未检测到已安装的 Claude Code，请先完成安装")
+	}
+
+	i.addLog(fmt.Sprintf("🔑 更换 %s API Key: %s", provider.Name, maskKey(apiKey)))
+	i.removeK2Config(provider)
+	if err := i.ConfigureProviderAPI(provider, apiKey, rpm, useSystemConfig); err != nil {
+		return err
+	}
+
+	i.addLog("🔌 验证新的 API Key...")
+	if err := i.TestConnection(provider, apiKey); err != nil {
+		return err
+	}
+	i.addLog("✅ API Key 已更换并验证通过")
+	return nil
+}
+
+// removeK2Config 删除之前写入的 K2 环境变量配置：shell 和 PowerShell 配置文件中的配置块、
+// ~/.claude/settings.json 中的 env、Windows 用户环境变量和当前进程中的变量。从永久配置改为临时配置时也不会留下旧的 Key
+func (i *Installer) removeK2Config(provider Provider) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 获取用户目录失败，跳过清理旧配置: %v", err))
+		return
+	}
+
+	names := append([]string{provider.EnvKeyName, provider.ConflictingEnvKey()}, k2EnvNames...)
+	for _, name := range names {
+		os.Unsetenv(name)
+	}
+
+	// 无论当前选择哪种配置方式，都清理之前写入 settings.json 的旧 Key，否则它会覆盖新写入的环境变量
+	i.restoreClaudeSettings(home)
+
+	if runtime.GOOS == "windows" {
+		for _, name := range names {
+			// 变量不存在时 reg delete 也会失败，无需提示
+			if exec.Command("reg", "delete", `HKCU\Environment`, "/v", name, "/f").Run() == nil {
+				i.addLog(fmt.Sprintf("✅ 已删除用户环境变量 %s", name))
+			}
+		}
+		i.broadcastEnvironmentChange()
+		i.removePowerShellProfileBlocks(home)
+		os.Remove(SetupScriptPath())
+		return
+	}
+
+	i.removeShellEnvBlocks(shellConfigFiles(home))
+	os.Remove(SetupScriptPath())
+}
+
+// removeShellEnvBlocks 从各 shell 配置文件中删除 K2 环境变量块
+func (i *Installer) removeShellEnvBlocks(paths []string) {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		content, changed := stripShellEnvBlock(string(data))
+		if !changed {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 清理 %s 中的旧配置失败: %v", path, err))
+		} else {
+			i.addLog(fmt.Sprintf("✅ 已删除 %s 中的旧配置", path))
+		}
+	}
+}
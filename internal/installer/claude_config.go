@@ -0,0 +1,178 @@
+package installer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// claudeConfigBackupSuffix 备份文件后缀，实际文件名附带时间戳，如 .claude.json.backup.20250110-153000
+const claudeConfigBackupSuffix = ".backup"
+
+// backupClaudeConfig 将原始配置内容写入带时间戳的备份文件
+func backupClaudeConfig(claudeJsonPath string, data []byte) (string, error) {
+	backupFile := claudeJsonPath + claudeConfigBackupSuffix + "." + time.Now().Format("20060102-150405")
+	if err := os.WriteFile(backupFile, data, 0600); err != nil {
+		return "", err
+	}
+	return backupFile, nil
+}
+
+// writeClaudeConfig 写入 .claude.json，文件中有 API Key 和登录凭据，只允许当前用户读写
+// 文件已存在时 WriteFile 不会修改权限，写入后显式设为 0600
+func writeClaudeConfig(claudeJsonPath string, data []byte) error {
+	if err := os.WriteFile(claudeJsonPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(claudeJsonPath, 0600)
+}
+
+// latestClaudeConfigBackup 返回最近一次的备份文件路径，没有备份时返回空字符串
+func latestClaudeConfigBackup(claudeJsonPath string) string {
+	matches, err := filepath.Glob(claudeJsonPath + claudeConfigBackupSuffix + "*")
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+
+	// 时间戳格式固定，按文件名排序即按时间排序
+	sort.Strings(matches)
+	return matches[len(matches)-1]
+}
+
+// claudeConfigWrittenByUs .claude.json 中是否有本工具写入的 K2 配置
+// apiBaseUrl 不是 Claude Code 自己会写入的键，以它判断文件是否由本工具修改过；文件不存在或无法解析时返回 false
+func claudeConfigWrittenByUs(claudeJsonPath string) bool {
+	fields, err := claudeConfigK2Fields(claudeJsonPath)
+	if err != nil {
+		return false
+	}
+	_, ok := fields["apiBaseUrl"]
+	return ok
+}
+
+// claudeConfigEntry .claude.json 中需要写入的一个键值
+type claudeConfigEntry struct {
+	Key   string
+	Value interface{}
+}
+
+// mergeClaudeConfig 将 updates 合并进原始 .claude.json 内容
+// 已存在的键原地更新，新键追加到末尾，其他键（如 mcpServers、projects）的顺序和内容保持不变
+func mergeClaudeConfig(original []byte, updates []claudeConfigEntry) ([]byte, error) {
+	object, err := parseOrderedObject(original)
+	if err != nil {
+		return nil, err
+	}
+	for _, update := range updates {
+		if err := object.set(update.Key, update.Value); err != nil {
+			return nil, err
+		}
+	}
+	return object.marshal()
+}
+
+// orderedObject 保留键顺序的 JSON 对象，修改配置文件时不打乱用户原有的内容
+type orderedObject struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// parseOrderedObject 解析 JSON 对象并记录键的顺序，内容为空时返回空对象
+func parseOrderedObject(data []byte) (*orderedObject, error) {
+	object := &orderedObject{values: make(map[string]json.RawMessage)}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return object, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("配置文件顶层不是 JSON 对象")
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("配置文件格式错误: 无效的键 %v", tok)
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if _, exists := object.values[key]; !exists {
+			object.keys = append(object.keys, key)
+		}
+		object.values[key] = raw
+	}
+
+	// 读取结尾的 }
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// set 已存在的键原地更新，新键追加到末尾
+func (o *orderedObject) set(key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = raw
+	return nil
+}
+
+// remove 删除键，返回键是否存在
+func (o *orderedObject) remove(key string) bool {
+	if _, exists := o.values[key]; !exists {
+		return false
+	}
+	delete(o.values, key)
+	for idx, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:idx], o.keys[idx+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// marshal 按原有顺序输出缩进格式的 JSON
+func (o *orderedObject) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for idx, key := range o.keys {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+		keyData, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyData)
+		buf.WriteByte(':')
+		buf.Write(o.values[key])
+	}
+	buf.WriteByte('}')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,299 @@
+package installer
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMergeClaudeConfigPreservesProjects(t *testing.T) {
+	original := []byte(`{
+  "numStartups": 12,
+  "projects": {
+    "/Users/dev/app": {
+      "allowedTools": ["Bash", "Edit"],
+      "history": [{"display": "fix <tests> & lint"}],
+      "mcpContextUris": []
+    }
+  },
+  "apiKey": "sk-old",
+  "mcpServers": {"fs": {"command": "npx", "args": ["-y", "server-fs"]}}
+}`)
+
+	merged, err := mergeClaudeConfig(original, []claudeConfigEntry{
+		{"apiKey", "sk-new"},
+		{"apiBaseUrl", DefaultProvider().BaseURL},
+	})
+	if err != nil {
+		t.Fatalf("mergeClaudeConfig: %v", err)
+	}
+
+	var before, after map[string]json.RawMessage
+	if err := json.Unmarshal(original, &before); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(merged, &after); err != nil {
+		t.Fatalf("merged config is not valid JSON: %v\n%s", err, merged)
+	}
+
+	for _, key := range []string{"projects", "mcpServers", "numStartups"} {
+		if !jsonEqual(t, before[key], after[key]) {
+			t.Errorf("%s changed:\nbefore: %s\nafter:  %s", key, before[key], after[key])
+		}
+	}
+
+	if got := string(after["apiKey"]); got != `"sk-new"` {
+		t.Errorf("apiKey = %s, want \"sk-new\"", got)
+	}
+
+	// 原有键保持顺序，新键追加到末尾
+	order := []string{`"numStartups"`, `"projects"`, `"apiKey"`, `"mcpServers"`, `"apiBaseUrl"`}
+	last := -1
+	for _, key := range order {
+		idx := strings.Index(string(merged), "\n  "+key)
+		if idx <= last {
+			t.Fatalf("key %s out of order in:\n%s", key, merged)
+		}
+		last = idx
+	}
+}
+
+func TestMergeClaudeConfigEmptyOriginal(t *testing.T) {
+	merged, err := mergeClaudeConfig(nil, []claudeConfigEntry{{"hasCompletedOnboarding", true}})
+	if err != nil {
+		t.Fatalf("mergeClaudeConfig: %v", err)
+	}
+	if want := "{\n  \"hasCompletedOnboarding\": true\n}"; string(merged) != want {
+		t.Errorf("merged = %s, want %s", merged, want)
+	}
+}
+
+func TestMergeClaudeConfigRejectsNonObject(t *testing.T) {
+	if _, err := mergeClaudeConfig([]byte(`["not", "an", "object"]`), nil); err == nil {
+		t.Error("expected error for non-object config")
+	}
+}
+
+func jsonEqual(t *testing.T, a, b json.RawMessage) bool {
+	t.Helper()
+	var ca, cb bytes.Buffer
+	if err := json.Compact(&ca, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Compact(&cb, b); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
+
+func TestEnvOnlyLeavesClaudeConfigUntouched(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	useTempSetupScript(t)
+
+	claudeJsonPath := filepath.Join(home, ".claude.json")
+	original := `{"numStartups": 3, "hasCompletedOnboarding": false}`
+	if err := os.WriteFile(claudeJsonPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.WriteClaudeJSON = false
+	if err := i.configureK2APIWithOptions(DefaultProvider(), "sk-test-key-0123456789", "3", false); err != nil {
+		t.Fatalf("configureK2APIWithOptions: %v", err)
+	}
+	if claudeConfigWrittenByUs(claudeJsonPath) {
+		t.Error("env-only configuration should not write K2 fields to .claude.json")
+	}
+
+	// 文件中没有本工具写入的配置，恢复时既不删除也不改写
+	if err := i.RestoreOriginalClaudeConfig(); err != nil {
+		t.Fatalf("RestoreOriginalClaudeConfig: %v", err)
+	}
+	data, err := os.ReadFile(claudeJsonPath)
+	if err != nil {
+		t.Fatalf(".claude.json should not be removed: %v", err)
+	}
+	if string(data) != original {
+		t.Errorf(".claude.json changed to %s", data)
+	}
+
+	i.WriteClaudeJSON = true
+	if err := i.configureK2APIWithOptions(DefaultProvider(), "sk-test-key-0123456789", "3", false); err != nil {
+		t.Fatalf("configureK2APIWithOptions: %v", err)
+	}
+	if !claudeConfigWrittenByUs(claudeJsonPath) {
+		t.Fatal("expected K2 fields in .claude.json")
+	}
+	if info, err := os.Stat(claudeJsonPath); err == nil && runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("expected .claude.json with the API key to be 0600, got %v", info.Mode().Perm())
+	}
+	if err := i.RestoreOriginalClaudeConfig(); err != nil {
+		t.Fatalf("RestoreOriginalClaudeConfig: %v", err)
+	}
+	if data, err := os.ReadFile(claudeJsonPath); err != nil || string(data) != original {
+		t.Errorf("expected .claude.json restored from backup, got %q, %v", data, err)
+	}
+	if info, err := os.Stat(claudeJsonPath); err == nil && runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("expected restored .claude.json to be 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestClaudeSettingsStrategyMergesAndRestores(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	settingsPath := claudeSettingsPath(home)
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	original := `{"model": "opus", "env": {"HTTPS_PROXY": "http://127.0.0.1:7890", "ANTHROPIC_AUTH_TOKEN": "old-token"}}`
+	if err := os.WriteFile(settingsPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.WriteClaudeJSON = false
+	i.ConfigStrategy = ConfigStrategySettings
+	if err := i.configureK2APIWithOptions(DefaultProvider(), "sk-test-key-0123456789", "3", true); err != nil {
+		t.Fatalf("configureK2APIWithOptions: %v", err)
+	}
+	for _, name := range shellConfigFiles(home) {
+		if _, err := os.Stat(name); err == nil {
+			t.Errorf("settings strategy should not write shell config %s", name)
+		}
+	}
+
+	var settings struct {
+		Model string            `json:"model"`
+		Env   map[string]string `json:"env"`
+	}
+	data, _ := os.ReadFile(settingsPath)
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("invalid settings.json: %v\n%s", err, data)
+	}
+	provider := DefaultProvider()
+	if settings.Model != "opus" || settings.Env["HTTPS_PROXY"] != "http://127.0.0.1:7890" {
+		t.Errorf("existing settings were not preserved: %s", data)
+	}
+	if settings.Env["ANTHROPIC_BASE_URL"] != provider.BaseURL || settings.Env[provider.EnvKeyName] != "sk-test-key-0123456789" {
+		t.Errorf("K2 variables missing from settings.json: %s", data)
+	}
+	if _, ok := settings.Env[provider.ConflictingEnvKey()]; ok {
+		t.Errorf("conflicting %s should be removed: %s", provider.ConflictingEnvKey(), data)
+	}
+
+	// 恢复时只删除 K2 变量，用户的其他设置保留
+	if err := i.RestoreOriginalClaudeConfig(); err != nil {
+		t.Fatalf("RestoreOriginalClaudeConfig: %v", err)
+	}
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatalf("settings.json with user settings should not be removed: %v", err)
+	}
+	settings.Env = nil
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatalf("invalid settings.json: %v\n%s", err, data)
+	}
+	if settings.Model != "opus" || len(settings.Env) != 1 || settings.Env["HTTPS_PROXY"] == "" {
+		t.Errorf("unexpected settings.json after restore: %s", data)
+	}
+
+	// 只有 K2 变量的 settings.json 恢复时删除
+	i.ConfigStrategy = ConfigStrategyBoth
+	os.Remove(settingsPath)
+	if err := i.configureK2APIWithOptions(DefaultProvider(), "sk-test-key-0123456789", "3", true); err != nil {
+		t.Fatalf("configureK2APIWithOptions: %v", err)
+	}
+	if err := i.RestoreOriginalClaudeConfig(); err != nil {
+		t.Fatalf("RestoreOriginalClaudeConfig: %v", err)
+	}
+	if _, err := os.Stat(settingsPath); !os.IsNotExist(err) {
+		t.Errorf("settings.json with only K2 variables should be removed, stat err = %v", err)
+	}
+}
+
+func TestConfigStrategyPreviewAndActivation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell rc files are only written on macOS/Linux")
+	}
+	const apiKey = "sk-strategy-key-0123456789"
+	provider := DefaultProvider()
+	useTempSetupScript(t)
+
+	for _, tt := range []struct {
+		strategy   ConfigStrategy
+		writeShell bool
+		changes    int
+		command    string
+	}{
+		{ConfigStrategySettings, false, 1, "claude"},
+		{ConfigStrategyBoth, true, 2, "source ~/.zshrc"},
+	} {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("SHELL", "/bin/zsh")
+		zshrc := filepath.Join(home, ".zshrc")
+		if err := os.WriteFile(zshrc, []byte("alias ll='ls -l'\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		i := New()
+		i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+		i.WriteClaudeJSON = false
+		i.ConfigStrategy = tt.strategy
+
+		changes, err := i.PlanShellConfigChanges(provider, apiKey, "3")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var settingsChange *ShellConfigChange
+		shellChanged := false
+		for n := range changes {
+			switch changes[n].Path {
+			case claudeSettingsPath(home):
+				settingsChange = &changes[n]
+			case zshrc:
+				shellChanged = true
+			}
+		}
+		if settingsChange == nil || shellChanged != tt.writeShell || len(changes) != tt.changes {
+			t.Fatalf("%s: unexpected preview %+v", tt.strategy, changes)
+		}
+		if diff := settingsChange.Diff(); strings.Contains(diff, apiKey) || !strings.Contains(diff, "ANTHROPIC_BASE_URL") {
+			t.Errorf("%s: settings.json diff should list the K2 variables with the key masked:\n%s", tt.strategy, diff)
+		}
+		if got := i.GetActivationCommand(true); got != tt.command {
+			t.Errorf("%s: GetActivationCommand = %q, want %q", tt.strategy, got, tt.command)
+		}
+
+		if err := i.configureK2APIWithOptions(provider, apiKey, "3", true); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(claudeSettingsPath(home))
+		if string(data) != settingsChange.After {
+			t.Errorf("%s: written settings.json differs from the preview:\n%s\nwant:\n%s", tt.strategy, data, settingsChange.After)
+		}
+		rc, _ := os.ReadFile(zshrc)
+		if strings.Contains(string(rc), apiKey) != tt.writeShell {
+			t.Errorf("%s: unexpected .zshrc after configure:\n%s", tt.strategy, rc)
+		}
+
+		// 更换 Key 前清理旧配置：settings.json 中的旧 Key 不能留下覆盖新 Key
+		i.removeK2Config(provider)
+		if data, err := os.ReadFile(claudeSettingsPath(home)); err == nil && strings.Contains(string(data), apiKey) {
+			t.Errorf("%s: removeK2Config left the old key in settings.json:\n%s", tt.strategy, data)
+		}
+		if rc, _ := os.ReadFile(zshrc); strings.Contains(string(rc), apiKey) {
+			t.Errorf("%s: removeK2Config left the old key in .zshrc:\n%s", tt.strategy, rc)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ConfigStrategy 配置 API 时永久环境变量的写入位置
+type ConfigStrategy string
+
+const (
+	ConfigStrategyShell    ConfigStrategy = "shell"    // 写入 shell 配置文件，Windows 上为用户环境变量和 PowerShell 配置（默认）
+	ConfigStrategySettings ConfigStrategy = "settings" // 写入 ~/.claude/settings.json 的 env，从图形界面启动的 Claude Code 也能读到
+	ConfigStrategyBoth     ConfigStrategy = "both"     // 两处都写入
+)
+
+// ConfigStrategies 可选的配置方式，按界面中的显示顺序排列
+var ConfigStrategies = []ConfigStrategy{ConfigStrategyShell, ConfigStrategySettings, ConfigStrategyBoth}
+
+// ValidateConfigStrategy 检查配置方式，为空表示使用默认的 shell 配置文件
+func ValidateConfigStrategy(strategy string) error {
+	if strategy == "" {
+		return nil
+	}
+	for _, s := range ConfigStrategies {
+		if ConfigStrategy(strategy) == s {
+			return nil
+		}
+	}
+	return fmt.Errorf("未知的配置方式: %s，应为 %s、%s 或 %s", strategy, ConfigStrategyShell, ConfigStrategySettings, ConfigStrategyBoth)
+}
+
+// writesShell 是否写入 shell 配置文件或系统环境变量
+func (s ConfigStrategy) writesShell() bool {
+	return s != ConfigStrategySettings
+}
+
+// writesSettings 是否写入 ~/.claude/settings.json
+func (s ConfigStrategy) writesSettings() bool {
+	return s == ConfigStrategySettings || s == ConfigStrategyBoth
+}
+
+// claudeSettingsK2Keys 本工具写入 settings.json 中 env 的变量，恢复配置时只删除这些变量
+var claudeSettingsK2Keys = []string{"ANTHROPIC_BASE_URL", "ANTHROPIC_API_KEY", "ANTHROPIC_AUTH_TOKEN", "CLAUDE_REQUEST_DELAY_MS", "CLAUDE_MAX_CONCURRENT_REQUESTS"}
+
+// claudeSettingsPath 返回 Claude Code 的用户设置文件路径
+func claudeSettingsPath(home string) string {
+	return filepath.Join(home, ".claude", "settings.json")
+}
+
+// mergeClaudeSettingsEnv 把 K2 环境变量合并进 settings.json 的 env，删除与服务商冲突的 Key 变量；
+// settings.json 中的其他设置和 env 中用户自己的变量保持不变
+func mergeClaudeSettingsEnv(original []byte, env []claudeConfigEntry, conflicting string) ([]byte, error) {
+	settings, err := parseOrderedObject(original)
+	if err != nil {
+		return nil, err
+	}
+	envObject, err := parseOrderedObject(settings.values["env"])
+	if err != nil {
+		return nil, fmt.Errorf("settings.json 中的 env 不是 JSON 对象: %v", err)
+	}
+
+	for _, entry := range env {
+		if err := envObject.set(entry.Key, entry.Value); err != nil {
+			return nil, err
+		}
+	}
+	envObject.remove(conflicting)
+
+	envData, err := envObject.marshal()
+	if err != nil {
+		return nil, err
+	}
+	if err := settings.set("env", json.RawMessage(envData)); err != nil {
+		return nil, err
+	}
+	return settings.marshal()
+}
+
+// removeClaudeSettingsEnv 从 settings.json 的 env 中删除本工具写入的变量，env 为空时一并删除；
+// changed 为 false 表示没有 K2 变量，empty 表示删除后已没有任何设置
+func removeClaudeSettingsEnv(original []byte) (data []byte, changed, empty bool, err error) {
+	settings, err := parseOrderedObject(original)
+	if err != nil {
+		return nil, false, false, err
+	}
+	raw, ok := settings.values["env"]
+	if !ok {
+		return original, false, len(settings.keys) == 0, nil
+	}
+	envObject, err := parseOrderedObject(raw)
+	if err != nil {
+		return nil, false, false, fmt.Errorf("settings.json 中的 env 不是 JSON 对象: %v", err)
+	}
+	// CLAUDE_REQUEST_DELAY_MS 只有本工具会写入，没有它时 ANTHROPIC_* 是用户自己配置的
+	if _, ok := envObject.values["CLAUDE_REQUEST_DELAY_MS"]; !ok {
+		return original, false, false, nil
+	}
+
+	for _, key := range claudeSettingsK2Keys {
+		envObject.remove(key)
+	}
+	if len(envObject.keys) == 0 {
+		settings.remove("env")
+	} else {
+		envData, err := envObject.marshal()
+		if err != nil {
+			return nil, false, false, err
+		}
+		if err := settings.set("env", json.RawMessage(envData)); err != nil {
+			return nil, false, false, err
+		}
+	}
+
+	data, err = settings.marshal()
+	if err != nil {
+		return nil, false, false, err
+	}
+	return data, true, len(settings.keys) == 0, nil
+}
+
+// planClaudeSettingsChange 返回把 K2 配置合并进 ~/.claude/settings.json 后的内容，不写入文件；
+// 界面中预览的修改和实际写入由同一函数生成
+func planClaudeSettingsChange(home string, provider Provider, apiKey string, requestDelay int) ShellConfigChange {
+	change := ShellConfigChange{Path: claudeSettingsPath(home), apiKey: apiKey}
+	original, err := os.ReadFile(change.Path)
+	change.Created = os.IsNotExist(err)
+	if err != nil && !change.Created {
+		change.Err = err
+		return change
+	}
+
+	env := []claudeConfigEntry{
+		{"ANTHROPIC_BASE_URL", provider.BaseURL},
+		{provider.EnvKeyName, apiKey},
+		{"CLAUDE_REQUEST_DELAY_MS", fmt.Sprintf("%d", requestDelay)},
+		{"CLAUDE_MAX_CONCURRENT_REQUESTS", "1"},
+	}
+	data, err := mergeClaudeSettingsEnv(original, env, provider.ConflictingEnvKey())
+	if err != nil {
+		// 不覆盖无法解析的设置文件，避免丢失用户的其他设置
+		change.Err = fmt.Errorf("无法解析，不写入 K2 配置: %v", err)
+		return change
+	}
+	_, changed, _, _ := removeClaudeSettingsEnv(original)
+	change.Before = string(original)
+	change.After = string(data)
+	change.Replaced = changed
+	return change
+}
+
+// writeClaudeSettings 把 K2 配置写入 ~/.claude/settings.json 的 env，与已有设置合并
+func (i *Installer) writeClaudeSettings(home string, provider Provider, apiKey string, requestDelay int) {
+	change := planClaudeSettingsChange(home, provider, apiKey, requestDelay)
+	if change.Err != nil {
+		i.addLog(fmt.Sprintf("⚠️ %s: %v", change.Path, change.Err))
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(change.Path), 0755); err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 创建 %s 失败: %v", filepath.Dir(change.Path), err))
+		return
+	}
+	// 文件中包含 API Key，只允许当前用户读取
+	if err := os.WriteFile(change.Path, []byte(change.After), 0600); err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 写入 %s 失败: %v", change.Path, err))
+		return
+	}
+	i.addLog(fmt.Sprintf("✅ 已将 K2 配置写入 %s 的 env", change.Path))
+}
+
+// restoreClaudeSettings 删除 ~/.claude/settings.json 中本工具写入的变量，其他设置保持不变；
+// 删除后没有任何设置时删除文件
+func (i *Installer) restoreClaudeSettings(home string) {
+	settingsPath := claudeSettingsPath(home)
+	original, err := os.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 读取 settings.json 失败: %v", err))
+		return
+	}
+
+	data, changed, empty, err := removeClaudeSettingsEnv(original)
+	switch {
+	case err != nil:
+		i.addLog(fmt.Sprintf("⚠️ 解析 settings.json 失败，保持不变: %v", err))
+	case !changed:
+		i.addLog("ℹ️ settings.json 中没有本工具写入的配置，保持不变")
+	case empty:
+		if err := os.Remove(settingsPath); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 删除 settings.json 失败: %v", err))
+		} else {
+			i.addLog("✅ 已删除 ~/.claude/settings.json")
+		}
+	default:
+		if err := os.WriteFile(settingsPath, data, 0600); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 更新 settings.json 失败: %v", err))
+		} else {
+			i.addLog("✅ 已删除 ~/.claude/settings.json 中的 K2 配置，其他设置保持不变")
+		}
+	}
+}
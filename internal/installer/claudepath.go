@@ -0,0 +1,128 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// claudeBinPathMarker 写入 shell 配置的 claude 所在目录 PATH 标记，恢复配置时据此清理
+const claudeBinPathMarker = "# Claude Code K2 claude PATH"
+
+// claudeExecutable 当前平台 npm 生成的 claude 启动文件名
+func claudeExecutable() string {
+	if runtime.GOOS == "windows" {
+		return "claude.cmd"
+	}
+	return "claude"
+}
+
+// claudeBinCandidates 返回 claude 可能所在的目录：npm 当前的全局 bin 目录，以及常见的全局前缀
+func claudeBinCandidates(home string) []string {
+	var dirs []string
+	if prefix, err := npmGlobalPrefix(); err == nil && prefix != "" {
+		_, binDir := npmGlobalDirs(prefix)
+		dirs = append(dirs, binDir)
+	}
+
+	if runtime.GOOS == "windows" {
+		if appData := os.Getenv("APPDATA"); appData != "" {
+			dirs = append(dirs, filepath.Join(appData, "npm"))
+		}
+		return append(dirs, filepath.Join(os.Getenv("ProgramFiles"), "nodejs"))
+	}
+
+	dirs = append(dirs,
+		filepath.Join(home, ".npm-global", "bin"),
+		filepath.Join(home, ".local", "node", "bin"),
+		filepath.Join(home, ".local", "bin"),
+	)
+	if runtime.GOOS == "darwin" {
+		dirs = append(dirs, macBinDirs()...)
+	}
+	return append(dirs, "/usr/local/bin")
+}
+
+// findClaudeBinDir 在候选目录中查找 claude，返回所在目录
+func findClaudeBinDir(candidates []string) string {
+	for _, dir := range candidates {
+		if dir == "" {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(dir, claudeExecutable())); err == nil && !info.IsDir() {
+			return dir
+		}
+	}
+	return ""
+}
+
+// ensureClaudeOnPath npm 安装成功但 PATH 中找不到 claude 时，查找其所在目录，
+// 加入当前进程的 PATH，并写入 shell 配置（Windows 为用户 PATH），新开的终端也能找到 claude
+func (i *Installer) ensureClaudeOnPath() {
+	if _, err := exec.LookPath("claude"); err == nil {
+		return
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	i.addLog("⚠️ PATH 中未找到 claude，正在 npm 全局目录中查找...")
+	dir := findClaudeBinDir(claudeBinCandidates(home))
+	if dir == "" {
+		i.addLog("❌ 未找到 claude，npm 全局安装可能失败")
+		return
+	}
+
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	i.addLog(fmt.Sprintf("✅ 找到 claude: %s", filepath.Join(dir, claudeExecutable())))
+	i.addLog(fmt.Sprintf("已将 %s 添加到 PATH 环境变量", dir))
+
+	if runtime.GOOS == "windows" {
+		i.addWindowsUserPath(dir)
+		return
+	}
+
+	// 用户目录下的路径写成 $HOME 形式，配置文件换用户或同步到其他机器也能使用
+	shellDir := dir
+	if rel, err := filepath.Rel(home, dir); err == nil && !strings.HasPrefix(rel, "..") {
+		shellDir = "$HOME/" + filepath.ToSlash(rel)
+	}
+	for _, shellConfig := range shellConfigFiles(home) {
+		line := fmt.Sprintf(`export PATH="%s:$PATH"`, shellDir)
+		if strings.HasSuffix(shellConfig, "config.fish") {
+			line = fmt.Sprintf("set -gx PATH %s $PATH", shellDir)
+		}
+
+		if data, err := os.ReadFile(shellConfig); err == nil && strings.Contains(string(data), claudeBinPathMarker+"\n"+line) {
+			continue
+		}
+		if err := appendMarkedLine(shellConfig, claudeBinPathMarker, line); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 写入 %s 失败: %v", shellConfig, err))
+			i.addLog(fmt.Sprintf("请手动将以下内容加入 shell 配置文件: %s", line))
+			continue
+		}
+		i.addLog(fmt.Sprintf("✅ 已将 %s 添加到 %s", dir, shellConfig))
+	}
+}
+
+// addWindowsUserPath 将目录追加到 Windows 用户 PATH，已存在时跳过
+// 不使用 setx：setx 会把超过 1024 个字符的 PATH 截断
+func (i *Installer) addWindowsUserPath(dir string) {
+	script := fmt.Sprintf(`$dir = %s
+$path = [Environment]::GetEnvironmentVariable('Path', 'User')
+if (($path -split ';') -notcontains $dir) {
+    [Environment]::SetEnvironmentVariable('Path', (($path, $dir) | Where-Object { $_ }) -join ';', 'User')
+}`, powerShellQuote(dir))
+	output, err := exec.Command("powershell", "-NoProfile", "-Command", script).CombinedOutput()
+	if err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 写入用户 PATH 失败: %v %s", err, strings.TrimSpace(string(output))))
+		i.addLog(fmt.Sprintf("请手动将 %s 加入 PATH 环境变量", dir))
+		return
+	}
+	i.broadcastEnvironmentChange()
+	i.addLog(fmt.Sprintf("✅ 已将 %s 添加到用户 PATH，新开的终端生效", dir))
+}
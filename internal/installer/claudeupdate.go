@@ -0,0 +1,98 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// ClaudeCodeUpdate 一次更新 Claude Code 前后的版本
+type ClaudeCodeUpdate struct {
+	OldVersion string
+	NewVersion string
+}
+
+// Updated 更新后版本是否发生变化
+func (u ClaudeCodeUpdate) Updated() bool {
+	return u.OldVersion != u.NewVersion
+}
+
+// Summary 更新结果的说明，如 "Claude Code 已从 1.0.51 更新到 1.0.60"
+func (u ClaudeCodeUpdate) Summary() string {
+	if !u.Updated() {
+		return fmt.Sprintf("Claude Code 已是最新版本: %s", u.NewVersion)
+	}
+	return fmt.Sprintf("Claude Code 已从 %s 更新到 %s", u.OldVersion, u.NewVersion)
+}
+
+// updateStepName 更新 Claude Code 时进度更新的步骤名
+const updateStepName = "更新 Claude Code"
+
+// StartUpdateClaudeCode 在后台更新 Claude Code，返回本次更新的进度 channel，更新结束时关闭
+// 成功时最后一条更新的 Message 为 ClaudeCodeUpdate.Summary()，失败时在关闭前发送一条带 Error 的更新
+func (i *Installer) StartUpdateClaudeCode() (<-chan ProgressUpdate, error) {
+	updates, err := i.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer i.endOperation()
+		result, err := i.UpdateClaudeCode()
+		if err != nil {
+			i.sendError(err)
+			return
+		}
+		i.sendProgress(updateStepName, result.Summary(), 1)
+	}()
+	return updates, nil
+}
+
+// UpdateClaudeCode 用 npm update -g 把已安装的 Claude Code 更新到最新版本，使用配置的 npm 镜像
+// npm 全局目录不可写时与安装时相同，按 NPMStrategy 改用用户目录或 sudo
+func (i *Installer) UpdateClaudeCode() (ClaudeCodeUpdate, error) {
+	var result ClaudeCodeUpdate
+
+	i.ensureClaudeOnPath()
+	result.OldVersion = claudeVersionOutputPattern.FindString(installedVersion("claude"))
+	if result.OldVersion == "" {
+		return result, errors.New("未检测到已安装的 Claude Code，请先完成安装")
+	}
+	i.addLog(fmt.Sprintf("🔄 更新 Claude Code（当前版本 %s）...", result.OldVersion))
+	i.applyProxyEnv()
+	i.applyIPv4Env()
+
+	stepName, stepStart, stepEnd := i.stepName, i.stepStart, i.stepEnd
+	i.stepName, i.stepStart, i.stepEnd, i.stepReported = updateStepName, 0, 0.95, 0
+	defer func() { i.stepName, i.stepStart, i.stepEnd = stepName, stepStart, stepEnd }()
+	i.sendStepProgress(0, "正在更新 Claude Code...")
+
+	prefix, err := npmGlobalPrefix()
+	if err != nil {
+		return result, fmt.Errorf("更新 Claude Code 失败: %v", err)
+	}
+	updateArgs := []string{"update", "-g", claudeCodePackage, "--registry=" + i.npmRegistry(), "--loglevel=http"}
+	cmd, err := i.npmGlobalCommand(updateArgs...)
+	if err != nil {
+		return result, fmt.Errorf("更新 Claude Code 失败: %v", err)
+	}
+	// 改用了用户目录时，新目录中还没有 Claude Code，npm update 不会做任何事，改为安装最新版本
+	if newPrefix, err := npmGlobalPrefix(); err == nil && newPrefix != prefix {
+		i.addLog("用户目录中尚未安装 Claude Code，改为安装最新版本")
+		cmd = exec.Command("npm", "install", "-g", claudeCodePackage+"@latest", "--registry="+i.npmRegistry(), "--loglevel=http")
+	}
+
+	logStart := i.LogCount()
+	tracker := &npmProgressTracker{i: i}
+	if err := i.executeCommandWithLineHandler(cmd, tracker.handleLine); err != nil {
+		return result, i.npmCommandError("更新 Claude Code 失败", err, logStart)
+	}
+
+	i.ensureClaudeOnPath()
+	result.NewVersion = claudeVersionOutputPattern.FindString(installedVersion("claude"))
+	if result.NewVersion == "" {
+		return result, errors.New("更新 Claude Code 后无法运行 claude --version，请重新打开终端后检查")
+	}
+	i.addLog("✅ " + result.Summary())
+	i.refreshInstallRecord()
+	return result, nil
+}
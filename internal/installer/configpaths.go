@@ -0,0 +1,53 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ConfigFile 本工具写入的配置文件
+type ConfigFile struct {
+	Label string `json:"label"` // 界面显示的说明
+	Path  string `json:"path"`
+}
+
+// unixSetupScriptPath macOS 和 Linux 上未勾选永久设置时写入的临时环境变量脚本，测试时指向临时目录
+var unixSetupScriptPath = "/tmp/claude_k2_setup.sh"
+
+// SetupScriptPath 返回未勾选永久设置时写入的临时环境变量脚本路径
+func SetupScriptPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.TempDir(), "claude_k2_setup.bat")
+	}
+	return unixSetupScriptPath
+}
+
+// ConfigFiles 返回配置 API 时写入的文件路径，方便用户检查配置是否正确
+func (i *Installer) ConfigFiles(useSystemConfig bool) []ConfigFile {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	files := []ConfigFile{
+		{"Claude Code 配置", filepath.Join(home, ".claude.json")},
+		{"Claude Code 设置", filepath.Join(home, ".claude", "settings.json")},
+	}
+
+	switch {
+	case runtime.GOOS == "windows" && useSystemConfig:
+		for _, profile := range powerShellProfiles(home) {
+			files = append(files, ConfigFile{"PowerShell 配置", profile})
+		}
+	case runtime.GOOS == "windows":
+		files = append(files, ConfigFile{"临时环境变量脚本", SetupScriptPath()})
+	case useSystemConfig:
+		for _, shellConfig := range shellConfigFiles(home) {
+			files = append(files, ConfigFile{"Shell 配置", shellConfig})
+		}
+	default:
+		files = append(files, ConfigFile{"临时环境变量脚本", SetupScriptPath()})
+	}
+	return files
+}
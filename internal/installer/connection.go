@@ -0,0 +1,106 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// connectionTestTimeout 测试连接的超时时间，首次调用模型可能较慢
+const connectionTestTimeout = 2 * time.Minute
+
+// connectionTestPrompt 测试连接时发送的提示词，尽量让回复简短
+const connectionTestPrompt = "Reply with the single word OK."
+
+// TestConnection 用配置的服务商和 API Key 非交互地运行一次 claude，验证整条链路可用
+func (i *Installer) TestConnection(provider Provider, apiKey string) error {
+	if apiKey == "" {
+		return fmt.Errorf("请先输入 API Key")
+	}
+	if _, err := exec.LookPath("claude"); err != nil {
+		return fmt.Errorf("未找到 claude 命令，请先完成安装")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTestTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "claude", "-p", connectionTestPrompt)
+	cmd.Env = connectionTestEnv(provider, apiKey)
+
+	i.addLog(fmt.Sprintf("🔌 测试连接 %s: claude -p %q", provider.Name, connectionTestPrompt))
+
+	// 标准输出和错误输出在两个协程中读取
+	var output []string
+	var outputMu sync.Mutex
+	err := i.executeCommandWithLineHandler(cmd, func(line string) {
+		outputMu.Lock()
+		output = append(output, line)
+		outputMu.Unlock()
+	})
+
+	// 额度用完时部分服务商也返回 429，先于速率限制判断
+	if linesContain(output, insufficientBalanceKeywords...) {
+		i.addLog("⚠️ " + ErrInsufficientBalance.Error())
+		return ErrInsufficientBalance
+	}
+	if linesContain(output, authFailureKeywords...) {
+		i.addLog("⚠️ " + ErrAPIKeyInvalid.Error())
+		return ErrAPIKeyInvalid
+	}
+	if isRateLimited(output) {
+		i.addLog("⚠️ " + ErrRateLimited.Error())
+		return ErrRateLimited
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("测试连接超时（%v），请检查网络和接口地址", connectionTestTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("测试连接失败: %v", err)
+	}
+	if len(output) == 0 {
+		return fmt.Errorf("测试连接失败: claude 没有返回任何内容")
+	}
+
+	i.addLog("✅ 测试连接成功")
+	return nil
+}
+
+// connectionTestEnv 在当前环境变量基础上设置服务商配置，并清除冲突的认证变量
+func connectionTestEnv(provider Provider, apiKey string) []string {
+	conflicting := provider.ConflictingEnvKey() + "="
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, conflicting) ||
+			strings.HasPrefix(kv, "ANTHROPIC_BASE_URL=") ||
+			strings.HasPrefix(kv, provider.EnvKeyName+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env,
+		"ANTHROPIC_BASE_URL="+provider.BaseURL,
+		provider.EnvKeyName+"="+apiKey,
+	)
+}
+
+// insufficientBalanceKeywords 服务商返回余额或额度不足时 claude 输出中的关键字
+var insufficientBalanceKeywords = []string{"insufficient balance", "insufficient_balance", "exceeded_current_quota", "insufficient_quota", "余额不足"}
+
+// authFailureKeywords 服务商拒绝 API Key 时 claude 输出中的关键字
+var authFailureKeywords = []string{"401", "invalid api key", "invalid_api_key", "invalid x-api-key", "invalid authentication", "authentication_error"}
+
+// isRateLimited 判断 claude 的输出中是否包含 429 速率限制错误
+func isRateLimited(lines []string) bool {
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "429") || strings.Contains(lower, "rate limit") ||
+			strings.Contains(lower, "rate_limit") || strings.Contains(lower, "too many requests") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package installer
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"claude-k2-installer/internal/version"
+)
+
+// DiagnosticCommand 诊断信息中一个命令的解析结果
+type DiagnosticCommand struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`    // 实际使用的可执行文件，找不到时为空
+	Version string `json:"version,omitempty"` // --version 输出的第一行
+}
+
+// Diagnostics 用户环境的完整状态，导出为 JSON 附在问题反馈中。密钥均只保留前缀
+type Diagnostics struct {
+	GeneratedAt       time.Time              `json:"generated_at"`
+	InstallerVersion  string                 `json:"installer_version"`
+	OS                string                 `json:"os"`
+	Arch              string                 `json:"arch"`
+	GoVersion         string                 `json:"go_version"`
+	Shell             string                 `json:"shell,omitempty"`
+	ShellConfigFiles  []string               `json:"shell_config_files,omitempty"` // 写入 K2 配置时使用的 shell 配置文件
+	Commands          []DiagnosticCommand    `json:"commands"`
+	Env               map[string]string      `json:"env"`                     // 当前进程中的 ANTHROPIC_* 和 CLAUDE_* 变量
+	K2Blocks          map[string]string      `json:"k2_blocks,omitempty"`     // 配置文件路径 -> 其中的 K2 配置块
+	ClaudeConfig      map[string]interface{} `json:"claude_config,omitempty"` // .claude.json 中本工具写入的字段
+	ClaudeConfigError string                 `json:"claude_config_error,omitempty"`
+	Path              []string               `json:"path"`
+}
+
+// diagnosticEnvPrefixes 诊断信息中记录的环境变量前缀
+var diagnosticEnvPrefixes = []string{"ANTHROPIC_", "CLAUDE_"}
+
+// claudeConfigK2Keys 配置 API 时写入 .claude.json 的键
+var claudeConfigK2Keys = []string{"hasCompletedOnboarding", "apiKey", "apiBaseUrl", "requestDelayMs", "maxConcurrentRequests"}
+
+// envSecretLine 匹配配置块中给 *_KEY、*_TOKEN 赋值的一行，兼容 bash/zsh、fish 和 PowerShell 语法
+var envSecretLine = regexp.MustCompile(`^(.*(?:_KEY|_TOKEN)\S*?\s*(?:=\s*|\s+))(["']?)([^"'\s]+)["']?\s*$`)
+
+// Diagnostics 收集排查问题需要的环境信息：系统、命令路径和版本、相关环境变量、
+// 配置文件中的 K2 配置块、.claude.json 中的 K2 字段、PATH 和当前 shell
+//
+// 会执行 node、git 等命令获取版本，耗时可能数秒，不要在 UI 主线程中调用。
+func (i *Installer) Diagnostics() *Diagnostics {
+	d := &Diagnostics{
+		GeneratedAt:      time.Now(),
+		InstallerVersion: version.String(),
+		OS:               runtime.GOOS,
+		Arch:             runtime.GOARCH,
+		GoVersion:        runtime.Version(),
+		Shell:            detectShell(),
+		Env:              make(map[string]string),
+	}
+
+	for _, name := range snapshotCommands {
+		d.Commands = append(d.Commands, diagnosticCommand(name))
+	}
+
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		for _, prefix := range diagnosticEnvPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				d.Env[key] = maskEnvValue(key, value)
+				break
+			}
+		}
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		d.Path = append(d.Path, maskSecrets(dir))
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return d
+	}
+
+	configFiles := shellProfileFiles(home)
+	if runtime.GOOS == "windows" {
+		configFiles = powerShellProfiles(home)
+	} else {
+		d.ShellConfigFiles = shellConfigFiles(home)
+	}
+	for _, path := range configFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if block := shellEnvBlockLines(string(data)); len(block) > 0 {
+			if d.K2Blocks == nil {
+				d.K2Blocks = make(map[string]string)
+			}
+			d.K2Blocks[path] = strings.Join(block, "\n")
+		}
+	}
+
+	d.ClaudeConfig, err = claudeConfigK2Fields(filepath.Join(home, ".claude.json"))
+	if err != nil {
+		d.ClaudeConfigError = err.Error()
+	}
+	return d
+}
+
+// JSON 返回缩进格式的诊断信息
+func (d *Diagnostics) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// diagnosticCommand 解析命令实际使用的路径和版本，PATH 中找不到时在 macOS 上检查常见的安装位置
+func diagnosticCommand(name string) DiagnosticCommand {
+	command := DiagnosticCommand{Name: name}
+	if path, err := exec.LookPath(name); err == nil {
+		command.Path = path
+	} else if runtime.GOOS == "darwin" {
+		for _, candidate := range macCommandCandidates(name) {
+			if _, err := os.Stat(candidate); err == nil {
+				command.Path = candidate
+				break
+			}
+		}
+	}
+	if command.Path != "" {
+		command.Version = commandVersion(command.Path)
+	}
+	return command
+}
+
+// detectShell 返回用户的登录 shell，Windows 上返回 ComSpec 指向的命令行程序
+func detectShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	if runtime.GOOS == "windows" {
+		return os.Getenv("ComSpec")
+	}
+	return ""
+}
+
+// shellEnvBlockLines 返回配置文件中本工具写入的 K2 配置块各行（含标记），密钥只保留前缀；
+// 与 stripShellEnvBlock 识别同样的新旧两种格式
+func shellEnvBlockLines(content string) []string {
+	var block []string
+	inBlock, inLegacy := false, false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			inBlock = trimmed != k2BlockEnd
+		case trimmed == k2BlockStart || trimmed == k2ConfigMarker:
+			inBlock = trimmed == k2BlockStart
+			inLegacy = !inBlock
+		case inLegacy && isLegacyEnvLine(trimmed):
+		default:
+			inLegacy = false
+			continue
+		}
+		block = append(block, maskEnvLine(trimmed))
+	}
+	return block
+}
+
+// maskEnvLine 隐藏配置行中 *_KEY、*_TOKEN 的值，保留引号以便看出语法是否正确
+func maskEnvLine(line string) string {
+	if match := envSecretLine.FindStringSubmatch(line); match != nil {
+		return match[1] + match[2] + maskKey(match[3]) + match[2]
+	}
+	return maskSecrets(line)
+}
+
+// claudeConfigK2Fields 读取 .claude.json 中本工具写入的字段，apiKey 只保留前缀；
+// 文件不存在时返回 nil
+func claudeConfigK2Fields(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	for _, key := range claudeConfigK2Keys {
+		raw, ok := config[key]
+		if !ok {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			continue
+		}
+		if apiKey, ok := value.(string); ok && key == "apiKey" {
+			value = maskKey(apiKey)
+		}
+		fields[key] = value
+	}
+	return fields, nil
+}
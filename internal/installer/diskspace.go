@@ -0,0 +1,83 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// MinFreeDiskSpace 安装所需的最小可用空间：Node.js 安装包、Git 安装包和 npm 依赖共需几百 MB
+const MinFreeDiskSpace = 500 << 20
+
+// freeDiskSpace 返回 path 所在磁盘的可用空间（字节）
+// Windows 通过 PowerShell 读取 DriveInfo，其他系统解析 df 的输出
+func freeDiskSpace(path string) (uint64, error) {
+	if runtime.GOOS == "windows" {
+		volume := filepath.VolumeName(path)
+		if volume == "" {
+			return 0, fmt.Errorf("无法识别 %s 所在的磁盘", path)
+		}
+		script := fmt.Sprintf(`([System.IO.DriveInfo]'%s\').AvailableFreeSpace`, volume)
+		output, err := exec.Command("powershell", "-NoProfile", "-Command", script).Output()
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseUint(strings.TrimSpace(string(output)), 10, 64)
+	}
+
+	output, err := exec.Command("df", "-Pk", path).Output()
+	if err != nil {
+		return 0, err
+	}
+	return parseDfAvailable(string(output))
+}
+
+// parseDfAvailable 解析 df -Pk 的输出，返回可用空间（字节）
+// POSIX 格式第二行为: 文件系统 总块数 已用 可用 使用率 挂载点，块大小为 1024 字节
+func parseDfAvailable(output string) (uint64, error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("无法解析 df 输出: %q", output)
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("无法解析 df 输出: %q", output)
+	}
+	kb, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析 df 输出: %q", output)
+	}
+	return kb * 1024, nil
+}
+
+// checkDiskSpace 在开始下载前检查临时目录和用户目录的可用空间，不足 MinFreeDiskSpace 时直接失败
+// 无法获取可用空间时只记录警告，不阻止安装
+func (i *Installer) checkDiskSpace() error {
+	dirs := []string{os.TempDir()}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, home)
+	}
+
+	for _, dir := range dirs {
+		free, err := freeDiskSpace(dir)
+		if err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 无法获取 %s 的可用空间，跳过检查: %v", dir, err))
+			continue
+		}
+		freeMB := free >> 20
+		if free < MinFreeDiskSpace {
+			err := fmt.Errorf("磁盘空间不足: %s 仅剩 %d MB 可用，至少需要 %d MB，请清理磁盘后重试", dir, freeMB, MinFreeDiskSpace>>20)
+			if i.DryRun {
+				i.addLog(fmt.Sprintf("%s ⚠️ %v", dryRunPrefix, err))
+				continue
+			}
+			return err
+		}
+		i.addLog(fmt.Sprintf("✅ %s 可用空间: %d MB", dir, freeMB))
+	}
+	return nil
+}
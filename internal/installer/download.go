@@ -0,0 +1,279 @@
+package installer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultDownloadRetries 每个镜像首次下载失败后的默认重试次数
+const DefaultDownloadRetries = 2
+
+// 下载超时的默认值和慢速网络模式下的值
+const (
+	DefaultDownloadTimeout     = 5 * time.Minute
+	DefaultStallTimeout        = 30 * time.Second
+	SlowNetworkDownloadTimeout = time.Hour
+	SlowNetworkStallTimeout    = 2 * time.Minute
+)
+
+// UseSlowNetwork 慢速网络模式：放宽下载总时间和停滞判断，大文件在慢速连接上也能下完
+func (i *Installer) UseSlowNetwork() {
+	i.DownloadTimeout = SlowNetworkDownloadTimeout
+	i.StallTimeout = SlowNetworkStallTimeout
+}
+
+// downloadTimeout 单个文件下载的总时间上限，未设置时使用默认值
+func (i *Installer) downloadTimeout() time.Duration {
+	if i.DownloadTimeout <= 0 {
+		return DefaultDownloadTimeout
+	}
+	return i.DownloadTimeout
+}
+
+// stallTimeout 判断下载停滞的时间，未设置时使用默认值
+func (i *Installer) stallTimeout() time.Duration {
+	if i.StallTimeout <= 0 {
+		return DefaultStallTimeout
+	}
+	return i.StallTimeout
+}
+
+// downloadRetryBase 第一次重试前的等待时间，之后每次翻倍
+var downloadRetryBase = time.Second
+
+// permanentDownloadError 重试也不会成功的下载错误，如 404，直接换下一个镜像
+type permanentDownloadError struct {
+	err error
+}
+
+func (e *permanentDownloadError) Error() string { return e.err.Error() }
+func (e *permanentDownloadError) Unwrap() error { return e.err }
+
+// isRetriableDownloadError 超时、连接失败、5xx 等临时错误可以重试
+func isRetriableDownloadError(err error) bool {
+	var permanent *permanentDownloadError
+	return !errors.As(err, &permanent)
+}
+
+// isRetriableStatus 判断 HTTP 状态码是否为临时错误：5xx、408 请求超时和 429 请求过多
+func isRetriableStatus(code int) bool {
+	return code >= 500 || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+// downloadRetryDelay 第 attempt 次重试前的等待时间：指数退避加最多一半的随机抖动，避免同时重试
+func downloadRetryDelay(attempt int) time.Duration {
+	delay := downloadRetryBase << attempt
+	if jitter := int64(delay / 2); jitter > 0 {
+		delay += time.Duration(rand.Int63n(jitter))
+	}
+	return delay
+}
+
+// downloadWithRetry 下载单个地址，临时错误按指数退避重试 DownloadRetries 次，永久错误立即返回
+func (i *Installer) downloadWithRetry(url, path string) error {
+	retries := i.DownloadRetries
+	if retries < 0 {
+		retries = 0
+	}
+
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			delay := downloadRetryDelay(attempt - 1)
+			i.addLog(fmt.Sprintf("🔄 %.1f 秒后第 %d/%d 次重试: %s", delay.Seconds(), attempt, retries, url))
+			time.Sleep(delay)
+		}
+
+		if err = i.downloadFile(url, path); err == nil {
+			return nil
+		}
+		os.Remove(path)
+		if !isRetriableDownloadError(err) {
+			i.addLog(fmt.Sprintf("❌ %v，不再重试该地址", err))
+			return err
+		}
+		i.addLog(fmt.Sprintf("⚠️ 第 %d 次下载失败: %v", attempt+1, err))
+	}
+	return err
+}
+
+// downloadFromMirrors 依次尝试各个镜像，每个镜像先按 downloadWithRetry 重试，仍失败再换下一个
+func (i *Installer) downloadFromMirrors(urls []string, path string) error {
+	_, err := i.downloadFromFirstMirror(urls, path)
+	return err
+}
+
+// downloadFromFirstMirror 与 downloadFromMirrors 相同，同时返回下载成功的地址
+func (i *Installer) downloadFromFirstMirror(urls []string, path string) (string, error) {
+	var err error
+	for idx, url := range urls {
+		if err = i.downloadWithRetry(url, path); err == nil {
+			return url, nil
+		}
+		if idx < len(urls)-1 {
+			i.addLog(fmt.Sprintf("⚠️ 镜像 %d 下载失败，尝试下一个镜像", idx+1))
+		}
+	}
+	return "", err
+}
+
+// minDownloadSize 安装包的最小大小，更小的文件通常是镜像返回的错误页面
+var minDownloadSize int64 = 1 << 20
+
+// sniffLen 判断内容类型时读取的开头字节数，与 http.DetectContentType 一致
+const sniffLen = 512
+
+// isHTMLContentType 判断 Content-Type 是否为网页
+func isHTMLContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// checkDownloadBody 拒绝网页响应：部分镜像会以 200 状态码返回验证码或错误页面
+// 同时检查 Content-Type 和内容开头，返回的 reader 包含已读取的开头部分
+func checkDownloadBody(resp *http.Response) (io.Reader, error) {
+	if isHTMLContentType(resp.Header.Get("Content-Type")) {
+		return nil, &permanentDownloadError{fmt.Errorf("镜像返回了网页而不是安装包（Content-Type: %s），可能需要验证码或已失效", resp.Header.Get("Content-Type"))}
+	}
+
+	br := bufio.NewReaderSize(resp.Body, sniffLen)
+	head, _ := br.Peek(sniffLen)
+	if len(head) > 0 && isHTMLContentType(http.DetectContentType(head)) {
+		return nil, &permanentDownloadError{fmt.Errorf("镜像返回了网页而不是安装包，可能需要验证码或已失效")}
+	}
+	return br, nil
+}
+
+// maxDownloadRedirects 下载时最多跟随的重定向次数
+const maxDownloadRedirects = 5
+
+// errMirrorAuthWall 镜像把下载重定向到登录、验证码页面或无关的网站
+var errMirrorAuthWall = errors.New("镜像需要登录或已失效")
+
+// authWallKeywords 重定向地址中出现时视为登录或验证码页面
+var authWallKeywords = []string{"login", "signin", "sign-in", "sso", "passport", "oauth", "captcha", "verify", "auth"}
+
+// downloadRedirectHosts 镜像常用的下载 CDN，重定向到这些域名（及其子域名）视为正常
+var downloadRedirectHosts = []string{
+	"githubusercontent.com", // GitHub Releases 的下载地址
+	"github.com",
+	"npmmirror.com",
+	"nodejs.org",
+	"aliyuncs.com",
+	"myhuaweicloud.com",
+	"tsinghua.edu.cn",
+	"ustc.edu.cn",
+}
+
+// checkDownloadRedirect 检查下载的重定向：过多的重定向、登录或验证码页面，
+// 以及与原地址和常用 CDN 都无关的网站都视为镜像失效，换下一个镜像
+func checkDownloadRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxDownloadRedirects {
+		return &permanentDownloadError{fmt.Errorf("%w: 重定向次数过多（超过 %d 次）", errMirrorAuthWall, maxDownloadRedirects)}
+	}
+	target := req.URL
+	lower := strings.ToLower(target.Host + target.Path)
+	for _, keyword := range authWallKeywords {
+		if strings.Contains(lower, keyword) {
+			return &permanentDownloadError{fmt.Errorf("%w: 被重定向到登录或验证页面 %s", errMirrorAuthWall, target.Redacted())}
+		}
+	}
+	if !expectedRedirectHost(via[0].URL, target) {
+		return &permanentDownloadError{fmt.Errorf("%w: 被重定向到无关的网站 %s", errMirrorAuthWall, target.Redacted())}
+	}
+	return nil
+}
+
+// expectedRedirectHost 重定向目标与原地址属于同一网站，或是常用的下载 CDN
+func expectedRedirectHost(from, to *url.URL) bool {
+	toHost := strings.ToLower(to.Hostname())
+	if toHost == strings.ToLower(from.Hostname()) || baseDomain(toHost) == baseDomain(strings.ToLower(from.Hostname())) {
+		return true
+	}
+	for _, host := range downloadRedirectHosts {
+		if toHost == host || strings.HasSuffix(toHost, "."+host) {
+			return true
+		}
+	}
+	return false
+}
+
+// baseDomain 返回主机名的主域名，如 cdn.npmmirror.com 为 npmmirror.com，
+// mirrors.tuna.tsinghua.edu.cn 为 tsinghua.edu.cn；IP 地址原样返回
+func baseDomain(host string) string {
+	labels := strings.Split(host, ".")
+	if len(labels) <= 2 || net.ParseIP(host) != nil {
+		return host
+	}
+	keep := 2
+	switch labels[len(labels)-2] {
+	case "com", "net", "org", "edu", "gov", "ac":
+		keep = 3
+	}
+	if len(labels) < keep {
+		return host
+	}
+	return strings.Join(labels[len(labels)-keep:], ".")
+}
+
+// checkDownloadSize 检查下载的字节数与 Content-Length 一致且不小于 minDownloadSize
+func checkDownloadSize(written, contentLength int64) error {
+	if contentLength > 0 && written != contentLength {
+		return fmt.Errorf("下载不完整: 收到 %d 字节，应为 %d 字节", written, contentLength)
+	}
+	if written < minDownloadSize {
+		return &permanentDownloadError{fmt.Errorf("下载的文件只有 %d 字节，不是有效的安装包", written)}
+	}
+	return nil
+}
+
+// downloadProgressInterval 下载时更新进度条的间隔
+const downloadProgressInterval = 250 * time.Millisecond
+
+// averageSpeed 下载开始以来的平均速度，单位 MB/s
+func (pr *progressReader) averageSpeed() float64 {
+	elapsed := time.Since(pr.StartTime).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(pr.Current) / elapsed / 1024 / 1024
+}
+
+// eta 使用平均速度预估剩余时间（比瞬时速度更稳定）
+func (pr *progressReader) eta() string {
+	avgSpeed := pr.averageSpeed()
+	if avgSpeed <= 0 {
+		return "计算中..."
+	}
+	etaSeconds := float64(pr.Total-pr.Current) / (avgSpeed * 1024 * 1024)
+	switch {
+	case etaSeconds < 60:
+		return fmt.Sprintf("%.0f秒", etaSeconds)
+	case etaSeconds < 3600:
+		return fmt.Sprintf("%.0f分钟", etaSeconds/60)
+	default:
+		return fmt.Sprintf("%.1f小时", etaSeconds/3600)
+	}
+}
+
+// sendProgress 把下载比例映射到当前步骤的进度范围内，并附带速度和剩余时间
+func (pr *progressReader) sendProgress() {
+	fraction := float64(pr.Current) / float64(pr.Total)
+	if fraction > 1 {
+		fraction = 1
+	}
+	message := fmt.Sprintf("正在下载 %.1f%% (%.1f/%.1f MB)", fraction*100,
+		float64(pr.Current)/1024/1024, float64(pr.Total)/1024/1024)
+	detail := fmt.Sprintf("%.1f MB/s · 剩余 %s", pr.averageSpeed(), pr.eta())
+	pr.Installer.sendStepDetail(fraction, message, detail)
+}
@@ -0,0 +1,472 @@
+package installer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadFromMirrorsRetriesTransientErrors(t *testing.T) {
+	defer func(base time.Duration) { downloadRetryBase = base }(downloadRetryBase)
+	downloadRetryBase = time.Millisecond
+	defer func(size int64) { minDownloadSize = size }(minDownloadSize)
+	minDownloadSize = 0
+
+	var missingHits, flakyHits int32
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&missingHits, 1)
+		http.NotFound(w, r)
+	}))
+	defer missing.Close()
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&flakyHits, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("node"))
+	}))
+	defer flaky.Close()
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	path := filepath.Join(t.TempDir(), "node.tar.xz")
+	if err := i.downloadFromMirrors([]string{missing.URL, flaky.URL}, path); err != nil {
+		t.Fatalf("downloadFromMirrors: %v", err)
+	}
+
+	if missingHits != 1 {
+		t.Errorf("404 should not be retried, got %d requests", missingHits)
+	}
+	if flakyHits != 3 {
+		t.Errorf("expected 2 retries after 503, got %d requests", flakyHits)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "node" {
+		t.Errorf("unexpected downloaded content %q", data)
+	}
+}
+
+func TestDownloadWithRetryGivesUp(t *testing.T) {
+	defer func(base time.Duration) { downloadRetryBase = base }(downloadRetryBase)
+	downloadRetryBase = time.Millisecond
+
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.DownloadRetries = 1
+	if err := i.downloadWithRetry(server.URL, filepath.Join(t.TempDir(), "file")); err == nil {
+		t.Fatal("expected an error after exhausting retries")
+	}
+	if hits != 2 {
+		t.Errorf("expected 2 attempts with DownloadRetries=1, got %d", hits)
+	}
+}
+
+func TestProbeServersAndOrderMirrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	reachable := probeServers(&http.Client{Timeout: time.Second}, []string{down.URL, up.URL})
+	if !reachable[up.URL] || reachable[down.URL] {
+		t.Fatalf("unexpected reachability %v", reachable)
+	}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.reachableMirrors = reachable
+	urls := []string{down.URL + "/v20/node.msi", up.URL + "/v20/node.msi"}
+	ordered := i.orderMirrors(urls)
+	if ordered[0] != urls[1] || ordered[1] != urls[0] {
+		t.Errorf("reachable mirror should come first, got %v", ordered)
+	}
+}
+
+func TestOfflineArtifactVerifiesChecksum(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	data := []byte("node installer")
+	if err := os.WriteFile(filepath.Join(dir, "node-v22.11.0.pkg"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(data)
+	checksums := hex.EncodeToString(sum[:]) + "  node-v22.11.0.pkg\n" +
+		hex.EncodeToString(sum[:]) + " *Git-2.50.1-64-bit.exe\n"
+	if err := os.WriteFile(filepath.Join(dir, OfflineChecksumFile), []byte(checksums), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := New()
+	inst.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	if path, err := inst.offlineArtifact("node-v22.11.0.pkg"); err != nil || path != "" {
+		t.Errorf("offlineArtifact without OfflineDir = %q, %v; want empty path", path, err)
+	}
+
+	inst.OfflineDir = dir
+	if path, err := inst.offlineArtifact("node-v22.11.0.pkg"); err != nil || path != filepath.Join(dir, "node-v22.11.0.pkg") {
+		t.Errorf("offlineArtifact = %q, %v; want verified local path", path, err)
+	}
+	if _, err := inst.offlineArtifact("node-v22.11.0-x64.msi"); err == nil {
+		t.Error("offlineArtifact should fail when the file is missing")
+	}
+
+	// 文件内容与校验值不一致
+	if err := os.WriteFile(filepath.Join(dir, "Git-2.50.1-64-bit.exe"), []byte("corrupted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := inst.offlineArtifact("Git-2.50.1-64-bit.exe"); err == nil {
+		t.Error("offlineArtifact should fail on checksum mismatch")
+	}
+}
+
+func TestFindClaudeCodeTarball(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"anthropic-ai-claude-code-1.0.9.tgz", "anthropic-ai-claude-code-1.0.51.tgz", "other.tgz"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if name, err := findClaudeCodeTarball(dir, "latest"); err != nil || name != "anthropic-ai-claude-code-1.0.51.tgz" {
+		t.Errorf("findClaudeCodeTarball(latest) = %q, %v; want the highest version", name, err)
+	}
+	if name, err := findClaudeCodeTarball(dir, "1.0.9"); err != nil || name != "anthropic-ai-claude-code-1.0.9.tgz" {
+		t.Errorf("findClaudeCodeTarball(1.0.9) = %q, %v; want the pinned version", name, err)
+	}
+	if _, err := findClaudeCodeTarball(dir, "1.0.60"); err == nil {
+		t.Error("findClaudeCodeTarball should fail when the pinned version is missing")
+	}
+	if _, err := findClaudeCodeTarball(t.TempDir(), "latest"); err == nil {
+		t.Error("findClaudeCodeTarball should fail on an empty directory")
+	}
+}
+
+func TestDownloadFromMirrorsSkipsHTMLPages(t *testing.T) {
+	defer func(size int64) { minDownloadSize = size }(minDownloadSize)
+	minDownloadSize = 16
+
+	var captchaHits, sniffedHits int32
+	captcha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&captchaHits, 1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html>captcha</html>"))
+	}))
+	defer captcha.Close()
+	// Content-Type 声称是二进制文件，但内容是网页
+	sniffed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sniffedHits, 1)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("<!DOCTYPE html><html><body>blocked</body></html>"))
+	}))
+	defer sniffed.Close()
+	tiny := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("short"))
+	}))
+	defer tiny.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("node installer package"))
+	}))
+	defer good.Close()
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	path := filepath.Join(t.TempDir(), "node.pkg")
+	if err := i.downloadFromMirrors([]string{captcha.URL, sniffed.URL, tiny.URL, good.URL}, path); err != nil {
+		t.Fatalf("downloadFromMirrors: %v", err)
+	}
+	if captchaHits != 1 || sniffedHits != 1 {
+		t.Errorf("HTML pages should not be retried, got %d and %d requests", captchaHits, sniffedHits)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "node installer package" {
+		t.Errorf("unexpected downloaded content %q", data)
+	}
+}
+
+func TestDownloadFromMirrorsSkipsLoginRedirects(t *testing.T) {
+	defer func(size int64) { minDownloadSize = size }(minDownloadSize)
+	minDownloadSize = 16
+
+	var loginHits int32
+	login := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/login") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html>please sign in</html>"))
+			return
+		}
+		atomic.AddInt32(&loginHits, 1)
+		http.Redirect(w, r, "/login?next="+r.URL.Path, http.StatusFound)
+	}))
+	defer login.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/node.pkg" {
+			http.Redirect(w, r, "/files/node.pkg", http.StatusFound)
+			return
+		}
+		w.Write([]byte("node installer package"))
+	}))
+	defer good.Close()
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	path := filepath.Join(t.TempDir(), "node.pkg")
+	err := i.downloadFile(login.URL+"/node.pkg", path)
+	if !errors.Is(err, errMirrorAuthWall) || isRetriableDownloadError(err) {
+		t.Errorf("login redirect should fail permanently with errMirrorAuthWall, got %v", err)
+	}
+	if err := i.downloadFromMirrors([]string{login.URL + "/node.pkg", good.URL + "/node.pkg"}, path); err != nil {
+		t.Fatalf("downloadFromMirrors: %v", err)
+	}
+	if loginHits != 2 {
+		t.Errorf("login redirects should not be retried, got %d requests", loginHits)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "node installer package" {
+		t.Errorf("unexpected downloaded content %q", data)
+	}
+}
+
+func TestCheckDownloadRedirect(t *testing.T) {
+	tests := []struct {
+		from, to string
+		ok       bool
+	}{
+		{"https://github.com/a/b/releases/download/v1/x.zip", "https://objects.githubusercontent.com/release/x.zip", true},
+		{"https://registry.npmmirror.com/-/binary/node/x.pkg", "https://cdn.npmmirror.com/binaries/node/x.pkg", true},
+		{"https://mirrors.tuna.tsinghua.edu.cn/x.pkg", "https://mirrors4.tuna.tsinghua.edu.cn/x.pkg", true},
+		{"https://mirror.example.com/x.pkg", "https://files.example.com/x.pkg", true},
+		{"https://mirror.example.com/x.pkg", "https://mirror.example.com/login?next=/x.pkg", false},
+		{"https://mirror.example.com/x.pkg", "https://captcha.example.com/check", false},
+		{"https://mirror.example.com/x.pkg", "https://portal.isp.net/welcome", false},
+	}
+	for _, tt := range tests {
+		from, _ := http.NewRequest("GET", tt.from, nil)
+		to, _ := http.NewRequest("GET", tt.to, nil)
+		err := checkDownloadRedirect(to, []*http.Request{from})
+		if (err == nil) != tt.ok {
+			t.Errorf("checkDownloadRedirect(%s -> %s) = %v, want ok=%v", tt.from, tt.to, err, tt.ok)
+		}
+	}
+
+	from, _ := http.NewRequest("GET", "https://mirror.example.com/x.pkg", nil)
+	via := make([]*http.Request, maxDownloadRedirects)
+	for idx := range via {
+		via[idx] = from
+	}
+	if err := checkDownloadRedirect(from, via); !errors.Is(err, errMirrorAuthWall) {
+		t.Errorf("too many redirects should fail with errMirrorAuthWall, got %v", err)
+	}
+}
+
+func TestCustomMirrorsAndProxy(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.NodeMirror = "https://npmmirror.com/mirrors/node/"
+	urls := i.nodeURLs("20.10.0", "node-v20.10.0.pkg")
+	if urls[0] != "https://npmmirror.com/mirrors/node/v20.10.0/node-v20.10.0.pkg" {
+		t.Errorf("custom mirror should come first, got %v", urls)
+	}
+	if len(urls) != len(nodeDownloadURLs("20.10.0", "node-v20.10.0.pkg"))+1 {
+		t.Errorf("built-in mirrors should be kept, got %v", urls)
+	}
+
+	if got := i.npmRegistry(); got != npmRegistryURL {
+		t.Errorf("npmRegistry() = %s, want default %s", got, npmRegistryURL)
+	}
+	i.NPMRegistry = "https://registry.npmjs.org/"
+	if got := i.npmRegistry(); got != "https://registry.npmjs.org" {
+		t.Errorf("npmRegistry() = %s", got)
+	}
+
+	for _, proxy := range []string{"http://127.0.0.1:7890", "socks5://proxy.local:1080"} {
+		if err := ValidateProxyURL(proxy); err != nil {
+			t.Errorf("ValidateProxyURL(%q): %v", proxy, err)
+		}
+	}
+	for _, proxy := range []string{"127.0.0.1:7890", "ftp://proxy.local", "http://"} {
+		if err := ValidateProxyURL(proxy); err == nil {
+			t.Errorf("ValidateProxyURL(%q) should fail", proxy)
+		}
+	}
+	if err := ValidateMirrorURL("npmmirror.com/mirrors/node"); err == nil {
+		t.Error("mirror without scheme should be rejected")
+	}
+}
+
+func TestCachedDownloadReusesVerifiedFile(t *testing.T) {
+	defer func(size int64) { minDownloadSize = size }(minDownloadSize)
+	minDownloadSize = 0
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	content := []byte("node installer package")
+	sum := sha256.Sum256(content)
+	expected := hex.EncodeToString(sum[:])
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	path, err := i.cachedDownload("node-v1.0.0.pkg", []string{server.URL}, expected)
+	if err != nil {
+		t.Fatalf("cachedDownload: %v", err)
+	}
+	if _, err := i.cachedDownload("node-v1.0.0.pkg", []string{server.URL}, expected); err != nil || hits != 1 {
+		t.Errorf("second download should reuse the cache, err=%v requests=%d", err, hits)
+	}
+	if size, err := DownloadCacheSize(); err != nil || size < int64(len(content)) {
+		t.Errorf("DownloadCacheSize() = %d, %v", size, err)
+	}
+
+	// 缓存文件损坏时重新下载
+	os.WriteFile(path, []byte("corrupted"), 0644)
+	if _, err := i.cachedDownload("node-v1.0.0.pkg", []string{server.URL}, ""); err != nil || hits != 2 {
+		t.Errorf("corrupted cache should be downloaded again, err=%v requests=%d", err, hits)
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(content) {
+		t.Errorf("cache content = %q after re-download", data)
+	}
+
+	// 与官方校验值不一致的下载不放入缓存
+	if _, err := i.cachedDownload("node-v2.0.0.pkg", []string{server.URL}, "0000"); err == nil {
+		t.Errorf("checksum mismatch should fail")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "node-v2.0.0.pkg")); err == nil {
+		t.Errorf("file with wrong checksum was cached")
+	}
+
+	if err := ClearDownloadCache(); err != nil {
+		t.Fatalf("ClearDownloadCache: %v", err)
+	}
+	if size, _ := DownloadCacheSize(); size != 0 {
+		t.Errorf("cache size after clear = %d", size)
+	}
+}
+
+func TestDownloadFileDetectsStall(t *testing.T) {
+	defer func(size int64) { minDownloadSize = size }(minDownloadSize)
+	minDownloadSize = 0
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1024")
+		w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		// 连接保持打开但不再发送数据
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.StallTimeout = 100 * time.Millisecond
+	start := time.Now()
+	err := i.downloadFile(server.URL, filepath.Join(t.TempDir(), "file"))
+	if err == nil || !strings.Contains(err.Error(), "停滞") {
+		t.Fatalf("expected a stall error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stall was detected too late: %v", elapsed)
+	}
+}
+
+func TestUseSlowNetwork(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	if i.DownloadTimeout != DefaultDownloadTimeout || i.StallTimeout != DefaultStallTimeout {
+		t.Errorf("unexpected default timeouts %v/%v", i.DownloadTimeout, i.StallTimeout)
+	}
+	i.UseSlowNetwork()
+	if i.downloadTimeout() != SlowNetworkDownloadTimeout || i.stallTimeout() != SlowNetworkStallTimeout {
+		t.Errorf("slow network mode not applied: %v/%v", i.DownloadTimeout, i.StallTimeout)
+	}
+}
+
+func TestForceIPv4Dialing(t *testing.T) {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("no IPv4 loopback: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	t.Setenv("HOME", t.TempDir())
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.ForceIPv4 = true
+	dial := i.dialContext()
+	conn, err := dial(context.Background(), "tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatalf("dialing IPv4 with ForceIPv4: %v", err)
+	}
+	conn.Close()
+	if conn, err := dial(context.Background(), "tcp", fmt.Sprintf("[::1]:%d", port)); err == nil {
+		conn.Close()
+		t.Error("ForceIPv4 should refuse IPv6 addresses")
+	}
+
+	t.Setenv("NODE_OPTIONS", "--max-old-space-size=4096")
+	i.applyIPv4Env()
+	if got := os.Getenv("NODE_OPTIONS"); got != "--max-old-space-size=4096 "+ipv4FirstNodeOption {
+		t.Errorf("NODE_OPTIONS = %q", got)
+	}
+}
+
+func TestReachableNpmRegistriesFallsBack(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+	defer func(registries []string) { npmFallbackRegistries = registries }(npmFallbackRegistries)
+	npmFallbackRegistries = []string{down.URL, up.URL}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.NPMRegistry = down.URL + "/"
+	if got := i.npmRegistries(); len(got) != 2 || got[0] != down.URL || got[1] != up.URL {
+		t.Fatalf("npmRegistries() = %v, want configured registry first without duplicates", got)
+	}
+	registries, err := i.reachableNpmRegistries()
+	if err != nil || len(registries) != 1 || registries[0] != up.URL {
+		t.Fatalf("reachableNpmRegistries() = %v, %v; want only %s", registries, err, up.URL)
+	}
+
+	npmFallbackRegistries = []string{down.URL}
+	if _, err := i.reachableNpmRegistries(); !errors.Is(err, ErrNpmRegistryUnreachable) {
+		t.Errorf("expected ErrNpmRegistryUnreachable when every registry is down, got %v", err)
+	}
+
+	i.OfflineDir = t.TempDir()
+	if registries, err := i.reachableNpmRegistries(); err != nil || len(registries) != 1 {
+		t.Errorf("offline install should not probe registries, got %v, %v", registries, err)
+	}
+}
@@ -0,0 +1,182 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// downloadCacheDirName 下载缓存的目录名，位于 ~/.claude-k2-installer 下
+const downloadCacheDirName = "cache"
+
+// cacheEntrySuffix 缓存文件旁记录来源和校验值的说明文件后缀
+const cacheEntrySuffix = ".json"
+
+// cacheEntry 缓存的安装包信息。文件名即安装包名，已包含版本号；
+// 只有文件的 SHA-256 与记录一致（并与官方校验值一致）时才会复用
+type cacheEntry struct {
+	URL          string    `json:"url"`
+	SHA256       string    `json:"sha256"`
+	Size         int64     `json:"size"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
+
+// downloadCacheDir 下载缓存目录
+func downloadCacheDir() (string, error) {
+	dir, err := logDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, downloadCacheDirName), nil
+}
+
+// DownloadCacheSize 返回下载缓存占用的字节数，缓存目录不存在时为 0
+func DownloadCacheSize() (int64, error) {
+	dir, err := downloadCacheDir()
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// ClearDownloadCache 删除所有缓存的安装包
+func ClearDownloadCache() error {
+	dir, err := downloadCacheDir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// cachedDownload 返回缓存中校验通过的安装包路径，没有时从 urls 下载到缓存。
+// expectedSHA256 为官方校验值，不为空时缓存和新下载的文件都必须与之一致；
+// 为空时只用下载时记录的校验值确认缓存文件没有损坏
+func (i *Installer) cachedDownload(artifact string, urls []string, expectedSHA256 string) (string, error) {
+	dir, err := downloadCacheDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, artifact)
+	if i.validCacheEntry(path, expectedSHA256) {
+		i.addLog(fmt.Sprintf("♻️ 使用已缓存的安装包: %s", path))
+		return path, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+	// 先下载到临时文件，校验通过后再放入缓存，中断的下载不会被当作缓存
+	partPath := path + ".part"
+	defer os.Remove(partPath)
+	url, err := i.downloadFromFirstMirror(urls, partPath)
+	if err != nil {
+		return "", err
+	}
+
+	sum, err := fileSHA256(partPath)
+	if err != nil {
+		return "", fmt.Errorf("计算 %s 的校验值失败: %v", artifact, err)
+	}
+	if expectedSHA256 != "" && sum != expectedSHA256 {
+		return "", fmt.Errorf("%s 校验失败: SHA-256 为 %s，应为 %s，下载的文件可能已损坏", artifact, sum, expectedSHA256)
+	}
+	info, err := os.Stat(partPath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(partPath, path); err != nil {
+		return "", fmt.Errorf("保存到缓存失败: %v", err)
+	}
+
+	entry := cacheEntry{URL: url, SHA256: sum, Size: info.Size(), DownloadedAt: time.Now()}
+	if data, err := json.MarshalIndent(entry, "", "  "); err == nil {
+		err = os.WriteFile(path+cacheEntrySuffix, data, 0644)
+		if err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 写入缓存信息失败: %v", err))
+		}
+	}
+	i.addLog(fmt.Sprintf("✅ 已下载并缓存 %s（SHA-256 %s）", artifact, sum))
+	return path, nil
+}
+
+// validCacheEntry 缓存文件存在且校验值与记录（以及 expectedSHA256）一致，不一致时删除该缓存
+func (i *Installer) validCacheEntry(path, expectedSHA256 string) bool {
+	data, err := os.ReadFile(path + cacheEntrySuffix)
+	if err != nil {
+		return false
+	}
+	var entry cacheEntry
+	valid := json.Unmarshal(data, &entry) == nil && entry.SHA256 != "" &&
+		(expectedSHA256 == "" || entry.SHA256 == expectedSHA256)
+	if valid {
+		sum, err := fileSHA256(path)
+		valid = err == nil && sum == entry.SHA256
+	}
+	if !valid {
+		i.addLog(fmt.Sprintf("⚠️ 缓存的 %s 校验不一致，重新下载", filepath.Base(path)))
+		os.Remove(path)
+		os.Remove(path + cacheEntrySuffix)
+	}
+	return valid
+}
+
+// discardCachedDownload 删除缓存中的安装包及其说明文件，不在缓存目录中的文件（如离线安装包）不会删除
+func (i *Installer) discardCachedDownload(path string) {
+	dir, err := downloadCacheDir()
+	if err != nil || path == "" || filepath.Dir(path) != dir {
+		return
+	}
+	os.Remove(path)
+	os.Remove(path + cacheEntrySuffix)
+	i.addLog(fmt.Sprintf("🗑️ 已删除缓存的 %s", filepath.Base(path)))
+}
+
+// nodeChecksum 从镜像获取 Node.js 官方 SHASUMS256.txt 中 artifact 的校验值，获取失败时返回空字符串
+func (i *Installer) nodeChecksum(version, artifact string) string {
+	client := i.httpClient(15 * time.Second)
+	for _, url := range i.nodeURLs(version, OfflineChecksumFile) {
+		resp, err := client.Get(url)
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK {
+			continue
+		}
+		if sum := parseChecksums(string(data))[artifact]; sum != "" {
+			return sum
+		}
+	}
+	i.addLog(fmt.Sprintf("ℹ️ 无法获取 %s 的官方校验值，只检查缓存文件是否完整", artifact))
+	return ""
+}
+
+// cachedInstaller 为安装脚本准备本地安装包：优先使用缓存，没有时在程序中下载到缓存。
+// 失败时返回空字符串，由安装脚本按原来的方式自行下载
+func (i *Installer) cachedInstaller(artifact string, urls []string, expectedSHA256 string) string {
+	path, err := i.cachedDownload(artifact, urls, expectedSHA256)
+	if err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 下载到缓存失败，改由安装脚本下载: %v", err))
+		return ""
+	}
+	return path
+}
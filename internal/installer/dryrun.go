@@ -0,0 +1,178 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// dryRunPrefix 模拟运行时日志的前缀
+const dryRunPrefix = "🔍 [模拟运行]"
+
+// planComponent 记录模拟运行中将要安装的组件及其操作，不修改系统
+func (i *Installer) planComponent(component string, actions ...string) {
+	// 重试时同一组件可能再次规划，只记录一次
+	if !slices.Contains(i.plannedComponents, component) {
+		i.plannedComponents = append(i.plannedComponents, component)
+	}
+	i.addLog(fmt.Sprintf("%s %s，将执行:", dryRunPrefix, component))
+	for _, action := range actions {
+		i.addLog("    - " + action)
+	}
+}
+
+// PlannedComponents 返回模拟运行中将要安装的组件
+func (i *Installer) PlannedComponents() []string {
+	return i.plannedComponents
+}
+
+// DryRunSummary 返回模拟运行的总结
+func (i *Installer) DryRunSummary() string {
+	if len(i.plannedComponents) == 0 {
+		return "模拟运行完成：所有组件均已安装，无需改动"
+	}
+	return fmt.Sprintf("模拟运行完成，将安装或配置: %s", strings.Join(i.plannedComponents, "、"))
+}
+
+// planNodeJS 列出安装 Node.js 将执行的操作
+func (i *Installer) planNodeJS() error {
+	version := i.nodeTargetVersion()
+	if err := ValidateNodeTargetVersion(version, i.minNodeVersion()); err != nil {
+		return err
+	}
+	arch := runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		arch = windowsNativeArch()
+	}
+	artifact, err := nodeArtifactName(version, runtime.GOOS, arch)
+	if err != nil {
+		return err
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		i.planComponent("Node.js",
+			fmt.Sprintf("下载 %s（阿里云 / npmmirror / nodejs.org）", artifact),
+			"静默安装到 C:\\Program Files\\nodejs 并加入 PATH")
+	case "darwin":
+		var actions []string
+		if exec.Command("brew", "--version").Run() != nil {
+			actions = append(actions, "使用国内镜像安装 Homebrew（需要管理员密码）")
+		}
+		if home, err := os.UserHomeDir(); err == nil && !homebrewShellenvConfigured(home) {
+			actions = append(actions, "将 brew shellenv 写入 shell 配置文件")
+		}
+		actions = append(actions,
+			"brew install node（中国科技大学镜像源）",
+			fmt.Sprintf("Homebrew 失败时下载 %s 安装包", artifact))
+		i.planComponent("Node.js", actions...)
+	case "linux":
+		if pm, ok := findLinuxPackageManager(); ok {
+			i.planComponent("Node.js", describeCommands(pm.commands(true, "nodejs", "npm"))...)
+		} else {
+			i.planComponent("Node.js",
+				fmt.Sprintf("下载 %s 并解压到 ~/.local/node", artifact),
+				"将 ~/.local/node/bin 加入 PATH 和 shell 配置文件")
+		}
+	default:
+		return fmt.Errorf("不支持的操作系统")
+	}
+	return nil
+}
+
+// planGit 列出安装 Git 将执行的操作
+func (i *Installer) planGit() error {
+	switch runtime.GOOS {
+	case "windows":
+		version := i.gitTargetVersion()
+		if err := ValidateGitTargetVersion(version, i.MinGitVersion); err != nil {
+			return err
+		}
+		i.planComponent("Git",
+			fmt.Sprintf("下载 %s 安装包（npmmirror / GitHub / 清华镜像）", gitWindowsArtifact(version, windowsNativeArch())),
+			"静默安装到 C:\\Program Files\\Git 并加入 PATH")
+	case "darwin":
+		if exec.Command("brew", "--version").Run() == nil {
+			i.planComponent("Git", "brew install git（中国科技大学镜像源）")
+		} else {
+			i.planComponent("Git", "xcode-select --install 安装 Xcode Command Line Tools")
+		}
+	case "linux":
+		if pm, ok := findLinuxPackageManager(); ok {
+			i.planComponent("Git", describeCommands(pm.commands(false, "git"))...)
+		} else {
+			return fmt.Errorf("无法自动安装 Git，请手动安装")
+		}
+	default:
+		return fmt.Errorf("不支持的操作系统")
+	}
+	return nil
+}
+
+// planClaudeCode 列出安装 Claude Code 将执行的操作
+func (i *Installer) planClaudeCode() error {
+	if output, err := exec.Command("claude", "--version").Output(); err == nil {
+		i.addLog(fmt.Sprintf("%s 已安装 Claude Code %s，将重新安装为 %s", dryRunPrefix, strings.TrimSpace(string(output)), i.claudeCodePackageSpec()))
+	}
+	if err := ValidateClaudeCodeVersion(i.claudeCodeTargetVersion()); err != nil {
+		return err
+	}
+	actions := []string{fmt.Sprintf("npm install -g %s --registry=%s", i.claudeCodePackageSpec(), i.npmRegistry())}
+	if prefix, err := npmGlobalPrefix(); err == nil {
+		if modulesDir, _ := npmGlobalDirs(prefix); !dirWritable(modulesDir) {
+			if i.NPMStrategy == NPMStrategySudo && runtime.GOOS == "linux" {
+				actions = append(actions, fmt.Sprintf("npm 全局目录 %s 不可写，使用 sudo 安装", prefix))
+			} else {
+				actions = append(actions, fmt.Sprintf("npm 全局目录 %s 不可写，改用 ~/.npm-global 并加入 PATH", prefix))
+			}
+		}
+	}
+	i.planComponent("Claude Code", actions...)
+	return nil
+}
+
+// planConfigure 列出配置 API 将修改的环境变量和文件
+func (i *Installer) planConfigure(provider Provider, rpm string, useSystemConfig bool) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("获取用户目录失败: %v", err)
+	}
+
+	envVars := fmt.Sprintf("ANTHROPIC_BASE_URL=%s、%s、CLAUDE_REQUEST_DELAY_MS、CLAUDE_MAX_CONCURRENT_REQUESTS", provider.BaseURL, provider.EnvKeyName)
+
+	var actions []string
+	if useSystemConfig && i.ConfigStrategy.writesSettings() {
+		actions = append(actions, fmt.Sprintf("在 %s 的 env 中写入: %s（与已有设置合并）", claudeSettingsPath(home), envVars))
+	}
+	switch {
+	case useSystemConfig && !i.ConfigStrategy.writesShell():
+		actions = append(actions, "不修改 shell 配置文件和系统环境变量")
+	case runtime.GOOS == "windows" && useSystemConfig:
+		actions = append(actions, "使用 setx 设置用户环境变量: "+envVars)
+		actions = append(actions, fmt.Sprintf("删除用户环境变量 %s，避免认证冲突", provider.ConflictingEnvKey()))
+		for _, profile := range powerShellProfiles(home) {
+			actions = append(actions, fmt.Sprintf("在 PowerShell 配置 %s 中写入: %s", profile, envVars))
+		}
+	case runtime.GOOS == "windows":
+		actions = append(actions, "创建临时脚本 "+SetupScriptPath())
+	case useSystemConfig:
+		for _, shellConfig := range shellConfigFiles(home) {
+			actions = append(actions, fmt.Sprintf("在 %s 中追加: %s", shellConfig, envVars))
+		}
+	default:
+		actions = append(actions, "创建临时脚本 "+SetupScriptPath())
+	}
+	if i.WriteClaudeJSON {
+		actions = append(actions, fmt.Sprintf("更新 %s（修改前先备份）", filepath.Join(home, ".claude.json")))
+	} else {
+		actions = append(actions, fmt.Sprintf("不修改 %s（仅配置环境变量）", filepath.Join(home, ".claude.json")))
+	}
+	actions = append(actions, fmt.Sprintf("速率限制: %s RPM", rpm))
+
+	i.planComponent(fmt.Sprintf("%s API 配置", provider.Name), actions...)
+	return nil
+}
@@ -0,0 +1,87 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// IsElevated 判断当前进程是否具有管理员（Windows）或 root（macOS/Linux）权限
+// Windows 上 net session 只有管理员才能执行成功
+func IsElevated() bool {
+	if runtime.GOOS == "windows" {
+		return exec.Command("net", "session").Run() == nil
+	}
+	return os.Geteuid() == 0
+}
+
+// ElevationNeeds 返回按当前环境将要安装、且需要管理员权限的组件及原因
+// 当前进程已有管理员权限或无需安装任何组件时返回 nil
+func ElevationNeeds(status EnvironmentStatus) []string {
+	return elevationNeeds(runtime.GOOS, IsElevated(), status)
+}
+
+// elevationNeeds 按操作系统列出需要提权的安装步骤
+func elevationNeeds(goos string, elevated bool, status EnvironmentStatus) []string {
+	if elevated {
+		return nil
+	}
+
+	var needs []string
+	switch goos {
+	case "windows":
+		if !status.NodeOK {
+			needs = append(needs, "Node.js：msiexec 以 ALLUSERS=1 安装到 C:\\Program Files，缺少管理员权限会报 1603 错误")
+		}
+		if !status.GitOK {
+			needs = append(needs, "Git：安装程序写入 C:\\Program Files\\Git")
+		}
+	case "darwin":
+		if !status.NodeOK {
+			needs = append(needs, "Node.js：安装 pkg 或 Homebrew 时会弹出管理员密码输入框")
+		}
+		if !status.GitOK {
+			needs = append(needs, "Git：通过 Homebrew 或命令行工具安装，可能需要输入管理员密码")
+		}
+	case "linux":
+		if !status.NodeOK {
+			needs = append(needs, "Node.js：通过 sudo 使用系统包管理器安装，需要输入 sudo 密码")
+		}
+		if !status.GitOK {
+			needs = append(needs, "Git：通过 sudo 使用系统包管理器安装，需要输入 sudo 密码")
+		}
+	}
+	return needs
+}
+
+// CanRelaunchElevated 当前系统是否支持以管理员身份重新启动本程序
+func CanRelaunchElevated() bool {
+	return runtime.GOOS == "windows"
+}
+
+// RelaunchElevated 以管理员身份重新启动本程序（通过 ShellExecute 的 runas 触发 UAC 提示）
+// 成功启动新进程后调用方应退出当前进程；用户在 UAC 中拒绝时返回错误
+func RelaunchElevated() error {
+	if !CanRelaunchElevated() {
+		return fmt.Errorf("当前系统不支持自动以管理员身份重新启动")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("获取程序路径失败: %v", err)
+	}
+
+	script := fmt.Sprintf("Start-Process -FilePath %s -Verb RunAs", powerShellQuote(exe))
+	if args := os.Args[1:]; len(args) > 0 {
+		quoted := make([]string, len(args))
+		for n, arg := range args {
+			quoted[n] = powerShellQuote(arg)
+		}
+		script += " -ArgumentList " + strings.Join(quoted, ",")
+	}
+	if output, err := exec.Command("powershell", "-NoProfile", "-Command", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("以管理员身份启动失败: %v %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
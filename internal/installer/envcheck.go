@@ -0,0 +1,64 @@
+package installer
+
+import (
+	"os"
+	"runtime"
+)
+
+// EnvironmentStatus 已安装组件的检测结果
+type EnvironmentStatus struct {
+	NodeOK   bool // Node.js 已安装且版本满足要求
+	GitOK    bool // Git 已安装且版本满足要求
+	ClaudeOK bool
+
+	NodeVersion   string // 检测到的版本，未安装时为空
+	GitVersion    string
+	ClaudeVersion string
+
+	MinNodeVersion int    // 检测时要求的最低 Node.js 主版本
+	MinGitVersion  string // 检测时要求的最低 Git 版本
+}
+
+// Ready Node.js、Git 和 Claude Code 均已安装，只需配置 API
+func (s EnvironmentStatus) Ready() bool {
+	return s.NodeOK && s.GitOK && s.ClaudeOK
+}
+
+// CheckEnvironment 检测 Node.js、Git 和 Claude Code 是否已安装
+//
+// 与安装流程中的检测不同，这里不写日志、不修改 PATH，适合启动时在后台调用。
+func (i *Installer) CheckEnvironment() EnvironmentStatus {
+	status := EnvironmentStatus{MinNodeVersion: i.minNodeVersion(), MinGitVersion: i.minGitVersion()}
+
+	status.NodeVersion = installedVersion("node")
+	if major, _, _, err := parseNodeVersion(status.NodeVersion); err == nil && major >= i.minNodeVersion() {
+		status.NodeOK = true
+	}
+
+	status.GitVersion = installedVersion("git")
+	status.GitOK = status.GitVersion != "" && gitVersionSatisfies(status.GitVersion, i.MinGitVersion)
+
+	status.ClaudeVersion = installedVersion("claude")
+	status.ClaudeOK = status.ClaudeVersion != ""
+
+	return status
+}
+
+// installedVersion 返回命令的版本，PATH 中找不到时在 macOS 上检查常见的安装位置
+func installedVersion(name string) string {
+	if version := commandVersion(name); version != "" {
+		return version
+	}
+
+	if runtime.GOOS == "darwin" {
+		for _, path := range macCommandCandidates(name) {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			if version := commandVersion(path); version != "" {
+				return version
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,186 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// envConflictComment 清理冲突时加在原定义行前的注释前缀，用户可删除前缀手动恢复
+const envConflictComment = "# [Claude Code K2 已注释] "
+
+// anthropicEnvLine 匹配 shell 配置中的 ANTHROPIC_* 定义，兼容 export、fish 的 set -gx 和 csh 的 setenv
+var anthropicEnvLine = regexp.MustCompile(`^(?:export\s+|set\s+-gx\s+|set\s+-x\s+|setenv\s+)?(ANTHROPIC_[A-Z0-9_]+)(?:=|\s+)(.*)$`)
+
+// EnvConflict 一处与 K2 配置冲突的 ANTHROPIC_* 定义
+type EnvConflict struct {
+	Name   string // 变量名
+	Value  string // 当前值，密钥类变量已打码
+	Source string // 配置文件路径，当前环境变量为空
+	Line   int    // 配置文件中的行号（从 1 开始）
+}
+
+// String 返回用于日志和对话框的描述
+func (c EnvConflict) String() string {
+	if c.Source == "" {
+		return fmt.Sprintf("环境变量 %s=%s", c.Name, c.Value)
+	}
+	return fmt.Sprintf("%s:%d %s=%s", c.Source, c.Line, c.Name, c.Value)
+}
+
+// maskEnvValue 密钥类变量只显示前缀
+func maskEnvValue(name, value string) string {
+	if strings.Contains(name, "KEY") || strings.Contains(name, "TOKEN") {
+		return maskKey(value)
+	}
+	return value
+}
+
+// DetectEnvConflicts 安装前检查当前环境变量和 shell 配置文件中已有的 ANTHROPIC_* 定义
+//
+// 当前环境中与即将写入的值不同的变量、以及 K2 配置块之外的定义都会覆盖或干扰 K2 配置，
+// 每处冲突都会写入日志。
+func (i *Installer) DetectEnvConflicts(provider Provider) []EnvConflict {
+	conflicts := environConflicts(os.Environ(), provider)
+	if runtime.GOOS != "windows" {
+		if home, err := os.UserHomeDir(); err == nil {
+			for _, path := range shellProfileFiles(home) {
+				conflicts = append(conflicts, shellFileConflicts(path)...)
+			}
+		}
+	}
+
+	if len(conflicts) == 0 {
+		i.addLog("✅ 未发现冲突的 ANTHROPIC_* 环境变量")
+		return nil
+	}
+	i.addLog(fmt.Sprintf("⚠️ 发现 %d 处冲突的 ANTHROPIC_* 环境变量:", len(conflicts)))
+	for _, conflict := range conflicts {
+		i.addLog("   • " + conflict.String())
+	}
+	return conflicts
+}
+
+// environConflicts 从环境变量列表中找出与 K2 配置冲突的 ANTHROPIC_* 变量
+// 认证变量会被 K2 配置覆盖，不算冲突；地址相同的 ANTHROPIC_BASE_URL 说明已是 K2 配置
+func environConflicts(environ []string, provider Provider) []EnvConflict {
+	var conflicts []EnvConflict
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, "ANTHROPIC_") || value == "" {
+			continue
+		}
+		if name == provider.EnvKeyName || (name == "ANTHROPIC_BASE_URL" && value == provider.BaseURL) {
+			continue
+		}
+		conflicts = append(conflicts, EnvConflict{Name: name, Value: maskEnvValue(name, value)})
+	}
+	return conflicts
+}
+
+// shellFileConflicts 找出 shell 配置文件中 K2 配置块之外的 ANTHROPIC_* 定义
+func shellFileConflicts(path string) []EnvConflict {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var conflicts []EnvConflict
+	inBlock, inLegacy := false, false
+	for idx, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			inBlock = trimmed != k2BlockEnd
+			continue
+		case trimmed == k2BlockStart || trimmed == k2ConfigMarker:
+			inBlock = trimmed == k2BlockStart
+			inLegacy = !inBlock
+			continue
+		case inLegacy && isLegacyEnvLine(trimmed):
+			continue
+		}
+		inLegacy = false
+
+		match := anthropicEnvLine.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+		value := strings.Trim(strings.TrimSpace(match[2]), `"'`)
+		conflicts = append(conflicts, EnvConflict{
+			Name:   match[1],
+			Value:  maskEnvValue(match[1], value),
+			Source: path,
+			Line:   idx + 1,
+		})
+	}
+	return conflicts
+}
+
+// ResolveEnvConflicts 清理冲突的定义：注释掉配置文件中的定义行，
+// 并从当前进程（Windows 下还有用户环境变量）中删除冲突的变量
+func (i *Installer) ResolveEnvConflicts(conflicts []EnvConflict) error {
+	byFile := make(map[string][]EnvConflict)
+	var files []string
+	var failed []string
+	for _, conflict := range conflicts {
+		if conflict.Source == "" {
+			os.Unsetenv(conflict.Name)
+			if runtime.GOOS == "windows" {
+				cmd := exec.Command("reg", "delete", `HKCU\Environment`, "/v", conflict.Name, "/f")
+				if output, err := cmd.CombinedOutput(); err != nil {
+					i.addLog(fmt.Sprintf("⚠️ 删除用户环境变量 %s 失败: %v %s", conflict.Name, err, strings.TrimSpace(string(output))))
+					failed = append(failed, conflict.Name)
+					continue
+				}
+			}
+			i.addLog(fmt.Sprintf("✅ 已移除环境变量 %s", conflict.Name))
+			continue
+		}
+		if _, ok := byFile[conflict.Source]; !ok {
+			files = append(files, conflict.Source)
+		}
+		byFile[conflict.Source] = append(byFile[conflict.Source], conflict)
+	}
+	i.broadcastEnvironmentChange()
+
+	for _, path := range files {
+		if err := commentOutConflicts(path, byFile[path]); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 清理 %s 中的 ANTHROPIC_* 定义失败: %v", path, err))
+			failed = append(failed, path)
+			continue
+		}
+		for _, conflict := range byFile[path] {
+			i.addLog(fmt.Sprintf("✅ 已注释 %s 第 %d 行的 %s", path, conflict.Line, conflict.Name))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("部分冲突未能清理: %s", strings.Join(failed, "、"))
+	}
+	return nil
+}
+
+// commentOutConflicts 在配置文件中给冲突的定义行加上注释前缀，行内容已变化时拒绝修改
+func commentOutConflicts(path string, conflicts []EnvConflict) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	for _, conflict := range conflicts {
+		idx := conflict.Line - 1
+		if idx < 0 || idx >= len(lines) {
+			return fmt.Errorf("第 %d 行不存在，文件可能已被修改", conflict.Line)
+		}
+		match := anthropicEnvLine.FindStringSubmatch(strings.TrimSpace(lines[idx]))
+		if match == nil || match[1] != conflict.Name {
+			return fmt.Errorf("第 %d 行已不是 %s 的定义，文件可能已被修改", conflict.Line, conflict.Name)
+		}
+		lines[idx] = envConflictComment + lines[idx]
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
@@ -0,0 +1,102 @@
+package installer
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// 安装、配置和测试连接返回的错误类型，用 errors.Is 判断，界面据此给出对应的解决办法
+// 返回时用 %w 包装并附上具体原因，错误信息仍可直接显示给用户
+var (
+	// ErrNoNetwork 所有下载服务器都无法连接
+	ErrNoNetwork = errors.New("无法连接到下载服务器")
+	// ErrNodeTooOld 已安装的 Node.js 低于要求的最低版本
+	ErrNodeTooOld = errors.New("Node.js 版本过低")
+	// ErrGitTooOld 已安装的 Git 低于要求的最低版本
+	ErrGitTooOld = errors.New("Git 版本过低")
+	// ErrNodeDownloadFailed Node.js 安装包的所有下载地址都失败
+	ErrNodeDownloadFailed = errors.New("Node.js 安装包下载失败")
+	// ErrGitDownloadFailed Git 安装包的所有下载地址都失败
+	ErrGitDownloadFailed = errors.New("Git 安装包下载失败")
+	// ErrBlockedByAntivirus Windows 上安装包被杀毒软件或 SmartScreen 隔离、锁定或阻止运行，重试一次后仍然失败
+	ErrBlockedByAntivirus = errors.New("杀毒软件可能拦截了安装程序，请临时关闭或添加信任")
+	// ErrUntrustedInstaller 安装包没有有效的数字签名或签名者不是官方发布者，镜像可能被篡改
+	ErrUntrustedInstaller = errors.New("安装包的数字签名无效")
+	// ErrNpmRegistryUnreachable 设置的 npm 镜像和备用镜像都无法连接或超时
+	ErrNpmRegistryUnreachable = errors.New("无法连接 npm 镜像")
+	// ErrNpmPermission npm 全局安装时没有写入权限 (EACCES)
+	ErrNpmPermission = errors.New("没有写入 npm 全局目录的权限")
+	// ErrNeedsElevation 操作需要管理员权限，当前进程没有权限
+	ErrNeedsElevation = errors.New("需要管理员权限")
+	// ErrUserCancelled 用户在系统密码框中点击了取消，已下载的安装包会保留，重试时无需重新下载
+	ErrUserCancelled = errors.New("您取消了授权，安装未完成")
+	// ErrAPIKeyInvalid 服务商拒绝了 API Key (401)
+	ErrAPIKeyInvalid = errors.New("API Key 无效或已失效 (401)")
+	// ErrInsufficientBalance 账户余额或额度不足
+	ErrInsufficientBalance = errors.New("账户余额不足或额度已用完")
+	// ErrRateLimited 服务商返回 429，通常是充值额度对应的 RPM 太低
+	ErrRateLimited = errors.New("请求过于频繁 (429)，已触发服务商的速率限制")
+)
+
+// scriptDownloadFailedExit Windows 安装脚本所有下载地址都失败时的退出码，与安装失败区分
+const scriptDownloadFailedExit = 90
+
+// scriptSignatureInvalidExit Windows 安装脚本检查安装包数字签名失败、拒绝运行时的退出码
+const scriptSignatureInvalidExit = 91
+
+// scriptInstallerMissingExit Windows 安装脚本发现安装包下载或复制后消失、无法复制时的退出码，通常是被杀毒软件隔离
+const scriptInstallerMissingExit = 92
+
+// exitCode 返回命令的退出码，不是命令退出导致的错误时返回 -1
+// 流式执行的命令失败时返回 *CommandError，其中已记录退出码
+func exitCode(err error) int {
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.ExitCode
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// commandOutputContains 命令失败时附带的输出中是否包含任一关键字（不区分大小写）
+func commandOutputContains(err error, keywords ...string) bool {
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	return linesContain([]string{cmdErr.Tail}, keywords...)
+}
+
+// linesContain 输出中是否有任一行包含任一关键字（不区分大小写）
+func linesContain(lines []string, keywords ...string) bool {
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		for _, keyword := range keywords {
+			if strings.Contains(lower, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sudoFailureKeywords sudo 无法取得权限时的输出
+var sudoFailureKeywords = []string{
+	"a password is required",
+	"no tty present",
+	"is not in the sudoers",
+	"incorrect password attempt",
+}
+
+// permissionDeniedKeywords npm 等命令没有写入权限时的输出
+var permissionDeniedKeywords = []string{"eacces", "eperm", "permission denied"}
+
+// npmNetworkErrorKeywords npm 连接镜像失败或超时的输出关键字，出现时改用下一个镜像重试
+var npmNetworkErrorKeywords = []string{
+	"etimedout", "esockettimedout", "err_socket_timeout", "econnreset", "econnrefused",
+	"enotfound", "eai_again", "network request", "socket hang up",
+}
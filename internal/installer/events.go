@@ -0,0 +1,72 @@
+package installer
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// progressEvent JSON 进度事件，每个事件占一行
+type progressEvent struct {
+	Type    string  `json:"type"` // progress: 进度更新, log: 仅日志, error: 错误, report: 安装报告
+	Step    string  `json:"step,omitempty"`
+	Message string  `json:"message,omitempty"`
+	Percent float64 `json:"percent"`
+	Error   string  `json:"error,omitempty"`
+	Detail  string  `json:"detail,omitempty"` // 进度详情，如下载速度和剩余时间
+
+	Report *InstallReport `json:"report,omitempty"` // report: RunFull 结束时的安装报告
+}
+
+// SetProgressJSON 设置 JSON 事件输出，设置后每个 ProgressUpdate 除发送到 channel 外，
+// 还会以一行一个 JSON 对象的格式写入 w，便于其他工具嵌入；传入 nil 关闭输出
+func (i *Installer) SetProgressJSON(w io.Writer) {
+	i.jsonMu.Lock()
+	defer i.jsonMu.Unlock()
+	i.progressJSON = w
+}
+
+// emitJSON 将进度更新写入 JSON 事件输出
+func (i *Installer) emitJSON(update ProgressUpdate) {
+	i.jsonMu.Lock()
+	defer i.jsonMu.Unlock()
+
+	if i.progressJSON == nil {
+		return
+	}
+
+	event := progressEvent{
+		Type:    "progress",
+		Step:    update.Step,
+		Message: update.Message,
+		Percent: update.Percent,
+		Detail:  update.Detail,
+	}
+	switch {
+	case update.Error != nil:
+		event.Type = "error"
+		event.Error = update.Error.Error()
+	case update.Percent < 0:
+		event.Type = "log"
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	i.progressJSON.Write(append(data, '\n'))
+}
+
+// emitReport 将安装报告作为最后一个事件写入 JSON 事件输出
+func (i *Installer) emitReport(report *InstallReport) {
+	i.jsonMu.Lock()
+	defer i.jsonMu.Unlock()
+
+	if i.progressJSON == nil {
+		return
+	}
+	data, err := json.Marshal(progressEvent{Type: "report", Percent: 1, Report: report})
+	if err != nil {
+		return
+	}
+	i.progressJSON.Write(append(data, '\n'))
+}
@@ -0,0 +1,138 @@
+package installer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultGitVersion Windows 上默认安装的 Git for Windows 版本
+// macOS 和 Linux 上使用 Homebrew、Xcode 命令行工具或系统包管理器提供的版本
+const DefaultGitVersion = "2.50.1"
+
+// DefaultMinGitVersion 默认要求的最低 Git 版本。1.x（如旧版 CentOS 自带的 1.8）缺少 Claude Code 使用的 git 功能
+const DefaultMinGitVersion = "2.0.0"
+
+// gitVersionPattern 匹配 git --version 输出中的版本号，兼容 "git version 2.39.3 (Apple Git-145)"、
+// "git version 2.45.1.windows.1" 和 "git version 1.8.3.1" 等形式，第三段可以省略
+var gitVersionPattern = regexp.MustCompile(`(?:^|\s)v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// gitTargetVersionPattern 可安装的 Git for Windows 版本号格式
+var gitTargetVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// gitMinVersionPattern 最低 Git 版本的格式，第三段可以省略
+var gitMinVersionPattern = regexp.MustCompile(`^v?\d+\.\d+(?:\.\d+)?$`)
+
+// parseGitVersion 解析 git --version 输出或版本号中的前三段数字，省略的第三段视为 0
+func parseGitVersion(version string) (major, minor, patch int, err error) {
+	match := gitVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return 0, 0, 0, fmt.Errorf("无法识别的 Git 版本: %q", version)
+	}
+
+	nums := make([]int, 3)
+	for idx, part := range match[1:4] {
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("无法识别的 Git 版本: %q", version)
+		}
+		nums[idx] = n
+	}
+	return nums[0], nums[1], nums[2], nil
+}
+
+// compareGitVersions 比较两个 Git 版本，a < b 返回 -1，相等返回 0，a > b 返回 1；无法解析的版本视为最小
+func compareGitVersions(a, b string) int {
+	aMajor, aMinor, aPatch, aErr := parseGitVersion(a)
+	bMajor, bMinor, bPatch, bErr := parseGitVersion(b)
+	switch {
+	case aErr != nil && bErr != nil:
+		return 0
+	case aErr != nil:
+		return -1
+	case bErr != nil:
+		return 1
+	}
+
+	for _, pair := range [][2]int{{aMajor, bMajor}, {aMinor, bMinor}, {aPatch, bPatch}} {
+		if pair[0] < pair[1] {
+			return -1
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// minGitVersion 返回要求的最低 Git 版本，未设置时使用默认值
+func (i *Installer) minGitVersion() string {
+	return effectiveMinGitVersion(i.MinGitVersion)
+}
+
+// effectiveMinGitVersion minVersion 为空时使用 DefaultMinGitVersion
+func effectiveMinGitVersion(minVersion string) string {
+	if minVersion = strings.TrimSpace(minVersion); minVersion == "" {
+		return DefaultMinGitVersion
+	}
+	return minVersion
+}
+
+// gitVersionSatisfies git --version 的输出是否满足最低版本要求
+// 无法识别的输出视为满足，只拒绝确定过旧的版本
+func gitVersionSatisfies(output, minVersion string) bool {
+	if _, _, _, err := parseGitVersion(output); err != nil {
+		return true
+	}
+	return compareGitVersions(output, effectiveMinGitVersion(minVersion)) >= 0
+}
+
+// validateGitVersion 检查 git --version 的输出是否满足最低版本要求，过旧时返回 ErrGitTooOld
+func (i *Installer) validateGitVersion(output string) error {
+	if _, _, _, err := parseGitVersion(output); err != nil {
+		i.addLog(fmt.Sprintf("⚠️ %v，跳过版本检查", err))
+		return nil
+	}
+	if !gitVersionSatisfies(output, i.MinGitVersion) {
+		return fmt.Errorf("%w: 当前为 %s，需要 %s 或更高版本", ErrGitTooOld, strings.TrimSpace(output), i.minGitVersion())
+	}
+	return nil
+}
+
+// gitTargetVersion 返回规范化后的 Git for Windows 目标版本（不带 v 前缀），未设置时使用默认版本
+func (i *Installer) gitTargetVersion() string {
+	version := strings.TrimPrefix(strings.TrimSpace(i.GitVersion), "v")
+	if version == "" {
+		return DefaultGitVersion
+	}
+	return version
+}
+
+// ValidateGitTargetVersion 检查要安装的 Git 版本格式，并且不低于要求的最低版本（为空时使用默认值）
+func ValidateGitTargetVersion(version, minVersion string) error {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if !gitTargetVersionPattern.MatchString(version) {
+		return fmt.Errorf("Git 版本格式不正确: %q，应为 2.50.1 这样的格式", version)
+	}
+	minVersion = effectiveMinGitVersion(minVersion)
+	if compareGitVersions(version, minVersion) < 0 {
+		return fmt.Errorf("Git 版本过低: %s，需要 %s 或更高版本", version, minVersion)
+	}
+	return nil
+}
+
+// ValidateMinGitVersion 检查设置的最低 Git 版本，为空表示使用默认值
+func ValidateMinGitVersion(minVersion string) error {
+	minVersion = strings.TrimSpace(minVersion)
+	if minVersion == "" {
+		return nil
+	}
+	if !gitMinVersionPattern.MatchString(minVersion) {
+		return fmt.Errorf("最低 Git 版本不正确: %q，应为 2.0 或 2.30.0 这样的格式", minVersion)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package installer
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseGitVersion(t *testing.T) {
+	tests := []struct {
+		input               string
+		major, minor, patch int
+		wantErr             bool
+	}{
+		{"git version 2.39.3 (Apple Git-145)", 2, 39, 3, false},
+		{"git version 2.45.1.windows.1", 2, 45, 1, false},
+		{"git version 2.43.0\n", 2, 43, 0, false},
+		{"git version 1.8.3.1", 1, 8, 3, false},
+		{"git version 1.7.1", 1, 7, 1, false},
+		{"2.30", 2, 30, 0, false},
+		{"v2.50.1", 2, 50, 1, false},
+		{"git: command not found", 0, 0, 0, true},
+		{"", 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		major, minor, patch, err := parseGitVersion(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseGitVersion(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if major != tt.major || minor != tt.minor || patch != tt.patch {
+			t.Errorf("parseGitVersion(%q) = %d.%d.%d, want %d.%d.%d", tt.input, major, minor, patch, tt.major, tt.minor, tt.patch)
+		}
+	}
+}
+
+func TestGitVersionSatisfies(t *testing.T) {
+	tests := []struct {
+		output, min string
+		want        bool
+	}{
+		{"git version 2.39.3 (Apple Git-145)", "", true},
+		{"git version 1.8.3.1", "", false},
+		{"git version 1.7.1", "2.0", false},
+		{"git version 2.25.1", "2.30", false},
+		{"git version 2.30.0", "2.30", true},
+		{"git version 2.45.1.windows.1", "2.45.2", false},
+		{"unrecognized output", "", true},
+	}
+	for _, tt := range tests {
+		if got := gitVersionSatisfies(tt.output, tt.min); got != tt.want {
+			t.Errorf("gitVersionSatisfies(%q, %q) = %v, want %v", tt.output, tt.min, got, tt.want)
+		}
+	}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	if err := i.validateGitVersion("git version 1.8.3.1"); !errors.Is(err, ErrGitTooOld) {
+		t.Errorf("expected ErrGitTooOld for git 1.8.3.1, got %v", err)
+	}
+	if err := i.validateGitVersion("git version 2.43.0"); err != nil {
+		t.Errorf("git 2.43.0 should satisfy the default minimum: %v", err)
+	}
+}
+
+func TestValidateGitVersions(t *testing.T) {
+	for _, version := range []string{"2.50.1", "v2.45.2"} {
+		if err := ValidateGitTargetVersion(version, ""); err != nil {
+			t.Errorf("ValidateGitTargetVersion(%q) = %v", version, err)
+		}
+	}
+	for _, version := range []string{"2.50", "latest", "1.9.5"} {
+		if err := ValidateGitTargetVersion(version, ""); err == nil {
+			t.Errorf("ValidateGitTargetVersion(%q) should fail", version)
+		}
+	}
+	if err := ValidateGitTargetVersion("2.40.0", "2.45"); err == nil {
+		t.Error("target version below the minimum should fail")
+	}
+
+	for _, version := range []string{"", "2.0", "2.30.1"} {
+		if err := ValidateMinGitVersion(version); err != nil {
+			t.Errorf("ValidateMinGitVersion(%q) = %v", version, err)
+		}
+	}
+	for _, version := range []string{"2", "git version 2.30", "2.x"} {
+		if err := ValidateMinGitVersion(version); err == nil {
+			t.Errorf("ValidateMinGitVersion(%q) should fail", version)
+		}
+	}
+}
@@ -2,26 +2,95 @@ package installer
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"claude-k2-installer/internal/i18n"
 )
 
 type Installer struct {
-	Progress chan ProgressUpdate
-	logs     []string
-	closed   bool       // 标记channel是否已关闭
-	mu       sync.Mutex // 保护closed字段
+	// progress 当前操作的进度 channel，没有进行中的操作时为 nil
+	//
+	// 所有权规则：每个操作（StartInstall、RetryFrom、StartConfigure）开始时创建新的 channel
+	// 并返回给调用方，操作结束时由安装器关闭；调用方只读取，不关闭也不复用，
+	// 且须读取到错误或 channel 关闭为止。
+	// 同一时间只能有一个操作在进行。没有进行中的操作时，日志只写入缓冲区和 JSON 输出。
+	progress chan ProgressUpdate
+	mu       sync.Mutex   // 保护progress、pendingProgress、completedSteps和操作的 context，发送和关闭都在持锁时进行
+	logs     *logRing     // 最近的日志，超出容量后丢弃最旧的
+	logsMu   sync.RWMutex // 保护logs，标准输出和错误输出的读取协程会并发写入
+
+	pendingProgress *ProgressUpdate // channel 已满时暂存的最新一条进度更新，等待补发
+
+	opCtx    context.Context    // 当前操作的 context，调用 Cancel 或操作结束时取消
+	opCancel context.CancelFunc // 取消 opCtx，没有进行中的操作时为 nil
+
+	beforeSnapshot *EnvSnapshot // 安装前的环境快照
+	runDiff        []string     // 本次运行改变的内容
+
+	progressJSON io.Writer  // JSON 事件输出，为 nil 时不输出
+	jsonMu       sync.Mutex // 保护 progressJSON 的写入
+
+	DryRun            bool        // 模拟运行：只记录将执行的操作，不修改系统
+	NodeVersion       string      // 需要安装 Node.js 时安装的版本，如 20.10.0
+	MinNodeVersion    int         // 要求的最低 Node.js 主版本，已安装的版本更低时升级，为 0 时使用 DefaultMinNodeVersion
+	GitVersion        string      // Windows 上需要安装 Git 时安装的 Git for Windows 版本，如 2.50.1
+	MinGitVersion     string      // 要求的最低 Git 版本，已安装的版本更低时视为未安装并重新安装，为空时使用 DefaultMinGitVersion
+	NPMStrategy       NPMStrategy // npm 全局目录不可写时的处理方式
+	ClaudeCodeVersion string      // 安装的 Claude Code 版本，默认 latest
+	DownloadRetries   int         // 每个镜像下载失败后的重试次数，404 等永久错误不重试
+	OfflineDir        string      // 离线安装包目录，设置后从该目录安装而不下载，文件名见 offline.go
+	SkipNode          bool        // 跳过检测和安装 Node.js，由用户自行管理
+	SkipGit           bool        // 跳过检测和安装 Git，由用户自行管理或无法安装
+	NodeMirror        string      // 自定义 Node.js 下载镜像（nodejs.org/dist 的镜像），优先于内置镜像
+	NPMRegistry       string      // 安装 Claude Code 使用的 npm 镜像，为空时使用 npmmirror
+	Proxy             string      // HTTP 代理地址，下载和 npm 安装都经过该代理，为空时沿用代理环境变量
+	ForceIPv4         bool        // 只使用 IPv4 连接，用于 IPv6 不通的网络
+	WriteClaudeJSON   bool        // 配置 API 时同时写入 ~/.claude.json，为 false 时只配置环境变量
+
+	// 下载超时，为 0 时使用默认值，慢速网络可调用 UseSlowNetwork 放宽
+	DownloadTimeout time.Duration // 单个文件下载的总时间上限
+	StallTimeout    time.Duration // 连续多久没有收到数据视为下载停滞
+
+	// 永久环境变量写入 shell 配置文件、~/.claude/settings.json 或两者，为空时写入 shell 配置文件
+	ConfigStrategy ConfigStrategy
+
+	// 当前步骤在总进度中的范围，供步骤内部汇报细分进度
+	stepName     string
+	stepStart    float64
+	stepEnd      float64
+	stepReported float64 // 当前步骤已汇报的最大进度，细分进度回退（如换镜像重新下载）时不再后退
+	stepNoop     bool    // 当前步骤发现无需执行（如已安装），不占用进度
+
+	plannedComponents []string        // 模拟运行中将要安装的组件
+	completedSteps    map[string]bool // 已完成的步骤，重试时跳过
+	reachableMirrors  map[string]bool // 网络检查中可以连接的下载服务器，下载时优先使用
+
+	// 安装报告：本次运行的开始时间、开始时的日志序号和最近一次生成的报告
+	reportStart    time.Time
+	reportLogStart int
+	lastReport     *InstallReport
+
+	logPolicy        LogPolicy  // 日志留存与隐私策略
+	logFile          *os.File   // 当前会话的日志文件，首次写入时创建
+	logFileSize      int64      // 当前日志文件已写入的字节数
+	logFileRotations int        // 本次会话因超出大小上限换新文件的次数
+	sessionFiles     []string   // 本次会话写入的日志和快照文件
+	logFileMu        sync.Mutex // 保护日志文件相关字段
 }
 
 type ProgressUpdate struct {
@@ -29,81 +98,362 @@ type ProgressUpdate struct {
 	Message string
 	Percent float64
 	Error   error
+	Detail  string // 进度条下方的详情，如下载速度和剩余时间
 }
 
 func New() *Installer {
 	return &Installer{
-		Progress: make(chan ProgressUpdate, 100),
-		logs:     make([]string, 0),
+		logs:              newLogRing(DefaultMaxLogLines),
+		logPolicy:         DefaultLogPolicy(),
+		NodeVersion:       DefaultNodeVersion,
+		MinNodeVersion:    DefaultMinNodeVersion,
+		GitVersion:        DefaultGitVersion,
+		MinGitVersion:     DefaultMinGitVersion,
+		ClaudeCodeVersion: DefaultClaudeCodeVersion,
+		DownloadRetries:   DefaultDownloadRetries,
+		DownloadTimeout:   DefaultDownloadTimeout,
+		StallTimeout:      DefaultStallTimeout,
+		WriteClaudeJSON:   true,
+	}
+}
+
+// installStep 安装流程中的一个步骤
+type installStep struct {
+	id           string // 步骤名称的消息 ID，不随界面语言变化，重试和记录已完成的步骤时使用
+	fn           func() error
+	weight       float64 // 大致反映步骤的耗时，下载和安装远多于检测
+	allowFailure bool    // 允许失败并继续的标志
+	skipped      bool    // 用户选择跳过，不执行也不计入进度
+}
+
+// name 返回步骤在当前界面语言下的名称
+func (s installStep) name() string {
+	return i18n.T(s.id)
+}
+
+// StepError 不允许失败的步骤出错，可调用 RetryFrom(StepID) 从该步骤继续安装
+type StepError struct {
+	Step   string // 界面显示的步骤名称
+	StepID string // 步骤的消息 ID，切换界面语言后仍可用于重试
+	Err    error
+}
+
+func (e *StepError) Error() string {
+	return i18n.T("step_progress.failed", e.Step, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// installSteps 返回安装流程的全部步骤
+func (i *Installer) installSteps() []installStep {
+	return []installStep{
+		{"step.check_system", i.checkSystem, 3, false, false},
+		{"step.check_node", i.checkNodeJS, 2, true, i.SkipNode}, // 允许检测失败，因为后面会安装
+		{"step.install_node", i.installNodeJS, 30, false, i.SkipNode},
+		{"step.check_git", i.checkGit, 2, true, i.SkipGit}, // 允许检测失败，因为后面会安装
+		{"step.install_git", i.installGit, 25, false, i.SkipGit},
+		{"step.check_npm", i.checkNPM, 3, false, false},
+		{"step.install_claude", i.installClaudeCode, 30, false, false},
+		{"step.verify", i.verifyInstallation, 5, false, false},
+	}
+}
+
+// StartInstall 在后台开始安装，返回本次安装的进度 channel，安装结束时关闭
+// 上一个操作仍在进行时返回错误
+func (i *Installer) StartInstall() (<-chan ProgressUpdate, error) {
+	updates, err := i.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer i.endOperation()
+		i.install()
+	}()
+	return updates, nil
+}
+
+// install 执行完整的安装流程
+func (i *Installer) install() {
+	// 日志实时写入文件，程序崩溃后仍可排查
+	i.startLogFile()
+	i.applyProxyEnv()
+	i.applyIPv4Env()
+	i.startReport()
+
+	// 记录安装前的环境快照，结束后（无论成功失败）对比本次运行改变了什么
+	// 模拟运行不修改环境，无需快照
+	i.mu.Lock()
+	i.completedSteps = make(map[string]bool)
+	i.mu.Unlock()
+	i.plannedComponents = nil
+	if !i.DryRun {
+		i.addLog("📸 记录安装前环境快照...")
+		i.beforeSnapshot = TakeEnvSnapshot()
+		i.persistSnapshot(i.beforeSnapshot, "before")
+		defer i.recordRunDiff("after-install")
 	}
+
+	i.runSteps()
 }
 
-// Install 开始安装过程
-func (i *Installer) Install() {
-	// 安装完成后关闭 channel
-	defer func() {
-		i.mu.Lock()
-		i.closed = true
-		i.mu.Unlock()
-		close(i.Progress)
+// RetryFrom 在后台从失败的步骤（StepError.StepID）重新开始安装，之前已完成的步骤不再重复执行
+// 返回本次重试的进度 channel，重试结束时关闭；上一个操作仍在进行时返回错误。
+func (i *Installer) RetryFrom(stepID string) (<-chan ProgressUpdate, error) {
+	i.mu.Lock()
+	started := i.completedSteps != nil
+	i.mu.Unlock()
+	if !started {
+		return nil, fmt.Errorf("尚未开始安装，无法重试")
+	}
+
+	steps := i.installSteps()
+	index := slices.IndexFunc(steps, func(step installStep) bool { return step.id == stepID })
+	if index < 0 {
+		return nil, fmt.Errorf("未知的安装步骤: %s", stepID)
+	}
+
+	updates, err := i.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+
+	// 失败的步骤及其后的步骤需要重新执行
+	for _, step := range steps[index:] {
+		i.setStepCompleted(step.id, false)
+	}
+
+	go func() {
+		defer i.endOperation()
+
+		i.addLog(fmt.Sprintf("🔁 从「%s」重试，跳过已完成的步骤", steps[index].name()))
+		if !i.DryRun {
+			defer i.recordRunDiff("after-retry")
+		}
+		i.runSteps()
 	}()
+	return updates, nil
+}
+
+// beginOperation 为新操作创建进度 channel，已有操作在进行时返回错误
+func (i *Installer) beginOperation() (chan ProgressUpdate, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.progress != nil {
+		return nil, fmt.Errorf("上一个操作仍在进行中，请稍候")
+	}
+	i.progress = make(chan ProgressUpdate, 100)
+	i.opCtx, i.opCancel = context.WithCancel(context.Background())
+	return i.progress, nil
+}
+
+// Cancel 取消进行中的操作：正在执行的长时间等待（如 Xcode 命令行工具安装）立即结束，
+// 后续步骤不再执行。没有进行中的操作时不做任何事
+func (i *Installer) Cancel() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.opCancel != nil {
+		i.opCancel()
+	}
+}
+
+// operationContext 返回当前操作的 context，没有进行中的操作时返回 context.Background()
+func (i *Installer) operationContext() context.Context {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.opCtx == nil {
+		return context.Background()
+	}
+	return i.opCtx
+}
+
+// endOperation 关闭当前操作的进度 channel
+func (i *Installer) endOperation() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.progress != nil {
+		// 没有发生错误时调用方会读取到 channel 关闭为止，暂存的最后一条进度一定能送达
+		if i.pendingProgress != nil {
+			i.progress <- *i.pendingProgress
+			i.pendingProgress = nil
+		}
+		close(i.progress)
+		i.progress = nil
+	}
+	if i.opCancel != nil {
+		i.opCancel()
+		i.opCtx, i.opCancel = nil, nil
+	}
+}
 
-	steps := []struct {
-		name         string
-		fn           func() error
-		weight       float64
-		allowFailure bool // 允许失败并继续的标志
-	}{
-		{"检查系统环境", i.checkSystem, 5, false},
-		{"检测 Node.js", i.checkNodeJS, 10, true}, // 允许检测失败，因为后面会安装
-		{"安装 Node.js", i.installNodeJS, 20, false},
-		{"检测 Git", i.checkGit, 10, true}, // 允许检测失败，因为后面会安装
-		{"安装 Git", i.installGit, 20, false},
-		{"安装 Claude Code", i.installClaudeCode, 20, false},
-		{"验证安装", i.verifyInstallation, 5, false},
-	}
-
-	totalWeight := 0.0
+// publish 写入 JSON 输出，并在有进行中的操作时发送到其进度 channel
+//
+// 持锁发送，不会与 endOperation 的关闭交错；channel 满时不阻塞安装流程：
+// 纯日志更新直接跳过（日志已写入缓冲区，UI 通过 LogsSince 补齐），
+// 进度更新暂存最新的一条，下次发送或操作结束时补发，进度条和步骤状态不会停在旧值。
+func (i *Installer) publish(update ProgressUpdate) {
+	i.emitJSON(update)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.progress == nil {
+		return
+	}
+	if i.pendingProgress != nil {
+		select {
+		case i.progress <- *i.pendingProgress:
+			i.pendingProgress = nil
+		default:
+		}
+	}
+	select {
+	case i.progress <- update:
+		// 成功发送
+	default:
+		if update.Percent >= 0 {
+			i.pendingProgress = &update
+		}
+	}
+}
+
+// stepCompleted 步骤是否已完成；completedSteps 由安装 goroutine 写入、重试时由调用方读取，读写都持锁
+func (i *Installer) stepCompleted(id string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.completedSteps[id]
+}
+
+// setStepCompleted 记录步骤是否已完成
+func (i *Installer) setStepCompleted(id string, done bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if done {
+		i.completedSteps[id] = true
+	} else {
+		delete(i.completedSteps, id)
+	}
+}
+
+// runSteps 依次执行尚未完成的步骤，不允许失败的步骤出错时发送 StepError 并停止
+func (i *Installer) runSteps() {
+	i.runStepList(i.installSteps())
+}
+
+// runStepList 依次执行 steps 中尚未完成的步骤
+//
+// 进度按剩余步骤的权重动态分配：每个步骤开始时，把尚未完成的进度按权重分给它和之后的步骤。
+// 用户跳过的步骤和发现无需执行的步骤（已安装）不占用进度，进度条不会在这些步骤上突然跳跃；
+// 汇报的进度只增不减。
+func (i *Installer) runStepList(steps []installStep) {
+	totalWeight, remainingWeight := 0.0, 0.0
 	for _, step := range steps {
+		if step.skipped {
+			continue
+		}
 		totalWeight += step.weight
+		if !i.stepCompleted(step.id) {
+			remainingWeight += step.weight
+		}
 	}
 
+	// 重试时已完成的步骤计入起始进度
 	currentProgress := 0.0
+	if totalWeight > 0 {
+		currentProgress = (totalWeight - remainingWeight) / totalWeight
+	}
 
 	for _, step := range steps {
-		i.sendProgress(step.name, fmt.Sprintf("正在%s...", step.name), currentProgress/totalWeight)
+		name := step.name()
+		if i.operationContext().Err() != nil {
+			// 已取消：停止安装，可从该步骤重试
+			i.sendError(&StepError{Step: name, StepID: step.id, Err: fmt.Errorf("安装已取消")})
+			return
+		}
+		if step.skipped {
+			i.addLog(fmt.Sprintf("⏭️ %s已跳过", name))
+			continue
+		}
+		if i.stepCompleted(step.id) {
+			// 重试时跳过已完成的步骤
+			i.sendProgress(name, i18n.T("step_progress.already_done", name), currentProgress)
+			continue
+		}
+
+		i.sendProgress(name, i18n.T("step_progress.running", name), currentProgress)
 
+		i.stepName = name
+		i.stepStart = currentProgress
+		i.stepEnd = currentProgress + (1-currentProgress)*step.weight/remainingWeight
+		i.stepReported = currentProgress
+		i.stepNoop = false
+		remainingWeight -= step.weight
 		err := step.fn()
 		if err != nil {
 			if step.allowFailure {
 				// 对于允许失败的步骤，记录但继续执行
-				i.addLog(fmt.Sprintf("⚠️ %s失败，继续下一步: %v", step.name, err))
-				i.sendProgress(step.name, fmt.Sprintf("%s未通过，继续安装", step.name), currentProgress/totalWeight)
+				i.addLog(fmt.Sprintf("⚠️ %s失败，继续下一步: %v", name, err))
+				i.sendProgress(name, i18n.T("step_progress.not_passed", name), i.stepReported)
 			} else {
-				// 对于不允许失败的步骤，停止安装
-				i.sendProgress(step.name, fmt.Sprintf("%s失败: %v", step.name, err), currentProgress/totalWeight)
-				i.sendError(fmt.Errorf("%s失败: %v", step.name, err))
+				// 对于不允许失败的步骤，停止安装，可从该步骤重试
+				i.sendProgress(name, i18n.T("step_progress.failed", name, err), i.stepReported)
+				i.sendError(&StepError{Step: name, StepID: step.id, Err: err})
 				return
 			}
-		} else {
-			i.sendProgress(step.name, fmt.Sprintf("%s完成", step.name), currentProgress/totalWeight)
+		} else if !i.stepNoop {
+			i.stepReported = i.stepEnd
+		}
+		// 无需执行的步骤只保留已汇报的进度，剩余部分留给之后的步骤
+		currentProgress = i.stepReported
+		if err == nil {
+			i.sendProgress(name, i18n.T("step_progress.done", name), currentProgress)
 		}
 
-		currentProgress += step.weight
+		i.setStepCompleted(step.id, true)
+	}
+
+	if i.DryRun {
+		summary := i.DryRunSummary()
+		i.addLog(fmt.Sprintf("%s %s", dryRunPrefix, summary))
+		i.sendProgress("完成", summary, 1.0)
+		return
 	}
 
-	i.sendProgress("完成", "所有组件安装完成！", 1.0)
+	i.sendProgress("完成", i18n.T("step_progress.all_done"), 1.0)
 }
 
 func (i *Installer) checkSystem() error {
 	i.addLog(fmt.Sprintf("操作系统: %s", runtime.GOOS))
 	i.addLog(fmt.Sprintf("架构: %s", runtime.GOARCH))
+	if i.DryRun {
+		i.addLog(fmt.Sprintf("%s 只报告将执行的操作，不会下载、安装或修改任何文件", dryRunPrefix))
+	}
 
 	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" && runtime.GOOS != "linux" {
 		return fmt.Errorf("不支持的操作系统: %s", runtime.GOOS)
 	}
 
-	return nil
+	// 下载前确认磁盘空间足够，避免安装到一半因写入失败报出难以理解的错误
+	if err := i.checkDiskSpace(); err != nil {
+		return err
+	}
+
+	if i.OfflineDir != "" {
+		info, err := os.Stat(i.OfflineDir)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("离线安装目录不存在: %s", i.OfflineDir)
+		}
+		i.addLog(fmt.Sprintf("📦 离线安装：使用 %s 中的安装包，跳过网络检查", i.OfflineDir))
+		return nil
+	}
+
+	// 确认能连接到下载服务器，没有网络时不必等待下载超时
+	return i.checkNetwork()
 }
 
 // getHomebrewPrefix 获取 Homebrew 的安装前缀
@@ -230,11 +580,8 @@ func (i *Installer) checkNodeJS() error {
 		// macOS 特殊处理：检查常见的安装位置
 		if runtime.GOOS == "darwin" {
 			i.addLog("正在检查 macOS 常见的 Node.js 安装位置...")
-			commonPaths := []string{
-				"/opt/homebrew/bin/node", // Apple Silicon Homebrew
-				"/usr/local/bin/node",    // Intel Homebrew
-				"/usr/bin/node",          // 系统默认
-			}
+			// Apple Silicon 优先检查 /opt/homebrew/bin，Intel 优先检查 /usr/local/bin
+			commonPaths := macCommandCandidates("node")
 
 			for _, path := range commonPaths {
 				if _, err := os.Stat(path); err == nil {
@@ -262,6 +609,12 @@ func (i *Installer) checkNodeJS() error {
 			}
 		}
 
+		// nvm / fnm / volta 安装的 Node.js 通常只在交互式 shell 中加入 PATH
+		i.addLog("正在检查 nvm / fnm / volta 管理的 Node.js...")
+		if i.useManagedNode() {
+			return nil
+		}
+
 		i.addLog("未检测到 Node.js，需要安装")
 		return fmt.Errorf("未安装 Node.js")
 	}
@@ -269,34 +622,88 @@ func (i *Installer) checkNodeJS() error {
 	version := strings.TrimSpace(string(output))
 	i.addLog(fmt.Sprintf("检测到 Node.js: %s", version))
 
-	return i.validateNodeVersion(version)
+	if err := i.validateNodeVersion(version); err != nil {
+		// 当前版本过低时，优先使用版本管理器中已有的新版本
+		if i.useManagedNode() {
+			return nil
+		}
+		return err
+	}
+	return nil
 }
 
 // validateNodeVersion 验证Node.js版本是否满足要求
 func (i *Installer) validateNodeVersion(version string) error {
 	// 检查版本是否满足要求 - 提取主版本号
-	// 版本格式通常是 v16.14.0 或 v20.10.0
-	if strings.HasPrefix(version, "v") {
-		// 提取主版本号
-		parts := strings.Split(version[1:], ".")
-		if len(parts) >= 1 {
-			majorVersion, err := strconv.Atoi(parts[0])
-			if err == nil && majorVersion >= 16 {
-				i.addLog(fmt.Sprintf("Node.js 版本满足要求 (v%d >= v16)", majorVersion))
-				return nil
-			}
-		}
+	// 版本格式通常是 v16.14.0 或 v20.10.0，也可能带 -nightly 等后缀
+	majorVersion, _, _, err := parseNodeVersion(version)
+	if err != nil {
+		return err
+	}
+	if majorVersion >= i.minNodeVersion() {
+		i.addLog(fmt.Sprintf("Node.js 版本满足要求 (v%d >= v%d)", majorVersion, i.minNodeVersion()))
+		return nil
 	}
 
-	return fmt.Errorf("Node.js 版本过低，需要 v16 或更高版本")
+	return fmt.Errorf("%w: 当前为 %s，需要 v%d 或更高版本", ErrNodeTooOld, strings.TrimSpace(version), i.minNodeVersion())
 }
 
 func (i *Installer) installNodeJS() error {
 	// 检查是否需要安装
-	if err := i.checkNodeJS(); err == nil {
+	checkErr := i.checkNodeJS()
+	if checkErr == nil {
 		i.addLog("Node.js 已安装，跳过")
+		i.skipStepProgress()
+		return nil
+	}
+
+	if err := ValidateNodeTargetVersion(i.nodeTargetVersion(), i.minNodeVersion()); err != nil {
+		return err
+	}
+	if errors.Is(checkErr, ErrNodeTooOld) {
+		i.addLog(fmt.Sprintf("⬆️ %v，将升级到 v%s", checkErr, i.nodeTargetVersion()))
+	}
+	i.addLog(fmt.Sprintf("目标 Node.js 版本: v%s", i.nodeTargetVersion()))
+
+	if i.DryRun {
+		return i.planNodeJS()
+	}
+
+	if err := i.installNodeJSForOS(); err != nil {
+		return err
+	}
+	return i.ensureNodeUpgraded()
+}
+
+// ensureNodeUpgraded 已有旧版本时 Homebrew 或系统包管理器可能什么也没升级，
+// 安装后仍低于最低版本时改用官方安装包安装目标版本
+func (i *Installer) ensureNodeUpgraded() error {
+	err := i.checkNodeJS()
+	if !errors.Is(err, ErrNodeTooOld) {
 		return nil
 	}
+	switch runtime.GOOS {
+	case "darwin":
+		i.addLog(fmt.Sprintf("⚠️ 安装后 %v，改用官方安装包升级到 v%s", err, i.nodeTargetVersion()))
+		return i.installNodeJSMacPkg()
+	case "linux":
+		i.addLog(fmt.Sprintf("⚠️ 安装后 %v（系统软件源的版本较旧），改用官方二进制包安装 v%s", err, i.nodeTargetVersion()))
+		return i.installNodeJSLinuxTarball()
+	}
+	return err
+}
+
+// installNodeJSForOS 按操作系统安装目标版本的 Node.js
+func (i *Installer) installNodeJSForOS() error {
+	// 离线安装直接使用安装包，不经过需要联网的 Homebrew 和系统包管理器
+	if i.OfflineDir != "" {
+		switch runtime.GOOS {
+		case "darwin":
+			return i.installNodeJSMacPkg()
+		case "linux":
+			return i.installNodeJSLinuxTarball()
+		}
+	}
 
 	switch runtime.GOOS {
 	case "windows":
@@ -313,6 +720,31 @@ func (i *Installer) installNodeJS() error {
 func (i *Installer) installNodeJSWindows() error {
 	i.addLog("开始 Node.js 安装流程...")
 
+	version := i.nodeTargetVersion()
+	arch, err := i.chooseWindowsArch("Node.js", func(arch string) ([]string, error) {
+		artifact, err := nodeArtifactName(version, "windows", arch)
+		if err != nil {
+			return nil, err
+		}
+		return i.nodeURLs(version, artifact), nil
+	})
+	if err != nil {
+		return err
+	}
+	artifact, err := nodeArtifactName(version, "windows", arch)
+	if err != nil {
+		return err
+	}
+	i.addLog(fmt.Sprintf("Node.js 安装包: %s", artifact))
+	urls := i.orderMirrors(i.nodeURLs(version, artifact))
+	localInstaller, err := i.offlineArtifact(artifact)
+	if err != nil {
+		return err
+	}
+	if localInstaller == "" {
+		localInstaller = i.cachedInstaller(artifact, urls, i.nodeChecksum(version, artifact))
+	}
+
 	tempDir := os.TempDir()
 	scriptPath := filepath.Join(tempDir, "install_nodejs.bat")
 
@@ -320,9 +752,10 @@ func (i *Installer) installNodeJSWindows() error {
 	scriptContent := `@echo off
 echo Starting Node.js installation...
 
-set "NODE_URL1=https://mirrors.aliyun.com/nodejs-release/v20.10.0/node-v20.10.0-x64.msi"
-set "NODE_URL2=https://cdn.npmmirror.com/binaries/node/v20.10.0/node-v20.10.0-x64.msi"
-set "NODE_URL3=https://nodejs.org/dist/v20.10.0/node-v20.10.0-x64.msi"
+set "NODE_URL1={{NODE_URL1}}"
+set "NODE_URL2={{NODE_URL2}}"
+set "NODE_URL3={{NODE_URL3}}"
+set "LOCAL_INSTALLER={{LOCAL_INSTALLER}}"
 set "INSTALLER_PATH=%TEMP%\node-installer.msi"
 
 echo [STEP 1] Cleaning up old installations...
@@ -331,6 +764,14 @@ if exist "C:\Program Files\nodejs" (
     rmdir /s /q "C:\Program Files\nodejs" 2>nul
 )
 
+if not "%LOCAL_INSTALLER%"=="" (
+    echo [STEP 2] Using offline installer: %LOCAL_INSTALLER%
+    copy /y "%LOCAL_INSTALLER%" "%INSTALLER_PATH%" >nul
+    if not errorlevel 1 goto :install
+    echo ERROR: Failed to copy offline installer
+    exit /b 92
+)
+
 echo [STEP 2] Downloading Node.js...
 echo Trying mirror 1...
 powershell -Command "try { $ProgressPreference='SilentlyContinue'; Invoke-WebRequest -Uri '%NODE_URL1%' -OutFile '%INSTALLER_PATH%' -TimeoutSec 60 -UseBasicParsing } catch { exit 1 }"
@@ -354,9 +795,15 @@ if %ERRORLEVEL% EQU 0 (
 )
 
 echo ERROR: All download attempts failed
-exit /b 1
+exit /b 90
 
 :install
+rem Antivirus usually scans new files within a few seconds, make sure the installer is still there
+ping 127.0.0.1 -n 3 >nul
+if not exist "%INSTALLER_PATH%" (
+    echo ERROR: Installer disappeared after download, it may have been quarantined by antivirus
+    exit /b 92
+)
 echo [STEP 3] Installing Node.js...
 msiexec /i "%INSTALLER_PATH%" /qn /norestart ADDLOCAL=ALL ALLUSERS=1
 set INSTALL_RESULT=%ERRORLEVEL%
@@ -412,48 +859,71 @@ echo WARNING: Installation completed but Node.js not found in PATH
 echo Please restart your terminal or computer
 exit /b 0
 `
+	runScript := func() error {
+		// 脚本中有大量 % 字符，用占位符替换下载地址
+		script := strings.NewReplacer(
+			"{{NODE_URL1}}", urls[0],
+			"{{NODE_URL2}}", urls[1],
+			"{{NODE_URL3}}", urls[2],
+			"{{LOCAL_INSTALLER}}", localInstaller,
+		).Replace(scriptContent)
+
+		// 写入脚本文件（使用UTF-8编码）
+		if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+			return fmt.Errorf("创建安装脚本失败: %v", err)
+		}
+		defer os.Remove(scriptPath)
 
-	// 写入脚本文件（使用UTF-8编码）
-	err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-	if err != nil {
-		return fmt.Errorf("创建安装脚本失败: %v", err)
-	}
-	defer os.Remove(scriptPath)
-
-	i.addLog(fmt.Sprintf("执行安装脚本: %s", scriptPath))
+		i.addLog(fmt.Sprintf("执行安装脚本: %s", scriptPath))
 
-	// 执行批处理脚本 - 使用流式输出避免UI卡住
-	cmd := exec.Command("cmd", "/c", scriptPath)
-	cmd.Dir = tempDir
+		// 执行批处理脚本 - 使用流式输出避免UI卡住
+		cmd := exec.Command("cmd", "/c", scriptPath)
+		cmd.Dir = tempDir
 
-	// 设置输出编码为UTF-8
-	cmd.Env = append(os.Environ(), "PYTHONIOENCODING=utf-8")
+		// 设置输出编码为UTF-8
+		cmd.Env = append(os.Environ(), "PYTHONIOENCODING=utf-8")
+		return i.executeCommandWithStreaming(cmd)
+	}
 
-	// 使用流式执行避免UI卡住
-	err = i.executeCommandWithStreaming(cmd)
+	// 安装包被杀毒软件拦截时，重新校验缓存（被隔离时重新下载）后重试一次
+	err = i.runInstallerScript("Node.js", runScript, func() {
+		if local, _ := i.offlineArtifact(artifact); local != "" {
+			localInstaller = local
+		} else {
+			localInstaller = i.cachedInstaller(artifact, urls, i.nodeChecksum(version, artifact))
+		}
+	})
 
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			code := exitErr.ExitCode()
-			switch code {
-			case 1603:
-				return fmt.Errorf("Node.js 安装失败 (1603): 致命错误。可能需要管理员权限或重启系统")
-			case 1638:
-				return fmt.Errorf("Node.js 安装失败 (1638): 已安装其他版本。请先卸载现有版本")
-			default:
-				return fmt.Errorf("Node.js 安装失败，错误代码: %d", code)
+		if errors.Is(err, ErrBlockedByAntivirus) {
+			return err
+		}
+		switch exitCode(err) {
+		case scriptDownloadFailedExit:
+			return fmt.Errorf("%w: 所有下载地址均失败", ErrNodeDownloadFailed)
+		case 1603:
+			if !IsElevated() {
+				return fmt.Errorf("Node.js 安装失败 (1603): %w，也可能需要重启系统", ErrNeedsElevation)
 			}
+			return fmt.Errorf("Node.js 安装失败 (1603): 致命错误。可能需要管理员权限或重启系统")
+		case 1925:
+			return fmt.Errorf("Node.js 安装失败 (1925): %w", ErrNeedsElevation)
+		case 1638:
+			return fmt.Errorf("Node.js 安装失败 (1638): 已安装其他版本。请先卸载现有版本")
 		}
 		return fmt.Errorf("Node.js 安装失败: %v", err)
 	}
 
+	// 安装程序修改的是注册表中的 PATH，刷新后当前进程才能找到 node
+	i.refreshWindowsEnvironment()
+
 	// 再次验证安装
 	if err := i.checkNodeJS(); err == nil {
 		i.addLog("✅ Node.js 安装并验证成功！")
 		return nil
 	}
 
-	// 如果验证失败，但安装脚本成功，说明可能需要重启
+	// 刷新 PATH 后仍验证失败，可能需要重启
 	i.addLog("⚠️ Node.js 已安装，但可能需要重启终端或系统才能生效")
 
 	// 尝试设置临时环境变量
@@ -498,6 +968,9 @@ func (i *Installer) installNodeJSMac() error {
 		i.addLog("✅ Homebrew 安装成功！")
 	}
 
+	// 确保新开的终端也能使用 brew 和 brew 安装的 node
+	i.ensureHomebrewShellenv()
+
 	i.addLog("配置 Homebrew 使用中国镜像源并安装 Node.js...")
 	
 	// 创建配置脚本
@@ -562,7 +1035,7 @@ fi
 // installHomebrewCN 使用国内镜像安装 Homebrew
 func (i *Installer) installHomebrewCN() error {
 	i.addLog("准备安装 Homebrew（使用国内镜像）...")
-	i.addLog("⚠️  安装需要管理员权限，系统将弹出密码输入框")
+	i.addLog("⚠️  安装过程中需要管理员权限时，系统将弹出密码输入框")
 	
 	tempDir := os.TempDir()
 	scriptPath := filepath.Join(tempDir, "install_homebrew.sh")
@@ -613,67 +1086,23 @@ fi
 	}
 	defer os.Remove(scriptPath)
 
-	// 使用 osascript 以管理员权限执行
-	// 这会弹出系统的密码输入对话框
-	executeScript := fmt.Sprintf(`do shell script "bash %s 2>&1" with administrator privileges`, scriptPath)
-	cmd := exec.Command("osascript", "-e", executeScript)
-	
-	// 创建一个 channel 来接收安装结果
-	done := make(chan error, 1)
-	var outputStr string
-	
-	// 在 goroutine 中执行安装
-	go func() {
-		output, err := cmd.CombinedOutput()
-		outputStr = string(output)
-		if err != nil {
-			// 如果用户取消了密码输入
-			if strings.Contains(err.Error(), "User canceled") || strings.Contains(outputStr, "User canceled") {
-				done <- fmt.Errorf("用户取消了密码输入")
-				return
-			}
-			done <- fmt.Errorf("安装失败: %v", err)
-			return
-		}
-		done <- nil
-	}()
-	
-	// 显示进度提示
-	progressCount := 0
-	ticker := time.NewTicker(3 * time.Second)
-	defer ticker.Stop()
-	
-	for {
-		select {
-		case err := <-done:
-			// 处理输出
-			if outputStr != "" {
-				lines := strings.Split(outputStr, "\n")
-				for _, line := range lines {
-					if strings.TrimSpace(line) != "" {
-						i.addLog(line)
-					}
-				}
-			}
-			if err != nil {
-				return err
-			}
-			goto homebrewInstallComplete
-		case <-ticker.C:
-			progressCount++
-			if progressCount <= 1 {
-				i.addLog("🔐 等待用户输入密码...")
-			} else if progressCount <= 10 {
-				i.addLog("📦 正在安装 Homebrew，这可能需要几分钟...")
-			} else if progressCount <= 20 {
-				i.addLog("⏳ Homebrew 安装仍在进行中...")
-			} else {
-				i.addLog("⏱️  安装时间较长，请耐心等待（首次安装可能需要 5-10 分钟）...")
-			}
+	// 以普通用户身份流式执行，安装脚本内部的 sudo 需要密码时才通过 SUDO_ASKPASS 弹出密码框
+	askpass, err := writeMacAskpass()
+	if err != nil {
+		return err
+	}
+	defer askpass.cleanup()
+
+	cmd := exec.Command("bash", scriptPath)
+	cmd.Dir = tempDir
+	cmd.Env = append(os.Environ(), "SUDO_ASKPASS="+askpass.Path)
+	if err := i.executeCommandWithStreaming(cmd); err != nil {
+		if askpass.cancelled() {
+			i.addLog("🚫 用户取消了密码输入")
+			return ErrUserCancelled
 		}
+		return fmt.Errorf("安装失败: %v", err)
 	}
-	
-homebrewInstallComplete:
 
 	// 设置 PATH 环境变量
 	if _, err := os.Stat("/opt/homebrew/bin/brew"); err == nil {
@@ -690,30 +1119,65 @@ homebrewInstallComplete:
 func (i *Installer) installNodeJSMacPkg() error {
 	i.addLog("准备下载并安装 Node.js...")
 
+	version := i.nodeTargetVersion()
+	artifact, err := nodeArtifactName(version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	urls := i.orderMirrors(i.nodeURLs(version, artifact))
+	localInstaller, err := i.offlineArtifact(artifact)
+	if err != nil {
+		return err
+	}
+	if localInstaller == "" {
+		localInstaller = i.cachedInstaller(artifact, urls, i.nodeChecksum(version, artifact))
+	}
+
 	tempDir := os.TempDir()
 	installerPath := filepath.Join(tempDir, "node-installer.pkg")
 	scriptPath := filepath.Join(tempDir, "install_nodejs.sh")
+	// 用户取消授权时保留脚本下载的安装包，重试时直接使用
+	retainedPath := filepath.Join(tempDir, "claude-k2-"+artifact)
+	if info, err := os.Stat(retainedPath); localInstaller == "" && err == nil && info.Size() >= 1000000 {
+		i.addLog(fmt.Sprintf("♻️ 使用上次取消授权时保留的安装包: %s", retainedPath))
+		localInstaller = retainedPath
+	}
 
 	// 创建下载脚本，支持多个镜像源
 	scriptContent := fmt.Sprintf(`#!/bin/bash
 set -e
 
 INSTALLER_PATH="%s"
+LOCAL_INSTALLER="%s"
 
 echo "[STEP 1] Starting Node.js download..."
 
 # Mirror URLs
 MIRRORS=(
-    "https://cdn.npmmirror.com/binaries/node/v20.10.0/node-v20.10.0.pkg"
-    "https://nodejs.org/dist/v20.10.0/node-v20.10.0.pkg"
+    "%s"
+    "%s"
+    "%s"
 )
 
+# 离线安装：复制本地安装包，不访问镜像
+if [ -n "$LOCAL_INSTALLER" ]; then
+    echo "[STEP 2] Using offline installer: $LOCAL_INSTALLER"
+    cp "$LOCAL_INSTALLER" "$INSTALLER_PATH"
+    MIRRORS=()
+fi
+
 # Try each mirror
 for i in "${!MIRRORS[@]}"; do
     MIRROR="${MIRRORS[$i]}"
     echo "[STEP 2] Trying mirror $((i+1)): ${MIRROR}"
     
     if curl -L --connect-timeout 10 --max-time 300 -o "$INSTALLER_PATH" "$MIRROR" 2>&1; then
+        # 部分镜像以 200 返回验证码或错误页面，视为该镜像失败
+        if head -c 512 "$INSTALLER_PATH" | grep -qi -e "<html" -e "<!doctype html"; then
+            echo "Mirror $((i+1)) returned an HTML page instead of the installer, trying next..."
+            rm -f "$INSTALLER_PATH"
+            continue
+        fi
         echo "[STEP 3] Download successful from mirror $((i+1))"
         break
     else
@@ -746,10 +1210,10 @@ echo "Installation will be performed with administrator privileges"
 # 保存安装器路径到临时文件，供 osascript 使用
 echo "$INSTALLER_PATH" > /tmp/nodejs_installer_path.txt
 exit 0
-`, installerPath)
+`, installerPath, localInstaller, urls[0], urls[1], urls[2])
 
 	// 写入脚本文件
-	err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
+	err = os.WriteFile(scriptPath, []byte(scriptContent), 0755)
 	if err != nil {
 		return fmt.Errorf("创建安装脚本失败: %v", err)
 	}
@@ -766,10 +1230,7 @@ exit 0
 	err = i.executeCommandWithStreaming(cmd)
 
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return fmt.Errorf("Node.js 下载失败，退出代码: %d", exitErr.ExitCode())
-		}
-		return fmt.Errorf("Node.js 下载失败: %v", err)
+		return fmt.Errorf("%w: %v", ErrNodeDownloadFailed, err)
 	}
 	
 	// 读取安装器路径
@@ -784,70 +1245,15 @@ exit 0
 		return fmt.Errorf("安装包不存在: %s", installerPath)
 	}
 	
-	i.addLog("正在安装 Node.js...")
-	i.addLog("⚠️  系统将弹出密码输入框，请输入您的密码")
-	
-	// 使用 osascript 以管理员权限安装
-	// 简化命令，让安装更快
-	installScript := fmt.Sprintf(`do shell script "installer -pkg '%s' -target / -verboseR" with administrator privileges`, installerPath)
-	installCmd := exec.Command("osascript", "-e", installScript)
-	
-	// 创建一个 channel 来接收安装结果
-	done := make(chan error, 1)
-	
-	// 在 goroutine 中执行安装
-	go func() {
-		output, err := installCmd.CombinedOutput()
-		if err != nil {
-			// 如果用户取消了密码输入
-			if strings.Contains(err.Error(), "User canceled") || strings.Contains(string(output), "User canceled") {
-				done <- fmt.Errorf("用户取消了密码输入")
-				return
-			}
-			done <- fmt.Errorf("Node.js 安装失败: %v\n输出: %s", err, string(output))
-			return
-		}
-		
-		// 解析输出中的有用信息
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line != "" && !strings.Contains(line, "installer:") {
-				i.addLog(line)
-			}
-		}
-		
-		done <- nil
-	}()
-	
-	// 显示进度提示
-	progressCount := 0
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-	
-	for {
-		select {
-		case err := <-done:
-			if err != nil {
-				return err
-			}
-			i.addLog("✅ Node.js 安装完成！")
-			goto installComplete
-		case <-ticker.C:
-			progressCount++
-			if progressCount <= 1 {
-				i.addLog("🔐 等待用户输入密码...")
-			} else if progressCount <= 10 {
-				i.addLog("📦 正在安装 Node.js，请稍候...")
-			} else if progressCount <= 20 {
-				i.addLog("⏳ 安装仍在进行中...")
-			} else {
-				i.addLog("⏱️  安装时间较长，请耐心等待...")
-			}
+	// 下载和校验以普通用户身份完成，只有安装这一步需要管理员权限
+	if err := i.runWithAdmin(fmt.Sprintf("installer -pkg %s -target /", ShellQuote(installerPath)), "安装 Node.js"); err != nil {
+		if errors.Is(err, ErrUserCancelled) && localInstaller == "" && os.Rename(installerPath, retainedPath) == nil {
+			i.addLog("已保留下载的安装包，重试时无需重新下载")
 		}
+		return err
 	}
-	
-installComplete:
+	os.Remove(retainedPath)
+	i.addLog("✅ Node.js 安装完成！")
 
 	// 再次验证安装
 	if err := i.checkNodeJS(); err == nil {
@@ -858,30 +1264,111 @@ installComplete:
 	// 如果验证失败，但安装脚本成功，说明可能需要重启终端
 	i.addLog("⚠️ Node.js 已安装，但可能需要重启终端才能生效")
 	
-	// 尝试添加到当前进程的PATH
-	os.Setenv("PATH", fmt.Sprintf("/usr/local/bin:%s", os.Getenv("PATH")))
+	// 尝试添加到当前进程的PATH：按架构优先级加入 /opt/homebrew/bin 和 /usr/local/bin
+	i.prependMacBinDirs()
+
+	for _, path := range macCommandCandidates("node") {
+		if _, err := os.Stat(path); err == nil {
+			i.addLog(fmt.Sprintf("Node.js 位于: %s", path))
+			break
+		}
+	}
 	
 	return nil
 }
 
 func (i *Installer) installNodeJSLinux() error {
 	// 尝试使用包管理器
-	if _, err := exec.LookPath("apt-get"); err == nil {
-		i.addLog("使用 apt-get 安装 Node.js...")
-		cmd := exec.Command("sudo", "apt-get", "update")
-		cmd.Run()
-
-		cmd = exec.Command("sudo", "apt-get", "install", "-y", "nodejs", "npm")
-		return i.executeCommandWithStreaming(cmd)
+	if pm, ok := findLinuxPackageManager(); ok {
+		i.addLog(fmt.Sprintf("使用 %s 安装 Node.js（版本由系统软件源决定）...", pm.name))
+		return i.installLinuxPackages(pm, true, "nodejs", "npm")
 	}
 
-	if _, err := exec.LookPath("yum"); err == nil {
-		i.addLog("使用 yum 安装 Node.js...")
-		cmd := exec.Command("sudo", "yum", "install", "-y", "nodejs", "npm")
-		return i.executeCommandWithStreaming(cmd)
+	// 没有包管理器时下载官方二进制包安装到用户目录
+	return i.installNodeJSLinuxTarball()
+}
+
+// linuxNodePathMarker 写入 shell 配置的 Node.js PATH 标记，恢复配置时据此清理
+const linuxNodePathMarker = "# Claude Code K2 Node.js PATH"
+
+// isMuslLibc 检测系统是否使用 musl libc（如 Alpine），官方二进制包依赖 glibc 无法运行
+func isMuslLibc() bool {
+	if matches, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(matches) > 0 {
+		return true
+	}
+	output, _ := exec.Command("ldd", "--version").CombinedOutput()
+	return strings.Contains(strings.ToLower(string(output)), "musl")
+}
+
+// installNodeJSLinuxTarball 下载指定版本的 Node.js 官方二进制包，解压到 ~/.local/node
+func (i *Installer) installNodeJSLinuxTarball() error {
+	version := i.nodeTargetVersion()
+	artifact, err := nodeArtifactName(version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	urls := i.orderMirrors(i.nodeURLs(version, artifact))
+
+	if isMuslLibc() {
+		i.addLog("⚠️ 检测到 musl libc（如 Alpine），官方 glibc 版本的 Node.js 无法运行")
+		if runtime.GOARCH != "amd64" {
+			return fmt.Errorf("musl 系统请使用系统包管理器安装 Node.js（如 apk add nodejs npm），或从 https://unofficial-builds.nodejs.org 下载")
+		}
+		// 非官方构建只提供 x64 的 musl 版本
+		artifact = fmt.Sprintf("node-v%s-linux-x64-musl.tar.xz", version)
+		urls = []string{fmt.Sprintf("https://unofficial-builds.nodejs.org/download/release/v%s/%s", version, artifact)}
+		i.addLog(fmt.Sprintf("改用 unofficial-builds 镜像的 musl 版本: %s", artifact))
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("获取用户目录失败: %v", err)
+	}
+	installDir := filepath.Join(home, ".local", "node")
+	if err := os.MkdirAll(installDir, 0755); err != nil {
+		return fmt.Errorf("创建安装目录失败: %v", err)
+	}
+
+	archivePath, err := i.offlineArtifact(artifact)
+	if err != nil {
+		return err
+	}
+	if archivePath == "" {
+		i.addLog(fmt.Sprintf("未找到可用的包管理器，下载 Node.js 官方二进制包: %s", artifact))
+		archivePath, err = i.cachedDownload(artifact, urls, i.nodeChecksum(version, artifact))
+		if err != nil {
+			return fmt.Errorf("%w，请手动安装: %v", ErrNodeDownloadFailed, err)
+		}
+	}
+
+	// 去掉压缩包中的顶层目录，直接解压到 ~/.local/node
+	cmd := exec.Command("tar", "-xJf", archivePath, "-C", installDir, "--strip-components=1")
+	if err := i.executeCommandWithStreaming(cmd); err != nil {
+		return fmt.Errorf("解压 Node.js 失败: %v", err)
 	}
 
-	return fmt.Errorf("无法自动安装 Node.js，请手动安装")
+	binDir := filepath.Join(installDir, "bin")
+	os.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	i.addLog(fmt.Sprintf("✅ Node.js v%s 已安装到 %s", version, installDir))
+
+	// 写入 shell 配置，新开的终端也能找到 node
+	for _, shellConfig := range shellConfigFiles(home) {
+		line := `export PATH="$HOME/.local/node/bin:$PATH"`
+		if strings.HasSuffix(shellConfig, "config.fish") {
+			line = "set -gx PATH $HOME/.local/node/bin $PATH"
+		}
+
+		if data, err := os.ReadFile(shellConfig); err == nil && strings.Contains(string(data), linuxNodePathMarker) {
+			continue
+		}
+		if err := appendMarkedLine(shellConfig, linuxNodePathMarker, line); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 写入 %s 失败: %v", shellConfig, err))
+			i.addLog(fmt.Sprintf("请手动将以下内容加入 shell 配置文件: %s", line))
+			continue
+		}
+		i.addLog(fmt.Sprintf("✅ 已将 %s 添加到 %s", binDir, shellConfig))
+	}
+	return nil
 }
 
 func (i *Installer) checkGit() error {
@@ -908,20 +1395,22 @@ func (i *Installer) checkGit() error {
 	cmd := exec.Command("git", "--version")
 	output, err := cmd.Output()
 
+	// 版本过低的 Git 与未安装相同，需要安装新版本
+	var tooOld error
 	if err == nil {
 		version := strings.TrimSpace(string(output))
 		i.addLog(fmt.Sprintf("检测到 Git: %s", version))
-		return nil
+		if tooOld = i.validateGitVersion(version); tooOld == nil {
+			return nil
+		}
+		i.addLog(fmt.Sprintf("⚠️ %v", tooOld))
 	}
 
 	// macOS 特殊处理：检查常见的安装位置
 	if runtime.GOOS == "darwin" {
 		i.addLog("正在检查 macOS 常见的 Git 安装位置...")
-		commonPaths := []string{
-			"/opt/homebrew/bin/git", // Apple Silicon Homebrew
-			"/usr/local/bin/git",    // Intel Homebrew
-			"/usr/bin/git",          // 系统默认
-		}
+		// Apple Silicon 优先检查 /opt/homebrew/bin，Intel 优先检查 /usr/local/bin
+		commonPaths := macCommandCandidates("git")
 
 		for _, path := range commonPaths {
 			if _, err := os.Stat(path); err == nil {
@@ -931,6 +1420,11 @@ func (i *Installer) checkGit() error {
 				if testOutput, testErr := testCmd.Output(); testErr == nil {
 					version := strings.TrimSpace(string(testOutput))
 					i.addLog(fmt.Sprintf("版本: %s", version))
+					if err := i.validateGitVersion(version); err != nil {
+						i.addLog(fmt.Sprintf("⚠️ %v", err))
+						tooOld = err
+						continue
+					}
 
 					// 将目录添加到当前进程的 PATH 中
 					gitDir := filepath.Dir(path)
@@ -945,17 +1439,46 @@ func (i *Installer) checkGit() error {
 		}
 	}
 
+	if tooOld != nil {
+		return tooOld
+	}
 	i.addLog("未检测到 Git，需要安装")
 	return fmt.Errorf("未安装 Git")
 }
 
 func (i *Installer) installGit() error {
 	// 检查是否需要安装
-	if err := i.checkGit(); err == nil {
+	checkErr := i.checkGit()
+	if checkErr == nil {
 		i.addLog("Git 已安装，跳过")
+		i.skipStepProgress()
 		return nil
 	}
+	if errors.Is(checkErr, ErrGitTooOld) {
+		i.addLog(fmt.Sprintf("⬆️ 已安装的 Git 版本过低（需要 %s 或更高版本），将安装新版本", i.minGitVersion()))
+	}
 
+	if i.DryRun {
+		return i.planGit()
+	}
+
+	if i.OfflineDir != "" && runtime.GOOS != "windows" {
+		i.addLog("⚠️ 离线安装包只包含 Windows 版 Git，将使用系统自带的安装方式，可能需要联网")
+	}
+
+	if err := i.installGitForOS(); err != nil {
+		return err
+	}
+
+	// 系统包管理器提供的版本可能仍然过低（如旧版 CentOS）
+	if err := i.checkGit(); errors.Is(err, ErrGitTooOld) {
+		return fmt.Errorf("%w，系统提供的 Git 版本过旧，请手动安装新版本 Git", err)
+	}
+	return nil
+}
+
+// installGitForOS 按操作系统安装 Git
+func (i *Installer) installGitForOS() error {
 	switch runtime.GOOS {
 	case "windows":
 		return i.installGitWindows()
@@ -972,6 +1495,28 @@ func (i *Installer) installGitWindows() error {
 	// 使用批处理脚本下载和安装
 	i.addLog("创建Git安装脚本...")
 
+	version := i.gitTargetVersion()
+	if err := ValidateGitTargetVersion(version, i.MinGitVersion); err != nil {
+		return err
+	}
+	arch, err := i.chooseWindowsArch("Git", func(arch string) ([]string, error) {
+		return gitWindowsDownloadURLs(version, gitWindowsArtifact(version, arch)), nil
+	})
+	if err != nil {
+		return err
+	}
+	artifact := gitWindowsArtifact(version, arch)
+	i.addLog(fmt.Sprintf("Git 安装包: %s", artifact))
+	urls := gitWindowsDownloadURLs(version, artifact)
+	localInstaller, err := i.offlineArtifact(artifact)
+	if err != nil {
+		return err
+	}
+	if localInstaller == "" {
+		// Git for Windows 没有统一的校验文件，只用下载时记录的校验值检查缓存
+		localInstaller = i.cachedInstaller(artifact, urls, "")
+	}
+
 	tempDir := os.TempDir()
 	scriptPath := filepath.Join(tempDir, "install_git.bat")
 
@@ -980,11 +1525,20 @@ func (i *Installer) installGitWindows() error {
 chcp 65001 >nul
 echo Starting Git installation...
 
-set "GIT_URL1=https://cdn.npmmirror.com/binaries/git-for-windows/v2.50.1.windows.1/Git-2.50.1-64-bit.exe"
-set "GIT_URL2=https://github.com/git-for-windows/git/releases/download/v2.50.1.windows.1/Git-2.50.1-64-bit.exe"
-set "GIT_URL3=https://mirrors.tuna.tsinghua.edu.cn/github-release/git-for-windows/git/v2.50.1.windows.1/Git-2.50.1-64-bit.exe"
+set "GIT_URL1={{GIT_URL1}}"
+set "GIT_URL2={{GIT_URL2}}"
+set "GIT_URL3={{GIT_URL3}}"
+set "LOCAL_INSTALLER={{LOCAL_INSTALLER}}"
 set "INSTALLER_PATH=%TEMP%\git-installer.exe"
 
+if not "%LOCAL_INSTALLER%"=="" (
+    echo Using offline installer: %LOCAL_INSTALLER%
+    copy /y "%LOCAL_INSTALLER%" "%INSTALLER_PATH%" >nul
+    if not errorlevel 1 goto :install
+    echo ERROR: Failed to copy offline installer
+    exit /b 92
+)
+
 echo Downloading Git from mirror 1...
 powershell -Command "try { Invoke-WebRequest -Uri '%GIT_URL1%' -OutFile '%INSTALLER_PATH%' -TimeoutSec 30 -UseBasicParsing } catch { exit 1 }"
 if %ERRORLEVEL% EQU 0 (
@@ -1007,9 +1561,23 @@ if %ERRORLEVEL% EQU 0 (
 )
 
 echo ERROR: All download sources failed
-exit /b 1
+exit /b 90
 
 :install
+rem Antivirus usually scans new files within a few seconds, make sure the installer is still there
+ping 127.0.0.1 -n 3 >nul
+if not exist "%INSTALLER_PATH%" (
+    echo ERROR: Installer disappeared after download, it may have been quarantined by antivirus
+    exit /b 92
+)
+echo Verifying Git installer signature...
+powershell -NoProfile -Command "$s = Get-AuthenticodeSignature -FilePath '%INSTALLER_PATH%'; Write-Host ('Signature: ' + $s.Status + ', signer: ' + $s.SignerCertificate.Subject); if ($s.Status -ne 'Valid' -or $s.SignerCertificate.Subject -notlike '{{GIT_PUBLISHER}},*') { exit 1 }"
+if %ERRORLEVEL% NEQ 0 (
+    echo ERROR: Git installer is not signed by Git for Windows, refusing to run it
+    del /f /q "%INSTALLER_PATH%" 2>nul
+    exit /b 91
+)
+
 echo Installing Git...
 "%INSTALLER_PATH%" /VERYSILENT /NORESTART /NOCANCEL /SP- /CLOSEAPPLICATIONS /RESTARTAPPLICATIONS
 if %ERRORLEVEL% NEQ 0 (
@@ -1045,40 +1613,67 @@ if %ERRORLEVEL% EQU 0 (
 echo Installation script completed
 exit /b 0
 `
+	runScript := func() error {
+		// 脚本中有大量 % 字符，用占位符替换下载地址
+		script := strings.NewReplacer(
+			"{{GIT_URL1}}", urls[0],
+			"{{GIT_URL2}}", urls[1],
+			"{{GIT_URL3}}", urls[2],
+			"{{LOCAL_INSTALLER}}", localInstaller,
+			"{{GIT_PUBLISHER}}", gitWindowsPublisher,
+		).Replace(scriptContent)
+
+		// 写入脚本文件（使用UTF-8编码）
+		if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+			return fmt.Errorf("创建安装脚本失败: %v", err)
+		}
+		defer os.Remove(scriptPath)
 
-	// 写入脚本文件（使用UTF-8编码）
-	err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
-	if err != nil {
-		return fmt.Errorf("创建安装脚本失败: %v", err)
-	}
-	defer os.Remove(scriptPath)
-
-	i.addLog(fmt.Sprintf("执行安装脚本: %s", scriptPath))
+		i.addLog(fmt.Sprintf("执行安装脚本: %s", scriptPath))
 
-	// 执行批处理脚本 - 使用流式输出避免UI卡住
-	cmd := exec.Command("cmd", "/c", scriptPath)
-	cmd.Dir = tempDir
+		// 执行批处理脚本 - 使用流式输出避免UI卡住
+		cmd := exec.Command("cmd", "/c", scriptPath)
+		cmd.Dir = tempDir
 
-	// 设置输出编码为UTF-8
-	cmd.Env = append(os.Environ(), "PYTHONIOENCODING=utf-8")
+		// 设置输出编码为UTF-8
+		cmd.Env = append(os.Environ(), "PYTHONIOENCODING=utf-8")
+		return i.executeCommandWithStreaming(cmd)
+	}
 
-	// 使用流式执行避免UI卡住
-	err = i.executeCommandWithStreaming(cmd)
+	// 安装包被杀毒软件拦截时，重新校验缓存（被隔离时重新下载）后重试一次
+	err = i.runInstallerScript("Git", runScript, func() {
+		if local, _ := i.offlineArtifact(artifact); local != "" {
+			localInstaller = local
+		} else {
+			localInstaller = i.cachedInstaller(artifact, urls, "")
+		}
+	})
 
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return fmt.Errorf("Git 安装失败，退出代码: %d", exitErr.ExitCode())
+		if errors.Is(err, ErrBlockedByAntivirus) {
+			return err
+		}
+		switch exitCode(err) {
+		case scriptDownloadFailedExit:
+			return fmt.Errorf("%w: 所有下载地址均失败", ErrGitDownloadFailed)
+		case scriptSignatureInvalidExit:
+			// 缓存的安装包签名不对时一并删除，下次重新下载
+			i.discardCachedDownload(localInstaller)
+			return fmt.Errorf("%w: Git 安装包不是由 Git for Windows 签名的，已拒绝运行", ErrUntrustedInstaller)
 		}
 		return fmt.Errorf("Git 安装失败: %v", err)
 	}
 
+	// 安装脚本只刷新了自己的 PATH，当前进程需要从注册表重新读取
+	i.refreshWindowsEnvironment()
+
 	// 再次验证安装
 	if err := i.checkGit(); err == nil {
 		i.addLog("✅ Git 安装验证成功")
 		return nil
 	}
 
-	// 如果验证失败，但安装脚本成功，说明可能需要重启
+	// 刷新 PATH 后仍验证失败，可能需要重启
 	i.addLog("⚠️ Git 已安装，但可能需要重启终端或系统才能生效")
 
 	// 尝试设置临时环境变量
@@ -1100,6 +1695,22 @@ exit /b 0
 	return nil
 }
 
+// xcodeCLTTimeout 等待 Xcode 命令行工具安装完成的总时间上限
+const xcodeCLTTimeout = 20 * time.Minute
+
+// xcodeCLTPath 返回已安装的 Xcode 命令行工具（或 Xcode）的开发者目录，未安装时 ok 为 false
+func xcodeCLTPath() (string, bool) {
+	output, err := exec.Command("xcode-select", "-p").Output()
+	if err != nil {
+		return "", false
+	}
+	path := strings.TrimSpace(string(output))
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
 func (i *Installer) installGitMac() error {
 	// 首先检查是否已经安装了 Git（通过 Xcode Command Line Tools）
 	if err := i.checkGit(); err == nil {
@@ -1159,6 +1770,16 @@ brew install git
 	}
 
 	// 如果没有 Homebrew 或 Homebrew 安装失败，尝试安装 Xcode Command Line Tools
+	// 命令行工具已安装时不再弹出安装对话框
+	if path, ok := xcodeCLTPath(); ok {
+		i.addLog(fmt.Sprintf("Xcode Command Line Tools 已安装: %s", path))
+		if err := i.checkGit(); err == nil {
+			return nil
+		} else if errors.Is(err, ErrGitTooOld) {
+			return fmt.Errorf("%w，请安装 Homebrew 后运行 'brew install git'", err)
+		}
+		return fmt.Errorf("Xcode Command Line Tools 已安装但 Git 不可用，请运行 'sudo xcode-select --reset' 后重试")
+	}
 	i.addLog("尝试安装 Xcode Command Line Tools (包含 Git)...")
 	
 	// 创建安装脚本
@@ -1171,7 +1792,8 @@ set -e
 echo "[STEP 1] Checking for Xcode Command Line Tools..."
 
 # Check if git is already available through Xcode CLT
-if /usr/bin/git --version >/dev/null 2>&1; then
+# 未安装命令行工具时 /usr/bin/git 本身会弹出安装对话框，先用 xcode-select -p 判断
+if xcode-select -p >/dev/null 2>&1 && /usr/bin/git --version >/dev/null 2>&1; then
     GIT_VERSION=$(/usr/bin/git --version)
     echo "Git is already installed: $GIT_VERSION"
     exit 0
@@ -1190,7 +1812,13 @@ echo "Waiting for installation to begin..."
 sleep 5
 
 # Check if installation is in progress
+# 等待时间有上限，超时后以 124 退出（与 timeout 命令一致）
+DEADLINE=$((SECONDS + ${CLT_TIMEOUT:-1200}))
 while true; do
+    if [ "$SECONDS" -ge "$DEADLINE" ]; then
+        echo "Timed out waiting for Xcode Command Line Tools installation."
+        exit 124
+    fi
     if pgrep -x "Install Command Line Developer Tools" >/dev/null 2>&1; then
         echo "Installation in progress..."
         sleep 10
@@ -1227,11 +1855,18 @@ done
 
 	i.addLog(fmt.Sprintf("执行安装脚本: %s", scriptPath))
 
-	// 使用流式执行
-	cmd = exec.Command("bash", scriptPath)
+	// 使用流式执行，等待安装的总时间有上限，取消安装时立即结束
+	ctx, cancel := context.WithTimeout(i.operationContext(), xcodeCLTTimeout)
+	defer cancel()
+	cmd = exec.CommandContext(ctx, "bash", scriptPath)
 	cmd.Dir = tempDir
+	cmd.Env = append(os.Environ(), fmt.Sprintf("CLT_TIMEOUT=%d", int(xcodeCLTTimeout.Seconds())))
 
 	err = i.executeCommandWithStreaming(cmd)
+	var cmdErr *CommandError
+	if ctx.Err() != nil || (errors.As(err, &cmdErr) && cmdErr.ExitCode == 124) {
+		return fmt.Errorf("Xcode 命令行工具安装超时或被取消，请手动运行 'xcode-select --install' 完成安装后重试")
+	}
 	if err != nil {
 		return fmt.Errorf("Git 安装失败: %v. 请手动运行 'xcode-select --install' 安装 Xcode Command Line Tools", err)
 	}
@@ -1246,54 +1881,206 @@ done
 }
 
 func (i *Installer) installGitLinux() error {
-	if _, err := exec.LookPath("apt-get"); err == nil {
-		cmd := exec.Command("sudo", "apt-get", "install", "-y", "git")
-		return i.executeCommandWithStreaming(cmd)
-	}
-
-	if _, err := exec.LookPath("yum"); err == nil {
-		cmd := exec.Command("sudo", "yum", "install", "-y", "git")
-		return i.executeCommandWithStreaming(cmd)
+	if pm, ok := findLinuxPackageManager(); ok {
+		i.addLog(fmt.Sprintf("使用 %s 安装 Git...", pm.name))
+		return i.installLinuxPackages(pm, false, "git")
 	}
 
 	return fmt.Errorf("无法自动安装 Git，请手动安装")
 }
 
 func (i *Installer) installClaudeCode() error {
-	i.addLog("安装 Claude Code...")
+	if i.DryRun {
+		return i.planClaudeCode()
+	}
 
-	// 使用淘宝 npm 镜像
-	cmd := exec.Command("npm", "install", "-g", "@anthropic-ai/claude-code", "--registry=https://registry.npmmirror.com")
+	if err := ValidateClaudeCodeVersion(i.claudeCodeTargetVersion()); err != nil {
+		return err
+	}
+	source, err := i.claudeCodeInstallSource()
+	if err != nil {
+		return err
+	}
+	i.addLog(fmt.Sprintf("安装 Claude Code (%s)...", source))
 
-	// 使用流式执行避免UI卡住
-	err := i.executeCommandWithStreaming(cmd)
+	if runtime.GOOS == "windows" {
+		i.checkWindowsLongPaths()
+	}
 
+	// 默认使用淘宝 npm 镜像，无法连接或超时时依次改用备用镜像
+	registries, err := i.reachableNpmRegistries()
 	if err != nil {
-		return fmt.Errorf("安装 Claude Code 失败: %v", err)
+		return fmt.Errorf("安装 Claude Code 失败: %w", err)
+	}
+	var tried []string
+	for _, registry := range registries {
+		logStart := i.LogCount()
+		err := i.npmInstallClaudeCode(source, registry)
+		if err == nil {
+			if registry != i.npmRegistry() {
+				i.addLog(fmt.Sprintf("✅ 已通过备用 npm 镜像 %s 安装", registry))
+			}
+			// 验证安装及版本
+			return i.verifyClaudeCodeVersion()
+		}
+		if !linesContain(i.logsSince(logStart), npmNetworkErrorKeywords...) {
+			return err
+		}
+		tried = append(tried, registry)
+		i.addLog(fmt.Sprintf("⚠️ 通过 npm 镜像 %s 安装时连接失败或超时", registry))
 	}
+	return fmt.Errorf("安装 Claude Code 失败: %w，已尝试 %s", ErrNpmRegistryUnreachable, strings.Join(tried, "、"))
+}
 
-	// 验证安装
-	cmd = exec.Command("claude", "--version")
-	output, err := cmd.Output()
+// npmInstallClaudeCode 通过指定的 npm 镜像安装 Claude Code
+func (i *Installer) npmInstallClaudeCode(source, registry string) error {
+	// --loglevel=http 输出每个请求，用于估算安装进度
+	i.addLog(fmt.Sprintf("使用 npm 镜像: %s", registry))
+	fetchTimeout := i.stallTimeout() * npmFetchTimeoutMultiple
+	installArgs := []string{"install", "-g", source, "--registry=" + registry, "--loglevel=http",
+		fmt.Sprintf("--fetch-timeout=%d", fetchTimeout.Milliseconds())}
+	if i.OfflineDir != "" {
+		// 本地安装包已包含 Claude Code 本身，依赖优先使用 npm 缓存
+		installArgs = append(installArgs, "--prefer-offline")
+	}
+	cmd, err := i.npmGlobalCommand(installArgs...)
 	if err != nil {
-		return fmt.Errorf("Claude Code 安装验证失败: %v", err)
+		return fmt.Errorf("安装 Claude Code 失败: %v", err)
 	}
 
-	i.addLog(fmt.Sprintf("Claude Code 安装成功: %s", string(output)))
+	// 记录安装前的日志位置，用于分析失败原因
+	logStart := i.LogCount()
+
+	// 使用流式执行避免UI卡住，并根据 npm 输出推进进度条
+	tracker := &npmProgressTracker{i: i}
+	err = i.executeCommandWithLineHandler(cmd, tracker.handleLine)
+
+	// Windows 长路径限制导致失败时，改用较短的 npm 缓存目录重试一次
+	if err != nil && runtime.GOOS == "windows" && isLongPathError(i.logsSince(logStart)) {
+		cacheDir := shortNpmCacheDir()
+		i.addLog("⚠️ 安装失败原因: 路径超过 Windows 260 字符限制 (ENAMETOOLONG)")
+		i.addLog(fmt.Sprintf("改用较短的 npm 缓存目录重试: %s", cacheDir))
+
+		// 重试沿用首次安装的全局目录处理方式（sudo 或用户目录），复制参数避免改动 installArgs
+		cmd, err = i.npmGlobalCommand(append(slices.Clone(installArgs), "--cache", cacheDir)...)
+		if err != nil {
+			return fmt.Errorf("安装 Claude Code 失败: %v", err)
+		}
+		logStart = i.LogCount()
+		tracker = &npmProgressTracker{i: i}
+		err = i.executeCommandWithLineHandler(cmd, tracker.handleLine)
+
+		if err != nil && isLongPathError(i.logsSince(logStart)) {
+			return fmt.Errorf("安装 Claude Code 失败: 路径超过 Windows 长度限制。请以管理员身份运行以下命令启用长路径支持后重试:\n%s", enableLongPathsCommand)
+		}
+	}
+
+	if err != nil {
+		return i.npmCommandError("安装 Claude Code 失败", err, logStart)
+	}
 	return nil
 }
 
+// shellConfigFiles 根据当前 shell 类型返回写入环境变量的配置文件
+func shellConfigFiles(home string) []string {
+	shell := os.Getenv("SHELL")
+	shellConfigs := []string{}
+
+	// 根据 shell 类型确定配置文件
+	if strings.Contains(shell, "zsh") {
+		shellConfigs = append(shellConfigs, filepath.Join(home, ".zshrc"))
+	} else if strings.Contains(shell, "bash") {
+		// bash 在 macOS 上通常使用 .bash_profile，在 Linux 上使用 .bashrc
+		if runtime.GOOS == "darwin" {
+			shellConfigs = append(shellConfigs, filepath.Join(home, ".bash_profile"))
+		} else {
+			shellConfigs = append(shellConfigs, filepath.Join(home, ".bashrc"))
+		}
+	} else if strings.Contains(shell, "fish") {
+		shellConfigs = append(shellConfigs, filepath.Join(home, ".config/fish/config.fish"))
+	} else {
+		// 默认使用 .profile
+		shellConfigs = append(shellConfigs, filepath.Join(home, ".profile"))
+	}
+
+	return shellConfigs
+}
+
+// RPM 的合理范围，超出时按边界处理
+const (
+	minRPM = 1
+	maxRPM = 10000
+)
+
+// requestDelayMs 根据 RPM 计算请求间隔（毫秒），无法解析时使用服务商默认 RPM
+// RPM 超出合理范围时按边界处理，note 说明做了哪种调整，未调整时为空
+func requestDelayMs(rpm string, defaultRPM int) (delay int, note string) {
+	value, err := strconv.Atoi(strings.TrimSpace(rpm))
+	switch {
+	case err != nil:
+		if defaultRPM < minRPM {
+			defaultRPM = minRPM
+		}
+		note = fmt.Sprintf("速率限制 %q 不是有效数字，使用默认值 %d RPM", rpm, defaultRPM)
+		value = defaultRPM
+	case value < minRPM:
+		note = fmt.Sprintf("速率限制 %d 过小，按 %d RPM 处理", value, minRPM)
+		value = minRPM
+	case value > maxRPM:
+		note = fmt.Sprintf("速率限制 %d 过大，按 %d RPM 处理", value, maxRPM)
+		value = maxRPM
+	}
+
+	// 60秒转毫秒除以RPM
+	return 60000 / value, note
+}
+
+// GetActivationCommand 返回在当前终端中启用 K2 配置的命令
+// 永久配置写入 shell 配置文件后，已打开的终端需要重新加载；临时配置需要先执行临时脚本；
+// 只写入 settings.json 时直接运行 claude 即可
+func (i *Installer) GetActivationCommand(useSystemConfig bool) string {
+	// 只写入 settings.json 时 Claude Code 启动时自行读取，已打开的终端也无需重新加载
+	if useSystemConfig && !i.ConfigStrategy.writesShell() {
+		return "claude"
+	}
+	if runtime.GOOS == "windows" {
+		if useSystemConfig {
+			// PowerShell 重新加载 $PROFILE 即可；命令提示符需要新开窗口
+			return ". $PROFILE"
+		}
+		return fmt.Sprintf(`"%s" && claude`, SetupScriptPath())
+	}
+
+	if !useSystemConfig {
+		return fmt.Sprintf("source %s && claude", SetupScriptPath())
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "exec $SHELL -l"
+	}
+	shellConfig := shellConfigFiles(home)[0]
+	if rel, err := filepath.Rel(home, shellConfig); err == nil {
+		shellConfig = "~/" + filepath.ToSlash(rel)
+	}
+	return "source " + shellConfig
+}
+
 func (i *Installer) configureK2API(apiKey string) error {
-	return i.configureK2APIWithOptions(apiKey, "30", false)
+	return i.configureK2APIWithOptions(DefaultProvider(), apiKey, "30", false)
 }
 
-func (i *Installer) configureK2APIWithOptions(apiKey string, rpm string, useSystemConfig bool) error {
+func (i *Installer) configureK2APIWithOptions(provider Provider, apiKey string, rpm string, useSystemConfig bool) error {
 	if apiKey == "" {
 		i.addLog("跳过 K2 API 配置（未提供 API Key）")
 		return nil
 	}
 
-	i.addLog(fmt.Sprintf("配置 K2 API（速率限制: %s RPM）...", rpm))
+	if i.DryRun {
+		return i.planConfigure(provider, rpm, useSystemConfig)
+	}
+
+	i.addLog(fmt.Sprintf("配置 %s API（速率限制: %s RPM）...", provider.Name, rpm))
 
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -1301,22 +2088,31 @@ func (i *Installer) configureK2APIWithOptions(apiKey string, rpm string, useSyst
 	}
 
 	// 计算请求延迟（毫秒）
-	rpmInt, _ := strconv.Atoi(rpm)
-	requestDelay := 60000 / rpmInt // 60秒转毫秒除以RPM
+	requestDelay, note := requestDelayMs(rpm, provider.DefaultRPM)
+	if note != "" {
+		i.addLog("⚠️ " + note)
+	}
 
 	// 配置内容 - 只使用 API KEY，避免认证冲突
 	// useSystemConfig 参数现在用于决定是否设置永久环境变量
 	// true: 设置永久环境变量（写入配置文件/注册表）
 	// false: 仅显示临时设置命令
 
+	// 写入 ~/.claude/settings.json：Claude Code 启动时读取其中的 env，不依赖终端加载 shell 配置
+	if useSystemConfig && i.ConfigStrategy.writesSettings() {
+		i.writeClaudeSettings(home, provider, apiKey, requestDelay)
+	}
+
 	// 根据操作系统设置配置
-	if runtime.GOOS == "windows" {
+	if useSystemConfig && !i.ConfigStrategy.writesShell() {
+		i.addLog("ℹ️ 仅写入 ~/.claude/settings.json，不修改 shell 配置文件和系统环境变量")
+	} else if runtime.GOOS == "windows" {
 		if useSystemConfig {
 			// Windows: 设置永久环境变量
 			i.addLog("设置 Windows 永久环境变量...")
 			envVars := map[string]string{
-				"ANTHROPIC_BASE_URL":             "https://api.moonshot.cn/anthropic/",
-				"ANTHROPIC_API_KEY":              apiKey,
+				"ANTHROPIC_BASE_URL":             provider.BaseURL,
+				provider.EnvKeyName:              apiKey,
 				"CLAUDE_REQUEST_DELAY_MS":        fmt.Sprintf("%d", requestDelay),
 				"CLAUDE_MAX_CONCURRENT_REQUESTS": "1",
 			}
@@ -1339,30 +2135,38 @@ func (i *Installer) configureK2APIWithOptions(apiKey string, rpm string, useSyst
 				}
 			}
 
-			i.addLog(fmt.Sprintf("永久环境变量已设置（请求延迟: %d毫秒），可能需要重启终端才能生效", requestDelay))
+			// setx 不能删除变量，用 reg delete 清除另一个认证变量，避免两种认证方式同时生效；变量不存在时无需提示
+			conflicting := provider.ConflictingEnvKey()
+			if exec.Command("reg", "delete", `HKCU\Environment`, "/v", conflicting, "/f").Run() == nil {
+				i.addLog(fmt.Sprintf("✅ 已删除用户环境变量 %s，避免认证冲突", conflicting))
+			}
+
+			// setx 只对新会话生效，同时写入 PowerShell 配置文件，新开的 PowerShell 窗口立即可用
+			i.writePowerShellProfiles(home, provider, apiKey, requestDelay)
+
+			i.broadcastEnvironmentChange()
+			i.addLog(fmt.Sprintf("永久环境变量已设置（请求延迟: %d毫秒），新开的终端即可生效，已打开的终端需要重新打开", requestDelay))
 		} else {
 			// 创建临时批处理脚本设置环境变量
 			i.addLog("正在创建临时环境变量脚本...")
 
-			// 获取临时目录
-			tempDir := os.TempDir()
 			// 使用批处理脚本，更稳定可靠
-			scriptPath := filepath.Join(tempDir, "claude_k2_setup.bat")
+			scriptPath := SetupScriptPath()
 			scriptContent := fmt.Sprintf(`@echo off
 REM Claude Code K2 Environment Variables Setup Script
-set "ANTHROPIC_BASE_URL=https://api.moonshot.cn/anthropic/"
-set "ANTHROPIC_API_KEY=%s"
+set "ANTHROPIC_BASE_URL=%s"
+set "%s=%s"
 set "CLAUDE_REQUEST_DELAY_MS=%d"
 set "CLAUDE_MAX_CONCURRENT_REQUESTS=1"
-set "ANTHROPIC_AUTH_TOKEN="
+set "%s="
 
 echo K2 Environment Variables Set:
-echo   - API Key: %s...
-echo   - Base URL: https://api.moonshot.cn/anthropic/
+echo   - API Key: %s
+echo   - Base URL: %s
 echo   - Request Delay: %d ms
 echo.
 echo You can now run 'claude' command with K2 API
-`, apiKey, requestDelay, apiKey[:10], requestDelay)
+`, provider.BaseURL, provider.EnvKeyName, apiKey, requestDelay, provider.ConflictingEnvKey(), maskKey(apiKey), provider.BaseURL, requestDelay)
 
 			err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
 			if err != nil {
@@ -1373,72 +2177,32 @@ echo You can now run 'claude' command with K2 API
 			}
 		}
 	} else {
-		// Mac/Linux: 只设置环境变量，不写入 settings.json
+		// Mac/Linux: 写入 shell 配置文件
 		if useSystemConfig {
-			// 设置永久环境变量
-			shell := os.Getenv("SHELL")
-			shellConfigs := []string{}
-
-			// 根据 shell 类型确定配置文件
-			if strings.Contains(shell, "zsh") {
-				shellConfigs = append(shellConfigs, filepath.Join(home, ".zshrc"))
-			} else if strings.Contains(shell, "bash") {
-				// bash 在 macOS 上通常使用 .bash_profile，在 Linux 上使用 .bashrc
-				if runtime.GOOS == "darwin" {
-					shellConfigs = append(shellConfigs, filepath.Join(home, ".bash_profile"))
-				} else {
-					shellConfigs = append(shellConfigs, filepath.Join(home, ".bashrc"))
-				}
-			} else if strings.Contains(shell, "fish") {
-				shellConfigs = append(shellConfigs, filepath.Join(home, ".config/fish/config.fish"))
-			} else {
-				// 默认使用 .profile
-				shellConfigs = append(shellConfigs, filepath.Join(home, ".profile"))
-			}
-
-			// 对每个配置文件进行处理
-			for _, shellConfig := range shellConfigs {
-				envConfig := fmt.Sprintf(`
-# Claude Code K2 Configuration
-export ANTHROPIC_BASE_URL="https://api.moonshot.cn/anthropic/"
-export ANTHROPIC_API_KEY="%s"
-export CLAUDE_REQUEST_DELAY_MS="%d"
-export CLAUDE_MAX_CONCURRENT_REQUESTS="1"
-unset ANTHROPIC_AUTH_TOKEN
-`, apiKey, requestDelay)
-
-				// 检查文件是否存在
-				if _, err := os.Stat(shellConfig); os.IsNotExist(err) {
-					// 文件不存在，跳过
-					continue
-				}
-
-				// 检查配置是否已存在
-				existingData, err := os.ReadFile(shellConfig)
-				if err != nil {
-					i.addLog(fmt.Sprintf("⚠️ 读取 %s 失败: %v", shellConfig, err))
-					continue
-				}
-
-				if strings.Contains(string(existingData), "# Claude Code K2 Configuration") {
-					i.addLog(fmt.Sprintf("⚠️ %s 中已存在配置，跳过", shellConfig))
+			// 设置永久环境变量，与界面中预览的修改由同一函数生成
+			for _, change := range planShellConfigChanges(home, provider, apiKey, requestDelay) {
+				if change.Err != nil {
+					i.addLog(fmt.Sprintf("⚠️ 读取 %s 失败: %v", change.Path, change.Err))
 					continue
 				}
 
-				// 追加到配置文件
-				f, err := os.OpenFile(shellConfig, os.O_APPEND|os.O_WRONLY, 0644)
-				if err != nil {
-					i.addLog(fmt.Sprintf("⚠️ 无法打开 %s: %v", shellConfig, err))
-					continue
+				if change.Created {
+					if err := os.MkdirAll(filepath.Dir(change.Path), 0755); err != nil {
+						i.addLog(fmt.Sprintf("⚠️ 创建 %s 失败: %v", filepath.Dir(change.Path), err))
+						continue
+					}
 				}
 
-				_, err = f.WriteString(envConfig)
-				f.Close()
-
-				if err != nil {
-					i.addLog(fmt.Sprintf("⚠️ 写入 %s 失败: %v", shellConfig, err))
-				} else {
-					i.addLog(fmt.Sprintf("✅ 永久环境变量已添加到 %s", shellConfig))
+				err := os.WriteFile(change.Path, []byte(change.After), 0644)
+				switch {
+				case err != nil:
+					i.addLog(fmt.Sprintf("⚠️ 写入 %s 失败: %v", change.Path, err))
+				case change.Created:
+					i.addLog(fmt.Sprintf("✅ %s 不存在，已创建并写入永久环境变量", change.Path))
+				case change.Replaced:
+					i.addLog(fmt.Sprintf("✅ 已更新 %s 中的永久环境变量", change.Path))
+				default:
+					i.addLog(fmt.Sprintf("✅ 永久环境变量已追加到 %s", change.Path))
 				}
 			}
 
@@ -1448,22 +2212,22 @@ unset ANTHROPIC_AUTH_TOKEN
 			i.addLog("正在创建临时环境变量脚本...")
 
 			// 创建临时脚本文件
-			scriptPath := "/tmp/claude_k2_setup.sh"
+			scriptPath := SetupScriptPath()
 			scriptContent := fmt.Sprintf(`#!/bin/bash
 # Claude Code K2 临时环境变量设置脚本
-export ANTHROPIC_BASE_URL="https://api.moonshot.cn/anthropic/"
-export ANTHROPIC_API_KEY="%s"
+export ANTHROPIC_BASE_URL="%s"
+export %s="%s"
 export CLAUDE_REQUEST_DELAY_MS="%d"
 export CLAUDE_MAX_CONCURRENT_REQUESTS="1"
-unset ANTHROPIC_AUTH_TOKEN
+unset %s
 
 echo "✅ K2环境变量已设置："
-echo "  - API Key: %s..."
-echo "  - Base URL: https://api.moonshot.cn/anthropic/"
+echo "  - API Key: %s"
+echo "  - Base URL: %s"
 echo "  - 请求延迟: %d毫秒"
 echo ""
 echo "现在可以运行 'claude' 命令使用K2 API"
-`, apiKey, requestDelay, apiKey[:10], requestDelay)
+`, provider.BaseURL, provider.EnvKeyName, apiKey, requestDelay, provider.ConflictingEnvKey(), maskKey(apiKey), provider.BaseURL, requestDelay)
 
 			err := os.WriteFile(scriptPath, []byte(scriptContent), 0755)
 			if err != nil {
@@ -1475,46 +2239,65 @@ echo "现在可以运行 'claude' 命令使用K2 API"
 		}
 	}
 
+	// 仅配置环境变量：不修改 .claude.json，避免重置用户的引导和项目状态
+	if !i.WriteClaudeJSON {
+		i.addLog("ℹ️ 仅配置环境变量，不修改 ~/.claude.json")
+		i.addLog("K2 API 配置完成")
+		return nil
+	}
+
 	// 处理 .claude.json 文件
 	claudeJsonPath := filepath.Join(home, ".claude.json")
-	backupPath := claudeJsonPath + ".backup"
+	backupPath := latestClaudeConfigBackup(claudeJsonPath)
 
 	i.addLog(fmt.Sprintf("🔍 处理配置文件: %s", claudeJsonPath))
 
-	// 读取或创建 .claude.json 配置
-	config := make(map[string]interface{})
-
-	// 尝试读取现有配置
+	// 读取现有配置，没有时从最近的备份读取
+	var original []byte
 	if data, err := os.ReadFile(claudeJsonPath); err == nil {
 		i.addLog("📖 读取现有配置文件...")
-		if err := json.Unmarshal(data, &config); err != nil {
-			i.addLog(fmt.Sprintf("⚠️ 解析配置文件失败: %v", err))
-			config = make(map[string]interface{})
+		original = data
+
+		// 写入 K2 配置前备份原始配置，已是 K2 配置的不再重复备份
+		var existing struct {
+			APIBaseURL string `json:"apiBaseUrl"`
+		}
+		if json.Unmarshal(data, &existing) != nil || !isProviderBaseURL(existing.APIBaseURL, provider) {
+			if backupFile, err := backupClaudeConfig(claudeJsonPath, data); err != nil {
+				i.addLog(fmt.Sprintf("⚠️ 备份配置文件失败: %v", err))
+			} else {
+				i.addLog(fmt.Sprintf("💾 已备份原始配置到: %s", backupFile))
+			}
 		}
-	} else if _, backupErr := os.Stat(backupPath); backupErr == nil {
+	} else if backupPath != "" {
 		i.addLog("📋 从备份文件读取配置...")
 		if backupData, readErr := os.ReadFile(backupPath); readErr == nil {
-			if err := json.Unmarshal(backupData, &config); err != nil {
-				i.addLog(fmt.Sprintf("⚠️ 解析备份文件失败: %v", err))
-				config = make(map[string]interface{})
-			}
+			original = backupData
 		}
 	} else {
 		i.addLog("📄 创建新的配置文件...")
 	}
 
-	// 添加/更新K2配置
-	config["hasCompletedOnboarding"] = true
-	config["apiKey"] = apiKey
-	config["apiBaseUrl"] = "https://api.moonshot.cn/anthropic/"
-	config["requestDelayMs"] = requestDelay
-	config["maxConcurrentRequests"] = 1
+	// 只添加/更新K2相关的键，其他键保持原有顺序和内容
+	k2Entries := []claudeConfigEntry{
+		{"hasCompletedOnboarding", true},
+		{"apiKey", apiKey},
+		{"apiBaseUrl", provider.BaseURL},
+		{"requestDelayMs", requestDelay},
+		{"maxConcurrentRequests", 1},
+	}
+
+	jsonData, err := mergeClaudeConfig(original, k2Entries)
+	if err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 解析配置文件失败: %v", err))
+		jsonData, err = mergeClaudeConfig(nil, k2Entries)
+	}
 
 	// 写回配置文件
-	if jsonData, err := json.MarshalIndent(config, "", "  "); err != nil {
+	if err != nil {
 		i.addLog(fmt.Sprintf("⚠️ 序列化配置失败: %v", err))
 	} else {
-		if err := os.WriteFile(claudeJsonPath, jsonData, 0644); err != nil {
+		if err := writeClaudeConfig(claudeJsonPath, jsonData); err != nil {
 			i.addLog(fmt.Sprintf("⚠️ 写入配置文件失败: %v", err))
 			i.forceCreateClaudeConfig(claudeJsonPath, string(jsonData))
 		} else {
@@ -1531,23 +2314,24 @@ func (i *Installer) forceCreateClaudeConfig(filePath, content string) {
 	i.addLog("💪 尝试强制创建配置文件...")
 
 	// 方法1: 直接写入
-	if err := os.WriteFile(filePath, []byte(content), 0644); err == nil {
+	if err := writeClaudeConfig(filePath, []byte(content)); err == nil {
 		i.addLog("✅ 方法1成功: 直接写入")
 		return
 	} else {
 		i.addLog(fmt.Sprintf("⚠️ 方法1失败: %v", err))
 	}
 
-	// 方法2: 尝试更宽松的权限
-	if err := os.WriteFile(filePath, []byte(content), 0666); err == nil {
-		i.addLog("✅ 方法2成功: 宽松权限写入")
+	// 方法2: 删除无法写入的旧文件后重新创建（文件中有 API Key，不放宽权限）
+	os.Remove(filePath)
+	if err := writeClaudeConfig(filePath, []byte(content)); err == nil {
+		i.addLog("✅ 方法2成功: 删除旧文件后重新写入")
 		return
 	} else {
 		i.addLog(fmt.Sprintf("⚠️ 方法2失败: %v", err))
 	}
 
 	// 方法3: 创建文件后写入
-	if file, err := os.Create(filePath); err == nil {
+	if file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err == nil {
 		defer file.Close()
 		if _, writeErr := file.WriteString(content); writeErr == nil {
 			i.addLog("✅ 方法3成功: 创建文件后写入")
@@ -1563,26 +2347,33 @@ func (i *Installer) forceCreateClaudeConfig(filePath, content string) {
 }
 
 func (i *Installer) verifyInstallation() error {
+	if i.DryRun {
+		i.addLog(fmt.Sprintf("%s 跳过安装验证", dryRunPrefix))
+		return nil
+	}
+
 	i.addLog("验证安装...")
 
-	// 验证 Node.js
-	cmd := exec.Command("node", "--version")
-	if err := cmd.Run(); err != nil {
+	// 与 Verify 使用相同的检测，环境变量要在新终端中才生效，这里只验证组件
+	i.ensureClaudeOnPath()
+	// 用户选择跳过的组件只提醒，不作为验证失败
+	status := i.CheckEnvironment()
+	switch {
+	case status.NodeVersion == "" && !i.SkipNode:
 		return fmt.Errorf("Node.js 验证失败")
-	}
-
-	// 验证 Git
-	cmd = exec.Command("git", "--version")
-	if err := cmd.Run(); err != nil {
+	case !status.GitOK && !i.SkipGit:
 		return fmt.Errorf("Git 验证失败")
-	}
-
-	// 验证 Claude Code
-	cmd = exec.Command("claude", "--version")
-	if err := cmd.Run(); err != nil {
+	case !status.ClaudeOK:
 		return fmt.Errorf("Claude Code 验证失败")
 	}
+	if status.NodeVersion == "" {
+		i.addLog("⚠️ 已跳过 Node.js，当前未检测到 Node.js")
+	}
+	if !status.GitOK {
+		i.addLog("⚠️ 已跳过 Git，当前未检测到 Git")
+	}
 
+	i.addLog(fmt.Sprintf("Node.js %s / %s / Claude Code %s", status.NodeVersion, status.GitVersion, status.ClaudeVersion))
 	i.addLog("所有组件验证通过！")
 	return nil
 }
@@ -1591,13 +2382,19 @@ func (i *Installer) downloadFile(url, filepath string) error {
 	// 创建带超时的 HTTP 客户端
 	// 注意：这是总体超时时间，包括连接和下载
 	client := &http.Client{
-		Timeout: 5 * time.Minute, // 5分钟总超时（大文件需要更长时间）
+		Timeout: i.downloadTimeout(), // 默认5分钟总超时，慢速网络模式下放宽
+		// 检查每次重定向，镜像跳转到登录或验证码页面时换下一个镜像，而不是把网页当作安装包保存
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if err := checkDownloadRedirect(req, via); err != nil {
+				return err
+			}
+			i.addLog(fmt.Sprintf("↪️ 重定向到: %s", req.URL.Redacted()))
+			return nil
+		},
 		Transport: &http.Transport{
-			// 连接超时设置
-			DialContext: (&net.Dialer{
-				Timeout:   10 * time.Second, // 连接超时10秒
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
+			Proxy: i.proxyFunc(),
+			// 连接超时10秒，IPv6 不通时自动改用 IPv4
+			DialContext:           i.dialContext(),
 			TLSHandshakeTimeout:   10 * time.Second,
 			ResponseHeaderTimeout: 10 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
@@ -1608,8 +2405,12 @@ func (i *Installer) downloadFile(url, filepath string) error {
 		},
 	}
 
+	// 停滞检测通过取消请求实现，连接断开、Read 一直阻塞时也能及时结束
+	ctx, cancel := context.WithCancel(i.operationContext())
+	defer cancel()
+
 	// 创建请求
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return err
 	}
@@ -1623,16 +2424,46 @@ func (i *Installer) downloadFile(url, filepath string) error {
 	// 发送请求
 	resp, err := client.Do(req)
 	if err != nil {
+		var permanent *permanentDownloadError
+		if errors.As(err, &permanent) {
+			return permanent
+		}
 		if strings.Contains(err.Error(), "timeout") {
 			return fmt.Errorf("连接超时，请检查网络或稍后重试")
 		}
 		return fmt.Errorf("连接失败: %v", err)
 	}
 	defer resp.Body.Close()
+	if finalURL := resp.Request.URL.Redacted(); finalURL != url {
+		i.addLog(fmt.Sprintf("最终下载地址: %s", finalURL))
+	}
 
-	// 检查响应状态
+	// 检查响应状态，404 等非临时错误重试也不会成功
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("下载失败，HTTP状态码: %d", resp.StatusCode)
+		err := fmt.Errorf("下载失败，HTTP状态码: %d", resp.StatusCode)
+		if !isRetriableStatus(resp.StatusCode) {
+			return &permanentDownloadError{err}
+		}
+		return err
+	}
+
+	// 停滞检测从收到响应开始：超过 StallTimeout 没有新数据时取消请求
+	stallTimeout := i.stallTimeout()
+	var stalled atomic.Bool
+	stallTimer := time.AfterFunc(stallTimeout, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer stallTimer.Stop()
+	stallError := fmt.Errorf("下载停滞：超过%d秒没有新数据", int(stallTimeout.Seconds()))
+
+	// 拒绝镜像以 200 返回的验证码或错误页面，换下一个镜像
+	body, err := checkDownloadBody(resp)
+	if stalled.Load() {
+		return stallError
+	}
+	if err != nil {
+		return err
 	}
 
 	// 获取文件大小
@@ -1646,32 +2477,42 @@ func (i *Installer) downloadFile(url, filepath string) error {
 	// 创建输出文件
 	out, err := os.Create(filepath)
 	if err != nil {
-		return err
+		return &permanentDownloadError{err}
 	}
 	defer out.Close()
 
-	// 创建带超时的进度读取器
+	// 创建带停滞检测的进度读取器
 	progressReader := &progressReader{
-		Reader:      resp.Body,
+		Reader:      body,
 		Total:       contentLength,
 		Current:     0,
 		LastLog:     time.Now(),
 		LastRead:    time.Now(),
 		Installer:   i,
-		ReadTimeout: 30 * time.Second, // 30秒内必须有数据传输
+		ReadTimeout: stallTimeout,
+		stallTimer:  stallTimer,
 	}
 
 	// 使用缓冲复制，提高性能
 	buf := make([]byte, 64*1024) // 64KB 缓冲区（增大缓冲区）
-	_, err = io.CopyBuffer(out, progressReader, buf)
+	written, err := io.CopyBuffer(out, progressReader, buf)
 
+	if stalled.Load() {
+		return stallError
+	}
 	if err != nil {
 		if err == io.ErrUnexpectedEOF {
 			return fmt.Errorf("下载中断，文件不完整")
 		}
 		return fmt.Errorf("下载失败: %v", err)
 	}
+	if err := checkDownloadSize(written, contentLength); err != nil {
+		return err
+	}
 
+	if contentLength > 0 {
+		progressReader.sendProgress()
+	}
 	i.addLog("✅ 下载完成")
 	return nil
 }
@@ -1685,8 +2526,10 @@ type progressReader struct {
 	LastRead    time.Time
 	LastBytes   int64     // 上次记录时的字节数
 	StartTime   time.Time // 下载开始时间
+	LastBar     time.Time // 上次更新进度条的时间
 	Installer   *Installer
 	ReadTimeout time.Duration
+	stallTimer  *time.Timer // 停滞检测的计时器，收到数据时重新计时
 }
 
 func (pr *progressReader) Read(p []byte) (int, error) {
@@ -1696,18 +2539,20 @@ func (pr *progressReader) Read(p []byte) (int, error) {
 		pr.LastBytes = 0
 	}
 
-	// 检查读取超时
-	if time.Since(pr.LastRead) > pr.ReadTimeout && pr.Current > 0 {
-		return 0, fmt.Errorf("下载停滞：超过%d秒没有新数据", int(pr.ReadTimeout.Seconds()))
-	}
-
 	n, err := pr.Reader.Read(p)
 	if n > 0 {
 		pr.Current += int64(n)
 		pr.LastRead = time.Now() // 更新最后读取时间
+		if pr.stallTimer != nil {
+			pr.stallTimer.Reset(pr.ReadTimeout)
+		}
 	}
 
-	// 每秒更新一次进度
+	// 进度条每 250 毫秒更新一次，日志每秒记录一次
+	if pr.Total > 0 && time.Since(pr.LastBar) >= downloadProgressInterval {
+		pr.sendProgress()
+		pr.LastBar = time.Now()
+	}
 	if time.Since(pr.LastLog) >= time.Second {
 		if pr.Total > 0 {
 			percent := float64(pr.Current) * 100 / float64(pr.Total)
@@ -1716,32 +2561,12 @@ func (pr *progressReader) Read(p []byte) (int, error) {
 			bytesInLastSecond := pr.Current - pr.LastBytes
 			instantSpeed := float64(bytesInLastSecond) / 1024 / 1024 // MB/s
 
-			// 计算平均速度
-			totalElapsed := time.Since(pr.StartTime).Seconds()
-			avgSpeed := float64(pr.Current) / totalElapsed / 1024 / 1024 // MB/s
-
-			// 使用平均速度预估剩余时间（更稳定）
-			remaining := pr.Total - pr.Current
-			var etaStr string
-			if avgSpeed > 0 {
-				etaSeconds := float64(remaining) / (avgSpeed * 1024 * 1024)
-				if etaSeconds < 60 {
-					etaStr = fmt.Sprintf("%.0f秒", etaSeconds)
-				} else if etaSeconds < 3600 {
-					etaStr = fmt.Sprintf("%.0f分钟", etaSeconds/60)
-				} else {
-					etaStr = fmt.Sprintf("%.1f小时", etaSeconds/3600)
-				}
-			} else {
-				etaStr = "计算中..."
-			}
-
 			pr.Installer.addLog(fmt.Sprintf("下载进度: %.1f%% (%.2f/%.2f MB) 速度: %.2f MB/s 剩余: %s",
 				percent,
 				float64(pr.Current)/1024/1024,
 				float64(pr.Total)/1024/1024,
 				instantSpeed,
-				etaStr))
+				pr.eta()))
 		} else {
 			pr.Installer.addLog(fmt.Sprintf("已下载: %.2f MB", float64(pr.Current)/1024/1024))
 		}
@@ -1753,65 +2578,122 @@ func (pr *progressReader) Read(p []byte) (int, error) {
 }
 
 func (i *Installer) sendProgress(step, message string, percent float64) {
-	i.mu.Lock()
-	closed := i.closed
-	i.mu.Unlock()
+	i.sendUpdate(ProgressUpdate{
+		Step:    step,
+		Message: message,
+		Percent: percent,
+	})
+}
 
-	if !closed {
-		select {
-		case i.Progress <- ProgressUpdate{
-			Step:    step,
-			Message: message,
-			Percent: percent,
-		}:
-			// 成功发送
-		default:
-			// channel满了，忽略
-		}
+// sendUpdate 发送一条进度更新到 JSON 输出和 channel
+func (i *Installer) sendUpdate(update ProgressUpdate) {
+	i.publish(update)
+}
+
+// sendStepProgress 汇报当前步骤内的细分进度，fraction 为步骤内的完成比例 0~1
+func (i *Installer) sendStepProgress(fraction float64, message string) {
+	if i.stepName == "" {
+		return
 	}
+	i.sendProgress(i.stepName, message, i.stepPercent(fraction))
+}
+
+// stepPercent 把步骤内的完成比例换算为总进度，不低于本步骤已汇报的进度，
+// 换镜像重新下载等细分进度回退的情况下进度条也不会后退
+func (i *Installer) stepPercent(fraction float64) float64 {
+	percent := i.stepStart + (i.stepEnd-i.stepStart)*fraction
+	if percent < i.stepReported {
+		return i.stepReported
+	}
+	i.stepReported = percent
+	return percent
+}
+
+// skipStepProgress 当前步骤发现无需执行（如组件已安装），不占用进度，其权重分给之后的步骤
+func (i *Installer) skipStepProgress() {
+	i.stepNoop = true
 }
 
+// sendStepDetail 与 sendStepProgress 相同，同时附带进度条下方显示的详情，如下载速度和剩余时间
+func (i *Installer) sendStepDetail(fraction float64, message, detail string) {
+	if i.stepName == "" {
+		return
+	}
+	i.sendUpdate(ProgressUpdate{
+		Step:    i.stepName,
+		Message: message,
+		Percent: i.stepPercent(fraction),
+		Detail:  detail,
+	})
+}
+
+// sendError 发送错误，与其他更新不同，channel 满时等待调用方读取，保证错误不会丢失
+// 只在操作所在的协程中调用，endOperation 在其后执行，发送期间 channel 不会被关闭
 func (i *Installer) sendError(err error) {
+	update := ProgressUpdate{
+		Error: err,
+	}
+	i.emitJSON(update)
+
+	// 调用方收到错误后可能不再读取，暂存的进度不再补发，避免 endOperation 阻塞
 	i.mu.Lock()
-	closed := i.closed
+	updates := i.progress
+	i.pendingProgress = nil
 	i.mu.Unlock()
 
-	if !closed {
-		select {
-		case i.Progress <- ProgressUpdate{
-			Error: err,
-		}:
-			// 成功发送
-		default:
-			// channel满了，忽略
-		}
+	if updates != nil {
+		updates <- update
+	}
+}
+
+// maskLog 按日志策略脱敏，用户选择记录完整参数时保留原文
+func (i *Installer) maskLog(message string) string {
+	if i.LogPolicy().RecordFullArgs {
+		return message
 	}
+	return maskSecrets(message)
 }
 
 func (i *Installer) addLog(message string) {
-	i.logs = append(i.logs, message)
-	// 检查channel是否已关闭
-	i.mu.Lock()
-	closed := i.closed
-	i.mu.Unlock()
+	// 默认脱敏，用户选择记录完整参数时保留原文
+	message = i.maskLog(message)
+
+	i.logsMu.Lock()
+	i.logs.add(message)
+	i.logsMu.Unlock()
+	i.writeLogFile(message)
+
+	// 同步发送到UI，确保实时显示
+	i.publish(ProgressUpdate{
+		Step:    "日志",
+		Message: message,
+		Percent: -1, // -1 表示只更新日志，不更新进度条
+	})
+}
 
-	if !closed {
-		// 同步发送到UI，确保实时显示
-		select {
-		case i.Progress <- ProgressUpdate{
-			Step:    "日志",
-			Message: message,
-			Percent: -1, // -1 表示只更新日志，不更新进度条
-		}:
-			// 成功发送
-		default:
-			// channel满了，忽略
-		}
-	}
+// AddLog 记录安装流程之外的事件（如检查更新失败），与安装日志一样脱敏、落盘并可导出
+func (i *Installer) AddLog(message string) {
+	i.addLog(message)
 }
 
+// GetLogs 返回本次会话的完整日志，用于导出和复制
+//
+// 内存缓冲区只保留最近 MaxLogLines 行；有更早的日志被丢弃时改为读取本次会话的日志文件
+// （带时间戳，不受缓冲区容量限制），日志不落盘时在开头注明丢弃的行数。
 func (i *Installer) GetLogs() []string {
-	return i.logs
+	i.logsMu.RLock()
+	logs, total := i.logs.since(0)
+	i.logsMu.RUnlock()
+
+	dropped := total - len(logs)
+	if dropped == 0 {
+		return logs
+	}
+	if lines, ok := i.sessionLogLines(); ok {
+		return lines
+	}
+	note := fmt.Sprintf("（内存中只保留最近 %d 行日志，更早的 %d 行已丢弃；开启日志留存可导出完整日志）", len(logs), dropped)
+	return append([]string{note}, logs...)
 }
 
 // ConfigureK2API 公开方法用于配置 API
@@ -1821,31 +2703,43 @@ func (i *Installer) ConfigureK2API(apiKey string) error {
 
 // ConfigureK2APIWithRateLimit 配置 API 和速率限制
 func (i *Installer) ConfigureK2APIWithRateLimit(apiKey string, rpm string) error {
-	return i.configureK2APIWithOptions(apiKey, rpm, false)
+	return i.configureK2APIWithOptions(DefaultProvider(), apiKey, rpm, false)
 }
 
 // ConfigureK2APIWithOptions 配置 API 和速率限制，带系统级配置选项
 func (i *Installer) ConfigureK2APIWithOptions(apiKey string, rpm string, useSystemConfig bool) error {
-	// 创建新的 Progress channel 用于配置阶段
-	i.mu.Lock()
-	if i.closed {
-		// 如果原channel已关闭，创建新的
-		i.Progress = make(chan ProgressUpdate, 100)
-		i.closed = false
+	return i.ConfigureProviderAPI(DefaultProvider(), apiKey, rpm, useSystemConfig)
+}
+
+// ConfigureProviderAPI 配置指定服务商的 API 和速率限制，带系统级配置选项
+// 同步执行，不创建进度 channel，日志可通过 LogsSince 读取；需要实时进度时使用 StartConfigure
+func (i *Installer) ConfigureProviderAPI(provider Provider, apiKey string, rpm string, useSystemConfig bool) error {
+	if i.reportStart.IsZero() {
+		// 环境已完整时只配置 API，报告从配置开始计时
+		i.startReport()
 	}
-	i.mu.Unlock()
+	err := i.configureK2APIWithOptions(provider, apiKey, rpm, useSystemConfig)
+	i.recordRunDiff("after-configure")
+	if err == nil {
+		i.saveInstallRecord(provider)
+	}
+	return err
+}
 
-	// 配置完成后关闭新的channel
-	defer func() {
-		i.mu.Lock()
-		if !i.closed {
-			i.closed = true
-			close(i.Progress)
+// StartConfigure 在后台配置 API，返回本次配置的进度 channel，配置结束时关闭
+// 配置失败时在关闭前发送一条带 Error 的更新；上一个操作仍在进行时返回错误
+func (i *Installer) StartConfigure(provider Provider, apiKey string, rpm string, useSystemConfig bool) (<-chan ProgressUpdate, error) {
+	updates, err := i.beginOperation()
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		defer i.endOperation()
+		if err := i.ConfigureProviderAPI(provider, apiKey, rpm, useSystemConfig); err != nil {
+			i.sendError(err)
 		}
-		i.mu.Unlock()
 	}()
-
-	return i.configureK2APIWithOptions(apiKey, rpm, useSystemConfig)
+	return updates, nil
 }
 
 // RestoreOriginalClaudeConfig 恢复 Claude Code 的原始配置
@@ -1857,9 +2751,24 @@ func (i *Installer) RestoreOriginalClaudeConfig() error {
 
 	i.addLog("开始恢复 Claude Code 原始配置...")
 
-	// 删除 .claude.json 文件
+	// 只处理写入过 K2 配置的 .claude.json：优先从最近的备份恢复，没有备份时删除
+	// 仅配置环境变量或用户已自行修改过时，文件中没有本工具写入的字段，保持不变
 	claudeJsonPath := filepath.Join(home, ".claude.json")
-	if _, err := os.Stat(claudeJsonPath); err == nil {
+	if !claudeConfigWrittenByUs(claudeJsonPath) {
+		if _, err := os.Stat(claudeJsonPath); err == nil {
+			i.addLog("ℹ️ .claude.json 中没有本工具写入的配置，保持不变")
+		}
+	} else if backupPath := latestClaudeConfigBackup(claudeJsonPath); backupPath != "" {
+		data, err := os.ReadFile(backupPath)
+		if err == nil {
+			err = writeClaudeConfig(claudeJsonPath, data)
+		}
+		if err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 从备份恢复 .claude.json 失败: %v", err))
+		} else {
+			i.addLog(fmt.Sprintf("✅ 已从备份恢复 .claude.json: %s", backupPath))
+		}
+	} else if _, err := os.Stat(claudeJsonPath); err == nil {
 		err = os.Remove(claudeJsonPath)
 		if err != nil {
 			i.addLog(fmt.Sprintf("⚠️ 删除 .claude.json 失败: %v", err))
@@ -1868,16 +2777,23 @@ func (i *Installer) RestoreOriginalClaudeConfig() error {
 		}
 	}
 
-	// 删除 ~/.claude/settings.json 文件
-	claudeDir := filepath.Join(home, ".claude")
-	settingsPath := filepath.Join(claudeDir, "settings.json")
-	if _, err := os.Stat(settingsPath); err == nil {
-		err = os.Remove(settingsPath)
-		if err != nil {
-			i.addLog(fmt.Sprintf("⚠️ 删除 settings.json 失败: %v", err))
-		} else {
-			i.addLog("✅ 已删除 ~/.claude/settings.json")
-		}
+	// 删除 ~/.claude/settings.json 中本工具写入的环境变量，用户的其他设置保持不变
+	i.restoreClaudeSettings(home)
+
+	// 清理本工具写入的 Homebrew shellenv
+	if runtime.GOOS == "darwin" {
+		i.removeMarkedLines(home, homebrewShellenvMarker, " Homebrew 配置")
+	}
+
+	// 清理本工具写入的 Node.js PATH（官方二进制包安装）
+	if runtime.GOOS == "linux" {
+		i.removeMarkedLines(home, linuxNodePathMarker, " Node.js PATH 配置")
+	}
+
+	// 清理本工具写入的 npm 全局目录 PATH
+	if runtime.GOOS != "windows" {
+		i.removeMarkedLines(home, npmUserPrefixMarker, " npm 全局目录 PATH 配置")
+		i.removeMarkedLines(home, claudeBinPathMarker, " claude PATH 配置")
 	}
 
 	// 清理环境变量配置
@@ -1885,6 +2801,7 @@ func (i *Installer) RestoreOriginalClaudeConfig() error {
 		// Windows: 使用PowerShell脚本清除环境变量，避免卡死
 		i.addLog("使用PowerShell清除 Windows 环境变量...")
 		i.createWindowsRestoreScript()
+		i.removePowerShellProfileBlocks(home)
 	} else {
 		// Mac/Linux: 清除永久环境变量
 		// Mac/Linux: 删除环境变量配置
@@ -1915,38 +2832,13 @@ func (i *Installer) RestoreOriginalClaudeConfig() error {
 
 			// 读取文件内容
 			if data, err := os.ReadFile(shellConfig); err == nil {
-				content := string(data)
-
-				// 移除 Claude Code K2 Configuration 部分
-				lines := strings.Split(content, "\n")
-				var newLines []string
-				skipSection := false
-
-				for _, line := range lines {
-					if strings.Contains(line, "# Claude Code K2 Configuration") {
-						skipSection = true
-						continue
-					}
-
-					if skipSection {
-						// 跳过以 export ANTHROPIC_ 或 export CLAUDE_ 开头的行
-						if strings.HasPrefix(strings.TrimSpace(line), "export ANTHROPIC_") ||
-							strings.HasPrefix(strings.TrimSpace(line), "export CLAUDE_") {
-							continue
-						}
-						// 如果遇到空行或其他注释，结束跳过
-						if strings.TrimSpace(line) == "" || (!strings.HasPrefix(strings.TrimSpace(line), "export") && strings.HasPrefix(strings.TrimSpace(line), "#")) {
-							skipSection = false
-						}
-					}
-
-					if !skipSection {
-						newLines = append(newLines, line)
-					}
+				// 移除 Claude Code K2 Configuration 部分（bash/zsh 的 export 或 fish 的 set -gx）
+				newContent, changed := stripShellEnvBlock(string(data))
+				if !changed {
+					continue
 				}
 
 				// 写回文件
-				newContent := strings.Join(newLines, "\n")
 				err = os.WriteFile(shellConfig, []byte(newContent), 0644)
 				if err != nil {
 					i.addLog(fmt.Sprintf("⚠️ 恢复 %s 失败: %v", shellConfig, err))
@@ -1963,6 +2855,33 @@ func (i *Installer) RestoreOriginalClaudeConfig() error {
 
 // executeCommandWithStreaming 执行命令并实时输出日志，避免UI卡住
 func (i *Installer) executeCommandWithStreaming(cmd *exec.Cmd) error {
+	return i.executeCommandWithLineHandler(cmd, nil)
+}
+
+// commandErrorTailLines 命令失败时错误信息中附带的输出行数
+const commandErrorTailLines = 10
+
+// CommandError 流式执行的命令失败，附带最后几行输出便于排查
+type CommandError struct {
+	ExitCode int
+	Tail     string // 最后几行错误输出（没有错误输出时为标准输出）
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	if e.Tail == "" {
+		return fmt.Sprintf("命令失败 (exit %d)", e.ExitCode)
+	}
+	return fmt.Sprintf("命令失败 (exit %d): %s", e.ExitCode, e.Tail)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// executeCommandWithLineHandler 流式执行命令，每行输出除写入日志外还交给 onLine 处理（可为 nil）
+// 命令失败时返回 *CommandError，其中包含最后几行输出
+func (i *Installer) executeCommandWithLineHandler(cmd *exec.Cmd, onLine func(line string)) error {
 	// 创建管道以实时获取输出
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -1978,39 +2897,53 @@ func (i *Installer) executeCommandWithStreaming(cmd *exec.Cmd) error {
 		return fmt.Errorf("启动命令失败: %v", err)
 	}
 
-	// 并发读取输出
+	// 并发读取输出，各自保留最后几行用于错误信息
 	var wg sync.WaitGroup
 	wg.Add(2)
+	var stdoutTail, stderrTail []string
 
-	// 读取标准输出
-	go func() {
+	readLines := func(r io.Reader, tail *[]string) {
 		defer wg.Done()
-		scanner := bufio.NewScanner(stdout)
+		scanner := bufio.NewScanner(r)
 		for scanner.Scan() {
 			line := strings.TrimSpace(scanner.Text())
-			if line != "" {
-				i.addLog(line)
+			if line == "" {
+				continue
 			}
-		}
-	}()
-
-	// 读取错误输出
-	go func() {
-		defer wg.Done()
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line != "" {
-				i.addLog(line)
+			i.addLog(line)
+			if onLine != nil {
+				onLine(line)
+			}
+			*tail = append(*tail, line)
+			if len(*tail) > commandErrorTailLines {
+				*tail = (*tail)[1:]
 			}
 		}
-	}()
+	}
+
+	// 读取标准输出和错误输出
+	go readLines(stdout, &stdoutTail)
+	go readLines(stderr, &stderrTail)
 
 	// 等待输出读取完成
 	wg.Wait()
 
 	// 等待命令执行完成
-	return cmd.Wait()
+	err = cmd.Wait()
+	if err == nil {
+		return nil
+	}
+
+	tail := stderrTail
+	if len(tail) == 0 {
+		tail = stdoutTail
+	}
+	cmdErr := &CommandError{ExitCode: -1, Tail: i.maskLog(strings.Join(tail, "\n")), Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		cmdErr.ExitCode = exitErr.ExitCode()
+	}
+	return cmdErr
 }
 
 // createWindowsRestoreScript 创建Windows恢复脚本
@@ -0,0 +1,441 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"claude-k2-installer/internal/i18n"
+)
+
+func TestRequestDelayMs(t *testing.T) {
+	tests := []struct {
+		rpm      string
+		want     int
+		wantNote bool
+	}{
+		{"", 20000, true},   // 使用默认 3 RPM
+		{"0", 60000, true},  // 按 1 RPM 处理
+		{"-5", 60000, true}, // 按 1 RPM 处理
+		{"abc", 20000, true},
+		{"99999", 6, true}, // 按 10000 RPM 处理
+		{"3", 20000, false},
+		{" 500 ", 120, false},
+	}
+
+	for _, tt := range tests {
+		got, note := requestDelayMs(tt.rpm, 3)
+		if got != tt.want {
+			t.Errorf("requestDelayMs(%q) = %d, want %d", tt.rpm, got, tt.want)
+		}
+		if (note != "") != tt.wantNote {
+			t.Errorf("requestDelayMs(%q) note = %q, want note: %v", tt.rpm, note, tt.wantNote)
+		}
+	}
+}
+
+func TestRequestDelayMsZeroDefault(t *testing.T) {
+	if got, _ := requestDelayMs("", 0); got != 60000 {
+		t.Errorf("requestDelayMs with zero default RPM = %d, want 60000", got)
+	}
+}
+
+func TestConfigureWithInvalidRPMDoesNotPanic(t *testing.T) {
+	for _, rpm := range []string{"", "0", "abc", "99999"} {
+		t.Run(rpm, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("USERPROFILE", t.TempDir())
+			useTempSetupScript(t)
+
+			i := New()
+			i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+			if err := i.configureK2APIWithOptions(DefaultProvider(), "sk-test-key-0123456789", rpm, false); err != nil {
+				t.Fatalf("configureK2APIWithOptions(rpm=%q): %v", rpm, err)
+			}
+		})
+	}
+}
+
+func TestParseDfAvailable(t *testing.T) {
+	output := "Filesystem     1024-blocks      Used Available Capacity Mounted on\n" +
+		"/dev/disk3s5     482797652 401234567  81563085      84% /System/Volumes/Data\n"
+	got, err := parseDfAvailable(output)
+	if err != nil {
+		t.Fatalf("parseDfAvailable: %v", err)
+	}
+	if want := uint64(81563085) * 1024; got != want {
+		t.Errorf("expected %d bytes, got %d", want, got)
+	}
+
+	if _, err := parseDfAvailable("df: /nope: No such file or directory\n"); err == nil {
+		t.Error("expected an error for unparsable df output")
+	}
+}
+
+func TestElevationNeeds(t *testing.T) {
+	missing := EnvironmentStatus{GitOK: true}
+	if needs := elevationNeeds("windows", false, missing); len(needs) != 1 {
+		t.Errorf("expected Node.js to need elevation on Windows, got %v", needs)
+	}
+	if needs := elevationNeeds("windows", true, missing); needs != nil {
+		t.Errorf("elevated process should need nothing, got %v", needs)
+	}
+	if needs := elevationNeeds("darwin", false, EnvironmentStatus{NodeOK: true, GitOK: true}); needs != nil {
+		t.Errorf("installed environment should need nothing, got %v", needs)
+	}
+}
+
+func TestStartConfigureDeliversProgress(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.DryRun = true
+
+	updates, err := i.StartConfigure(DefaultProvider(), "sk-test-key-1234567890", "3", false)
+	if err != nil {
+		t.Fatalf("StartConfigure: %v", err)
+	}
+	if _, err := i.StartConfigure(DefaultProvider(), "sk-test-key-1234567890", "3", false); err == nil {
+		t.Error("expected an error when another operation is running")
+	}
+
+	received := 0
+	for update := range updates {
+		if update.Error != nil {
+			t.Fatalf("unexpected error: %v", update.Error)
+		}
+		received++
+	}
+	if received == 0 {
+		t.Error("expected configuration progress on the returned channel")
+	}
+
+	// 上一个操作结束后可以开始新的操作
+	updates, err = i.StartConfigure(DefaultProvider(), "sk-test-key-1234567890", "3", false)
+	if err != nil {
+		t.Fatalf("StartConfigure after completion: %v", err)
+	}
+	for range updates {
+	}
+}
+
+func TestCancelEndsOperationContext(t *testing.T) {
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.Cancel() // 没有进行中的操作时不做任何事
+
+	updates, err := i.beginOperation()
+	if err != nil {
+		t.Fatalf("beginOperation: %v", err)
+	}
+	ctx := i.operationContext()
+	if ctx.Err() != nil {
+		t.Fatal("operation context should not be canceled before Cancel")
+	}
+
+	i.Cancel()
+	if ctx.Err() == nil {
+		t.Error("expected Cancel to cancel the operation context")
+	}
+	i.completedSteps = make(map[string]bool)
+	go func() {
+		defer i.endOperation()
+		i.runSteps()
+	}()
+
+	var stepErr *StepError
+	for update := range updates {
+		if update.Error != nil && !errors.As(update.Error, &stepErr) {
+			t.Errorf("unexpected error type: %v", update.Error)
+		}
+	}
+	if stepErr == nil || stepErr.StepID != i.installSteps()[0].id || stepErr.Step != i.installSteps()[0].name() {
+		t.Errorf("expected canceled install to stop at the first step, got %v", stepErr)
+	}
+	if i.operationContext().Err() != nil {
+		t.Error("context after the operation ended should not be canceled")
+	}
+}
+
+func TestPublishKeepsLatestProgressWhenFull(t *testing.T) {
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	updates, err := i.beginOperation()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 填满 channel 后继续写日志和进度，日志跳过，最后一条进度在结束时补发
+	for n := 0; n < cap(updates)+50; n++ {
+		i.addLog("npm http fetch")
+	}
+	i.sendProgress("完成", "所有组件安装完成！", 1.0)
+
+	go i.endOperation()
+	var last ProgressUpdate
+	for update := range updates {
+		last = update
+	}
+	if last.Percent != 1.0 || last.Step != "完成" {
+		t.Errorf("expected the final progress update to be delivered, got %+v", last)
+	}
+}
+
+func TestGetLogsNotesDroppedLines(t *testing.T) {
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.SetMaxLogLines(10)
+	for n := 0; n < 25; n++ {
+		i.addLog("line")
+	}
+
+	logs := i.GetLogs()
+	if len(logs) != 11 || !strings.Contains(logs[0], "15") {
+		t.Errorf("expected a note about 15 dropped lines followed by 10 lines, got %d lines: %q", len(logs), logs[0])
+	}
+}
+
+func TestVerifyReportChecks(t *testing.T) {
+	provider := DefaultProvider()
+	report := VerifyReport{
+		EnvironmentStatus: EnvironmentStatus{NodeOK: true, GitOK: true, ClaudeOK: true, NodeVersion: "v22.11.0", GitVersion: "git version 2.50.1", ClaudeVersion: "1.0.51"},
+		Provider:          provider,
+		BaseURL:           strings.TrimSuffix(provider.BaseURL, "/"),
+		KeySet:            true,
+	}
+	report.BaseURLOK = sameBaseURL(report.BaseURL, provider.BaseURL)
+	if !report.Healthy() {
+		t.Errorf("report should be healthy, checks: %+v", report.Checks())
+	}
+
+	report.BaseURL = "https://api.anthropic.com"
+	report.BaseURLOK = sameBaseURL(report.BaseURL, provider.BaseURL)
+	if report.Healthy() {
+		t.Error("report pointing at another base URL should not be healthy")
+	}
+	checks := report.Checks()
+	if len(checks) != 5 || checks[3].Name != "ANTHROPIC_BASE_URL" || checks[3].OK {
+		t.Errorf("unexpected checks: %+v", checks)
+	}
+}
+
+func TestParseRegQueryValue(t *testing.T) {
+	output := "\r\nHKEY_CURRENT_USER\\Environment\r\n    ANTHROPIC_BASE_URL    REG_SZ    https://api.moonshot.cn/anthropic/\r\n\r\n"
+	if value, ok := parseRegQueryValue(output, "ANTHROPIC_BASE_URL"); !ok || value != "https://api.moonshot.cn/anthropic/" {
+		t.Errorf("parseRegQueryValue = %q, %v", value, ok)
+	}
+	if _, ok := parseRegQueryValue(output, "ANTHROPIC_API_KEY"); ok {
+		t.Error("parseRegQueryValue should not find a missing value")
+	}
+}
+
+func TestProviderRequiredTier(t *testing.T) {
+	kimi := DefaultProvider()
+	tests := []struct {
+		rpm   int
+		label string
+	}{
+		{3, "免费"},
+		{150, "¥50"},
+		{200, "¥50"},
+		{201, "¥100"},
+		{9000, "¥500"},
+	}
+	for _, tt := range tests {
+		tier, ok := kimi.RequiredTier(tt.rpm)
+		if !ok || tier.Label != tt.label {
+			t.Errorf("RequiredTier(%d) = %+v, %v; want %s", tt.rpm, tier, ok, tt.label)
+		}
+	}
+
+	if _, ok := (Provider{}).RequiredTier(100); ok {
+		t.Error("RequiredTier should report no tiers for a provider without published tiers")
+	}
+	if delay, _ := kimi.RequestDelay("200"); delay != 300 {
+		t.Errorf("RequestDelay(200) = %d, want 300", delay)
+	}
+}
+
+func TestPrivilegedCommandQuoting(t *testing.T) {
+	if got := ShellQuote("/tmp/it's node.pkg"); got != `'/tmp/it'\''s node.pkg'` {
+		t.Errorf("ShellQuote = %s", got)
+	}
+	if got := appleScriptString(`installer -pkg "a\b"`); got != `installer -pkg \"a\\b\"` {
+		t.Errorf("appleScriptString = %s", got)
+	}
+	if !isUserCanceled(nil, "execution error: User canceled. (-128)") {
+		t.Error("expected -128 output to be treated as canceled")
+	}
+	if !isUserCanceled(nil, adminCancelledMarker+"\n") {
+		t.Error("expected the cancel marker to be treated as canceled")
+	}
+	if isUserCanceled(nil, "installer: Error - the package path specified was invalid") {
+		t.Error("installer error should not be treated as canceled")
+	}
+	script := adminScript("installer -pkg '/tmp/node.pkg' -target /")
+	if !strings.Contains(script, "-128") || !strings.Contains(script, adminCancelledMarker) {
+		t.Errorf("adminScript should catch error -128: %s", script)
+	}
+}
+
+func TestRetryFromUsesStepIDs(t *testing.T) {
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	if _, err := i.RetryFrom("step.verify"); err == nil {
+		t.Error("RetryFrom before any install should fail")
+	}
+
+	i.completedSteps = make(map[string]bool)
+	// 界面显示的名称随语言变化，不能用来重试
+	if _, err := i.RetryFrom(i18n.T("step.verify")); err == nil {
+		t.Errorf("RetryFrom(%q) should only accept step IDs", i18n.T("step.verify"))
+	}
+}
+
+func TestInstallStepsSkipsComponents(t *testing.T) {
+	i := New()
+	i.SkipGit = true
+
+	var skipped []string
+	for _, step := range i.installSteps() {
+		if step.skipped {
+			skipped = append(skipped, step.id)
+		}
+	}
+	want := []string{"step.check_git", "step.install_git"}
+	if strings.Join(skipped, ",") != strings.Join(want, ",") {
+		t.Errorf("skipped steps = %v, want %v", skipped, want)
+	}
+}
+
+func TestRunStepListProgressIsMonotonic(t *testing.T) {
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	updates, err := i.beginOperation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	i.completedSteps = make(map[string]bool)
+
+	var downloadStart float64
+	download := func() error {
+		downloadStart = i.stepStart
+		// 第一个镜像下载到一半失败，换镜像从头下载
+		for _, fraction := range []float64{0.2, 0.5, 0.8, 0, 0.3, 0.6, 1} {
+			i.sendStepProgress(fraction, "正在下载")
+		}
+		return nil
+	}
+	steps := []installStep{
+		{"check", func() error { return nil }, 3, false, false},
+		{"skipped", func() error { return nil }, 20, false, true},
+		{"installed", func() error { i.skipStepProgress(); return nil }, 30, false, false},
+		{"optional", func() error { return errors.New("not found") }, 2, true, false},
+		{"download", download, 30, false, false},
+		{"verify", func() error { return nil }, 5, false, false},
+	}
+	go func() {
+		defer i.endOperation()
+		i.runStepList(steps)
+	}()
+
+	var percents []float64
+	for update := range updates {
+		if update.Error != nil {
+			t.Fatalf("unexpected error: %v", update.Error)
+		}
+		if update.Step != "日志" {
+			percents = append(percents, update.Percent)
+		}
+	}
+	for n := 1; n < len(percents); n++ {
+		if percents[n] < percents[n-1] {
+			t.Fatalf("progress went backwards at update %d: %v", n, percents)
+		}
+	}
+	if len(percents) == 0 || percents[len(percents)-1] != 1.0 {
+		t.Errorf("expected progress to end at 1.0, got %v", percents)
+	}
+	// 已安装的步骤不占用进度，下载开始时进度只有两个检测步骤的份额
+	if downloadStart > 0.1 {
+		t.Errorf("download started at %.2f, the already installed step should not consume progress", downloadStart)
+	}
+}
+
+func TestErrorClassificationKeywords(t *testing.T) {
+	cmdErr := fmt.Errorf("安装失败: %w", &CommandError{ExitCode: 243, Tail: "npm ERR! Error: EACCES: permission denied, mkdir '/usr/lib/node_modules'"})
+	if !commandOutputContains(cmdErr, permissionDeniedKeywords...) {
+		t.Errorf("EACCES output not recognized as permission error")
+	}
+	if commandOutputContains(cmdErr, sudoFailureKeywords...) {
+		t.Errorf("EACCES output recognized as sudo failure")
+	}
+	if commandOutputContains(errors.New("EACCES"), permissionDeniedKeywords...) {
+		t.Errorf("plain error should not be treated as command output")
+	}
+
+	balance := []string{`API Error: 403 {"error":{"type":"exceeded_current_quota_error"}}`}
+	if !linesContain(balance, insufficientBalanceKeywords...) {
+		t.Errorf("quota error not recognized as insufficient balance")
+	}
+	auth := []string{`API Error: 401 {"error":{"message":"Invalid Authentication"}}`}
+	if !linesContain(auth, authFailureKeywords...) || linesContain(auth, insufficientBalanceKeywords...) {
+		t.Errorf("401 error classified incorrectly")
+	}
+
+	if got := exitCode(errors.New("not a command")); got != -1 {
+		t.Errorf("exitCode(non-exit error) = %d, want -1", got)
+	}
+}
+
+func TestClaudeCodeUpdateSummary(t *testing.T) {
+	updated := ClaudeCodeUpdate{OldVersion: "1.0.51", NewVersion: "1.0.60"}
+	if !updated.Updated() || !strings.Contains(updated.Summary(), "1.0.51") || !strings.Contains(updated.Summary(), "1.0.60") {
+		t.Errorf("Summary() = %q, want both versions", updated.Summary())
+	}
+	same := ClaudeCodeUpdate{OldVersion: "1.0.60", NewVersion: "1.0.60"}
+	if same.Updated() || !strings.Contains(same.Summary(), "最新版本") {
+		t.Errorf("Summary() = %q, want already-latest message", same.Summary())
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.addLog("安装前的日志 ⚠️ 不计入报告")
+	i.SkipGit = true
+	i.startReport()
+	i.addLog("⚠️ 设置环境变量 CLAUDE_REQUEST_DELAY_MS 失败")
+	i.addLog("✅ 完成")
+
+	provider := DefaultProvider()
+	report := i.BuildReport(provider, "sk-abcdefghijklmnop", "30", true, nil)
+	if !report.Success || report.Error != "" {
+		t.Errorf("expected a successful report, got %+v", report)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "CLAUDE_REQUEST_DELAY_MS") {
+		t.Errorf("Warnings = %q", report.Warnings)
+	}
+	if len(report.Components) != 3 || report.Components[1].Status != ComponentSkipped {
+		t.Errorf("Components = %+v, want Git skipped", report.Components)
+	}
+	if key := report.EnvVars[provider.EnvKeyName]; key == "" || strings.Contains(key, "ijklmnop") {
+		t.Errorf("API key should be masked in the report, got %q", key)
+	}
+	if report.EnvVars["ANTHROPIC_BASE_URL"] != provider.BaseURL || !report.Permanent {
+		t.Errorf("unexpected env vars %v", report.EnvVars)
+	}
+	if i.LastReport() != report {
+		t.Error("LastReport should return the built report")
+	}
+
+	failed := i.BuildReport(provider, "sk-abcdefghijklmnop", "30", false, errors.New("配置失败"))
+	if failed.Success || failed.Error != "配置失败" || failed.EnvVars != nil {
+		t.Errorf("unexpected failed report %+v", failed)
+	}
+	data, err := failed.JSON()
+	if err != nil || !strings.Contains(string(data), `"success": false`) {
+		t.Errorf("JSON() = %s, %v", data, err)
+	}
+}
@@ -0,0 +1,116 @@
+package installer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// installRecordFile 安装记录的文件名，保存在 ~/.claude-k2-installer 下
+const installRecordFile = "install-state.json"
+
+// InstallRecord 安装和配置成功后保存的记录，重新打开程序时据此显示已安装状态
+type InstallRecord struct {
+	CompletedAt   time.Time `json:"completed_at"`
+	Provider      string    `json:"provider,omitempty"`
+	NodeVersion   string    `json:"node_version,omitempty"` // 安装完成时检测到的版本，跳过的组件为空
+	GitVersion    string    `json:"git_version,omitempty"`
+	ClaudeVersion string    `json:"claude_version,omitempty"`
+}
+
+// Stale 记录中的组件已被删除：Claude Code 不可用，或记录过的 Node.js、Git 已检测不到
+func (r InstallRecord) Stale(status EnvironmentStatus) bool {
+	return !status.ClaudeOK ||
+		(r.NodeVersion != "" && status.NodeVersion == "") ||
+		(r.GitVersion != "" && !status.GitOK)
+}
+
+// installRecordPath 安装记录的路径
+func installRecordPath() (string, error) {
+	dir, err := logDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, installRecordFile), nil
+}
+
+// LoadInstallRecord 读取安装记录，从未成功安装过时返回 nil
+func LoadInstallRecord() (*InstallRecord, error) {
+	path, err := installRecordPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record InstallRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("安装记录格式不正确: %v", err)
+	}
+	return &record, nil
+}
+
+// ClearInstallRecord 删除安装记录，记录不存在时不报错
+func ClearInstallRecord() error {
+	path, err := installRecordPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// saveInstallRecord 配置成功后按当前检测到的组件版本保存安装记录，模拟运行不保存
+func (i *Installer) saveInstallRecord(provider Provider) {
+	if i.DryRun {
+		return
+	}
+	status := i.CheckEnvironment()
+	record := InstallRecord{
+		CompletedAt:   time.Now(),
+		Provider:      provider.Name,
+		NodeVersion:   status.NodeVersion,
+		GitVersion:    status.GitVersion,
+		ClaudeVersion: status.ClaudeVersion,
+	}
+	if err := writeInstallRecord(record); err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 保存安装记录失败: %v", err))
+	}
+}
+
+// refreshInstallRecord 更新 Claude Code 后把新版本写入已有的安装记录，没有记录时不做任何事
+func (i *Installer) refreshInstallRecord() {
+	record, err := LoadInstallRecord()
+	if err != nil || record == nil {
+		return
+	}
+	record.ClaudeVersion = installedVersion("claude")
+	if err := writeInstallRecord(*record); err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 更新安装记录失败: %v", err))
+	}
+}
+
+// writeInstallRecord 写入安装记录
+func writeInstallRecord(record InstallRecord) error {
+	path, err := installRecordPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
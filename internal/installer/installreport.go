@@ -0,0 +1,156 @@
+package installer
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 组件在安装报告中的状态
+const (
+	ComponentOK      = "ok"      // 已安装且版本满足要求
+	ComponentFailed  = "failed"  // 未安装或版本过低
+	ComponentSkipped = "skipped" // 用户选择跳过
+)
+
+// ComponentResult 安装报告中单个组件的结果
+type ComponentResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Version string `json:"version,omitempty"` // 结束时检测到的版本，未安装时为空
+}
+
+// InstallReport 一次安装和配置的汇总结果，完成对话框据此显示摘要，也可导出为 JSON 附在问题反馈中
+type InstallReport struct {
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  time.Time         `json:"finished_at"`
+	Duration    string            `json:"duration"`
+	Success     bool              `json:"success"`
+	Error       string            `json:"error,omitempty"`
+	DryRun      bool              `json:"dry_run,omitempty"`
+	Provider    string            `json:"provider,omitempty"`
+	Components  []ComponentResult `json:"components"`
+	ConfigFiles []ConfigFile      `json:"config_files,omitempty"` // 写入 K2 配置的文件
+	EnvVars     map[string]string `json:"env_vars,omitempty"`     // 设置的环境变量，API Key 只保留前缀
+	Permanent   bool              `json:"permanent"`              // 环境变量是否永久生效
+	Changes     []string          `json:"changes,omitempty"`      // 相对安装前快照的环境变化
+	Warnings    []string          `json:"warnings,omitempty"`     // 本次运行日志中的警告
+}
+
+// JSON 返回缩进格式的报告
+func (r *InstallReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// startReport 开始记录新的安装报告
+func (i *Installer) startReport() {
+	i.reportStart = time.Now()
+	i.reportLogStart = i.LogCount()
+}
+
+// RunFull 依次安装全部组件和配置 API，结束后返回本次运行的汇总报告
+//
+// 同步执行，进度和错误通过 onUpdate 实时报告（可为 nil）；安装失败时不再配置。
+// 会执行命令检测版本，不要在 UI 主线程中调用。
+func (i *Installer) RunFull(provider Provider, apiKey, rpm string, useSystemConfig bool, onUpdate func(ProgressUpdate)) *InstallReport {
+	var runErr error
+	forward := func(updates <-chan ProgressUpdate, err error) {
+		if err != nil {
+			runErr = err
+			return
+		}
+		for update := range updates {
+			if update.Error != nil && runErr == nil {
+				runErr = update.Error
+			}
+			if onUpdate != nil {
+				onUpdate(update)
+			}
+		}
+	}
+
+	i.startReport()
+	forward(i.StartInstall())
+	if runErr == nil {
+		forward(i.StartConfigure(provider, apiKey, rpm, useSystemConfig))
+	}
+
+	report := i.BuildReport(provider, apiKey, rpm, useSystemConfig, runErr)
+	i.emitReport(report)
+	return report
+}
+
+// BuildReport 按当前环境和本次运行的日志生成安装报告，runErr 为安装或配置失败的原因
+//
+// 会执行命令检测版本，不要在 UI 主线程中调用。生成后下次安装或配置重新开始计时。
+func (i *Installer) BuildReport(provider Provider, apiKey, rpm string, useSystemConfig bool, runErr error) *InstallReport {
+	start := i.reportStart
+	if start.IsZero() {
+		start = time.Now()
+	}
+	status := i.CheckEnvironment()
+	report := &InstallReport{
+		StartedAt:   start,
+		FinishedAt:  time.Now(),
+		Success:     runErr == nil,
+		DryRun:      i.DryRun,
+		Provider:    provider.Name,
+		Components:  i.componentResults(status),
+		ConfigFiles: i.ConfigFiles(useSystemConfig),
+		Permanent:   useSystemConfig,
+		Changes:     i.RunDiff(),
+	}
+	report.Duration = report.FinishedAt.Sub(start).Round(time.Second).String()
+	if runErr != nil {
+		report.Error = maskSecrets(runErr.Error())
+	}
+	if runErr == nil && apiKey != "" && !i.DryRun {
+		report.EnvVars = reportEnvVars(provider, apiKey, rpm)
+	}
+	logs, _ := i.LogsSince(i.reportLogStart)
+	for _, line := range logs {
+		if strings.Contains(line, "⚠️") {
+			report.Warnings = append(report.Warnings, maskSecrets(line))
+		}
+	}
+
+	i.reportStart = time.Time{}
+	i.lastReport = report
+	return report
+}
+
+// LastReport 返回最近一次生成的安装报告，尚未生成时返回 nil
+func (i *Installer) LastReport() *InstallReport {
+	return i.lastReport
+}
+
+// componentResults 按检测到的环境汇总各组件的结果
+func (i *Installer) componentResults(status EnvironmentStatus) []ComponentResult {
+	result := func(name string, skipped, ok bool, version string) ComponentResult {
+		component := ComponentResult{Name: name, Status: ComponentFailed, Version: version}
+		switch {
+		case skipped:
+			component.Status = ComponentSkipped
+		case ok:
+			component.Status = ComponentOK
+		}
+		return component
+	}
+	return []ComponentResult{
+		result("Node.js", i.SkipNode, status.NodeOK, status.NodeVersion),
+		result("Git", i.SkipGit, status.GitOK, status.GitVersion),
+		result("Claude Code", false, status.ClaudeOK, status.ClaudeVersion),
+	}
+}
+
+// reportEnvVars 配置 API 时设置的环境变量，API Key 只保留前缀
+func reportEnvVars(provider Provider, apiKey, rpm string) map[string]string {
+	delay, _ := requestDelayMs(rpm, provider.DefaultRPM)
+	return map[string]string{
+		"ANTHROPIC_BASE_URL":             provider.BaseURL,
+		provider.EnvKeyName:              maskKey(apiKey),
+		"CLAUDE_REQUEST_DELAY_MS":        strconv.Itoa(delay),
+		"CLAUDE_MAX_CONCURRENT_REQUESTS": "1",
+	}
+}
@@ -0,0 +1,73 @@
+package installer
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// linuxPackageManager Linux 包管理器及其安装命令
+type linuxPackageManager struct {
+	name    string
+	update  []string // 安装前刷新软件源的命令，为空表示不需要
+	install []string // 安装命令，后接包名
+}
+
+// linuxPackageManagers 支持的包管理器，按检测顺序排列
+// Fedora 上 yum 是 dnf 的别名，因此先检测 dnf
+var linuxPackageManagers = []linuxPackageManager{
+	{name: "apt-get", update: []string{"apt-get", "update"}, install: []string{"apt-get", "install", "-y"}},
+	{name: "dnf", install: []string{"dnf", "install", "-y"}},
+	{name: "yum", install: []string{"yum", "install", "-y"}},
+	{name: "pacman", install: []string{"pacman", "-S", "--noconfirm"}},
+	{name: "zypper", install: []string{"zypper", "--non-interactive", "install"}},
+}
+
+// findLinuxPackageManager 返回系统中第一个可用的包管理器
+func findLinuxPackageManager() (linuxPackageManager, bool) {
+	for _, pm := range linuxPackageManagers {
+		if _, err := exec.LookPath(pm.name); err == nil {
+			return pm, true
+		}
+	}
+	return linuxPackageManager{}, false
+}
+
+// commands 返回安装指定软件包需要执行的命令（均通过 sudo 执行），refresh 为 true 时先刷新软件源
+func (pm linuxPackageManager) commands(refresh bool, packages ...string) [][]string {
+	var cmds [][]string
+	if refresh && len(pm.update) > 0 {
+		cmds = append(cmds, append([]string{"sudo"}, pm.update...))
+	}
+	install := append([]string{"sudo"}, pm.install...)
+	cmds = append(cmds, append(install, packages...))
+	return cmds
+}
+
+// describeCommands 返回命令的可读形式，用于日志和模拟运行
+func describeCommands(cmds [][]string) []string {
+	var lines []string
+	for _, cmd := range cmds {
+		lines = append(lines, strings.Join(cmd, " "))
+	}
+	return lines
+}
+
+// installLinuxPackages 使用系统包管理器安装软件包，刷新软件源失败时继续安装
+func (i *Installer) installLinuxPackages(pm linuxPackageManager, refresh bool, packages ...string) error {
+	cmds := pm.commands(refresh, packages...)
+	for idx, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		if idx < len(cmds)-1 {
+			// 与原来的 apt-get update 一样，刷新失败不影响安装
+			cmd.Run()
+			continue
+		}
+		err := i.executeCommandWithStreaming(cmd)
+		if err != nil && commandOutputContains(err, sudoFailureKeywords...) {
+			return fmt.Errorf("%w: %v", ErrNeedsElevation, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package installer
+
+// DefaultMaxLogLines 内存中默认保留的日志行数
+const DefaultMaxLogLines = 2000
+
+// logRing 固定容量的日志环形缓冲区，写满后丢弃最旧的日志
+//
+// 每条日志有一个递增的序号，调用方记住上次读到的序号即可只取新增日志。
+type logRing struct {
+	lines []string
+	head  int // 最旧一条日志在 lines 中的位置
+	size  int // 当前保留的条数
+	total int // 累计写入的条数，即下一条日志的序号
+}
+
+func newLogRing(capacity int) *logRing {
+	if capacity <= 0 {
+		capacity = DefaultMaxLogLines
+	}
+	return &logRing{lines: make([]string, capacity)}
+}
+
+// add 追加一条日志，缓冲区已满时覆盖最旧的一条
+func (r *logRing) add(line string) {
+	if r.size < len(r.lines) {
+		r.lines[(r.head+r.size)%len(r.lines)] = line
+		r.size++
+	} else {
+		r.lines[r.head] = line
+		r.head = (r.head + 1) % len(r.lines)
+	}
+	r.total++
+}
+
+// since 返回序号不小于 seq 且仍在缓冲区中的日志副本，以及下一条日志的序号
+func (r *logRing) since(seq int) ([]string, int) {
+	oldest := r.total - r.size
+	if seq < oldest {
+		seq = oldest
+	}
+	if seq > r.total {
+		seq = r.total
+	}
+
+	lines := make([]string, 0, r.total-seq)
+	for n := seq - oldest; n < r.size; n++ {
+		lines = append(lines, r.lines[(r.head+n)%len(r.lines)])
+	}
+	return lines, r.total
+}
+
+// resize 调整容量，保留最新的日志
+func (r *logRing) resize(capacity int) {
+	if capacity <= 0 {
+		capacity = DefaultMaxLogLines
+	}
+	lines, _ := r.since(0)
+	if len(lines) > capacity {
+		lines = lines[len(lines)-capacity:]
+	}
+
+	r.lines = make([]string, capacity)
+	copy(r.lines, lines)
+	r.head = 0
+	r.size = len(lines)
+}
+
+// SetMaxLogLines 设置内存中最多保留的日志行数，超出后丢弃最旧的日志
+func (i *Installer) SetMaxLogLines(n int) {
+	i.logsMu.Lock()
+	defer i.logsMu.Unlock()
+	i.logs.resize(n)
+}
+
+// MaxLogLines 返回内存中最多保留的日志行数
+func (i *Installer) MaxLogLines() int {
+	i.logsMu.RLock()
+	defer i.logsMu.RUnlock()
+	return len(i.logs.lines)
+}
+
+// LogsSince 返回序号从 seq 开始的日志副本和下一条日志的序号
+//
+// 已被丢弃的日志不再返回，UI 记住返回的序号即可只追加新日志。
+func (i *Installer) LogsSince(seq int) ([]string, int) {
+	i.logsMu.RLock()
+	defer i.logsMu.RUnlock()
+	return i.logs.since(seq)
+}
+
+// LogCount 返回累计写入的日志条数，即下一条日志的序号
+func (i *Installer) LogCount() int {
+	i.logsMu.RLock()
+	defer i.logsMu.RUnlock()
+	return i.logs.total
+}
+
+// logsSince 返回序号从 seq 开始的日志副本
+func (i *Installer) logsSince(seq int) []string {
+	logs, _ := i.LogsSince(seq)
+	return logs
+}
@@ -0,0 +1,284 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"claude-k2-installer/internal/version"
+)
+
+// LogRetention 日志落盘的留存方式
+type LogRetention string
+
+const (
+	LogRetentionOff     LogRetention = "off"     // 不落盘，日志只保留在内存中
+	LogRetentionSession LogRetention = "session" // 仅本次会话，程序退出时删除
+	LogRetentionDays    LogRetention = "days"    // 留存 N 天
+	LogRetentionForever LogRetention = "forever" // 永久留存
+)
+
+// DefaultLogRetentionDays 默认日志留存天数
+const DefaultLogRetentionDays = 7
+
+// maxLogFileSize 单个日志文件的大小上限，超出后换新文件继续写
+const maxLogFileSize = 5 << 20
+
+// LogPolicy 日志留存与隐私策略
+type LogPolicy struct {
+	Retention      LogRetention `json:"retention"`
+	Days           int          `json:"days,omitempty"`             // Retention 为 days 时的留存天数
+	RecordFullArgs bool         `json:"record_full_args,omitempty"` // 记录命令完整参数，可能包含 API Key 等敏感信息
+}
+
+// DefaultLogPolicy 默认策略：脱敏并留存 7 天
+func DefaultLogPolicy() LogPolicy {
+	return LogPolicy{Retention: LogRetentionDays, Days: DefaultLogRetentionDays}
+}
+
+// Describe 返回策略的中文说明
+func (p LogPolicy) Describe() string {
+	var retention string
+	switch p.Retention {
+	case LogRetentionOff:
+		retention = "日志不落盘"
+	case LogRetentionSession:
+		retention = "日志仅保留到本次会话结束"
+	case LogRetentionForever:
+		retention = "日志永久保存"
+	default:
+		retention = fmt.Sprintf("日志保留 %d 天", p.Days)
+	}
+
+	if p.RecordFullArgs {
+		return retention + "，记录命令完整参数（可能包含 API Key）"
+	}
+	return retention + "，API Key 等敏感信息脱敏"
+}
+
+// secretPattern 匹配 sk- 开头的密钥，快照和差异中只保留前缀
+var secretPattern = regexp.MustCompile(`sk-[A-Za-z0-9_\-]{4,}`)
+
+// secretAssignPattern 匹配 API_KEY=xxx、"apiKey": "xxx" 形式的赋值，覆盖非 sk- 开头的密钥
+// 也覆盖 setx ANTHROPIC_API_KEY "xxx" 这种以空格分隔的命令参数
+var secretAssignPattern = regexp.MustCompile(`((?:API_KEY|AUTH_TOKEN|apiKey)"?(?:\s*[=:]\s*"?|\s+"))([^"\s]{4})[^"\s]*`)
+
+// maskSecrets 隐藏文本中的密钥
+func maskSecrets(text string) string {
+	text = secretPattern.ReplaceAllStringFunc(text, func(secret string) string {
+		return secret[:7] + "****"
+	})
+	return secretAssignPattern.ReplaceAllString(text, "${1}${2}****")
+}
+
+// maskKey 返回密钥前 10 个字符加省略号，用于在脚本和日志中提示当前使用的密钥
+// 密钥不足 10 个字符时显示已有部分，避免越界
+func maskKey(apiKey string) string {
+	runes := []rune(apiKey)
+	if len(runes) > 10 {
+		runes = runes[:10]
+	}
+	return string(runes) + "..."
+}
+
+// logDir 日志和环境快照所在目录
+func logDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude-k2-installer"), nil
+}
+
+// SetLogPolicy 应用日志策略，并按策略清理过期的日志和环境快照
+func (i *Installer) SetLogPolicy(policy LogPolicy) {
+	if policy.Retention == "" {
+		policy = DefaultLogPolicy()
+	}
+	if policy.Retention == LogRetentionDays && policy.Days <= 0 {
+		policy.Days = DefaultLogRetentionDays
+	}
+
+	i.logFileMu.Lock()
+	i.logPolicy = policy
+	// 关闭落盘后不再写入当前日志文件
+	if policy.Retention == LogRetentionOff && i.logFile != nil {
+		i.logFile.Close()
+		i.logFile = nil
+	}
+	i.logFileMu.Unlock()
+
+	switch policy.Retention {
+	case LogRetentionDays:
+		pruneLogs(time.Now().AddDate(0, 0, -policy.Days))
+	case LogRetentionSession:
+		// 之前会话遗留的日志（例如程序异常退出）一并清理
+		pruneLogs(time.Now())
+	}
+}
+
+// LogPolicy 返回当前的日志策略
+func (i *Installer) LogPolicy() LogPolicy {
+	i.logFileMu.Lock()
+	defer i.logFileMu.Unlock()
+	return i.logPolicy
+}
+
+// startLogFile 安装开始时打开日志文件并写入分隔行，之后每条日志实时落盘
+//
+// 程序崩溃或被强制退出时，已写入的日志仍可用于排查“安装卡住”等问题。
+func (i *Installer) startLogFile() {
+	i.logFileMu.Lock()
+	defer i.logFileMu.Unlock()
+
+	if i.logPolicy.Retention == LogRetentionOff || i.openLogFile() != nil {
+		return
+	}
+	i.writeLogLine(fmt.Sprintf("==== 开始安装 安装器 %s (%s/%s) ====", version.String(), runtime.GOOS, runtime.GOARCH))
+}
+
+// openLogFile 打开当前会话的日志文件，已打开时直接返回；调用方需持有 logFileMu
+func (i *Installer) openLogFile() error {
+	if i.logFile != nil {
+		return nil
+	}
+
+	dir, err := logDir()
+	if err != nil {
+		return err
+	}
+	dir = filepath.Join(dir, "logs")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("install-%s.log", time.Now().Format("20060102-150405"))
+	if i.logFileRotations > 0 {
+		name = fmt.Sprintf("install-%s-%d.log", time.Now().Format("20060102-150405"), i.logFileRotations)
+	}
+	path := filepath.Join(dir, name)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	i.logFile = f
+	i.logFileSize = 0
+	if info, err := f.Stat(); err == nil {
+		i.logFileSize = info.Size()
+	}
+	i.sessionFiles = append(i.sessionFiles, path)
+	return nil
+}
+
+// writeLogLine 写入一行带时间戳的日志，文件超过大小上限时换新文件；调用方需持有 logFileMu
+func (i *Installer) writeLogLine(message string) {
+	if i.logFileSize >= maxLogFileSize {
+		i.logFile.Close()
+		i.logFile = nil
+		i.logFileRotations++
+		if i.openLogFile() != nil {
+			return
+		}
+	}
+
+	n, _ := fmt.Fprintf(i.logFile, "%s %s\n", time.Now().Format("2006-01-02 15:04:05"), message)
+	i.logFileSize += int64(n)
+}
+
+// sessionLogLines 读取本次会话写入的全部日志文件（含因超出大小上限换新的文件）
+// 日志不落盘或文件已删除时返回 false
+func (i *Installer) sessionLogLines() ([]string, bool) {
+	i.logFileMu.Lock()
+	defer i.logFileMu.Unlock()
+
+	var lines []string
+	found := false
+	for _, path := range i.sessionFiles {
+		if filepath.Ext(path) != ".log" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, false
+		}
+		found = true
+		lines = append(lines, strings.Split(strings.TrimRight(string(data), "\n"), "\n")...)
+	}
+	return lines, found
+}
+
+// writeLogFile 按策略将一行日志写入日志文件，首次写入时创建文件
+func (i *Installer) writeLogFile(message string) {
+	i.logFileMu.Lock()
+	defer i.logFileMu.Unlock()
+
+	if i.logPolicy.Retention == LogRetentionOff || i.openLogFile() != nil {
+		return
+	}
+	i.writeLogLine(message)
+}
+
+// CloseLog 关闭日志文件，策略为仅本次会话时删除本次写入的日志和快照
+func (i *Installer) CloseLog() {
+	i.logFileMu.Lock()
+	defer i.logFileMu.Unlock()
+
+	if i.logFile != nil {
+		i.logFile.Close()
+		i.logFile = nil
+	}
+
+	if i.logPolicy.Retention == LogRetentionSession {
+		for _, path := range i.sessionFiles {
+			os.Remove(path)
+		}
+		i.sessionFiles = nil
+	}
+}
+
+// persistSnapshot 按日志策略保存环境快照
+func (i *Installer) persistSnapshot(snapshot *EnvSnapshot, stage string) {
+	if i.LogPolicy().Retention == LogRetentionOff {
+		return
+	}
+
+	path, err := saveSnapshot(snapshot, stage)
+	if err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 保存环境快照失败: %v", err))
+		return
+	}
+
+	i.logFileMu.Lock()
+	i.sessionFiles = append(i.sessionFiles, path)
+	i.logFileMu.Unlock()
+	i.addLog(fmt.Sprintf("📸 已保存环境快照: %s", path))
+}
+
+// pruneLogs 删除修改时间早于 before 的日志和环境快照
+func pruneLogs(before time.Time) {
+	dir, err := logDir()
+	if err != nil {
+		return
+	}
+
+	for _, sub := range []string{"logs", "snapshots"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || entry.IsDir() {
+				continue
+			}
+			if info.ModTime().Before(before) {
+				os.Remove(filepath.Join(dir, sub, entry.Name()))
+			}
+		}
+	}
+}
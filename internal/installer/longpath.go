@@ -0,0 +1,56 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// enableLongPathsCommand 启用 Windows 长路径支持的命令（需要管理员权限）
+const enableLongPathsCommand = `reg add "HKLM\SYSTEM\CurrentControlSet\Control\FileSystem" /v LongPathsEnabled /t REG_DWORD /d 1 /f`
+
+// windowsLongPathsEnabled 检查注册表中是否已启用长路径支持
+func windowsLongPathsEnabled() bool {
+	cmd := exec.Command("reg", "query", `HKLM\SYSTEM\CurrentControlSet\Control\FileSystem`, "/v", "LongPathsEnabled")
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(output), "0x1")
+}
+
+// isLongPathError 判断 npm 输出是否是由 Windows 260 字符路径限制引起的失败
+func isLongPathError(lines []string) bool {
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "enametoolong") ||
+			strings.Contains(lower, "name too long") ||
+			strings.Contains(lower, "path too long") ||
+			strings.Contains(lower, "filename or extension is too long") {
+			return true
+		}
+	}
+	return false
+}
+
+// shortNpmCacheDir 返回路径较短的 npm 缓存目录，减少依赖解压时的路径长度
+func shortNpmCacheDir() string {
+	drive := os.Getenv("SystemDrive")
+	if drive == "" {
+		drive = "C:"
+	}
+	return filepath.Join(drive+`\`, "npmc")
+}
+
+// checkWindowsLongPaths 安装前检查长路径设置，未启用时提示用户
+func (i *Installer) checkWindowsLongPaths() {
+	if windowsLongPathsEnabled() {
+		i.addLog("✅ 已启用 Windows 长路径支持")
+		return
+	}
+
+	i.addLog("⚠️ 未启用 Windows 长路径支持，Claude Code 的部分依赖路径可能超过 260 字符导致安装失败")
+	i.addLog("   如安装失败，请以管理员身份运行以下命令后重试:")
+	i.addLog("   " + enableLongPathsCommand)
+}
@@ -0,0 +1,54 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// macBinDirs 返回 macOS 上 Homebrew 和 Node.js 安装包使用的 bin 目录，按当前架构的优先级排序
+// Apple Silicon 的 Homebrew 安装在 /opt/homebrew，Intel 的 Homebrew 和官方 .pkg 安装包使用 /usr/local
+func macBinDirs() []string {
+	if runtime.GOARCH == "arm64" {
+		return []string{"/opt/homebrew/bin", "/usr/local/bin"}
+	}
+	return []string{"/usr/local/bin", "/opt/homebrew/bin"}
+}
+
+// macCommandCandidates 返回 macOS 上命令可能的安装位置，按架构优先级排序，最后是系统自带的 /usr/bin
+func macCommandCandidates(name string) []string {
+	var paths []string
+	for _, dir := range append(macBinDirs(), "/usr/bin") {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	return paths
+}
+
+// prependMacBinDirs 将存在的 bin 目录按架构优先级加入当前进程的 PATH，已在 PATH 中的目录不重复添加
+func (i *Installer) prependMacBinDirs() {
+	dirs := macBinDirs()
+	current := filepath.SplitList(os.Getenv("PATH"))
+
+	// 倒序添加，保证优先级最高的目录排在最前
+	for idx := len(dirs) - 1; idx >= 0; idx-- {
+		dir := dirs[idx]
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+
+		exists := false
+		for _, p := range current {
+			if strings.TrimRight(p, "/") == dir {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+
+		os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		i.addLog("已添加 " + dir + " 到 PATH")
+	}
+}
@@ -0,0 +1,262 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// manualGuideKeyPlaceholder 手动安装指南中代替 API Key 的占位符，指南可能被导出或发给他人，不包含真实密钥
+const manualGuideKeyPlaceholder = "<你的 API Key>"
+
+// manualGuideInput 生成手动安装指南所需的信息，由 ManualInstallGuide 检测后填写
+type manualGuideInput struct {
+	goos, arch  string
+	failedStep  string            // 自动安装失败的步骤，为空时不显示
+	status      EnvironmentStatus // 已安装的组件，满足要求的组件不再列出安装步骤
+	skipNode    bool
+	skipGit     bool
+	nodeVersion string
+	nodeURLs    []string // 当前平台的 Node.js 安装包下载地址
+	gitURLs     []string // Windows 上的 Git for Windows 下载地址
+	gitCommands []string // Linux 上安装 Git 的包管理器命令
+	registry    string   // 可以连接的 npm 镜像
+	packageSpec string   // 如 @anthropic-ai/claude-code@latest
+	shellConfig string   // macOS/Linux 上写入环境变量的 shell 配置文件
+	provider    Provider
+	keyHint     string // API Key 的前缀，提示用户替换占位符时使用哪个密钥
+	delay       int    // 请求延迟（毫秒）
+}
+
+// ManualInstallGuide 自动安装反复失败时，按当前系统、架构和失败的步骤生成手动安装指南（Markdown 格式）：
+// 本平台的安装包下载地址、使用可连接镜像的 npm 命令，以及当前 shell 的环境变量设置。
+// failedStep 为失败的步骤名称，可为空。指南中的 API Key 用占位符代替，可以放心导出。
+//
+// 会检测已安装的组件和 npm 镜像的连通性，不要在 UI 主线程中调用。
+func (i *Installer) ManualInstallGuide(provider Provider, apiKey, rpm, failedStep string) string {
+	input := manualGuideInput{
+		goos:        runtime.GOOS,
+		arch:        runtime.GOARCH,
+		failedStep:  failedStep,
+		status:      i.CheckEnvironment(),
+		skipNode:    i.SkipNode,
+		skipGit:     i.SkipGit,
+		nodeVersion: i.nodeTargetVersion(),
+		packageSpec: i.claudeCodePackageSpec(),
+		provider:    provider,
+	}
+	if runtime.GOOS == "windows" {
+		input.arch = windowsNativeArch()
+	}
+	if apiKey != "" {
+		input.keyHint = maskKey(apiKey)
+	}
+	input.delay, _ = requestDelayMs(rpm, provider.DefaultRPM)
+
+	if artifact, err := nodeArtifactName(input.nodeVersion, input.goos, input.arch); err == nil {
+		input.nodeURLs = i.nodeURLs(input.nodeVersion, artifact)
+	}
+	switch input.goos {
+	case "windows":
+		version := i.gitTargetVersion()
+		input.gitURLs = gitWindowsDownloadURLs(version, gitWindowsArtifact(version, input.arch))
+	case "linux":
+		if pm, ok := findLinuxPackageManager(); ok {
+			input.gitCommands = describeCommands(pm.commands(true, "git"))
+		}
+	}
+
+	// 自动安装时 npm 镜像可能无法连接，指南中使用检测时可以连接的镜像
+	input.registry = i.npmRegistry()
+	if registries, err := i.reachableNpmRegistries(); err == nil {
+		input.registry = registries[0]
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		if configs := shellConfigFiles(home); len(configs) > 0 {
+			input.shellConfig = configs[0]
+		}
+	}
+	return buildManualGuide(input)
+}
+
+// buildManualGuide 按检测结果生成手动安装指南
+func buildManualGuide(in manualGuideInput) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Claude Code 手动安装指南\n\n")
+	fmt.Fprintf(&b, "- 生成时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- 系统: %s / %s\n", manualGuideOSName(in.goos), in.arch)
+	if in.failedStep != "" {
+		fmt.Fprintf(&b, "- 自动安装失败的步骤: %s\n", in.failedStep)
+	}
+	b.WriteString("\n按顺序完成以下步骤，已满足要求的步骤可以跳过。每一步完成后请重新打开终端再继续。\n")
+
+	step := 0
+	section := func(title string) {
+		step++
+		fmt.Fprintf(&b, "\n## %d. %s\n\n", step, title)
+	}
+
+	section("安装 Node.js")
+	switch {
+	case in.skipNode:
+		b.WriteString("已选择自行管理 Node.js，请确认 `node --version` 输出的版本满足要求。\n")
+	case in.status.NodeOK:
+		fmt.Fprintf(&b, "✅ 已安装 %s，可以跳过。\n", in.status.NodeVersion)
+	default:
+		writeManualNodeSteps(&b, in)
+	}
+
+	section("安装 Git")
+	switch {
+	case in.skipGit:
+		b.WriteString("已选择自行管理 Git，请确认 `git --version` 可以正常输出。\n")
+	case in.status.GitOK:
+		fmt.Fprintf(&b, "✅ 已安装 %s，可以跳过。\n", in.status.GitVersion)
+	default:
+		writeManualGitSteps(&b, in)
+	}
+
+	section("安装 Claude Code")
+	if in.status.ClaudeOK {
+		fmt.Fprintf(&b, "✅ 已安装 %s，可以跳过。需要重新安装时执行下面的命令。\n\n", in.status.ClaudeVersion)
+	}
+	b.WriteString("在新打开的终端中执行：\n\n```\n")
+	fmt.Fprintf(&b, "npm install -g %s --registry=%s\n```\n", in.packageSpec, in.registry)
+	if in.goos == "windows" {
+		b.WriteString("\n提示没有权限时，右键「以管理员身份运行」PowerShell 后重新执行。\n")
+	} else {
+		b.WriteString("\n提示 EACCES 没有权限时，改为安装到用户目录：\n\n```\n")
+		b.WriteString("npm config set prefix ~/.npm-global\n")
+		b.WriteString(shellPathLine(in.shellConfig, "$HOME/.npm-global/bin") + "\n")
+		fmt.Fprintf(&b, "npm install -g %s --registry=%s\n```\n", in.packageSpec, in.registry)
+	}
+
+	section(fmt.Sprintf("配置 %s API", in.provider.Name))
+	writeManualEnvSteps(&b, in)
+
+	section("验证")
+	b.WriteString("重新打开终端后执行：\n\n```\nnode --version\ngit --version\nclaude --version\n```\n\n")
+	b.WriteString("三条命令都能输出版本号后，在项目目录中运行 `claude` 即可开始使用。\n")
+	return b.String()
+}
+
+// writeManualNodeSteps 写入当前平台安装 Node.js 的步骤
+func writeManualNodeSteps(b *strings.Builder, in manualGuideInput) {
+	if in.status.NodeVersion != "" {
+		fmt.Fprintf(b, "当前的 %s 版本过低，需要 v%d 或更高版本。\n\n", in.status.NodeVersion, effectiveMinNodeVersion(in.status.MinNodeVersion))
+	}
+	if len(in.nodeURLs) > 0 {
+		fmt.Fprintf(b, "下载 Node.js v%s 安装包（任选一个能打开的地址）：\n\n", in.nodeVersion)
+		for _, url := range in.nodeURLs {
+			fmt.Fprintf(b, "- %s\n", url)
+		}
+		b.WriteString("\n")
+	}
+
+	switch in.goos {
+	case "windows":
+		b.WriteString("双击下载的 .msi 文件，按提示完成安装（保持默认选项）。杀毒软件拦截时请选择允许。\n")
+	case "darwin":
+		b.WriteString("双击下载的 .pkg 文件，按提示完成安装。已安装 Homebrew 时也可以执行 `brew install node`。\n")
+	default:
+		name := path.Base(firstOrEmpty(in.nodeURLs))
+		b.WriteString("解压到 ~/.local/node 并加入 PATH：\n\n```\n")
+		b.WriteString("mkdir -p ~/.local/node\n")
+		fmt.Fprintf(b, "tar -xJf %s -C ~/.local/node --strip-components=1\n", name)
+		b.WriteString(shellPathLine(in.shellConfig, "$HOME/.local/node/bin") + "\n```\n")
+		b.WriteString("\n也可以使用系统包管理器安装，但发行版自带的版本可能过低。\n")
+	}
+}
+
+// writeManualGitSteps 写入当前平台安装 Git 的步骤
+func writeManualGitSteps(b *strings.Builder, in manualGuideInput) {
+	if in.status.GitVersion != "" {
+		fmt.Fprintf(b, "当前的 %s 版本过低，需要 %s 或更高版本。\n\n", in.status.GitVersion, effectiveMinGitVersion(in.status.MinGitVersion))
+	}
+
+	switch in.goos {
+	case "windows":
+		b.WriteString("下载 Git for Windows 安装包（任选一个能打开的地址）：\n\n")
+		for _, url := range in.gitURLs {
+			fmt.Fprintf(b, "- %s\n", url)
+		}
+		b.WriteString("\n双击下载的 .exe 文件，一路点击「Next」使用默认选项完成安装。\n")
+	case "darwin":
+		b.WriteString("在终端中执行以下命令，在弹出的窗口中点击「安装」（安装 Xcode 命令行工具，包含 Git）：\n\n```\nxcode-select --install\n```\n")
+		b.WriteString("\n已安装 Homebrew 时也可以执行 `brew install git`。\n")
+	default:
+		if len(in.gitCommands) == 0 {
+			b.WriteString("使用系统包管理器安装 git 软件包，例如 `sudo apt-get install -y git`。\n")
+			return
+		}
+		b.WriteString("在终端中执行：\n\n```\n")
+		for _, cmd := range in.gitCommands {
+			b.WriteString(cmd + "\n")
+		}
+		b.WriteString("```\n")
+	}
+}
+
+// writeManualEnvSteps 写入当前 shell 中设置 K2 环境变量的步骤
+func writeManualEnvSteps(b *strings.Builder, in manualGuideInput) {
+	key := manualGuideKeyPlaceholder
+	if in.keyHint != "" {
+		fmt.Fprintf(b, "把下面的 %s 替换为你的密钥（%s）。\n\n", key, in.keyHint)
+	} else {
+		fmt.Fprintf(b, "把下面的 %s 替换为你的密钥。\n\n", key)
+	}
+
+	if in.goos == "windows" {
+		b.WriteString("在 PowerShell 中执行（永久生效，执行后重新打开终端）：\n\n```\n")
+		fmt.Fprintf(b, "setx ANTHROPIC_BASE_URL \"%s\"\n", in.provider.BaseURL)
+		fmt.Fprintf(b, "setx %s \"%s\"\n", in.provider.EnvKeyName, key)
+		fmt.Fprintf(b, "setx CLAUDE_REQUEST_DELAY_MS \"%d\"\n", in.delay)
+		b.WriteString("setx CLAUDE_MAX_CONCURRENT_REQUESTS \"1\"\n")
+		fmt.Fprintf(b, "[Environment]::SetEnvironmentVariable('%s', $null, 'User')\n```\n", in.provider.ConflictingEnvKey())
+		return
+	}
+
+	shellConfig := in.shellConfig
+	if shellConfig == "" {
+		shellConfig = "~/.profile"
+	}
+	fmt.Fprintf(b, "把以下内容添加到 %s 的末尾：\n\n```\n", shellConfig)
+	b.WriteString(strings.TrimSpace(shellEnvBlock(shellConfig, in.provider, key, in.delay)) + "\n```\n")
+	fmt.Fprintf(b, "\n保存后执行 `source %s` 或重新打开终端。\n", shellConfig)
+}
+
+// shellPathLine 返回把目录加入 PATH 的配置命令，追加到 shell 配置文件后新开的终端也能生效
+func shellPathLine(shellConfig, dir string) string {
+	if shellConfig == "" {
+		shellConfig = "~/.profile"
+	}
+	if isFishConfig(shellConfig) {
+		return fmt.Sprintf("echo 'set -gx PATH %s $PATH' >> %s", dir, shellConfig)
+	}
+	return fmt.Sprintf("echo 'export PATH=\"%s:$PATH\"' >> %s", dir, shellConfig)
+}
+
+// manualGuideOSName 返回系统的显示名称
+func manualGuideOSName(goos string) string {
+	switch goos {
+	case "windows":
+		return "Windows"
+	case "darwin":
+		return "macOS"
+	case "linux":
+		return "Linux"
+	}
+	return goos
+}
+
+// firstOrEmpty 返回第一个元素，切片为空时返回空字符串
+func firstOrEmpty(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,67 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildManualGuide(t *testing.T) {
+	provider := DefaultProvider()
+	apiKey := "sk-test-key-0123456789"
+
+	windows := buildManualGuide(manualGuideInput{
+		goos:        "windows",
+		arch:        "arm64",
+		failedStep:  "安装 Git",
+		status:      EnvironmentStatus{NodeOK: true, NodeVersion: "v20.10.0"},
+		nodeVersion: DefaultNodeVersion,
+		gitURLs:     gitWindowsDownloadURLs(DefaultGitVersion, gitWindowsArtifact(DefaultGitVersion, "arm64")),
+		registry:    "https://registry.npmjs.org",
+		packageSpec: "@anthropic-ai/claude-code@latest",
+		provider:    provider,
+		keyHint:     maskKey(apiKey),
+		delay:       20000,
+	})
+	for _, want := range []string{
+		"自动安装失败的步骤: 安装 Git",
+		"✅ 已安装 v20.10.0",
+		"Git-" + DefaultGitVersion + "-arm64.exe",
+		"npm install -g @anthropic-ai/claude-code@latest --registry=https://registry.npmjs.org",
+		"setx ANTHROPIC_BASE_URL \"" + provider.BaseURL + "\"",
+		"setx " + provider.EnvKeyName + " \"" + manualGuideKeyPlaceholder + "\"",
+	} {
+		if !strings.Contains(windows, want) {
+			t.Errorf("Windows guide is missing %q:\n%s", want, windows)
+		}
+	}
+	if strings.Contains(windows, apiKey) {
+		t.Error("guide must not contain the real API key")
+	}
+
+	linux := buildManualGuide(manualGuideInput{
+		goos:        "linux",
+		arch:        "amd64",
+		status:      EnvironmentStatus{NodeVersion: "v16.20.0", MinNodeVersion: 18, GitOK: true, GitVersion: "git version 2.43.0"},
+		nodeVersion: DefaultNodeVersion,
+		nodeURLs:    nodeDownloadURLs(DefaultNodeVersion, "node-v"+DefaultNodeVersion+"-linux-x64.tar.xz"),
+		registry:    npmRegistryURL,
+		packageSpec: "@anthropic-ai/claude-code@latest",
+		shellConfig: "/home/user/.config/fish/config.fish",
+		provider:    provider,
+		delay:       20000,
+	})
+	for _, want := range []string{
+		"当前的 v16.20.0 版本过低，需要 v18",
+		"tar -xJf node-v" + DefaultNodeVersion + "-linux-x64.tar.xz",
+		"set -gx PATH $HOME/.local/node/bin $PATH",
+		"set -gx " + provider.EnvKeyName,
+		"source /home/user/.config/fish/config.fish",
+	} {
+		if !strings.Contains(linux, want) {
+			t.Errorf("Linux guide is missing %q:\n%s", want, linux)
+		}
+	}
+	if strings.Contains(linux, "export ANTHROPIC_BASE_URL") {
+		t.Error("fish guide should not use export syntax")
+	}
+}
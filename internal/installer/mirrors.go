@@ -0,0 +1,122 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ValidateMirrorURL 检查自定义镜像地址，须为 http 或 https 地址
+func ValidateMirrorURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("镜像地址格式不正确: %q，应为 https://npmmirror.com/mirrors/node 这样的地址", raw)
+	}
+	return nil
+}
+
+// ValidateProxyURL 检查代理地址，支持 http、https 和 socks5 代理
+func ValidateProxyURL(raw string) error {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("代理地址格式不正确: %q，应为 http://127.0.0.1:7890 这样的地址", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return nil
+	default:
+		return fmt.Errorf("不支持的代理协议: %s，支持 http、https 和 socks5", u.Scheme)
+	}
+}
+
+// nodeURLs 返回 Node.js 安装包的下载地址，设置了自定义镜像时排在内置镜像之前
+func (i *Installer) nodeURLs(version, artifact string) []string {
+	urls := nodeDownloadURLs(version, artifact)
+	if i.NodeMirror == "" {
+		return urls
+	}
+	custom := fmt.Sprintf("%s/v%s/%s", strings.TrimRight(strings.TrimSpace(i.NodeMirror), "/"), version, artifact)
+	return append([]string{custom}, urls...)
+}
+
+// npmRegistry 返回安装 Claude Code 使用的 npm 镜像，未设置时使用 npmRegistryURL
+func (i *Installer) npmRegistry() string {
+	if registry := strings.TrimRight(strings.TrimSpace(i.NPMRegistry), "/"); registry != "" {
+		return registry
+	}
+	return npmRegistryURL
+}
+
+// proxyFunc 返回 HTTP 请求使用的代理：设置了 Proxy 时固定使用该代理，否则读取代理环境变量
+func (i *Installer) proxyFunc() func(*http.Request) (*url.URL, error) {
+	if i.Proxy == "" {
+		return http.ProxyFromEnvironment
+	}
+	proxyURL, err := url.Parse(strings.TrimSpace(i.Proxy))
+	if err != nil {
+		return http.ProxyFromEnvironment
+	}
+	return http.ProxyURL(proxyURL)
+}
+
+// dialTimeout 建立单个 TCP 连接的超时时间
+const dialTimeout = 10 * time.Second
+
+// ipv6FallbackDelay 域名同时有 IPv6 和 IPv4 地址时，IPv6 连接多久没有建立就同时尝试 IPv4，
+// 部分运营商的 IPv6 路由不通，不必等到连接超时才改用 IPv4
+const ipv6FallbackDelay = 300 * time.Millisecond
+
+// ipv4FirstNodeOption 让 npm 优先使用 IPv4 地址的 Node.js 参数（Node.js 16.4 起支持）
+const ipv4FirstNodeOption = "--dns-result-order=ipv4first"
+
+// dialContext 返回建立 TCP 连接的函数：默认 IPv6 和 IPv4 并行尝试，先连上的生效；
+// ForceIPv4 时只连接 IPv4 地址
+func (i *Installer) dialContext() func(ctx context.Context, network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:       dialTimeout,
+		KeepAlive:     30 * time.Second,
+		FallbackDelay: ipv6FallbackDelay,
+	}
+	forceIPv4 := i.ForceIPv4
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if forceIPv4 && network == "tcp" {
+			network = "tcp4"
+		}
+		return dialer.DialContext(ctx, network, address)
+	}
+}
+
+// httpClient 返回探测和下载使用的 HTTP 客户端，请求经过 proxyFunc 选择的代理
+func (i *Installer) httpClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: &http.Transport{Proxy: i.proxyFunc(), DialContext: i.dialContext()}}
+}
+
+// applyIPv4Env 设置了 ForceIPv4 时让安装过程中启动的 npm 优先连接 IPv4 地址
+func (i *Installer) applyIPv4Env() {
+	if !i.ForceIPv4 {
+		return
+	}
+	options := os.Getenv("NODE_OPTIONS")
+	if !strings.Contains(options, "--dns-result-order") {
+		os.Setenv("NODE_OPTIONS", strings.TrimSpace(options+" "+ipv4FirstNodeOption))
+	}
+	i.addLog("🌐 已强制使用 IPv4 连接")
+}
+
+// applyProxyEnv 设置了 Proxy 时写入当前进程的代理环境变量，
+// 安装过程中启动的 npm、curl 和安装脚本都会继承
+func (i *Installer) applyProxyEnv() {
+	if i.Proxy == "" {
+		return
+	}
+	proxy := strings.TrimSpace(i.Proxy)
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		os.Setenv(name, proxy)
+	}
+	i.addLog(fmt.Sprintf("🌐 使用代理: %s", proxy))
+}
@@ -0,0 +1,140 @@
+package installer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// networkProbeTimeout 探测单个下载服务器的超时时间
+const networkProbeTimeout = 5 * time.Second
+
+// npmRegistryURL 安装 Claude Code 使用的 npm 镜像
+const npmRegistryURL = "https://registry.npmmirror.com"
+
+// npmFallbackRegistries 设置的 npm 镜像无法连接时依次尝试的备用镜像
+var npmFallbackRegistries = []string{npmRegistryURL, "https://registry.npmjs.org", "https://r.cnpmjs.org"}
+
+// npmFetchTimeoutMultiple npm 单个请求的超时时间为下载停滞判断时间的倍数，镜像不通时不会一直等待
+const npmFetchTimeoutMultiple = 2
+
+// mirrorOrigin 返回下载地址的协议和主机部分，如 https://nodejs.org
+func mirrorOrigin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// downloadServers 安装过程中会访问的下载服务器：Node.js 各镜像和 npm 镜像
+func (i *Installer) downloadServers() []string {
+	var servers []string
+	for _, u := range i.nodeURLs(i.nodeTargetVersion(), "") {
+		servers = append(servers, mirrorOrigin(u))
+	}
+	return append(servers, i.npmRegistry())
+}
+
+// probeServers 并发向各服务器发送 HEAD 请求，收到任何 HTTP 响应即视为可以连接
+func probeServers(client *http.Client, servers []string) map[string]bool {
+	reachable := make(map[string]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server string) {
+			defer wg.Done()
+			resp, err := client.Head(server)
+			if err != nil {
+				return
+			}
+			resp.Body.Close()
+			mu.Lock()
+			reachable[server] = true
+			mu.Unlock()
+		}(server)
+	}
+	wg.Wait()
+	return reachable
+}
+
+// checkNetwork 开始下载前快速检查下载服务器能否连接，全部无法连接时直接失败，
+// 避免在没有网络时等待每个下载超时；部分可连接时下载会优先使用可连接的镜像
+func (i *Installer) checkNetwork() error {
+	servers := i.downloadServers()
+	i.addLog("🌐 检查下载服务器连接...")
+	reachable := probeServers(i.httpClient(networkProbeTimeout), servers)
+
+	for _, server := range servers {
+		if reachable[server] {
+			i.addLog(fmt.Sprintf("   ✅ %s", server))
+		} else {
+			i.addLog(fmt.Sprintf("   ❌ %s 无法连接", server))
+		}
+	}
+
+	if len(reachable) == 0 {
+		err := fmt.Errorf("%w，请检查网络连接、代理或防火墙设置后重试", ErrNoNetwork)
+		if i.DryRun {
+			i.addLog(fmt.Sprintf("%s ⚠️ %v", dryRunPrefix, err))
+			return nil
+		}
+		return err
+	}
+	i.reachableMirrors = reachable
+	return nil
+}
+
+// npmRegistries 安装 Claude Code 时依次尝试的 npm 镜像：设置的镜像在前，备用镜像在后
+func (i *Installer) npmRegistries() []string {
+	registries := []string{i.npmRegistry()}
+	for _, registry := range npmFallbackRegistries {
+		if registry != registries[0] {
+			registries = append(registries, registry)
+		}
+	}
+	return registries
+}
+
+// reachableNpmRegistries 安装前检查各 npm 镜像能否连接，返回可以连接的镜像，顺序与 npmRegistries 相同；
+// 全部无法连接时返回 ErrNpmRegistryUnreachable，不必等 npm 超时。离线安装时不检查
+func (i *Installer) reachableNpmRegistries() ([]string, error) {
+	candidates := i.npmRegistries()
+	if i.OfflineDir != "" {
+		return candidates[:1], nil
+	}
+
+	reachable := probeServers(i.httpClient(networkProbeTimeout), candidates)
+	var usable []string
+	for _, registry := range candidates {
+		if reachable[registry] {
+			usable = append(usable, registry)
+		} else {
+			i.addLog(fmt.Sprintf("   ❌ npm 镜像 %s 无法连接", registry))
+		}
+	}
+	if len(usable) == 0 {
+		return nil, fmt.Errorf("%w: %s 均无法连接，请检查网络、代理或防火墙设置", ErrNpmRegistryUnreachable, strings.Join(candidates, "、"))
+	}
+	if usable[0] != candidates[0] {
+		i.addLog(fmt.Sprintf("⚠️ npm 镜像 %s 无法连接，改用 %s", candidates[0], usable[0]))
+	}
+	return usable, nil
+}
+
+// orderMirrors 把可以连接的镜像排在前面，其余保持原有顺序；未做网络检查时原样返回
+func (i *Installer) orderMirrors(urls []string) []string {
+	if len(i.reachableMirrors) == 0 {
+		return urls
+	}
+	ordered := append([]string(nil), urls...)
+	sort.SliceStable(ordered, func(a, b int) bool {
+		return i.reachableMirrors[mirrorOrigin(ordered[a])] && !i.reachableMirrors[mirrorOrigin(ordered[b])]
+	})
+	return ordered
+}
@@ -0,0 +1,287 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultMinNodeVersion 默认要求的最低 Node.js 主版本，与当前 Claude Code 的要求一致
+const DefaultMinNodeVersion = 18
+
+// minNodeVersion 返回要求的最低 Node.js 主版本，未设置时使用默认值
+func (i *Installer) minNodeVersion() int {
+	return effectiveMinNodeVersion(i.MinNodeVersion)
+}
+
+// effectiveMinNodeVersion minMajor 不大于 0 时使用 DefaultMinNodeVersion
+func effectiveMinNodeVersion(minMajor int) int {
+	if minMajor <= 0 {
+		return DefaultMinNodeVersion
+	}
+	return minMajor
+}
+
+// nodeVersionPattern 匹配一个完整的版本号：可选的 v 前缀、三段数字、可选的 -nightly 等预发布后缀
+// 和 +build 构建后缀。版本号前后只能是空白或字符串边界，兼容 "node v20.10.0" 这样带前缀的输出
+var nodeVersionPattern = regexp.MustCompile(`(?:^|\s)v?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.\-]+))?(?:\+[0-9A-Za-z.\-]+)?(?:\s|$)`)
+
+// parseNodeVersion 解析 node --version 输出或版本目录名中的版本号，
+// 接受 v20.10.0、20.10.0、v20.10.0-nightly2023、node v20.10.0 等形式，忽略首尾空白
+func parseNodeVersion(version string) (major, minor, patch int, err error) {
+	match := nodeVersionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return 0, 0, 0, fmt.Errorf("无法识别的 Node.js 版本: %q", version)
+	}
+
+	nums := make([]int, 3)
+	for idx, part := range match[1:4] {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("无法识别的 Node.js 版本: %q", version)
+		}
+		nums[idx] = n
+	}
+	return nums[0], nums[1], nums[2], nil
+}
+
+// nodePrerelease 返回版本号的预发布后缀（如 nightly2023、rc.1），正式版本返回空字符串
+func nodePrerelease(version string) string {
+	if match := nodeVersionPattern.FindStringSubmatch(strings.TrimSpace(version)); match != nil {
+		return match[4]
+	}
+	return ""
+}
+
+// compareNodeVersions 比较两个版本号，a < b 返回 -1，相等返回 0，a > b 返回 1；无法解析的版本视为最小
+// 数字相同时预发布版本小于正式版本，两个预发布后缀按字符串比较
+func compareNodeVersions(a, b string) int {
+	aMajor, aMinor, aPatch, aErr := parseNodeVersion(a)
+	bMajor, bMinor, bPatch, bErr := parseNodeVersion(b)
+	switch {
+	case aErr != nil && bErr != nil:
+		return 0
+	case aErr != nil:
+		return -1
+	case bErr != nil:
+		return 1
+	}
+
+	for _, pair := range [][2]int{{aMajor, bMajor}, {aMinor, bMinor}, {aPatch, bPatch}} {
+		if pair[0] < pair[1] {
+			return -1
+		}
+		if pair[0] > pair[1] {
+			return 1
+		}
+	}
+
+	aPre, bPre := nodePrerelease(a), nodePrerelease(b)
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return strings.Compare(aPre, bPre)
+}
+
+// managedNode 由版本管理器（nvm/fnm/volta）安装的 Node.js
+type managedNode struct {
+	Manager string
+	Version string
+	BinDir  string // node 可执行文件所在目录
+}
+
+// nodeExecutable 当前平台的 node 可执行文件名
+func nodeExecutable() string {
+	if runtime.GOOS == "windows" {
+		return "node.exe"
+	}
+	return "node"
+}
+
+// findManagedNodes 枚举 nvm、fnm、volta 已安装的 Node.js 版本
+func findManagedNodes() []managedNode {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	// 各版本管理器的版本目录，{version} 之后是 node 所在的子目录
+	type layout struct {
+		manager string
+		dir     string
+		binSub  string
+	}
+	var layouts []layout
+
+	// nvm: $NVM_DIR/versions/node/v20.10.0/bin/node
+	nvmDir := os.Getenv("NVM_DIR")
+	if nvmDir == "" {
+		nvmDir = filepath.Join(home, ".nvm")
+	}
+	layouts = append(layouts, layout{"nvm", filepath.Join(nvmDir, "versions", "node"), "bin"})
+	// nvm-windows: %NVM_HOME%\v20.10.0\node.exe
+	if nvmHome := os.Getenv("NVM_HOME"); nvmHome != "" {
+		layouts = append(layouts, layout{"nvm", nvmHome, ""})
+	}
+
+	// fnm: $FNM_DIR/node-versions/v20.10.0/installation/bin/node
+	fnmDirs := []string{os.Getenv("FNM_DIR"), filepath.Join(home, ".fnm")}
+	switch runtime.GOOS {
+	case "darwin":
+		fnmDirs = append(fnmDirs, filepath.Join(home, "Library", "Application Support", "fnm"))
+	case "windows":
+		fnmDirs = append(fnmDirs, filepath.Join(os.Getenv("APPDATA"), "fnm"))
+	default:
+		fnmDirs = append(fnmDirs, filepath.Join(home, ".local", "share", "fnm"))
+	}
+	fnmBin := filepath.Join("installation", "bin")
+	if runtime.GOOS == "windows" {
+		fnmBin = "installation"
+	}
+	for _, dir := range fnmDirs {
+		if dir != "" {
+			layouts = append(layouts, layout{"fnm", filepath.Join(dir, "node-versions"), fnmBin})
+		}
+	}
+
+	// volta: $VOLTA_HOME/tools/image/node/20.10.0/bin/node
+	voltaHome := os.Getenv("VOLTA_HOME")
+	if voltaHome == "" {
+		voltaHome = filepath.Join(home, ".volta")
+	}
+	voltaBin := "bin"
+	if runtime.GOOS == "windows" {
+		voltaBin = ""
+	}
+	layouts = append(layouts, layout{"volta", filepath.Join(voltaHome, "tools", "image", "node"), voltaBin})
+
+	var nodes []managedNode
+	seen := make(map[string]bool)
+	for _, l := range layouts {
+		entries, err := os.ReadDir(l.dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			if _, _, _, err := parseNodeVersion(entry.Name()); err != nil {
+				continue
+			}
+
+			binDir := filepath.Join(l.dir, entry.Name(), l.binSub)
+			if seen[binDir] {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(binDir, nodeExecutable())); err != nil {
+				continue
+			}
+			seen[binDir] = true
+			nodes = append(nodes, managedNode{Manager: l.manager, Version: entry.Name(), BinDir: binDir})
+		}
+	}
+	return nodes
+}
+
+// useManagedNode 选用版本管理器中满足要求的最高版本 Node.js，加入当前进程的 PATH
+func (i *Installer) useManagedNode() bool {
+	var candidates []managedNode
+	for _, node := range findManagedNodes() {
+		if major, _, _, err := parseNodeVersion(node.Version); err == nil && major >= i.minNodeVersion() {
+			candidates = append(candidates, node)
+		}
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return compareNodeVersions(candidates[a].Version, candidates[b].Version) > 0
+	})
+	selected := candidates[0]
+
+	os.Setenv("PATH", selected.BinDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	i.addLog(fmt.Sprintf("✅ 使用 %s 管理的 Node.js %s: %s", selected.Manager, selected.Version, selected.BinDir))
+	i.addLog(fmt.Sprintf("已将 %s 添加到 PATH 环境变量，跳过安装 Node.js", selected.BinDir))
+	return true
+}
+
+// DefaultNodeVersion 需要安装 Node.js 时默认安装的版本
+const DefaultNodeVersion = "20.10.0"
+
+// nodeTargetVersionPattern 可安装的版本号格式，不接受预发布版本
+var nodeTargetVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// ValidateNodeTargetVersion 检查要安装的 Node.js 版本，接受 20.10.0 或 v20.10.0，
+// 版本不能低于 minMajor（不大于 0 时使用 DefaultMinNodeVersion）
+func ValidateNodeTargetVersion(version string, minMajor int) error {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if !nodeTargetVersionPattern.MatchString(version) {
+		return fmt.Errorf("Node.js 版本格式不正确: %q，应为 20.10.0 这样的格式", version)
+	}
+	minMajor = effectiveMinNodeVersion(minMajor)
+	if major, _, _, _ := parseNodeVersion(version); major < minMajor {
+		return fmt.Errorf("Node.js 版本过低: %s，需要 v%d 或更高版本", version, minMajor)
+	}
+	return nil
+}
+
+// ValidateMinNodeVersion 检查设置的最低 Node.js 主版本，0 表示使用默认值
+func ValidateMinNodeVersion(minMajor int) error {
+	if minMajor < 0 || minMajor > 99 {
+		return fmt.Errorf("最低 Node.js 版本不正确: %d，应为 18、20 这样的主版本号", minMajor)
+	}
+	return nil
+}
+
+// nodeTargetVersion 返回规范化后的目标版本（不带 v 前缀），未设置时使用默认版本
+func (i *Installer) nodeTargetVersion() string {
+	version := strings.TrimPrefix(strings.TrimSpace(i.NodeVersion), "v")
+	if version == "" {
+		return DefaultNodeVersion
+	}
+	return version
+}
+
+// nodeArtifactName 返回指定平台的 Node.js 安装包文件名
+func nodeArtifactName(version, goos, goarch string) (string, error) {
+	switch goos {
+	case "windows":
+		arch := map[string]string{"amd64": "x64", "arm64": "arm64", "386": "x86"}[goarch]
+		if arch == "" {
+			return "", fmt.Errorf("不支持的 Windows 架构: %s", goarch)
+		}
+		return fmt.Sprintf("node-v%s-%s.msi", version, arch), nil
+	case "darwin":
+		// macOS 的 .pkg 是同时支持 Intel 和 Apple Silicon 的通用安装包
+		return fmt.Sprintf("node-v%s.pkg", version), nil
+	case "linux":
+		arch := map[string]string{"amd64": "x64", "arm64": "arm64", "arm": "armv7l", "ppc64le": "ppc64le", "s390x": "s390x"}[goarch]
+		if arch == "" {
+			return "", fmt.Errorf("不支持的 Linux 架构: %s", goarch)
+		}
+		return fmt.Sprintf("node-v%s-linux-%s.tar.xz", version, arch), nil
+	default:
+		return "", fmt.Errorf("不支持的操作系统: %s", goos)
+	}
+}
+
+// nodeDownloadURLs 返回安装包的下载地址，国内镜像优先
+func nodeDownloadURLs(version, artifact string) []string {
+	return []string{
+		fmt.Sprintf("https://mirrors.aliyun.com/nodejs-release/v%s/%s", version, artifact),
+		fmt.Sprintf("https://cdn.npmmirror.com/binaries/node/v%s/%s", version, artifact),
+		fmt.Sprintf("https://nodejs.org/dist/v%s/%s", version, artifact),
+	}
+}
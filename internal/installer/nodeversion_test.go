@@ -0,0 +1,116 @@
+package installer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseNodeVersion(t *testing.T) {
+	tests := []struct {
+		input               string
+		major, minor, patch int
+		wantErr             bool
+	}{
+		{"v16.0.0", 16, 0, 0, false},
+		{"v20.10.0", 20, 10, 0, false},
+		{"20.10.0", 20, 10, 0, false},
+		{"  v20.10.0\r\n", 20, 10, 0, false},
+		{"v18.0.0-pre", 18, 0, 0, false},
+		{"v20.10.0-nightly20231010abcdef", 20, 10, 0, false},
+		{"v21.0.0-rc.1+build.5", 21, 0, 0, false},
+		{"node v20.10.0", 20, 10, 0, false},
+		{"", 0, 0, 0, true},
+		{"garbage", 0, 0, 0, true},
+		{"v20.10", 0, 0, 0, true},
+		{"v20.10.0.1", 0, 0, 0, true},
+		{"vx.y.z", 0, 0, 0, true},
+		{"command not found: node", 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		major, minor, patch, err := parseNodeVersion(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseNodeVersion(%q) = %d.%d.%d, want error", tt.input, major, minor, patch)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseNodeVersion(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if major != tt.major || minor != tt.minor || patch != tt.patch {
+			t.Errorf("parseNodeVersion(%q) = %d.%d.%d, want %d.%d.%d", tt.input, major, minor, patch, tt.major, tt.minor, tt.patch)
+		}
+	}
+}
+
+func TestCompareNodeVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v20.10.0", "v20.10.0", 0},
+		{"v20.10.0", "20.10.0", 0},
+		{"v20.9.0", "v20.10.0", -1},
+		{"v22.0.0", "v20.10.0", 1},
+		{"v20.10.0-nightly", "v20.10.0", -1},
+		{"v20.10.0", "v20.10.0-rc.1", 1},
+		{"v20.10.0-rc.1", "v20.10.0-rc.2", -1},
+		{"v20.10.0+build.1", "v20.10.0", 0},
+		{"garbage", "v16.0.0", -1},
+		{"garbage", "nonsense", 0},
+	}
+	for _, tt := range tests {
+		if got := compareNodeVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareNodeVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestValidateNodeVersion(t *testing.T) {
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	for _, version := range []string{"v18.0.0", "v20.10.0", "v20.10.0-nightly20231010", "node v20.10.0"} {
+		if err := i.validateNodeVersion(version); err != nil {
+			t.Errorf("validateNodeVersion(%q) = %v, want nil", version, err)
+		}
+	}
+	for _, version := range []string{"v16.0.0", "v17.9.1", "v15.0.0-pre", "garbage", ""} {
+		if err := i.validateNodeVersion(version); err == nil {
+			t.Errorf("validateNodeVersion(%q) should fail", version)
+		}
+	}
+}
+
+func TestMinNodeVersion(t *testing.T) {
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	if i.MinNodeVersion != DefaultMinNodeVersion {
+		t.Errorf("MinNodeVersion defaults to %d, want %d", i.MinNodeVersion, DefaultMinNodeVersion)
+	}
+
+	i.MinNodeVersion = 20
+	err := i.validateNodeVersion("v18.19.0")
+	if !errors.Is(err, ErrNodeTooOld) {
+		t.Fatalf("validateNodeVersion(v18.19.0) with minimum 20 = %v, want ErrNodeTooOld", err)
+	}
+	if !strings.Contains(err.Error(), "v20") || !strings.Contains(err.Error(), "v18.19.0") {
+		t.Errorf("error should report the installed and required versions: %v", err)
+	}
+
+	i.MinNodeVersion = 0
+	if err := i.validateNodeVersion("v17.0.0"); !errors.Is(err, ErrNodeTooOld) {
+		t.Errorf("MinNodeVersion 0 should fall back to v%d, got %v", DefaultMinNodeVersion, err)
+	}
+
+	if err := ValidateNodeTargetVersion("18.19.0", 20); err == nil {
+		t.Error("target version below the minimum should be rejected")
+	}
+	if err := ValidateNodeTargetVersion("v20.10.0", 0); err != nil {
+		t.Errorf("default target version should satisfy the default minimum: %v", err)
+	}
+	if err := ValidateMinNodeVersion(-1); err == nil {
+		t.Error("negative minimum should be rejected")
+	}
+}
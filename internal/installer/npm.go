@@ -0,0 +1,306 @@
+package installer
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// npmExecutable 当前平台的 npm 可执行文件名
+func npmExecutable() string {
+	if runtime.GOOS == "windows" {
+		return "npm.cmd"
+	}
+	return "npm"
+}
+
+// checkNPM 确认 npm 可用，PATH 中找不到时在 node 所在目录查找
+func (i *Installer) checkNPM() error {
+	if output, err := exec.Command("npm", "--version").Output(); err == nil {
+		i.addLog(fmt.Sprintf("检测到 npm: %s", strings.TrimSpace(string(output))))
+		return nil
+	}
+
+	// 模拟运行时 Node.js 尚未真正安装，npm 会随 Node.js 一起安装
+	if i.DryRun {
+		for _, component := range i.plannedComponents {
+			if component == "Node.js" {
+				i.addLog(fmt.Sprintf("%s npm 将随 Node.js 一起安装", dryRunPrefix))
+				return nil
+			}
+		}
+	}
+
+	i.addLog("⚠️ PATH 中未找到 npm，正在 Node.js 所在目录查找...")
+
+	nodePath, err := exec.LookPath("node")
+	if err != nil {
+		return fmt.Errorf("npm 未找到，请重新安装 Node.js")
+	}
+
+	// Homebrew 等安装方式的 node 是符号链接，npm 可能在真实路径旁边
+	dirs := []string{filepath.Dir(nodePath)}
+	if resolved, err := filepath.EvalSymlinks(nodePath); err == nil && filepath.Dir(resolved) != dirs[0] {
+		dirs = append(dirs, filepath.Dir(resolved))
+	}
+
+	for _, dir := range dirs {
+		npmPath := filepath.Join(dir, npmExecutable())
+		if _, err := os.Stat(npmPath); err != nil {
+			continue
+		}
+
+		output, err := exec.Command(npmPath, "--version").Output()
+		if err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 找到 %s 但无法运行: %v", npmPath, err))
+			continue
+		}
+
+		os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+		i.addLog(fmt.Sprintf("✅ 找到 npm %s: %s", strings.TrimSpace(string(output)), npmPath))
+		i.addLog(fmt.Sprintf("已将 %s 添加到 PATH 环境变量", dir))
+		return nil
+	}
+
+	return fmt.Errorf("npm 未找到，请重新安装 Node.js")
+}
+
+// NPMStrategy npm 全局目录不可写时的处理方式
+type NPMStrategy string
+
+const (
+	NPMStrategyUserPrefix NPMStrategy = "user-prefix" // 改用用户目录下的 ~/.npm-global（默认）
+	NPMStrategySudo       NPMStrategy = "sudo"        // 使用 sudo 安装（仅 Linux）
+)
+
+// npmUserPrefixMarker 写入 shell 配置的 npm 全局目录 PATH 标记，恢复配置时据此清理
+const npmUserPrefixMarker = "# Claude Code K2 npm global PATH"
+
+// npmGlobalPrefix 返回 npm 全局安装前缀
+func npmGlobalPrefix() (string, error) {
+	output, err := exec.Command("npm", "config", "get", "prefix").Output()
+	if err != nil {
+		return "", fmt.Errorf("获取 npm 全局目录失败: %v", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// npmGlobalDirs 返回前缀对应的全局模块目录和可执行文件目录
+func npmGlobalDirs(prefix string) (modulesDir, binDir string) {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(prefix, "node_modules"), prefix
+	}
+	return filepath.Join(prefix, "lib", "node_modules"), filepath.Join(prefix, "bin")
+}
+
+// dirWritable 检查目录是否可写，目录不存在时检查最近的已存在上级目录
+func dirWritable(dir string) bool {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".k2-write-test-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// npmGlobalCommand 构造 npm 全局安装命令
+// 全局目录不可写（常见于 root 所有的 /usr/local）时，按策略改用用户目录或 sudo，避免 EACCES
+func (i *Installer) npmGlobalCommand(args ...string) (*exec.Cmd, error) {
+	prefix, err := npmGlobalPrefix()
+	if err != nil {
+		return nil, err
+	}
+
+	modulesDir, _ := npmGlobalDirs(prefix)
+	if dirWritable(modulesDir) {
+		i.addLog(fmt.Sprintf("npm 全局目录: %s", prefix))
+		return exec.Command("npm", args...), nil
+	}
+
+	i.addLog(fmt.Sprintf("⚠️ npm 全局目录 %s 不可写，直接安装会出现 EACCES 权限错误", prefix))
+
+	if i.NPMStrategy == NPMStrategySudo && runtime.GOOS == "linux" {
+		i.addLog("使用 sudo 安装到 npm 全局目录")
+		return exec.Command("sudo", append([]string{"npm"}, args...)...), nil
+	}
+
+	if err := i.useNPMUserPrefix(); err != nil {
+		return nil, err
+	}
+	return exec.Command("npm", args...), nil
+}
+
+// npmCommandError 按 npm 全局命令的失败原因包装错误：sudo 无法取得权限为 ErrNeedsElevation，
+// logStart 之后的输出中有权限错误为 ErrNpmPermission
+func (i *Installer) npmCommandError(action string, err error, logStart int) error {
+	if commandOutputContains(err, sudoFailureKeywords...) {
+		return fmt.Errorf("%s: %w: %v", action, ErrNeedsElevation, err)
+	}
+	if linesContain(i.logsSince(logStart), permissionDeniedKeywords...) {
+		return fmt.Errorf("%s: %w: %v", action, ErrNpmPermission, err)
+	}
+	return fmt.Errorf("%s: %v", action, err)
+}
+
+// useNPMUserPrefix 将 npm 全局前缀设为 ~/.npm-global，并把其 bin 目录加入 PATH 和 shell 配置
+func (i *Installer) useNPMUserPrefix() error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("获取用户目录失败: %v", err)
+	}
+
+	prefix := filepath.Join(home, ".npm-global")
+	if err := os.MkdirAll(prefix, 0755); err != nil {
+		return fmt.Errorf("创建 %s 失败: %v", prefix, err)
+	}
+
+	if output, err := exec.Command("npm", "config", "set", "prefix", prefix).CombinedOutput(); err != nil {
+		return fmt.Errorf("设置 npm 全局目录失败: %v\n%s", err, string(output))
+	}
+	i.addLog(fmt.Sprintf("✅ 已将 npm 全局目录设为 %s（npm config set prefix）", prefix))
+
+	// 加入当前进程的 PATH，安装后的 claude --version 验证无需重启终端
+	_, binDir := npmGlobalDirs(prefix)
+	os.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	i.addLog(fmt.Sprintf("已将 %s 添加到 PATH 环境变量", binDir))
+
+	// Windows 上 npm 默认前缀在用户目录，一般不会走到这里，只处理 shell 配置
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	for _, shellConfig := range shellConfigFiles(home) {
+		line := `export PATH="$HOME/.npm-global/bin:$PATH"`
+		if strings.HasSuffix(shellConfig, "config.fish") {
+			line = "set -gx PATH $HOME/.npm-global/bin $PATH"
+		}
+
+		if data, err := os.ReadFile(shellConfig); err == nil && strings.Contains(string(data), npmUserPrefixMarker) {
+			continue
+		}
+		if err := appendMarkedLine(shellConfig, npmUserPrefixMarker, line); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 写入 %s 失败: %v", shellConfig, err))
+			i.addLog(fmt.Sprintf("请手动将以下内容加入 shell 配置文件: %s", line))
+			continue
+		}
+		i.addLog(fmt.Sprintf("✅ 已将 %s 添加到 %s", binDir, shellConfig))
+	}
+	return nil
+}
+
+// claudeCodePackage Claude Code 的 npm 包名
+const claudeCodePackage = "@anthropic-ai/claude-code"
+
+// DefaultClaudeCodeVersion 默认安装的 Claude Code 版本
+const DefaultClaudeCodeVersion = "latest"
+
+// claudeCodeVersionPattern 可安装的版本：具体版本号（如 1.0.51）或 dist-tag（如 latest、stable）
+var claudeCodeVersionPattern = regexp.MustCompile(`^(\d+\.\d+\.\d+(-[0-9A-Za-z.\-]+)?|[a-z][a-z0-9\-]*)$`)
+
+// claudeVersionOutputPattern 从 claude --version 的输出中提取版本号
+var claudeVersionOutputPattern = regexp.MustCompile(`\d+\.\d+\.\d+(-[0-9A-Za-z.\-]+)?`)
+
+// ValidateClaudeCodeVersion 检查要安装的 Claude Code 版本格式
+func ValidateClaudeCodeVersion(version string) error {
+	if !claudeCodeVersionPattern.MatchString(strings.TrimSpace(version)) {
+		return fmt.Errorf("Claude Code 版本格式不正确: %q，应为 1.0.51 这样的版本号或 latest", version)
+	}
+	return nil
+}
+
+// claudeCodeTargetVersion 返回要安装的版本，未设置时为 latest
+func (i *Installer) claudeCodeTargetVersion() string {
+	version := strings.TrimSpace(i.ClaudeCodeVersion)
+	if version == "" {
+		return DefaultClaudeCodeVersion
+	}
+	// 允许 v1.0.51 的写法，npm 需要不带 v 的版本号
+	if len(version) > 1 && version[0] == 'v' && version[1] >= '0' && version[1] <= '9' {
+		version = version[1:]
+	}
+	return version
+}
+
+// claudeCodePackageSpec 返回带版本的 npm 包名，如 @anthropic-ai/claude-code@1.0.51
+func (i *Installer) claudeCodePackageSpec() string {
+	return claudeCodePackage + "@" + i.claudeCodeTargetVersion()
+}
+
+// verifyClaudeCodeVersion 运行 claude --version 确认安装结果，版本与要求不一致时记录警告
+func (i *Installer) verifyClaudeCodeVersion() error {
+	// npm 全局 bin 目录不在 PATH 中时，安装成功也找不到 claude
+	i.ensureClaudeOnPath()
+
+	output, err := exec.Command("claude", "--version").Output()
+	if err != nil {
+		return fmt.Errorf("Claude Code 安装验证失败: %v", err)
+	}
+	i.addLog(fmt.Sprintf("Claude Code 安装成功: %s", strings.TrimSpace(string(output))))
+
+	requested := i.claudeCodeTargetVersion()
+	if !claudeVersionOutputPattern.MatchString(requested) {
+		// dist-tag（如 latest）无法直接比较
+		return nil
+	}
+
+	installed := claudeVersionOutputPattern.FindString(string(output))
+	if installed != requested {
+		i.addLog(fmt.Sprintf("⚠️ 已安装的 Claude Code 版本 %s 与要求的版本 %s 不一致，PATH 中可能存在其他安装", installed, requested))
+	}
+	return nil
+}
+
+// npmProgressTracker 根据 npm --loglevel=http 的输出估算安装进度
+// 粗略分为三个阶段：解析依赖（获取包信息）、下载（获取 .tgz）、安装（执行安装脚本）
+type npmProgressTracker struct {
+	i        *Installer
+	mu       sync.Mutex
+	fraction float64 // 当前步骤内的完成比例 0~1
+}
+
+// advance 将进度推进到 target，只前进不后退
+func (t *npmProgressTracker) advance(target float64, message string) {
+	if target <= t.fraction {
+		return
+	}
+	t.fraction = target
+	t.i.sendStepProgress(t.fraction, message)
+}
+
+// handleLine 处理一行 npm 输出
+func (t *npmProgressTracker) handleLine(line string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lower := strings.ToLower(line)
+	switch {
+	case strings.HasPrefix(lower, "added ") || strings.HasPrefix(lower, "changed ") || strings.HasPrefix(lower, "up to date"):
+		t.advance(1.0, "Claude Code 安装完成")
+	case strings.Contains(lower, "postinstall") || strings.Contains(lower, "install script"):
+		t.advance(0.9, "正在安装 Claude Code（执行安装脚本）...")
+	case strings.Contains(lower, "http fetch") && strings.Contains(lower, ".tgz"):
+		// 下载阶段：0.3 ~ 0.85，每个包推进一点
+		t.advance(math.Min(math.Max(t.fraction, 0.3)+0.05, 0.85), "正在安装 Claude Code（下载）...")
+	case strings.Contains(lower, "http fetch"):
+		// 解析依赖阶段：0.05 ~ 0.3
+		t.advance(math.Min(math.Max(t.fraction, 0.05)+0.03, 0.3), "正在安装 Claude Code（解析依赖）...")
+	}
+}
@@ -0,0 +1,147 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 离线安装目录（Installer.OfflineDir）中需要预先放好的文件：
+//
+//	node-v<版本>-x64.msi / node-v<版本>-arm64.msi   Windows 的 Node.js 安装包
+//	node-v<版本>.pkg                                macOS 的 Node.js 安装包
+//	node-v<版本>-linux-<架构>.tar.xz                 Linux 的 Node.js 二进制包
+//	Git-<版本>-64-bit.exe / Git-<版本>-arm64.exe     Windows 的 Git 安装包，版本为 GitVersion
+//	anthropic-ai-claude-code-<版本>.tgz             npm pack @anthropic-ai/claude-code 的输出
+//	SHASUMS256.txt                                  可选，sha256sum 格式的校验文件
+//
+// 文件名与官方下载地址中的文件名一致，直接把下载的文件放进目录即可。
+// 目录中有 SHASUMS256.txt 时，其中列出的文件在安装前会校验 SHA-256。
+
+// OfflineChecksumFile 离线安装目录中的校验文件名，格式与 sha256sum 的输出相同
+const OfflineChecksumFile = "SHASUMS256.txt"
+
+// claudeCodeTarballPrefix npm pack 生成的 Claude Code 安装包文件名前缀
+const claudeCodeTarballPrefix = "anthropic-ai-claude-code-"
+
+// parseChecksums 解析 sha256sum 格式的校验文件，返回文件名到小写哈希的映射
+// 每行为 "<哈希>  <文件名>"，二进制模式的文件名带 * 前缀
+func parseChecksums(data string) map[string]string {
+	sums := make(map[string]string)
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// fileSHA256 计算文件的 SHA-256
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyOfflineChecksum 校验文件与 SHASUMS256.txt 中的哈希是否一致，没有校验文件或没有对应条目时跳过
+func (i *Installer) verifyOfflineChecksum(path string) error {
+	name := filepath.Base(path)
+	data, err := os.ReadFile(filepath.Join(i.OfflineDir, OfflineChecksumFile))
+	if err != nil {
+		i.addLog(fmt.Sprintf("ℹ️ 未提供 %s，跳过 %s 的校验", OfflineChecksumFile, name))
+		return nil
+	}
+	expected, ok := parseChecksums(string(data))[name]
+	if !ok {
+		i.addLog(fmt.Sprintf("⚠️ %s 中没有 %s 的校验值，跳过校验", OfflineChecksumFile, name))
+		return nil
+	}
+
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return fmt.Errorf("计算 %s 的校验值失败: %v", name, err)
+	}
+	if actual != expected {
+		return fmt.Errorf("%s 校验失败: SHA-256 为 %s，应为 %s，文件可能已损坏", name, actual, expected)
+	}
+	i.addLog(fmt.Sprintf("✅ %s 校验通过", name))
+	return nil
+}
+
+// offlineArtifact 返回离线安装目录中的安装包路径并校验，未设置离线目录时返回空字符串
+// 设置了离线目录但缺少该文件时返回错误，不会回退到网络下载
+func (i *Installer) offlineArtifact(name string) (string, error) {
+	if i.OfflineDir == "" {
+		return "", nil
+	}
+	path := filepath.Join(i.OfflineDir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("离线安装目录 %s 中缺少 %s", i.OfflineDir, name)
+	}
+	i.addLog(fmt.Sprintf("📦 使用离线安装包: %s", path))
+	if err := i.verifyOfflineChecksum(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// hasOfflineArtifact 离线安装目录中是否有指定文件
+func (i *Installer) hasOfflineArtifact(name string) bool {
+	if i.OfflineDir == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(i.OfflineDir, name))
+	return err == nil
+}
+
+// findClaudeCodeTarball 在目录中查找 Claude Code 安装包：指定了版本时只接受该版本，
+// 否则有多个时选择版本最高的
+func findClaudeCodeTarball(dir, version string) (string, error) {
+	if claudeVersionOutputPattern.MatchString(version) {
+		name := claudeCodeTarballPrefix + version + ".tgz"
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return "", fmt.Errorf("离线安装目录 %s 中缺少 %s", dir, name)
+		}
+		return name, nil
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, claudeCodeTarballPrefix+"*.tgz"))
+	best, bestVersion := "", ""
+	for _, match := range matches {
+		name := filepath.Base(match)
+		v := strings.TrimSuffix(strings.TrimPrefix(name, claudeCodeTarballPrefix), ".tgz")
+		if best == "" || compareNodeVersions(v, bestVersion) > 0 {
+			best, bestVersion = name, v
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("离线安装目录 %s 中缺少 %s<版本>.tgz，请用 npm pack %s 生成", dir, claudeCodeTarballPrefix, claudeCodePackage)
+	}
+	return best, nil
+}
+
+// claudeCodeInstallSource 返回 npm install -g 的安装源：离线安装时为本地 .tgz 路径，否则为带版本的包名
+func (i *Installer) claudeCodeInstallSource() (string, error) {
+	if i.OfflineDir == "" {
+		return i.claudeCodePackageSpec(), nil
+	}
+	name, err := findClaudeCodeTarball(i.OfflineDir, i.claudeCodeTargetVersion())
+	if err != nil {
+		return "", err
+	}
+	return i.offlineArtifact(name)
+}
@@ -0,0 +1,155 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// macOS 上需要管理员权限的操作分两种方式执行，都只在真正需要提权的那一步弹出密码框：
+//
+//   - 单条命令（如 installer -pkg）：runWithAdmin 通过 osascript 的
+//     "with administrator privileges" 执行，下载和验证等其余步骤仍以普通用户身份流式执行；
+//   - 内部自行调用 sudo 的长脚本（如 Homebrew 安装脚本）：以普通用户身份流式执行，
+//     通过 SUDO_ASKPASS 指向 writeMacAskpass 生成的辅助程序，sudo 需要密码时才弹出系统密码框。
+//     Homebrew 不允许以 root 身份安装，这也是它官方支持的无终端提权方式。
+//
+// 两种方式都在 AppleScript 中捕获错误 -128（用户点击取消），不依赖系统语言的错误文字，
+// 用户取消时统一返回 ErrUserCancelled。
+
+// adminCancelledMarker 用户在密码框中点击取消时 AppleScript 输出的标记
+const adminCancelledMarker = "K2_USER_CANCELLED"
+
+// askpassCancelledFile 用户在 SUDO_ASKPASS 密码框中点击取消时，辅助程序在其所在目录创建的标记文件
+const askpassCancelledFile = "cancelled"
+
+// macAskpassScript SUDO_ASKPASS 辅助程序：用 osascript 弹出密码框，把输入的密码写到标准输出交给 sudo
+// 密码不会写入文件或日志；用户点击取消时创建标记文件并以非零状态退出，sudo 随之失败
+const macAskpassScript = `#!/bin/bash
+ANSWER=$(/usr/bin/osascript <<'EOF'
+try
+	set answer to display dialog "Claude Code K2 安装器需要管理员权限才能继续，请输入登录密码：" default answer "" with hidden answer with title "需要管理员权限" with icon caution
+	return text returned of answer
+on error number -128
+	return "` + adminCancelledMarker + `"
+end try
+EOF
+)
+if [ $? -ne 0 ] || [ "$ANSWER" = "` + adminCancelledMarker + `" ]; then
+	touch "$(dirname "$0")/` + askpassCancelledFile + `"
+	exit 1
+fi
+printf '%s\n' "$ANSWER"
+`
+
+// macAskpass 写入临时目录的 SUDO_ASKPASS 辅助程序
+type macAskpass struct {
+	dir  string
+	Path string
+}
+
+// writeMacAskpass 写入 SUDO_ASKPASS 辅助程序，用完后调用 cleanup 删除
+func writeMacAskpass() (*macAskpass, error) {
+	dir, err := os.MkdirTemp("", "claude-k2-askpass-")
+	if err != nil {
+		return nil, fmt.Errorf("创建密码输入辅助程序失败: %v", err)
+	}
+	askpass := &macAskpass{dir: dir, Path: filepath.Join(dir, "askpass.sh")}
+	if err := os.WriteFile(askpass.Path, []byte(macAskpassScript), 0700); err != nil {
+		askpass.cleanup()
+		return nil, fmt.Errorf("创建密码输入辅助程序失败: %v", err)
+	}
+	return askpass, nil
+}
+
+// cancelled 用户是否在辅助程序弹出的密码框中点击了取消
+func (a *macAskpass) cancelled() bool {
+	_, err := os.Stat(filepath.Join(a.dir, askpassCancelledFile))
+	return err == nil
+}
+
+// cleanup 删除辅助程序及其临时目录
+func (a *macAskpass) cleanup() {
+	os.RemoveAll(a.dir)
+}
+
+// adminScript 以管理员权限执行 shellCmd 的 AppleScript，用户取消时输出 adminCancelledMarker 而不是报错
+func adminScript(shellCmd string) string {
+	return fmt.Sprintf(`try
+	do shell script "%s" with administrator privileges
+on error errMsg number errNum
+	if errNum is -128 then return "%s"
+	error errMsg number errNum
+end try`, appleScriptString(shellCmd), adminCancelledMarker)
+}
+
+// isUserCanceled 判断 osascript 的结果是否因为用户在密码框中点击了取消：
+// 优先识别 adminScript 输出的标记，其次是错误信息中的 -128
+func isUserCanceled(err error, output string) bool {
+	text := output
+	if err != nil {
+		text += err.Error()
+	}
+	return strings.Contains(text, adminCancelledMarker) ||
+		strings.Contains(text, "User canceled") || strings.Contains(text, "(-128)")
+}
+
+// runWithAdmin 以管理员权限执行一条 shell 命令，系统此时弹出密码框；用户取消时返回 ErrUserCancelled
+// osascript 只能在结束后返回输出，执行期间每隔几秒记录一次等待提示，避免看起来卡住
+func (i *Installer) runWithAdmin(shellCmd, action string) error {
+	i.addLog(fmt.Sprintf("🔐 %s需要管理员权限，系统将弹出密码输入框", action))
+	cmd := exec.Command("osascript", "-e", adminScript(shellCmd))
+
+	done := make(chan error, 1)
+	go func() {
+		output, err := cmd.CombinedOutput()
+		for _, line := range strings.Split(string(output), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && line != adminCancelledMarker && !strings.Contains(line, "installer:") {
+				i.addLog(line)
+			}
+		}
+		switch {
+		case isUserCanceled(err, string(output)):
+			i.addLog("🚫 用户取消了密码输入")
+			done <- ErrUserCancelled
+		case err == nil:
+			done <- nil
+		default:
+			done <- fmt.Errorf("%s失败: %v", action, err)
+		}
+	}()
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for waited := 1; ; waited++ {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			switch {
+			case waited <= 1:
+				i.addLog("🔐 等待用户输入密码...")
+			case waited <= 10:
+				i.addLog(fmt.Sprintf("📦 正在%s，请稍候...", action))
+			case waited <= 20:
+				i.addLog("⏳ 仍在进行中...")
+			default:
+				i.addLog("⏱️  时间较长，请耐心等待...")
+			}
+		}
+	}
+}
+
+// ShellQuote 用单引号包裹 POSIX shell 参数，并转义其中的单引号
+func ShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// appleScriptString 转义放进 AppleScript 双引号字符串的内容
+func appleScriptString(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
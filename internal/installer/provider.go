@@ -0,0 +1,166 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Provider Anthropic 兼容接口的服务商
+type Provider struct {
+	Name       string // 显示名称
+	BaseURL    string // Anthropic 兼容接口地址
+	EnvKeyName string // 保存 API Key 的环境变量名
+	DefaultRPM int    // 默认速率限制（每分钟请求数）
+	KeyPrefix  string // API Key 的固定前缀，为空时不检查前缀
+
+	// RPMTiers 服务商公布的速率限制档位，按 RPM 从低到高排列，第一个为免费档位；为空表示未公布
+	RPMTiers []RPMTier
+}
+
+// RPMTier 按账户充值额度划分的速率限制档位
+type RPMTier struct {
+	Label string // 档位名称，如 ¥50
+	RPM   int    // 该档位允许的每分钟请求数
+}
+
+// CustomProviderName 自定义网关的名称，BaseURL 由用户填写
+const CustomProviderName = "自定义网关"
+
+// BuiltinProviders 内置服务商列表，第一个为默认服务商
+var BuiltinProviders = []Provider{
+	{
+		Name:       "Kimi K2 (月之暗面)",
+		BaseURL:    "https://api.moonshot.cn/anthropic/",
+		EnvKeyName: "ANTHROPIC_API_KEY",
+		DefaultRPM: 3,
+		KeyPrefix:  "sk-",
+		RPMTiers: []RPMTier{
+			{"免费", 3},
+			{"¥50", 200},
+			{"¥100", 500},
+			{"¥500", 5000},
+		},
+	},
+	{
+		Name:       "DeepSeek",
+		BaseURL:    "https://api.deepseek.com/anthropic",
+		EnvKeyName: "ANTHROPIC_AUTH_TOKEN",
+		DefaultRPM: 60,
+		KeyPrefix:  "sk-",
+	},
+	{
+		Name:       "智谱 GLM",
+		BaseURL:    "https://open.bigmodel.cn/api/anthropic",
+		EnvKeyName: "ANTHROPIC_AUTH_TOKEN",
+		DefaultRPM: 30,
+	},
+}
+
+// DefaultProvider 返回默认服务商 Kimi K2
+func DefaultProvider() Provider {
+	return BuiltinProviders[0]
+}
+
+// FindProvider 按名称查找内置服务商
+func FindProvider(name string) (Provider, bool) {
+	for _, p := range BuiltinProviders {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Provider{}, false
+}
+
+// NewCustomProvider 创建指向自建 Anthropic 兼容网关的服务商
+func NewCustomProvider(baseURL string) (Provider, error) {
+	baseURL = strings.TrimSpace(baseURL)
+	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
+		return Provider{}, fmt.Errorf("网关地址必须以 http:// 或 https:// 开头")
+	}
+
+	return Provider{
+		Name:       CustomProviderName,
+		BaseURL:    baseURL,
+		EnvKeyName: "ANTHROPIC_API_KEY",
+		DefaultRPM: 60,
+	}, nil
+}
+
+// minAPIKeyLength 各服务商 API Key 的最短长度，更短的通常是只复制了一部分
+const minAPIKeyLength = 20
+
+// NormalizeAPIKey 去掉粘贴时带上的首尾空白和引号
+func NormalizeAPIKey(apiKey string) string {
+	apiKey = strings.TrimSpace(apiKey)
+	for len(apiKey) >= 2 {
+		first, last := apiKey[0], apiKey[len(apiKey)-1]
+		if (first == '"' && last == '"') || (first == '\'' && last == '\'') || (first == '`' && last == '`') {
+			apiKey = strings.TrimSpace(apiKey[1 : len(apiKey)-1])
+			continue
+		}
+		break
+	}
+	return apiKey
+}
+
+// CheckAPIKeyFormat 检查 API Key 格式，返回的错误只作提醒，调用方可让用户确认后继续
+func (p Provider) CheckAPIKeyFormat(apiKey string) error {
+	if strings.ContainsAny(apiKey, " \t\r\n") {
+		return fmt.Errorf("密钥中包含空格或换行，可能复制了多余的内容")
+	}
+	if p.KeyPrefix != "" && !strings.HasPrefix(apiKey, p.KeyPrefix) {
+		return fmt.Errorf("%s 的密钥通常以 %s 开头，可能粘贴了其他服务商的密钥", p.Name, p.KeyPrefix)
+	}
+	if len(apiKey) < minAPIKeyLength {
+		return fmt.Errorf("密钥只有 %d 个字符，可能没有复制完整", len(apiKey))
+	}
+	return nil
+}
+
+// RequiredTier 返回达到 rpm 所需的最低档位，rpm 超过最高档位时返回最高档位；未公布档位时 ok 为 false
+func (p Provider) RequiredTier(rpm int) (tier RPMTier, ok bool) {
+	if len(p.RPMTiers) == 0 {
+		return RPMTier{}, false
+	}
+	for _, tier := range p.RPMTiers {
+		if rpm <= tier.RPM {
+			return tier, true
+		}
+	}
+	return p.RPMTiers[len(p.RPMTiers)-1], true
+}
+
+// FreeRPM 返回免费档位的 RPM，未公布档位时 ok 为 false
+func (p Provider) FreeRPM() (rpm int, ok bool) {
+	if len(p.RPMTiers) == 0 {
+		return 0, false
+	}
+	return p.RPMTiers[0].RPM, true
+}
+
+// RequestDelay 返回按 rpm 计算的请求间隔（毫秒），与写入 CLAUDE_REQUEST_DELAY_MS 的值一致
+// rpm 无法解析或超出范围时 note 说明实际使用的值
+func (p Provider) RequestDelay(rpm string) (delay int, note string) {
+	return requestDelayMs(rpm, p.DefaultRPM)
+}
+
+// ConflictingEnvKey 返回需要清除的另一个认证变量，避免认证冲突
+func (p Provider) ConflictingEnvKey() string {
+	if p.EnvKeyName == "ANTHROPIC_AUTH_TOKEN" {
+		return "ANTHROPIC_API_KEY"
+	}
+	return "ANTHROPIC_AUTH_TOKEN"
+}
+
+// isProviderBaseURL 判断地址是否是本工具写入的服务商地址
+func isProviderBaseURL(url string, current Provider) bool {
+	if url == current.BaseURL {
+		return true
+	}
+	for _, p := range BuiltinProviders {
+		if url == p.BaseURL {
+			return true
+		}
+	}
+	return false
+}
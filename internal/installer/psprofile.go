@@ -0,0 +1,101 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// powerShellProfiles 返回 Windows PowerShell 和 PowerShell 7 的当前用户配置文件（$PROFILE）
+// 优先询问 PowerShell 本身，文档目录被重定向（如 OneDrive）时也能找到正确位置
+func powerShellProfiles(home string) []string {
+	var profiles []string
+	for _, shell := range []struct {
+		exe, dir string
+	}{
+		{"powershell", "WindowsPowerShell"},
+		{"pwsh", "PowerShell"},
+	} {
+		if _, err := exec.LookPath(shell.exe); err != nil {
+			continue
+		}
+
+		output, err := exec.Command(shell.exe, "-NoProfile", "-NonInteractive", "-Command", "$PROFILE.CurrentUserCurrentHost").Output()
+		profile := strings.TrimSpace(string(output))
+		if err != nil || profile == "" {
+			profile = filepath.Join(home, "Documents", shell.dir, "Microsoft.PowerShell_profile.ps1")
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles
+}
+
+// powerShellQuote 用单引号包裹 PowerShell 字符串，内部单引号写两次
+func powerShellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// powerShellEnvBlock 生成写入 $PROFILE 的 K2 环境变量配置块
+func powerShellEnvBlock(provider Provider, apiKey string, requestDelay int) string {
+	return fmt.Sprintf(`%s
+$env:ANTHROPIC_BASE_URL = %s
+$env:%s = %s
+$env:CLAUDE_REQUEST_DELAY_MS = '%d'
+$env:CLAUDE_MAX_CONCURRENT_REQUESTS = '1'
+Remove-Item Env:\%s -ErrorAction SilentlyContinue
+%s
+`, k2BlockStart, powerShellQuote(provider.BaseURL), provider.EnvKeyName, powerShellQuote(apiKey),
+		requestDelay, provider.ConflictingEnvKey(), k2BlockEnd)
+}
+
+// writePowerShellProfiles 把 K2 环境变量写入 PowerShell 配置文件，新开的 PowerShell 窗口立即生效
+// 已有配置块时替换，保证更换密钥或服务商后配置是最新的
+func (i *Installer) writePowerShellProfiles(home string, provider Provider, apiKey string, requestDelay int) {
+	block := powerShellEnvBlock(provider, apiKey, requestDelay)
+
+	for _, profile := range powerShellProfiles(home) {
+		data, err := os.ReadFile(profile)
+		if err != nil && !os.IsNotExist(err) {
+			i.addLog(fmt.Sprintf("⚠️ 读取 %s 失败: %v", profile, err))
+			continue
+		}
+
+		content, _ := stripShellEnvBlock(string(data))
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += "\n" + block
+
+		if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 创建 %s 失败: %v", filepath.Dir(profile), err))
+			continue
+		}
+		if err := os.WriteFile(profile, []byte(content), 0644); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 写入 %s 失败: %v", profile, err))
+			continue
+		}
+		i.addLog(fmt.Sprintf("✅ 环境变量已写入 PowerShell 配置: %s", profile))
+	}
+}
+
+// removePowerShellProfileBlocks 从 PowerShell 配置文件中删除本工具写入的配置块
+func (i *Installer) removePowerShellProfileBlocks(home string) {
+	for _, profile := range powerShellProfiles(home) {
+		data, err := os.ReadFile(profile)
+		if err != nil {
+			continue
+		}
+
+		content, changed := stripShellEnvBlock(string(data))
+		if !changed {
+			continue
+		}
+		if err := os.WriteFile(profile, []byte(content), 0644); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 清理 %s 失败: %v", profile, err))
+		} else {
+			i.addLog(fmt.Sprintf("✅ 已清理 PowerShell 配置: %s", profile))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"claude-k2-installer/internal/version"
+)
+
+// DiagnosticReport 生成用于排查问题的安装报告：环境信息在前，完整日志在后
+//
+// 会执行 node、git 等命令获取版本，耗时可能数秒，不要在 UI 主线程中调用。
+func (i *Installer) DiagnosticReport() string {
+	var b strings.Builder
+
+	i.writeReportHeader(&b)
+	fmt.Fprintf(&b, "日志策略: %s\n", i.LogPolicy().Describe())
+
+	b.WriteString("\n---- 已检测到的命令 ----\n")
+	for _, name := range snapshotCommands {
+		version := commandVersion(name)
+		if version == "" {
+			version = "未找到"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, version)
+	}
+
+	b.WriteString("\n---- PATH ----\n")
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		b.WriteString(maskSecrets(dir) + "\n")
+	}
+
+	b.WriteString("\n---- 安装日志 ----\n")
+	for _, line := range i.GetLogs() {
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+// writeReportHeader 写入日志头部：导出时间、安装器版本、系统和目标版本，粘贴出去的日志也能看出来源
+func (i *Installer) writeReportHeader(b *strings.Builder) {
+	b.WriteString("==== Claude Code + K2 安装日志 ====\n")
+	fmt.Fprintf(b, "导出时间: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(b, "安装器版本: %s\n", version.String())
+	fmt.Fprintf(b, "系统: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(b, "目标版本: Node.js %s, Claude Code %s\n", i.NodeVersion, i.ClaudeCodeVersion)
+}
+
+// LogsWithHeader 返回带头部信息的完整日志，不执行任何命令，可在 UI 主线程中调用
+func (i *Installer) LogsWithHeader() string {
+	var b strings.Builder
+	i.writeReportHeader(&b)
+	b.WriteString("\n")
+	for _, line := range i.GetLogs() {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,213 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// homebrewShellenvMarker 写入 shell 配置的 Homebrew shellenv 标记，恢复配置时据此清理
+const homebrewShellenvMarker = "# Claude Code K2 Homebrew shellenv"
+
+// shellProfileFiles 用户可能使用的 shell 配置文件
+func shellProfileFiles(home string) []string {
+	return []string{
+		filepath.Join(home, ".zprofile"),
+		filepath.Join(home, ".zshrc"),
+		filepath.Join(home, ".bash_profile"),
+		filepath.Join(home, ".bashrc"),
+		filepath.Join(home, ".profile"),
+		filepath.Join(home, ".config/fish/config.fish"),
+	}
+}
+
+// homebrewShellenvConfigured 检查用户的 shell 配置中是否已加载 brew shellenv
+func homebrewShellenvConfigured(home string) bool {
+	for _, path := range shellProfileFiles(home) {
+		if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), "brew shellenv") {
+			return true
+		}
+	}
+	return false
+}
+
+// appendMarkedLine 在 shell 配置文件末尾追加一行带标记的配置，文件不存在时创建
+func appendMarkedLine(profile, marker, line string) error {
+	if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(profile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(fmt.Sprintf("\n%s\n%s\n", marker, line))
+	return err
+}
+
+// ensureHomebrewShellenv 确保新开的终端也能找到 brew 及通过 brew 安装的 node
+// installHomebrewCN 只修改了当前进程的 PATH，这里把 brew shellenv 写入用户的 shell 配置
+func (i *Installer) ensureHomebrewShellenv() {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+
+	if homebrewShellenvConfigured(home) {
+		i.addLog("✅ shell 配置中已加载 Homebrew 环境 (brew shellenv)")
+		return
+	}
+
+	prefix := getHomebrewPrefix()
+	brewPath := filepath.Join(prefix, "bin", "brew")
+	if prefix == "" {
+		return
+	}
+	if _, err := os.Stat(brewPath); err != nil {
+		return
+	}
+
+	// 按 Homebrew 官方建议选择配置文件
+	shell := os.Getenv("SHELL")
+	var profile, line string
+	switch {
+	case strings.Contains(shell, "fish"):
+		profile = filepath.Join(home, ".config/fish/config.fish")
+		line = fmt.Sprintf("eval (%s shellenv)", brewPath)
+	case strings.Contains(shell, "bash"):
+		profile = filepath.Join(home, ".bash_profile")
+		line = fmt.Sprintf(`eval "$(%s shellenv)"`, brewPath)
+	default:
+		// macOS 默认 shell 为 zsh
+		profile = filepath.Join(home, ".zprofile")
+		line = fmt.Sprintf(`eval "$(%s shellenv)"`, brewPath)
+	}
+
+	i.addLog("⚠️ 未在 shell 配置中找到 brew shellenv，新开终端将找不到 brew 和 node")
+
+	if err := appendMarkedLine(profile, homebrewShellenvMarker, line); err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 写入 %s 失败: %v", profile, err))
+		return
+	}
+	i.addLog(fmt.Sprintf("✅ 已将 Homebrew 环境写入 %s", profile))
+}
+
+// removeMarkedLines 从 shell 配置文件中删除本工具写入的标记行及紧随其后的配置行
+func (i *Installer) removeMarkedLines(home, marker, label string) {
+	for _, path := range shellProfileFiles(home) {
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(data), marker) {
+			continue
+		}
+
+		lines := strings.Split(string(data), "\n")
+		var newLines []string
+		for idx := 0; idx < len(lines); idx++ {
+			if strings.TrimSpace(lines[idx]) == marker {
+				// 跳过标记行和紧随其后的配置行
+				if idx+1 < len(lines) && strings.TrimSpace(lines[idx+1]) != "" {
+					idx++
+				}
+				continue
+			}
+			newLines = append(newLines, lines[idx])
+		}
+
+		if err := os.WriteFile(path, []byte(strings.Join(newLines, "\n")), 0644); err != nil {
+			i.addLog(fmt.Sprintf("⚠️ 清理 %s 中的%s失败: %v", path, label, err))
+		} else {
+			i.addLog(fmt.Sprintf("✅ 已清理 %s 中的%s", path, label))
+		}
+	}
+}
+
+// K2 环境变量块的标记，配置块以 START 行开始、END 行结束，恢复配置时删除两者之间的全部内容
+// 旧版本只写了 k2ConfigMarker 一行开始标记，恢复时仍按环境变量行识别
+const (
+	k2ConfigMarker = "# Claude Code K2 Configuration"
+	k2BlockStart   = k2ConfigMarker + " START"
+	k2BlockEnd     = k2ConfigMarker + " END"
+)
+
+// isFishConfig 判断配置文件是否为 fish 的配置，fish 不支持 export 语法
+func isFishConfig(path string) bool {
+	return filepath.Base(path) == "config.fish"
+}
+
+// fishQuote 用单引号包裹 fish 字符串，转义反斜杠和单引号
+func fishQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+// shellEnvBlock 生成写入 shell 配置文件的 K2 环境变量块，fish 使用 set -gx 语法
+func shellEnvBlock(shellConfig string, provider Provider, apiKey string, requestDelay int) string {
+	if isFishConfig(shellConfig) {
+		return fmt.Sprintf(`
+%s
+set -gx ANTHROPIC_BASE_URL %s
+set -gx %s %s
+set -gx CLAUDE_REQUEST_DELAY_MS "%d"
+set -gx CLAUDE_MAX_CONCURRENT_REQUESTS "1"
+set -e %s
+%s
+`, k2BlockStart, fishQuote(provider.BaseURL), provider.EnvKeyName, fishQuote(apiKey), requestDelay, provider.ConflictingEnvKey(), k2BlockEnd)
+	}
+
+	return fmt.Sprintf(`
+%s
+export ANTHROPIC_BASE_URL="%s"
+export %s="%s"
+export CLAUDE_REQUEST_DELAY_MS="%d"
+export CLAUDE_MAX_CONCURRENT_REQUESTS="1"
+unset %s
+%s
+`, k2BlockStart, provider.BaseURL, provider.EnvKeyName, apiKey, requestDelay, provider.ConflictingEnvKey(), k2BlockEnd)
+}
+
+// isLegacyEnvLine 判断是否为旧版本 K2 环境变量块中的一行，兼容 bash/zsh 和 fish 语法
+func isLegacyEnvLine(line string) bool {
+	for _, prefix := range []string{"export ", "unset ", "set -gx ", "set -e "} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			return strings.HasPrefix(rest, "ANTHROPIC_") || strings.HasPrefix(rest, "CLAUDE_")
+		}
+	}
+	return false
+}
+
+// stripShellEnvBlock 删除本工具写入的 K2 环境变量块及其前面的空行，返回新内容和是否有改动
+//
+// START 和 END 之间（含两行标记）的内容全部删除，其余内容原样保留；
+// 旧版本没有 END 标记的块只删除紧随标记的环境变量行。
+func stripShellEnvBlock(content string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	var newLines []string
+	inBlock, inLegacy, changed := false, false, false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			if trimmed == k2BlockEnd {
+				inBlock = false
+			}
+			continue
+		case trimmed == k2BlockStart || trimmed == k2ConfigMarker:
+			inBlock = trimmed == k2BlockStart
+			inLegacy = !inBlock
+			changed = true
+			// 去掉写入时在配置块前加的空行
+			if n := len(newLines); n > 0 && strings.TrimSpace(newLines[n-1]) == "" {
+				newLines = newLines[:n-1]
+			}
+			continue
+		case inLegacy && isLegacyEnvLine(trimmed):
+			continue
+		}
+		inLegacy = false
+		newLines = append(newLines, line)
+	}
+	return strings.Join(newLines, "\n"), changed
+}
@@ -0,0 +1,328 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFishConfigUsesFishSyntax(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fish config is only written on macOS/Linux")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/usr/bin/fish")
+
+	fishConfig := filepath.Join(home, ".config", "fish", "config.fish")
+	original := "set -gx EDITOR vim\n"
+	if err := os.MkdirAll(filepath.Dir(fishConfig), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fishConfig, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	if err := i.configureK2APIWithOptions(DefaultProvider(), `sk-test'key\value`, "3", true); err != nil {
+		t.Fatalf("configureK2APIWithOptions: %v", err)
+	}
+
+	data, err := os.ReadFile(fishConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := string(data)
+	if !strings.Contains(config, k2ConfigMarker) {
+		t.Fatalf("K2 block not written to config.fish:\n%s", config)
+	}
+	for _, line := range strings.Split(config, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, "set ") {
+			t.Errorf("non-fish line in config.fish: %q", line)
+		}
+	}
+	if !strings.Contains(config, `set -gx ANTHROPIC_API_KEY 'sk-test\'key\\value'`) {
+		t.Errorf("API key not escaped for fish single quotes:\n%s", config)
+	}
+
+	if fish, err := exec.LookPath("fish"); err == nil {
+		if output, err := exec.Command(fish, "--no-execute", fishConfig).CombinedOutput(); err != nil {
+			t.Errorf("fish rejected the generated config: %v\n%s", err, output)
+		}
+	}
+
+	if err := i.RestoreOriginalClaudeConfig(); err != nil {
+		t.Fatalf("RestoreOriginalClaudeConfig: %v", err)
+	}
+	data, err = os.ReadFile(fishConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != strings.TrimSpace(original) {
+		t.Errorf("config.fish after restore = %q, want %q", got, original)
+	}
+}
+
+func TestStripShellEnvBlockKeepsUserContentAfterBlock(t *testing.T) {
+	block := shellEnvBlock("/home/dev/.bashrc", DefaultProvider(), "sk-test-key", 20000)
+	before := "alias ll='ls -l'\n"
+	after := "export ANTHROPIC_MODEL=kimi-k2\nexport PATH=$HOME/bin:$PATH\n"
+
+	got, changed := stripShellEnvBlock(before + block + after)
+	if !changed {
+		t.Fatal("block not detected")
+	}
+	if want := before + after; got != want {
+		t.Errorf("stripShellEnvBlock() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestStripShellEnvBlockRemovesEveryBlock(t *testing.T) {
+	block := shellEnvBlock("/home/dev/.config/fish/config.fish", DefaultProvider(), "sk-test-key", 20000)
+	content := "set -gx EDITOR vim\n" + block + block + "set -gx ANTHROPIC_MODEL kimi-k2\n"
+
+	got, _ := stripShellEnvBlock(content)
+	if want := "set -gx EDITOR vim\nset -gx ANTHROPIC_MODEL kimi-k2\n"; got != want {
+		t.Errorf("stripShellEnvBlock() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestStripShellEnvBlockLegacyBlock(t *testing.T) {
+	// 旧版本写入的块没有 END 标记
+	content := "alias ll='ls -l'\n\n" + k2ConfigMarker + "\n" +
+		"export ANTHROPIC_BASE_URL=\"https://api.moonshot.cn/anthropic/\"\n" +
+		"export ANTHROPIC_API_KEY=\"sk-test-key\"\n" +
+		"unset ANTHROPIC_AUTH_TOKEN\n" +
+		"eval \"$(starship init bash)\"\n"
+
+	got, changed := stripShellEnvBlock(content)
+	if !changed {
+		t.Fatal("legacy block not detected")
+	}
+	if want := "alias ll='ls -l'\neval \"$(starship init bash)\"\n"; got != want {
+		t.Errorf("stripShellEnvBlock() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestRepeatedConfigureKeepsSingleBlock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell rc files are only written on macOS/Linux")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/zsh")
+
+	zshrc := filepath.Join(home, ".zshrc")
+	original := "alias ll='ls -l'\n"
+	if err := os.WriteFile(zshrc, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	for _, key := range []string{"sk-first-key-0123456789", "sk-second-key-0123456789", "sk-third-key-0123456789"} {
+		if err := i.configureK2APIWithOptions(DefaultProvider(), key, "3", true); err != nil {
+			t.Fatalf("configureK2APIWithOptions(%s): %v", key, err)
+		}
+	}
+
+	data, err := os.ReadFile(zshrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := string(data)
+	if n := strings.Count(config, k2BlockStart); n != 1 {
+		t.Fatalf("found %d K2 blocks, want 1:\n%s", n, config)
+	}
+	if !strings.Contains(config, "sk-third-key-0123456789") || strings.Contains(config, "sk-first-key") {
+		t.Errorf("block does not hold the latest key:\n%s", config)
+	}
+	if !strings.HasPrefix(config, original) {
+		t.Errorf("user content changed:\n%s", config)
+	}
+}
+
+func TestPlanShellConfigChangesMatchesWrite(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell rc files are only written on macOS/Linux")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/zsh")
+
+	zshrc := filepath.Join(home, ".zshrc")
+	if err := os.WriteFile(zshrc, []byte("alias ll='ls -l'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const apiKey = "sk-preview-key-0123456789"
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	changes, err := i.PlanShellConfigChanges(DefaultProvider(), apiKey, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != zshrc {
+		t.Fatalf("changes = %+v, want one change to %s", changes, zshrc)
+	}
+	if data, _ := os.ReadFile(zshrc); string(data) != "alias ll='ls -l'\n" {
+		t.Fatalf("planning modified the file:\n%s", data)
+	}
+
+	diff := changes[0].Diff()
+	if strings.Contains(diff, apiKey) || !strings.Contains(diff, "+ "+k2BlockStart) {
+		t.Errorf("diff should list the added block with the key masked:\n%s", diff)
+	}
+
+	if err := i.configureK2APIWithOptions(DefaultProvider(), apiKey, "3", true); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(zshrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != changes[0].After {
+		t.Errorf("written content differs from the preview:\n%s\nwant:\n%s", data, changes[0].After)
+	}
+}
+
+func TestShellFileConflictsSkipsK2Block(t *testing.T) {
+	provider := DefaultProvider()
+	path := filepath.Join(t.TempDir(), ".zshrc")
+	content := "export ANTHROPIC_BASE_URL=https://api.anthropic.com\n" +
+		"# export ANTHROPIC_MODEL=old\n" +
+		shellEnvBlock(path, provider, "sk-k2-secret-key", 20000) +
+		"ANTHROPIC_AUTH_TOKEN='sk-official-token'\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts := shellFileConflicts(path)
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts outside the K2 block, got %+v", conflicts)
+	}
+	if conflicts[0].Name != "ANTHROPIC_BASE_URL" || conflicts[0].Line != 1 {
+		t.Errorf("unexpected first conflict: %+v", conflicts[0])
+	}
+	if conflicts[1].Name != "ANTHROPIC_AUTH_TOKEN" || strings.Contains(conflicts[1].Value, "official-token") {
+		t.Errorf("token conflict should be masked: %+v", conflicts[1])
+	}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	if err := i.ResolveEnvConflicts(conflicts); err != nil {
+		t.Fatalf("ResolveEnvConflicts: %v", err)
+	}
+	if remaining := shellFileConflicts(path); len(remaining) != 0 {
+		t.Errorf("expected no conflicts after cleanup, got %+v", remaining)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), envConflictComment+"export ANTHROPIC_BASE_URL=https://api.anthropic.com") {
+		t.Errorf("conflicting line should be commented out, got:\n%s", data)
+	}
+}
+
+func TestEnvironConflicts(t *testing.T) {
+	provider := DefaultProvider()
+	environ := []string{
+		"PATH=/usr/bin",
+		"ANTHROPIC_BASE_URL=" + provider.BaseURL,
+		provider.EnvKeyName + "=sk-old",
+		provider.ConflictingEnvKey() + "=sk-official",
+		"ANTHROPIC_MODEL=claude-opus",
+	}
+	conflicts := environConflicts(environ, provider)
+	var names []string
+	for _, conflict := range conflicts {
+		names = append(names, conflict.Name)
+	}
+	want := provider.ConflictingEnvKey() + ",ANTHROPIC_MODEL"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("expected conflicts %s, got %s", want, got)
+	}
+}
+
+func TestFindClaudeBinDir(t *testing.T) {
+	empty, withClaude := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(withClaude, claudeExecutable()), []byte(""), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// 与可执行文件同名的目录不算
+	if err := os.Mkdir(filepath.Join(empty, claudeExecutable()), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if dir := findClaudeBinDir([]string{"", empty, withClaude}); dir != withClaude {
+		t.Errorf("findClaudeBinDir = %q, want %q", dir, withClaude)
+	}
+	if dir := findClaudeBinDir([]string{empty}); dir != "" {
+		t.Errorf("findClaudeBinDir = %q, want empty", dir)
+	}
+}
+
+func TestRemoveK2Config(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell config files are not used on Windows")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	useTempSetupScript(t)
+	provider := DefaultProvider()
+	t.Setenv(provider.EnvKeyName, "sk-old")
+
+	rc := shellConfigFiles(home)[0]
+	before := "alias ll='ls -l'\n"
+	if err := os.WriteFile(rc, []byte(before+shellEnvBlock(rc, provider, "sk-old", 2000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(SetupScriptPath(), []byte("export "+provider.EnvKeyName+"=\"sk-old\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.removeK2Config(provider)
+
+	data, _ := os.ReadFile(rc)
+	if strings.Contains(string(data), "sk-old") || !strings.Contains(string(data), "alias ll") {
+		t.Errorf("old K2 block should be removed and other lines kept, got:\n%s", data)
+	}
+	if _, ok := os.LookupEnv(provider.EnvKeyName); ok {
+		t.Errorf("%s should be unset in the current process", provider.EnvKeyName)
+	}
+	if _, err := os.Stat(SetupScriptPath()); !os.IsNotExist(err) {
+		t.Errorf("temporary setup script should be removed, got %v", err)
+	}
+}
+
+func TestMergeWindowsPath(t *testing.T) {
+	system := `C:\Windows\system32;C:\Windows;C:\Program Files\nodejs\`
+	user := `C:\Users\me\AppData\Roaming\npm;c:\windows\System32`
+	current := `C:\Windows\system32;C:\Windows;C:\Users\me\bin;;C:\Program Files\nodejs`
+
+	got := mergeWindowsPath(system, user, current)
+	want := `C:\Windows\system32;C:\Windows;C:\Program Files\nodejs\;C:\Users\me\AppData\Roaming\npm;C:\Users\me\bin`
+	if got != want {
+		t.Errorf("mergeWindowsPath() = %q, want %q", got, want)
+	}
+}
+
+func TestExpandWindowsEnv(t *testing.T) {
+	t.Setenv("K2_TEST_ROOT", `C:\Windows`)
+	got := expandWindowsEnv(`%K2_TEST_ROOT%\system32;%K2_TEST_UNDEFINED%\bin`)
+	if want := `C:\Windows\system32;%K2_TEST_UNDEFINED%\bin`; got != want {
+		t.Errorf("expandWindowsEnv() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// ShellConfigChange 永久设置环境变量时对一个 shell 配置文件的修改
+//
+// 预览和实际写入使用同一份计划，确认对话框中展示的内容就是将写入的内容。
+type ShellConfigChange struct {
+	Path     string
+	Created  bool   // 文件不存在，将新建
+	Replaced bool   // 文件中已有 K2 配置块，将被替换
+	Before   string // 修改前的内容
+	After    string // 修改后的内容
+	Err      error  // 读取失败时不修改该文件
+
+	apiKey string
+}
+
+// PlanShellConfigChanges 按配置方式返回永久设置环境变量时将对 shell 配置文件和 ~/.claude/settings.json 做的修改，
+// 不写入任何文件。Windows 的 shell 配置使用 setx 和 PowerShell 配置文件，不在其中
+func (i *Installer) PlanShellConfigChanges(provider Provider, apiKey, rpm string) ([]ShellConfigChange, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("获取用户目录失败: %v", err)
+	}
+	requestDelay, _ := requestDelayMs(rpm, provider.DefaultRPM)
+
+	var changes []ShellConfigChange
+	if i.ConfigStrategy.writesShell() && runtime.GOOS != "windows" {
+		changes = planShellConfigChanges(home, provider, apiKey, requestDelay)
+	}
+	if i.ConfigStrategy.writesSettings() {
+		changes = append(changes, planClaudeSettingsChange(home, provider, apiKey, requestDelay))
+	}
+	return changes, nil
+}
+
+// planShellConfigChanges 先删除已有的 K2 配置块再追加当前配置，重复安装、更换密钥或服务商后始终只保留一份
+func planShellConfigChanges(home string, provider Provider, apiKey string, requestDelay int) []ShellConfigChange {
+	var changes []ShellConfigChange
+	for _, shellConfig := range shellConfigFiles(home) {
+		change := ShellConfigChange{Path: shellConfig, apiKey: apiKey}
+
+		// 读取现有配置，文件不存在时创建（新账户可能还没有 .zshrc 等配置文件）
+		existingData, err := os.ReadFile(shellConfig)
+		change.Created = os.IsNotExist(err)
+		if err != nil && !change.Created {
+			change.Err = err
+			changes = append(changes, change)
+			continue
+		}
+
+		change.Before = string(existingData)
+		content, replaced := stripShellEnvBlock(change.Before)
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		change.After = content + shellEnvBlock(shellConfig, provider, apiKey, requestDelay)
+		change.Replaced = replaced
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Diff 返回修改前后删除和新增的行（以 "- "、"+ " 开头），API Key 已打码
+func (c ShellConfigChange) Diff() string {
+	var b strings.Builder
+	for _, line := range diffLines(c.Before, c.After) {
+		line = strings.TrimPrefix(line, "    ")
+		if c.apiKey != "" {
+			line = strings.ReplaceAll(line, c.apiKey, maskKey(c.apiKey))
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
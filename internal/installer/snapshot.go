@@ -0,0 +1,257 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EnvSnapshot 某一时刻与安装相关的环境快照
+type EnvSnapshot struct {
+	TakenAt  time.Time         `json:"taken_at"`
+	PathDirs []string          `json:"path"`
+	Env      map[string]string `json:"env"`
+	Versions map[string]string `json:"versions"`
+	Files    map[string]string `json:"files"` // 文件路径 -> 内容，不存在的文件不记录
+}
+
+// snapshotEnvPrefixes 需要记录的环境变量前缀
+var snapshotEnvPrefixes = []string{"ANTHROPIC_", "CLAUDE_", "NODE_", "NPM_", "NVM_", "HOMEBREW_"}
+
+// snapshotCommands 需要记录版本的命令
+var snapshotCommands = []string{"node", "npm", "git", "claude", "brew"}
+
+// TakeEnvSnapshot 记录当前的 PATH、相关环境变量、命令版本和配置文件内容
+func TakeEnvSnapshot() *EnvSnapshot {
+	snapshot := &EnvSnapshot{
+		TakenAt:  time.Now(),
+		PathDirs: filepath.SplitList(os.Getenv("PATH")),
+		Env:      make(map[string]string),
+		Versions: make(map[string]string),
+		Files:    make(map[string]string),
+	}
+
+	for _, kv := range os.Environ() {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		for _, prefix := range snapshotEnvPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				snapshot.Env[key] = maskSecrets(key + "=" + value)[len(key)+1:]
+				break
+			}
+		}
+	}
+
+	for _, name := range snapshotCommands {
+		if version := commandVersion(name); version != "" {
+			snapshot.Versions[name] = version
+		}
+	}
+
+	for _, path := range snapshotFiles() {
+		if data, err := os.ReadFile(path); err == nil {
+			snapshot.Files[path] = maskSecrets(string(data))
+		}
+	}
+
+	return snapshot
+}
+
+// commandVersion 返回命令 --version 输出的第一行，命令不可用时返回空字符串
+func commandVersion(name string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, name, "--version").Output()
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line)
+}
+
+// snapshotFiles 返回安装过程可能修改的配置文件
+func snapshotFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	return []string{
+		filepath.Join(home, ".claude.json"),
+		filepath.Join(home, ".claude", "settings.json"),
+		filepath.Join(home, ".zprofile"),
+		filepath.Join(home, ".zshrc"),
+		filepath.Join(home, ".bashrc"),
+		filepath.Join(home, ".bash_profile"),
+		filepath.Join(home, ".profile"),
+		filepath.Join(home, ".config/fish/config.fish"),
+	}
+}
+
+// DiffSnapshots 比较两次快照，返回可读的变化列表
+func DiffSnapshots(before, after *EnvSnapshot) []string {
+	var changes []string
+
+	// PATH 变化
+	beforeDirs := make(map[string]bool)
+	for _, dir := range before.PathDirs {
+		beforeDirs[dir] = true
+	}
+	afterDirs := make(map[string]bool)
+	for _, dir := range after.PathDirs {
+		afterDirs[dir] = true
+		if !beforeDirs[dir] {
+			changes = append(changes, fmt.Sprintf("PATH 新增: %s", dir))
+		}
+	}
+	for _, dir := range before.PathDirs {
+		if !afterDirs[dir] {
+			changes = append(changes, fmt.Sprintf("PATH 移除: %s", dir))
+		}
+	}
+
+	changes = append(changes, diffStringMaps("环境变量", before.Env, after.Env)...)
+	changes = append(changes, diffStringMaps("命令版本", before.Versions, after.Versions)...)
+
+	// 配置文件变化
+	for _, path := range sortedKeys(before.Files, after.Files) {
+		oldContent, existed := before.Files[path]
+		newContent, exists := after.Files[path]
+		switch {
+		case !existed && exists:
+			changes = append(changes, fmt.Sprintf("文件新建: %s", path))
+			changes = append(changes, diffLines(oldContent, newContent)...)
+		case existed && !exists:
+			changes = append(changes, fmt.Sprintf("文件删除: %s", path))
+		case oldContent != newContent:
+			changes = append(changes, fmt.Sprintf("文件修改: %s", path))
+			changes = append(changes, diffLines(oldContent, newContent)...)
+		}
+	}
+
+	return changes
+}
+
+// diffStringMaps 比较两个键值表
+func diffStringMaps(label string, before, after map[string]string) []string {
+	var changes []string
+	for _, key := range sortedKeys(before, after) {
+		oldValue, existed := before[key]
+		newValue, exists := after[key]
+		switch {
+		case !existed && exists:
+			changes = append(changes, fmt.Sprintf("%s新增: %s = %s", label, key, newValue))
+		case existed && !exists:
+			changes = append(changes, fmt.Sprintf("%s移除: %s (原值 %s)", label, key, oldValue))
+		case oldValue != newValue:
+			changes = append(changes, fmt.Sprintf("%s变化: %s: %s → %s", label, key, oldValue, newValue))
+		}
+	}
+	return changes
+}
+
+// diffLines 按行比较文件内容，列出新增和删除的行
+func diffLines(before, after string) []string {
+	beforeLines := strings.Split(before, "\n")
+	afterLines := strings.Split(after, "\n")
+
+	count := func(lines []string) map[string]int {
+		counts := make(map[string]int)
+		for _, line := range lines {
+			counts[line]++
+		}
+		return counts
+	}
+
+	var changes []string
+	remaining := count(afterLines)
+	for _, line := range beforeLines {
+		if remaining[line] > 0 {
+			remaining[line]--
+		} else if strings.TrimSpace(line) != "" {
+			changes = append(changes, "    - "+line)
+		}
+	}
+
+	remaining = count(beforeLines)
+	for _, line := range afterLines {
+		if remaining[line] > 0 {
+			remaining[line]--
+		} else if strings.TrimSpace(line) != "" {
+			changes = append(changes, "    + "+line)
+		}
+	}
+	return changes
+}
+
+// sortedKeys 返回两个表中所有键的有序列表
+func sortedKeys(a, b map[string]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string]string{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// saveSnapshot 将快照保存到 ~/.claude-k2-installer/snapshots 目录
+func saveSnapshot(snapshot *EnvSnapshot, stage string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(home, ".claude-k2-installer", "snapshots")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.json", snapshot.TakenAt.Format("20060102-150405"), stage))
+	return path, os.WriteFile(path, data, 0600)
+}
+
+// recordRunDiff 记录当前快照并与安装前的快照比较，输出本次运行改变的内容
+func (i *Installer) recordRunDiff(stage string) {
+	if i.beforeSnapshot == nil {
+		return
+	}
+
+	after := TakeEnvSnapshot()
+	i.persistSnapshot(after, stage)
+
+	i.runDiff = DiffSnapshots(i.beforeSnapshot, after)
+	if len(i.runDiff) == 0 {
+		i.addLog("本次运行未改变环境")
+		return
+	}
+
+	i.addLog("📋 本次运行改变了以下内容:")
+	for _, change := range i.runDiff {
+		i.addLog("  " + change)
+	}
+}
+
+// RunDiff 返回本次运行相对安装前快照的环境变化
+func (i *Installer) RunDiff() []string {
+	return i.runDiff
+}
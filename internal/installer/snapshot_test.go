@@ -0,0 +1,148 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestMaskKeyShortKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"", "..."},
+		{"abc", "abc..."},
+		{"sk-1234567", "sk-1234567..."},
+		{"sk-1234567890abcdef", "sk-1234567..."},
+		{"密钥密钥密钥密钥密钥密钥", "密钥密钥密钥密钥密钥..."},
+	}
+
+	for _, tt := range tests {
+		if got := maskKey(tt.key); got != tt.want {
+			t.Errorf("maskKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+// useTempSetupScript 让临时环境变量脚本写入测试的临时目录，避免覆盖真实的 /tmp/claude_k2_setup.sh
+func useTempSetupScript(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("TMP", dir)
+	t.Setenv("TEMP", dir)
+	original := unixSetupScriptPath
+	unixSetupScriptPath = filepath.Join(dir, "claude_k2_setup.sh")
+	t.Cleanup(func() { unixSetupScriptPath = original })
+}
+
+func TestConfigureWithShortKeyDoesNotPanic(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+	useTempSetupScript(t)
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	if err := i.configureK2APIWithOptions(DefaultProvider(), "abc", "3", false); err != nil {
+		t.Fatalf("configureK2APIWithOptions with a 3-character key: %v", err)
+	}
+}
+
+func TestInstallRecordRoundTripAndStale(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	if record, err := LoadInstallRecord(); err != nil || record != nil {
+		t.Fatalf("expected no record before install, got %v, %v", record, err)
+	}
+
+	i := New()
+	i.SetLogPolicy(LogPolicy{Retention: LogRetentionOff})
+	i.saveInstallRecord(DefaultProvider())
+	record, err := LoadInstallRecord()
+	if err != nil || record == nil {
+		t.Fatalf("LoadInstallRecord: %v, %v", record, err)
+	}
+	if record.Provider != DefaultProvider().Name || record.CompletedAt.IsZero() {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	installed := EnvironmentStatus{NodeOK: true, GitOK: true, ClaudeOK: true, NodeVersion: "v20.10.0", GitVersion: "git version 2.43.0"}
+	withGit := InstallRecord{NodeVersion: "v20.10.0", GitVersion: "git version 2.43.0"}
+	if withGit.Stale(installed) {
+		t.Error("record should not be stale while all components are installed")
+	}
+	if !withGit.Stale(EnvironmentStatus{NodeOK: true, ClaudeOK: true, NodeVersion: "v20.10.0"}) {
+		t.Error("record should be stale after Git was removed")
+	}
+	if (InstallRecord{NodeVersion: "v20.10.0"}).Stale(EnvironmentStatus{NodeOK: true, ClaudeOK: true, NodeVersion: "v20.10.0"}) {
+		t.Error("skipped Git should not make the record stale")
+	}
+	if !withGit.Stale(EnvironmentStatus{NodeOK: true, GitOK: true, NodeVersion: "v20.10.0"}) {
+		t.Error("record should be stale after Claude Code was removed")
+	}
+
+	if err := ClearInstallRecord(); err != nil {
+		t.Fatalf("ClearInstallRecord: %v", err)
+	}
+	if record, _ := LoadInstallRecord(); record != nil {
+		t.Error("record should be gone after ClearInstallRecord")
+	}
+}
+
+func TestDiagnosticsMasksSecrets(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("ANTHROPIC_API_KEY", "sk-diagnostics-secret-value")
+	t.Setenv("ANTHROPIC_BASE_URL", "https://api.moonshot.cn/anthropic")
+
+	rc := "alias ll='ls -l'\n\n" + shellEnvBlock(".zshrc", DefaultProvider(), "sk-diagnostics-secret-value", 1000) +
+		"export PATH=\"$HOME/bin:$PATH\"\n"
+	if err := os.WriteFile(filepath.Join(home, ".zshrc"), []byte(rc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	claudeJSON := `{"mcpServers": {}, "apiKey": "sk-diagnostics-secret-value", "apiBaseUrl": "https://api.moonshot.cn/anthropic", "requestDelayMs": 1000}`
+	if err := os.WriteFile(filepath.Join(home, ".claude.json"), []byte(claudeJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := New().Diagnostics()
+	data, err := d.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-value") {
+		t.Errorf("diagnostics leak the API key:\n%s", data)
+	}
+	if d.Env["ANTHROPIC_BASE_URL"] != "https://api.moonshot.cn/anthropic" {
+		t.Errorf("ANTHROPIC_BASE_URL = %q", d.Env["ANTHROPIC_BASE_URL"])
+	}
+	if d.ClaudeConfig["apiBaseUrl"] != "https://api.moonshot.cn/anthropic" || d.ClaudeConfig["mcpServers"] != nil {
+		t.Errorf("unexpected .claude.json fields %v", d.ClaudeConfig)
+	}
+
+	if runtime.GOOS != "windows" {
+		block := d.K2Blocks[filepath.Join(home, ".zshrc")]
+		if !strings.HasPrefix(block, k2BlockStart) || !strings.HasSuffix(block, k2BlockEnd) || strings.Contains(block, "alias") {
+			t.Errorf("unexpected K2 block:\n%s", block)
+		}
+	}
+}
+
+func TestMaskEnvLine(t *testing.T) {
+	tests := map[string]string{
+		`export ANTHROPIC_API_KEY="sk-1234567890abcdef"`:                      `export ANTHROPIC_API_KEY="sk-1234567..."`,
+		`set -gx ANTHROPIC_AUTH_TOKEN 'abcdefghijklmnop'`:                     `set -gx ANTHROPIC_AUTH_TOKEN 'abcdefghij...'`,
+		`$env:ANTHROPIC_API_KEY = 'abcdefghijklmnop'`:                         `$env:ANTHROPIC_API_KEY = 'abcdefghij...'`,
+		`export CLAUDE_REQUEST_DELAY_MS="1000"`:                               `export CLAUDE_REQUEST_DELAY_MS="1000"`,
+		`Remove-Item Env:\ANTHROPIC_AUTH_TOKEN -ErrorAction SilentlyContinue`: `Remove-Item Env:\ANTHROPIC_AUTH_TOKEN -ErrorAction SilentlyContinue`,
+	}
+	for line, want := range tests {
+		if got := maskEnvLine(line); got != want {
+			t.Errorf("maskEnvLine(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
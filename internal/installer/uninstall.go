@@ -0,0 +1,124 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// setupScriptNames 安装和配置过程中写入临时目录的脚本
+var setupScriptNames = []string{
+	"install_nodejs.bat",
+	"install_nodejs.sh",
+	"brew_install_nodejs.sh",
+	"install_homebrew.sh",
+	"install_git.bat",
+	"install_git.sh",
+	"brew_install_git.sh",
+	"claude_k2_setup.bat",
+	"claude_k2_setup.sh",
+	"claude_restore.ps1",
+}
+
+// UninstallOptions 卸载选项
+type UninstallOptions struct {
+	RemoveData bool // 同时删除 ~/.claude-k2-installer 中的日志、环境快照和下载缓存
+}
+
+// UninstallPlan 返回卸载将删除的内容，供确认对话框逐项展示
+func UninstallPlan(opts UninstallOptions) []string {
+	plan := []string{
+		fmt.Sprintf("全局 npm 包 %s（npm uninstall -g）", claudeCodePackage),
+		"~/.claude.json 中的 K2 配置（有备份时恢复备份，没有本工具写入的配置时不修改）",
+		"~/.claude/settings.json 中 env 的 K2 环境变量（其他设置保持不变）",
+		"本工具写入的环境变量和 shell 配置（ANTHROPIC_*、PATH 标记行）",
+		"临时目录中的安装和配置脚本",
+	}
+	if opts.RemoveData {
+		plan = append(plan, "~/.claude-k2-installer 目录（安装日志、环境快照和下载缓存）")
+	}
+	return plan
+}
+
+// Uninstall 卸载 Claude Code 并清除 K2 配置，Node.js 和 Git 保留
+func (i *Installer) Uninstall(opts UninstallOptions) error {
+	i.addLog("开始卸载 Claude Code...")
+
+	if err := i.uninstallClaudeCode(); err != nil {
+		return err
+	}
+	if err := ClearInstallRecord(); err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 删除安装记录失败: %v", err))
+	}
+
+	i.removeSetupScripts()
+
+	if err := i.RestoreOriginalClaudeConfig(); err != nil {
+		return err
+	}
+
+	if opts.RemoveData {
+		dir, err := logDir()
+		if err != nil {
+			return fmt.Errorf("获取用户目录失败: %v", err)
+		}
+		// 先关闭日志文件，Windows 上打开的文件无法删除；本次会话之后不再落盘，避免重新创建目录
+		policy := i.LogPolicy()
+		policy.Retention = LogRetentionOff
+		i.SetLogPolicy(policy)
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("删除 %s 失败: %v", dir, err)
+		}
+		i.addLog(fmt.Sprintf("✅ 已删除 %s，本次运行不再保存日志文件", dir))
+	}
+
+	i.addLog("✅ 卸载完成，Node.js 和 Git 已保留")
+	return nil
+}
+
+// uninstallClaudeCode 卸载全局安装的 Claude Code，npm 不可用时跳过
+func (i *Installer) uninstallClaudeCode() error {
+	if _, err := exec.LookPath("npm"); err != nil {
+		i.addLog("未找到 npm，跳过卸载 Claude Code")
+		return nil
+	}
+
+	args := []string{"uninstall", "-g", claudeCodePackage}
+	cmd := exec.Command("npm", args...)
+
+	// 全局目录不可写时，Linux 上按策略使用 sudo，否则直接尝试并在失败时给出提示
+	if prefix, err := npmGlobalPrefix(); err == nil {
+		modulesDir, _ := npmGlobalDirs(prefix)
+		if !dirWritable(modulesDir) && i.NPMStrategy == NPMStrategySudo && runtime.GOOS == "linux" {
+			i.addLog("使用 sudo 卸载 npm 全局包")
+			cmd = exec.Command("sudo", append([]string{"npm"}, args...)...)
+		}
+	}
+
+	i.addLog(fmt.Sprintf("执行: npm uninstall -g %s", claudeCodePackage))
+	if err := i.executeCommandWithStreaming(cmd); err != nil {
+		return fmt.Errorf("卸载 Claude Code 失败: %v", err)
+	}
+	i.addLog("✅ 已卸载 Claude Code")
+	return nil
+}
+
+// removeSetupScripts 删除安装和配置过程中写入临时目录的脚本
+func (i *Installer) removeSetupScripts() {
+	dirs := []string{os.TempDir()}
+	if runtime.GOOS != "windows" && os.TempDir() != "/tmp" {
+		// 临时环境变量脚本固定写在 /tmp
+		dirs = append(dirs, "/tmp")
+	}
+
+	for _, dir := range dirs {
+		for _, name := range setupScriptNames {
+			path := filepath.Join(dir, name)
+			if err := os.Remove(path); err == nil {
+				i.addLog(fmt.Sprintf("✅ 已删除临时脚本: %s", path))
+			}
+		}
+	}
+}
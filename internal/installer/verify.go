@@ -0,0 +1,163 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// liveEnvTimeout 启动 shell 读取环境变量的超时时间，避免配置文件中的交互命令卡住检测
+const liveEnvTimeout = 5 * time.Second
+
+// liveEnvMarker 区分 shell 配置文件打印的内容和要读取的变量值
+const liveEnvMarker = "__CLAUDE_K2_ENV__="
+
+// VerifyCheck 检测环境中的一项检查
+type VerifyCheck struct {
+	Name   string // 检查项，如 Node.js
+	OK     bool
+	Detail string // 版本号、变量值或失败原因
+}
+
+// VerifyReport 检测环境的结果：组件是否安装及版本，以及新终端中的 K2 环境变量
+type VerifyReport struct {
+	EnvironmentStatus
+
+	Provider  Provider // 检查环境变量时对照的服务商
+	BaseURL   string   // ANTHROPIC_BASE_URL 的值，未设置时为空
+	BaseURLOK bool     // ANTHROPIC_BASE_URL 指向服务商的接口地址
+	KeySet    bool     // 服务商的 API Key 环境变量已设置
+	EnvSource string   // 环境变量的读取来源
+}
+
+// Healthy 组件均已安装，且环境变量指向服务商
+func (r VerifyReport) Healthy() bool {
+	return r.NodeOK && r.GitOK && r.ClaudeOK && r.BaseURLOK && r.KeySet
+}
+
+// Checks 返回逐项检查结果，用于显示检查清单
+func (r VerifyReport) Checks() []VerifyCheck {
+	component := func(name, version string, ok bool, missing string) VerifyCheck {
+		if version == "" {
+			return VerifyCheck{Name: name, Detail: missing}
+		}
+		return VerifyCheck{Name: name, OK: ok, Detail: version}
+	}
+
+	nodeCheck := component("Node.js", r.NodeVersion, r.NodeOK, "未安装")
+	if r.NodeVersion != "" && !r.NodeOK {
+		nodeCheck.Detail = fmt.Sprintf("%s（需要 v%d 或更高版本）", r.NodeVersion, effectiveMinNodeVersion(r.MinNodeVersion))
+	}
+
+	gitCheck := component("Git", r.GitVersion, r.GitOK, "未安装")
+	if r.GitVersion != "" && !r.GitOK {
+		gitCheck.Detail = fmt.Sprintf("%s（需要 %s 或更高版本）", r.GitVersion, effectiveMinGitVersion(r.MinGitVersion))
+	}
+
+	baseURLCheck := VerifyCheck{Name: "ANTHROPIC_BASE_URL", OK: r.BaseURLOK, Detail: r.BaseURL}
+	switch {
+	case r.BaseURL == "":
+		baseURLCheck.Detail = "未设置"
+	case !r.BaseURLOK:
+		baseURLCheck.Detail = fmt.Sprintf("%s（不是 %s 的地址 %s）", r.BaseURL, r.Provider.Name, r.Provider.BaseURL)
+	}
+
+	keyCheck := VerifyCheck{Name: r.Provider.EnvKeyName, OK: r.KeySet, Detail: "已设置"}
+	if !r.KeySet {
+		keyCheck.Detail = "未设置"
+	}
+
+	return []VerifyCheck{
+		nodeCheck,
+		gitCheck,
+		component("Claude Code", r.ClaudeVersion, r.ClaudeOK, "未安装"),
+		baseURLCheck,
+		keyCheck,
+	}
+}
+
+// Verify 检测已安装的组件和版本，以及新打开的终端中 K2 环境变量是否指向 provider，不修改系统
+//
+// 环境变量从新终端实际会读到的位置获取：Windows 读取用户环境变量，其他系统启动交互式 shell 读取，
+// 读取失败时退回到当前进程的环境变量（程序启动后写入的配置不会出现在其中）。
+func (i *Installer) Verify(provider Provider) VerifyReport {
+	i.addLog("🩺 检测环境...")
+	report := VerifyReport{
+		EnvironmentStatus: i.CheckEnvironment(),
+		Provider:          provider,
+	}
+
+	var key string
+	report.BaseURL, report.EnvSource = liveEnvValue("ANTHROPIC_BASE_URL")
+	key, _ = liveEnvValue(provider.EnvKeyName)
+	report.BaseURLOK = sameBaseURL(report.BaseURL, provider.BaseURL)
+	report.KeySet = key != ""
+
+	i.addLog(fmt.Sprintf("环境变量来源: %s", report.EnvSource))
+	for _, check := range report.Checks() {
+		icon := "✅"
+		if !check.OK {
+			icon = "❌"
+		}
+		i.addLog(fmt.Sprintf("   %s %s: %s", icon, check.Name, check.Detail))
+	}
+	return report
+}
+
+// sameBaseURL 比较接口地址，忽略首尾空白和末尾的 /
+func sameBaseURL(a, b string) bool {
+	a = strings.TrimRight(strings.TrimSpace(a), "/")
+	b = strings.TrimRight(strings.TrimSpace(b), "/")
+	return a != "" && a == b
+}
+
+// liveEnvValue 返回新打开的终端中环境变量的值及读取来源
+func liveEnvValue(name string) (value, source string) {
+	if runtime.GOOS == "windows" {
+		output, err := exec.Command("reg", "query", `HKCU\Environment`, "/v", name).Output()
+		if err == nil {
+			if value, ok := parseRegQueryValue(string(output), name); ok {
+				return value, "用户环境变量"
+			}
+		}
+		// reg query 找不到该值时返回错误，也可能只在当前进程中设置过
+		if value := os.Getenv(name); value != "" {
+			return value, "当前进程"
+		}
+		return "", "用户环境变量"
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), liveEnvTimeout)
+	defer cancel()
+	script := fmt.Sprintf(`printf '%s%%s\n' "$%s"`, liveEnvMarker, name)
+	output, err := exec.CommandContext(ctx, shell, "-i", "-c", script).Output()
+	if err == nil {
+		for _, line := range strings.Split(string(output), "\n") {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(line), liveEnvMarker); ok {
+				return value, "新终端（" + shell + "）"
+			}
+		}
+	}
+	return os.Getenv(name), "当前进程"
+}
+
+// parseRegQueryValue 从 reg query 的输出中解析值，格式为 "    名称    REG_SZ    值"
+func parseRegQueryValue(output, name string) (string, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], name) || !strings.HasPrefix(fields[1], "REG_") {
+			continue
+		}
+		_, value, _ := strings.Cut(line, fields[1])
+		return strings.TrimSpace(value), true
+	}
+	return "", false
+}
@@ -0,0 +1,108 @@
+package installer
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// gitWindowsPublisher Git for Windows 安装包 Authenticode 签名证书的签名者（维护者 Johannes Schindelin）
+const gitWindowsPublisher = "CN=Johannes Schindelin"
+
+// windowsNativeArch 返回 Windows 系统的原生架构（amd64、arm64 或 386）
+// x64 程序在 ARM64 系统上仿真运行时 runtime.GOARCH 为 amd64，需要从注册表读取真实架构
+func windowsNativeArch() string {
+	if runtime.GOARCH == "arm64" {
+		return "arm64"
+	}
+
+	arch := os.Getenv("PROCESSOR_ARCHITEW6432")
+	if output, err := exec.Command("reg", "query",
+		`HKLM\SYSTEM\CurrentControlSet\Control\Session Manager\Environment`,
+		"/v", "PROCESSOR_ARCHITECTURE").Output(); err == nil {
+		fields := strings.Fields(string(output))
+		if len(fields) > 0 {
+			arch = fields[len(fields)-1]
+		}
+	}
+	if arch == "" {
+		arch = os.Getenv("PROCESSOR_ARCHITECTURE")
+	}
+
+	switch strings.ToUpper(arch) {
+	case "ARM64":
+		return "arm64"
+	case "X86":
+		return "386"
+	case "AMD64":
+		return "amd64"
+	default:
+		return runtime.GOARCH
+	}
+}
+
+// gitWindowsArtifact 返回指定版本的 Git for Windows 安装包文件名
+func gitWindowsArtifact(version, arch string) string {
+	if arch == "arm64" {
+		return fmt.Sprintf("Git-%s-arm64.exe", version)
+	}
+	return fmt.Sprintf("Git-%s-64-bit.exe", version)
+}
+
+// gitWindowsDownloadURLs 返回 Git for Windows 安装包的下载地址，国内镜像优先
+func gitWindowsDownloadURLs(version, artifact string) []string {
+	release := fmt.Sprintf("v%s.windows.1", version)
+	return []string{
+		fmt.Sprintf("https://cdn.npmmirror.com/binaries/git-for-windows/%s/%s", release, artifact),
+		fmt.Sprintf("https://github.com/git-for-windows/git/releases/download/%s/%s", release, artifact),
+		fmt.Sprintf("https://mirrors.tuna.tsinghua.edu.cn/github-release/git-for-windows/git/%s/%s", release, artifact),
+	}
+}
+
+// urlReachable 检查下载地址是否存在
+func urlReachable(client *http.Client, url string) bool {
+	resp, err := client.Head(url)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// chooseWindowsArch 选择安装包架构：ARM64 系统优先使用 arm64 安装包，
+// 所有镜像都没有 arm64 安装包时回退到 x64（由系统仿真运行）
+func (i *Installer) chooseWindowsArch(component string, urlsFor func(arch string) ([]string, error)) (string, error) {
+	arch := windowsNativeArch()
+	i.addLog(fmt.Sprintf("检测到系统架构: %s", arch))
+	if arch != "arm64" {
+		return arch, nil
+	}
+
+	urls, err := urlsFor("arm64")
+	if err != nil {
+		return "", err
+	}
+	if i.OfflineDir != "" {
+		// 离线安装不探测镜像，离线目录中有 arm64 安装包时使用，否则使用 x64 安装包
+		if len(urls) > 0 && i.hasOfflineArtifact(path.Base(urls[0])) {
+			i.addLog(fmt.Sprintf("使用 ARM64 版 %s 离线安装包", component))
+			return "arm64", nil
+		}
+		i.addLog(fmt.Sprintf("⚠️ 离线安装目录中没有 ARM64 版 %s 安装包，改用 x64 版本（通过系统仿真运行）", component))
+		return "amd64", nil
+	}
+	for _, url := range urls {
+		if urlReachable(i.httpClient(10*time.Second), url) {
+			i.addLog(fmt.Sprintf("使用 ARM64 版 %s 安装包", component))
+			return "arm64", nil
+		}
+	}
+
+	i.addLog(fmt.Sprintf("⚠️ 未找到 ARM64 版 %s 安装包，改用 x64 版本（通过系统仿真运行）", component))
+	return "amd64", nil
+}
@@ -0,0 +1,103 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// windowsSystemEnvKey 系统环境变量所在的注册表项
+const windowsSystemEnvKey = `HKLM\SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
+
+// windowsEnvReference 匹配注册表 REG_EXPAND_SZ 值中的 %变量% 引用
+var windowsEnvReference = regexp.MustCompile(`%([^%;]+)%`)
+
+// broadcastEnvironmentChangeScript 向所有顶层窗口广播 WM_SETTINGCHANGE("Environment")，
+// 资源管理器收到后重新读取环境变量，之后从开始菜单或资源管理器打开的终端无需重启系统即可看到新的 PATH。
+// SMTO_ABORTIFHUNG 跳过无响应的窗口，最多等待 5 秒
+const broadcastEnvironmentChangeScript = `Add-Type -Namespace K2 -Name Env -MemberDefinition '[DllImport("user32.dll", SetLastError = true, CharSet = CharSet.Unicode)] public static extern IntPtr SendMessageTimeout(IntPtr hWnd, uint Msg, UIntPtr wParam, string lParam, uint fuFlags, uint uTimeout, out UIntPtr lpdwResult);'
+$result = [UIntPtr]::Zero
+[void][K2.Env]::SendMessageTimeout([IntPtr]0xffff, 0x1A, [UIntPtr]::Zero, 'Environment', 2, 5000, [ref]$result)`
+
+// broadcastEnvironmentChange 修改注册表中的环境变量后通知系统刷新
+// 通过 PowerShell 调用 SendMessageTimeout，不引入只能在 Windows 上编译的 syscall 代码
+func (i *Installer) broadcastEnvironmentChange() {
+	if runtime.GOOS != "windows" {
+		return
+	}
+	output, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", broadcastEnvironmentChangeScript).CombinedOutput()
+	if err != nil {
+		i.addLog(fmt.Sprintf("⚠️ 通知系统刷新环境变量失败，新开的终端可能要注销后才能看到变化: %v %s", err, strings.TrimSpace(string(output))))
+	}
+}
+
+// refreshWindowsEnvironment 安装 Node.js、Git 后通知系统刷新环境变量，并从注册表重新读取 PATH，
+// 当前进程和之后启动的子进程无需重启终端即可找到新安装的命令
+func (i *Installer) refreshWindowsEnvironment() {
+	if runtime.GOOS != "windows" {
+		return
+	}
+	i.broadcastEnvironmentChange()
+	i.refreshWindowsPath()
+}
+
+// refreshWindowsPath 把注册表中系统和用户 PATH 合并后写回当前进程的 PATH
+func (i *Installer) refreshWindowsPath() {
+	systemPath := windowsRegistryValue(windowsSystemEnvKey, "Path")
+	userPath := windowsRegistryValue(`HKCU\Environment`, "Path")
+	if systemPath == "" && userPath == "" {
+		i.addLog("⚠️ 无法从注册表读取 PATH，继续使用当前 PATH")
+		return
+	}
+
+	current := os.Getenv("PATH")
+	merged := mergeWindowsPath(expandWindowsEnv(systemPath), expandWindowsEnv(userPath), current)
+	if merged == current {
+		return
+	}
+	os.Setenv("PATH", merged)
+	i.addLog("🔄 已从注册表刷新 PATH，无需重启终端")
+}
+
+// windowsRegistryValue 读取注册表中的字符串值，读取失败时返回空字符串
+func windowsRegistryValue(key, name string) string {
+	output, err := exec.Command("reg", "query", key, "/v", name).Output()
+	if err != nil {
+		return ""
+	}
+	value, _ := parseRegQueryValue(string(output), name)
+	return value
+}
+
+// expandWindowsEnv 展开 REG_EXPAND_SZ 值中的 %变量%，未定义的变量保持原样
+func expandWindowsEnv(value string) string {
+	return windowsEnvReference.ReplaceAllStringFunc(value, func(ref string) string {
+		if expanded, ok := os.LookupEnv(strings.Trim(ref, "%")); ok {
+			return expanded
+		}
+		return ref
+	})
+}
+
+// mergeWindowsPath 按新终端的顺序合并 PATH：系统 PATH 在前，用户 PATH 在后，
+// 最后保留当前进程中注册表里没有的目录（例如安装过程中临时加入的目录）。
+// Windows 路径不区分大小写，末尾的 \ 也不影响比较，重复的目录只保留第一个
+func mergeWindowsPath(systemPath, userPath, current string) string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, path := range []string{systemPath, userPath, current} {
+		for _, dir := range strings.Split(path, ";") {
+			dir = strings.TrimSpace(dir)
+			key := strings.ToLower(strings.TrimRight(dir, `\/`))
+			if dir == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return strings.Join(dirs, ";")
+}
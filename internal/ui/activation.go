@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"claude-k2-installer/internal/activation"
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// CreateActivationContent 未激活时启动显示的激活界面，激活码校验通过并保存后切换到主界面；
+// 只有开发构建（-tags dev）列出示例激活码
+func (m *Manager) CreateActivationContent() fyne.CanvasObject {
+	title := canvas.NewText(i18n.T("app.title"), theme.Color(theme.ColorNameForeground))
+	title.TextSize = 24
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.Alignment = fyne.TextAlignCenter
+
+	hintLabel := widget.NewLabel(i18n.T("activation.hint"))
+	hintLabel.Wrapping = fyne.TextWrapWord
+
+	codeEntry := widget.NewEntry()
+	codeEntry.SetPlaceHolder("CK2025-XXXX-XXXX-XXXX")
+
+	errLabel := widget.NewLabel(i18n.T("activation.invalid"))
+	errLabel.Importance = widget.DangerImportance
+	errLabel.Hide()
+
+	activate := func() {
+		code := strings.TrimSpace(codeEntry.Text)
+		if !activation.Validate(code) {
+			errLabel.Show()
+			return
+		}
+		if err := activation.SaveActivation(code); err != nil {
+			dialog.ShowError(errors.New(i18n.T("error.activation", err)), m.window)
+			return
+		}
+		m.window.SetContent(m.CreateMainContent())
+	}
+	codeEntry.OnSubmitted = func(string) { activate() }
+	codeEntry.OnChanged = func(string) { errLabel.Hide() }
+
+	activateButton := widget.NewButton(i18n.T("button.activate"), activate)
+	activateButton.Importance = widget.HighImportance
+
+	content := container.NewVBox(title, hintLabel, codeEntry, errLabel, activateButton)
+	if showSampleActivationCodes {
+		content.Add(widget.NewSeparator())
+		content.Add(widget.NewLabel(i18n.T("activation.samples")))
+		for _, code := range activation.GetSampleActivationCodes() {
+			code := code
+			sampleButton := widget.NewButton(code, func() { codeEntry.SetText(code) })
+			sampleButton.Importance = widget.LowImportance
+			content.Add(sampleButton)
+		}
+	}
+
+	return container.NewVBox(layout.NewSpacer(), container.NewPadded(content), layout.NewSpacer())
+}
+
+// activationStatus 主界面标题下方的激活状态，激活码只显示前两段；未激活时不显示
+func (m *Manager) activationStatus() fyne.CanvasObject {
+	statusLabel := widget.NewLabel("")
+	statusLabel.Alignment = fyne.TextAlignCenter
+	statusLabel.Importance = widget.LowImportance
+
+	info := activation.Current()
+	if info == nil {
+		statusLabel.Hide()
+		return statusLabel
+	}
+	statusLabel.SetText(i18n.T("activation.status", info.MaskedCode(), info.ActivatedAt.Format("2006-01-02")))
+	return statusLabel
+}
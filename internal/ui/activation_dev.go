@@ -0,0 +1,6 @@
+//go:build dev
+
+package ui
+
+// showSampleActivationCodes 开发构建（-tags dev）的激活界面列出示例激活码，方便调试
+const showSampleActivationCodes = true
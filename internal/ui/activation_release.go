@@ -0,0 +1,6 @@
+//go:build !dev
+
+package ui
+
+// showSampleActivationCodes 发布构建不显示示例激活码，示例码只在 -tags dev 构建中可见
+const showSampleActivationCodes = false
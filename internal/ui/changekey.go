@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"errors"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showChangeKeyDialog 输入新的 API Key，确认后只重新配置和验证，不重新安装任何组件
+func (m *Manager) showChangeKeyDialog() {
+	keyEntry := widget.NewPasswordEntry()
+	keyEntry.SetPlaceHolder(i18n.T("change_key.placeholder"))
+	hint := widget.NewLabel(i18n.T("change_key.hint"))
+	hint.Wrapping = fyne.TextWrapWord
+
+	keyDialog := dialog.NewCustomConfirm(i18n.T("change_key.title"), i18n.T("button.ok"), i18n.T("button.cancel"),
+		container.NewVBox(hint, keyEntry),
+		func(confirmed bool) {
+			if confirmed {
+				m.confirmChangeKey(installer.NormalizeAPIKey(keyEntry.Text))
+			}
+		}, m.window)
+	keyDialog.Resize(fyne.NewSize(480, 0))
+	keyDialog.Show()
+	m.window.Canvas().Focus(keyEntry)
+}
+
+// confirmChangeKey 检查新的 API Key，格式可疑时先请用户确认
+func (m *Manager) confirmChangeKey(apiKey string) {
+	if apiKey == "" {
+		dialog.ShowError(errors.New(i18n.T("error.api_key_required")), m.window)
+		return
+	}
+	provider, err := m.selectedProvider()
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	if err := provider.CheckAPIKeyFormat(apiKey); err != nil {
+		dialog.ShowConfirm(i18n.T("confirm.api_key_title"),
+			i18n.T("confirm.api_key_format", err),
+			func(proceed bool) {
+				if proceed {
+					m.changeAPIKey(provider, apiKey)
+				}
+			}, m.window)
+		return
+	}
+	m.changeAPIKey(provider, apiKey)
+}
+
+// changeAPIKey 在后台删除旧的 K2 配置并用新的 API Key 重新配置和验证，
+// 成功后保存新的 Key，并询问是否重新打开 Claude Code
+func (m *Manager) changeAPIKey(provider installer.Provider, apiKey string) {
+	useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
+	m.installer.DryRun = false
+	updates, err := m.installer.StartChangeAPIKey(provider, apiKey, m.rpmEntry.Text, useSystemConfig)
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+
+	m.changeKeyButton.Disable()
+	m.clearLogs()
+	m.statusLabel.SetText(i18n.T("status.changing_key"))
+
+	go func() {
+		var changeErr error
+		for update := range updates {
+			if update.Error != nil {
+				changeErr = update.Error
+			}
+			m.syncLogs()
+		}
+		m.syncLogs()
+
+		m.updateUI(func() {
+			m.changeKeyButton.Enable()
+			if changeErr != nil {
+				m.statusLabel.SetText(i18n.T("status.change_key_failed"))
+				if !m.showErrorWithRemedy(i18n.T("dialog.change_key_failed"), changeErr, i18n.T("change_key.retry"), m.showChangeKeyDialog) {
+					dialog.ShowError(changeErr, m.window)
+				}
+				return
+			}
+
+			m.apiKeyEntry.SetText(apiKey)
+			m.saveCurrentConfig()
+			m.statusLabel.SetText(i18n.T("status.key_changed"))
+			relaunch := dialog.NewConfirm(i18n.T("dialog.key_changed"), i18n.T("dialog.key_changed_relaunch"),
+				func(open bool) {
+					if open {
+						m.openClaudeCode()
+					}
+				}, m.window)
+			relaunch.SetConfirmText(i18n.T("button.open_claude"))
+			relaunch.SetDismissText(i18n.T("button.close"))
+			relaunch.Show()
+		})
+	}()
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2/dialog"
+)
+
+// updateClaudeCode 在后台把已安装的 Claude Code 更新到最新版本，实时显示进度和日志，
+// 结束后显示新旧版本并重新检测环境
+func (m *Manager) updateClaudeCode() {
+	proxy := strings.TrimSpace(m.proxyEntry.Text)
+	if proxy != "" {
+		if err := installer.ValidateProxyURL(proxy); err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+	}
+	m.installer.Proxy = proxy
+	m.applyNetworkSettings()
+	m.installer.NPMStrategy = m.npmStrategy()
+
+	updates, err := m.installer.StartUpdateClaudeCode()
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+
+	m.updateClaudeButton.Disable()
+	m.installButton.Disable()
+	m.clearLogs()
+	m.progressBar.SetValue(0)
+	m.statusLabel.SetText(i18n.T("status.updating"))
+
+	go func() {
+		var summary string
+		var updateErr error
+		for update := range updates {
+			if update.Error != nil {
+				updateErr = update.Error
+				continue
+			}
+			if update.Step != "日志" {
+				summary = update.Message
+				m.updateUI(func() {
+					if update.Percent >= 0 {
+						m.progressBar.SetValue(update.Percent)
+					}
+					m.statusLabel.SetText(update.Message)
+				})
+			}
+			m.syncLogs()
+		}
+		m.syncLogs()
+
+		m.updateUI(func() {
+			m.updateClaudeButton.Enable()
+			m.installButton.Enable()
+			if updateErr != nil {
+				m.statusLabel.SetText(i18n.T("status.update_failed"))
+				if !m.showErrorWithRemedy(i18n.T("dialog.update_failed"), updateErr, i18n.T("button.retry_update"), m.updateClaudeCode) {
+					dialog.ShowError(updateErr, m.window)
+				}
+				return
+			}
+			m.progressBar.SetValue(1)
+			m.statusLabel.SetText("✅ " + summary)
+			dialog.ShowInformation(i18n.T("dialog.update_done"), summary, m.window)
+			go m.checkEnvironment()
+		})
+	}()
+}
@@ -1,26 +1,122 @@
 package ui
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"claude-k2-installer/internal/installer"
+
+	"github.com/zalando/go-keyring"
+	"gopkg.in/yaml.v3"
 )
 
+// Profile 命名的配置档，保存一组服务商、API Key 和速率限制
+type Profile struct {
+	Name          string `json:"name"`
+	APIKey        string `json:"api_key"`
+	RPM           string `json:"rpm"`
+	Provider      string `json:"provider,omitempty"`
+	CustomBaseURL string `json:"custom_base_url,omitempty"`
+}
+
 type AppConfig struct {
-	APIKey string `json:"api_key"`
-	RPM    string `json:"rpm"`
+	APIKey        string `json:"api_key"`
+	RPM           string `json:"rpm"`
+	Provider      string `json:"provider,omitempty"`
+	CustomBaseURL string `json:"custom_base_url,omitempty"`
+	HighContrast  bool   `json:"high_contrast"`
+	ThemeMode     string `json:"theme_mode,omitempty"` // 外观：light、dark，为空时跟随系统
+	NodeVersion   string `json:"node_version,omitempty"`
+	NPMStrategy   string `json:"npm_strategy,omitempty"`
+	ClaudeVersion string `json:"claude_code_version,omitempty"`
+	OfflineDir    string `json:"offline_dir,omitempty"`  // 离线安装包目录，为空时联网下载
+	SkipNode      bool   `json:"skip_node,omitempty"`    // 跳过 Node.js 的安装步骤
+	SkipGit       bool   `json:"skip_git,omitempty"`     // 跳过 Git 的安装步骤
+	NodeMirror    string `json:"node_mirror,omitempty"`  // 自定义 Node.js 下载镜像，优先于内置镜像
+	NPMRegistry   string `json:"npm_registry,omitempty"` // 安装 Claude Code 使用的 npm 镜像
+	Proxy         string `json:"proxy,omitempty"`        // 下载和 npm 安装使用的 HTTP 代理
+	SlowNetwork   bool   `json:"slow_network,omitempty"` // 慢速网络模式，放宽下载超时
+	ForceIPv4     bool   `json:"force_ipv4,omitempty"`   // 只使用 IPv4 连接
+	ProjectDir    string `json:"project_dir,omitempty"`  // 打开 Claude Code 时进入的项目目录
+	MacTerminal   string `json:"mac_terminal,omitempty"` // 打开 Claude Code 使用的 macOS 终端，为空时自动检测
+	Language      string `json:"language,omitempty"`     // 界面语言，如 zh-CN、en-US，为空时使用默认语言
+
+	// MinNodeVersion 要求的最低 Node.js 主版本，已安装的版本更低时升级，为 0 时使用默认值
+	MinNodeVersion int `json:"min_node_version,omitempty"`
+
+	// GitVersion Windows 上需要安装 Git 时安装的版本；MinGitVersion 要求的最低 Git 版本，为空时使用默认值
+	GitVersion    string `json:"git_version,omitempty"`
+	MinGitVersion string `json:"min_git_version,omitempty"`
+
+	// EnvOnly 仅配置环境变量，不修改 ~/.claude.json
+	EnvOnly bool `json:"env_only,omitempty"`
+
+	// ConfigStrategy 永久环境变量写入 shell 配置文件（shell）、~/.claude/settings.json（settings）或两者（both），为空时为 shell
+	ConfigStrategy string `json:"config_strategy,omitempty"`
+
+	// UIScale 界面缩放比例，为空时为 1.0
+	UIScale float32 `json:"ui_scale,omitempty"`
+
+	// LogPolicy 日志留存与隐私策略，为空表示用户尚未确认过（首次运行）
+	LogPolicy *installer.LogPolicy `json:"log_policy,omitempty"`
+
+	// Profiles 已保存的配置档，LastUsed 为最近使用的配置档名称
+	// 不使用 omitempty：空列表表示用户删光了配置档，缺少该字段才是需要迁移的旧版配置
+	Profiles []Profile `json:"profiles"`
+	LastUsed string    `json:"last_used,omitempty"`
 }
 
 const configFileName = ".claude-k2-installer-config.json"
 
+const (
+	keyringService = "claude-k2-installer"
+	keyringUser    = "api-key"
+	// keyringRef 配置文件中的 API Key 引用，表示真实的 Key 存在系统钥匙串中
+	keyringRef = "keyring:" + keyringService + "/" + keyringUser
+	// keyringProfilePrefix 配置档的 API Key 在钥匙串中的用户名前缀
+	keyringProfilePrefix = "profile:"
+)
+
+// defaultProfileName 从旧版单一配置迁移时使用的配置档名称
+const defaultProfileName = "默认"
+
+// profileKeyringRef 配置档的 API Key 在配置文件中的钥匙串引用
+func profileKeyringRef(name string) string {
+	return "keyring:" + keyringService + "/" + keyringProfilePrefix + name
+}
+
 // SaveConfig 保存配置到本地文件
-func SaveConfig(apiKey, rpm string) error {
-	config := AppConfig{
-		APIKey: apiKey,
-		RPM:    rpm,
+// API Key 优先存入系统钥匙串（macOS Keychain、Windows 凭据管理器、Linux Secret Service），
+// 配置文件中只保存引用；钥匙串不可用时回退到文件存储
+func SaveConfig(config *AppConfig) error {
+	stored := *config
+	if stored.APIKey == "" {
+		// 清空 API Key 时同时删除钥匙串中的 Key，没有保存过时忽略错误
+		keyring.Delete(keyringService, keyringUser)
+	} else if stored.APIKey != keyringRef {
+		if err := keyring.Set(keyringService, keyringUser, stored.APIKey); err == nil {
+			stored.APIKey = keyringRef
+		}
 	}
-	
-	data, err := json.Marshal(config)
+
+	// 复制配置档列表，避免把调用方的 API Key 替换成引用
+	stored.Profiles = make([]Profile, len(config.Profiles))
+	copy(stored.Profiles, config.Profiles)
+	for idx, profile := range stored.Profiles {
+		ref := profileKeyringRef(profile.Name)
+		if profile.APIKey == "" || profile.APIKey == ref {
+			continue
+		}
+		if err := keyring.Set(keyringService, keyringProfilePrefix+profile.Name, profile.APIKey); err == nil {
+			stored.Profiles[idx].APIKey = ref
+		}
+	}
+
+	data, err := json.Marshal(&stored)
 	if err != nil {
 		return err
 	}
@@ -50,10 +146,127 @@ func LoadConfig() (*AppConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	// 从系统钥匙串读取 API Key，读取失败时留空让用户重新输入
+	if config.APIKey == keyringRef {
+		apiKey, err := keyring.Get(keyringService, keyringUser)
+		if err != nil {
+			apiKey = ""
+		}
+		config.APIKey = apiKey
+	}
+
+	for idx, profile := range config.Profiles {
+		if profile.APIKey != profileKeyringRef(profile.Name) {
+			continue
+		}
+		apiKey, err := keyring.Get(keyringService, keyringProfilePrefix+profile.Name)
+		if err != nil {
+			apiKey = ""
+		}
+		config.Profiles[idx].APIKey = apiKey
+	}
+
+	// 旧版只保存一组 API Key 和 RPM，自动迁移为「默认」配置档
+	if config.Profiles == nil && config.APIKey != "" {
+		config.Profiles = []Profile{{
+			Name:          defaultProfileName,
+			APIKey:        config.APIKey,
+			RPM:           config.RPM,
+			Provider:      config.Provider,
+			CustomBaseURL: config.CustomBaseURL,
+		}}
+		config.LastUsed = defaultProfileName
+	}
 	
 	return &config, nil
 }
 
+// LoadConfigFile 读取管理员分发的配置文件（JSON 或 YAML，按扩展名 .yaml/.yml 区分），
+// 字段名与保存的配置相同。不读取系统钥匙串，出现未知字段时报错，避免拼写错误的设置被悄悄忽略
+func LoadConfigFile(path string) (*AppConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	// YAML 先转换为 JSON，与 JSON 配置共用字段名和校验
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var values map[string]interface{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("配置文件 %s 不是有效的 YAML: %v", path, err)
+		}
+		if data, err = json.Marshal(values); err != nil {
+			return nil, fmt.Errorf("配置文件 %s 格式不正确: %v", path, err)
+		}
+	}
+
+	var config AppConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("配置文件 %s 格式不正确: %v", path, err)
+	}
+	return &config, nil
+}
+
+// LoadProfiles 返回已保存的配置档和最近使用的配置档名称
+func LoadProfiles() ([]Profile, string, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	return config.Profiles, config.LastUsed, nil
+}
+
+// SaveProfile 保存配置档，同名时覆盖，并记为最近使用
+func SaveProfile(profile Profile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("配置档名称不能为空")
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		config = &AppConfig{}
+	}
+
+	replaced := false
+	for idx := range config.Profiles {
+		if config.Profiles[idx].Name == profile.Name {
+			config.Profiles[idx] = profile
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		config.Profiles = append(config.Profiles, profile)
+	}
+	config.LastUsed = profile.Name
+	return SaveConfig(config)
+}
+
+// DeleteProfile 删除配置档及其在钥匙串中的 API Key
+func DeleteProfile(name string) error {
+	config, err := LoadConfig()
+	if err != nil {
+		return err
+	}
+
+	profiles := config.Profiles[:0]
+	for _, profile := range config.Profiles {
+		if profile.Name != name {
+			profiles = append(profiles, profile)
+		}
+	}
+	config.Profiles = profiles
+	if config.LastUsed == name {
+		config.LastUsed = ""
+	}
+	keyring.Delete(keyringService, keyringProfilePrefix+name)
+	return SaveConfig(config)
+}
+
 // getConfigPath 获取配置文件路径
 func getConfigPath() (string, error) {
 	home, err := os.UserHomeDir()
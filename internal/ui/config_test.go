@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestSaveConfigClearsKeyringAPIKey(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", t.TempDir())
+
+	if err := SaveConfig(&AppConfig{APIKey: "sk-test-key-0123456789"}); err != nil {
+		t.Fatalf("SaveConfig: %v", err)
+	}
+	if got, err := keyring.Get(keyringService, keyringUser); err != nil || got != "sk-test-key-0123456789" {
+		t.Fatalf("expected the API key in the keyring, got %q, %v", got, err)
+	}
+
+	if err := SaveConfig(&AppConfig{}); err != nil {
+		t.Fatalf("SaveConfig with an empty key: %v", err)
+	}
+	if _, err := keyring.Get(keyringService, keyringUser); !errors.Is(err, keyring.ErrNotFound) {
+		t.Errorf("expected the keyring entry to be deleted, got %v", err)
+	}
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if config.APIKey != "" {
+		t.Errorf("expected an empty API key after clearing, got %q", config.APIKey)
+	}
+}
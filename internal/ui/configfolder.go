@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// revealInFileManager 在文件管理器中显示文件，文件不存在时打开所在目录
+func revealInFileManager(path string) error {
+	var cmd *exec.Cmd
+	_, statErr := os.Stat(path)
+	exists := statErr == nil
+
+	switch runtime.GOOS {
+	case "windows":
+		if exists {
+			cmd = exec.Command("explorer", "/select,"+path)
+		} else {
+			cmd = exec.Command("explorer", filepath.Dir(path))
+		}
+	case "darwin":
+		if exists {
+			cmd = exec.Command("open", "-R", path)
+		} else {
+			cmd = exec.Command("open", filepath.Dir(path))
+		}
+	default: // linux，xdg-open 不支持选中文件，打开所在目录
+		cmd = exec.Command("xdg-open", filepath.Dir(path))
+	}
+	return cmd.Start()
+}
+
+// openConfigFolder 在文件管理器中显示 ~/.claude.json，找不到时打开用户目录
+func (m *Manager) openConfigFolder() {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		dialog.ShowError(errors.New(i18n.T("error.home_dir", err)), m.window)
+		return
+	}
+
+	claudeJsonPath := filepath.Join(home, ".claude.json")
+	if err := revealInFileManager(claudeJsonPath); err != nil {
+		m.window.Clipboard().SetContent(home)
+		dialog.ShowInformation(i18n.T("config_folder.copied_title"),
+			i18n.T("config_folder.copied", home), m.window)
+	}
+}
+
+// createConfigPathsCard 创建配置文件路径信息区，配置完成后才显示
+func (m *Manager) createConfigPathsCard() fyne.CanvasObject {
+	m.configPathsLabel = widget.NewLabel("")
+	m.configPathsLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	m.configPathsLabel.Wrapping = fyne.TextWrapBreak
+
+	m.configPathsCard = container.NewVBox(
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("config_folder.files")),
+		m.configPathsLabel,
+	)
+	m.configPathsCard.Hide()
+	return m.configPathsCard
+}
+
+// showConfigPaths 显示本次配置写入的文件路径，须在主线程中调用
+func (m *Manager) showConfigPaths() {
+	if m.configPathsCard == nil {
+		return
+	}
+
+	useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
+	var lines []string
+	for _, file := range m.installer.ConfigFiles(useSystemConfig) {
+		lines = append(lines, fmt.Sprintf("%s: %s", file.Label, file.Path))
+	}
+	m.configPathsLabel.SetText(strings.Join(lines, "\n"))
+	m.configPathsCard.Show()
+}
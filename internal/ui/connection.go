@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"errors"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2/dialog"
+)
+
+// kimiChargeURL Kimi 充值页面，充值额度决定速率限制
+const kimiChargeURL = "https://platform.moonshot.cn/console/pay"
+
+// kimiAPIKeyURL Kimi 的 API Key 管理页面
+const kimiAPIKeyURL = "https://platform.moonshot.cn/console/api-keys"
+
+// testConnection 用当前填写的服务商和 API Key 运行一次 claude，报告链路是否可用
+func (m *Manager) testConnection() {
+	apiKey := installer.NormalizeAPIKey(m.apiKeyEntry.Text)
+	if apiKey == "" {
+		dialog.ShowError(errors.New(i18n.T("error.api_key_empty")), m.window)
+		return
+	}
+	provider, err := m.selectedProvider()
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+
+	m.testButton.Disable()
+	m.statusLabel.SetText(i18n.T("connection.testing"))
+
+	go func() {
+		err := m.installer.TestConnection(provider, apiKey)
+		m.syncLogs()
+
+		m.updateUI(func() {
+			m.testButton.Enable()
+
+			switch {
+			case errors.Is(err, installer.ErrRateLimited):
+				m.statusLabel.SetText(i18n.T("connection.rate_limited"))
+				m.showErrorWithRemedy(i18n.T("connection.rate_limited_title"), err, "", nil)
+			case err != nil:
+				m.statusLabel.SetText(i18n.T("connection.failed"))
+				// API Key 无效、余额不足等已知错误给出对应的处理按钮
+				if !m.showErrorWithRemedy(i18n.T("connection.failed_title"), err, i18n.T("button.retest"), m.testConnection) {
+					dialog.ShowError(err, m.window)
+				}
+			default:
+				m.statusLabel.SetText(i18n.T("connection.success"))
+				dialog.ShowInformation(i18n.T("connection.success_title"),
+					i18n.T("connection.success_message", provider.Name),
+					m.window)
+			}
+		})
+	}()
+}
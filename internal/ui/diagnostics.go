@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// exportDiagnostics 让用户选择保存位置，生成 JSON 格式的诊断报告，反馈问题时附上即可看到完整的环境状态
+func (m *Manager) exportDiagnostics() {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if writer == nil {
+			// 用户取消
+			return
+		}
+
+		// 收集诊断信息需要执行命令检测版本，放到后台避免界面卡顿
+		go func() {
+			data, writeErr := m.installer.Diagnostics().JSON()
+			if writeErr == nil {
+				_, writeErr = writer.Write(data)
+			}
+			if closeErr := writer.Close(); writeErr == nil {
+				writeErr = closeErr
+			}
+
+			m.updateUI(func() {
+				if writeErr != nil {
+					dialog.ShowError(errors.New(i18n.T("error.diagnostics", writeErr)), m.window)
+					return
+				}
+				dialog.ShowInformation(i18n.T("dialog.diagnostics_saved_title"),
+					i18n.T("dialog.diagnostics_saved", writer.URI().Path()), m.window)
+			})
+		}()
+	}, m.window)
+
+	saveDialog.SetFileName(fmt.Sprintf("claude-k2-diagnostics-%s.json", time.Now().Format("20060102-150405")))
+	if desktop := desktopDir(); desktop != "" {
+		if lister, err := storage.ListerForURI(storage.NewFileURI(desktop)); err == nil {
+			saveDialog.SetLocation(lister)
+		}
+	}
+	saveDialog.Show()
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"errors"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createCacheRow 高级选项中的下载缓存大小和清除按钮
+func (m *Manager) createCacheRow() fyne.CanvasObject {
+	m.cacheLabel = widget.NewLabel("")
+	clearButton := widget.NewButton(i18n.T("button.clear_cache"), m.confirmClearCache)
+	go m.refreshCacheSize()
+	return container.NewBorder(nil, nil, nil, clearButton, m.cacheLabel)
+}
+
+// refreshCacheSize 在后台统计下载缓存的大小并更新显示
+func (m *Manager) refreshCacheSize() {
+	size, err := installer.DownloadCacheSize()
+	m.updateUI(func() {
+		if err != nil {
+			m.cacheLabel.SetText(i18n.T("cache.size_unknown"))
+			return
+		}
+		m.cacheLabel.SetText(i18n.T("cache.size", float64(size)/1024/1024))
+	})
+}
+
+// confirmClearCache 确认后删除缓存的安装包
+func (m *Manager) confirmClearCache() {
+	dialog.ShowConfirm(i18n.T("button.clear_cache"), i18n.T("cache.confirm"), func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := installer.ClearDownloadCache(); err != nil {
+			dialog.ShowError(errors.New(i18n.T("error.clear_cache", err)), m.window)
+		}
+		go m.refreshCacheSize()
+	}, m.window)
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// checkElevation 安装前检查即将执行的步骤是否需要管理员权限，当前进程没有权限时提醒用户
+// Windows 上可以直接以管理员身份重新启动；无需提权时直接调用 proceed
+// 检测环境需要执行命令，放到后台避免界面卡顿，检测期间禁用安装按钮防止重复点击
+func (m *Manager) checkElevation(proceed func()) {
+	m.installButton.Disable()
+	go func() {
+		// 跳过的组件不会安装，也就不需要提权
+		status := m.installer.CheckEnvironment()
+		status.NodeOK = status.NodeOK || m.installer.SkipNode
+		status.GitOK = status.GitOK || m.installer.SkipGit
+		needs := installer.ElevationNeeds(status)
+
+		m.updateUI(func() {
+			m.installButton.Enable()
+			if len(needs) == 0 {
+				proceed()
+				return
+			}
+			m.showElevationDialog(needs, proceed)
+		})
+	}()
+}
+
+// showElevationDialog 列出需要管理员权限的步骤，由用户选择继续、取消或以管理员身份重新启动
+func (m *Manager) showElevationDialog(needs []string, proceed func()) {
+	hint := i18n.T("elevation.hint")
+	if installer.CanRelaunchElevated() {
+		hint = i18n.T("elevation.hint_windows")
+	}
+	hintLabel := widget.NewLabel(hint)
+	hintLabel.Wrapping = fyne.TextWrapWord
+	needsLabel := widget.NewLabel("• " + strings.Join(needs, "\n• "))
+	needsLabel.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(hintLabel, needsLabel)
+
+	var elevationDialog *dialog.CustomDialog
+	continueButton := widget.NewButton(i18n.T("elevation.continue"), func() {
+		elevationDialog.Hide()
+		proceed()
+	})
+	cancelButton := widget.NewButton(i18n.T("button.cancel"), func() {
+		elevationDialog.Hide()
+	})
+	buttons := []fyne.CanvasObject{cancelButton, continueButton}
+
+	if installer.CanRelaunchElevated() {
+		relaunchButton := widget.NewButton(i18n.T("elevation.relaunch"), func() {
+			elevationDialog.Hide()
+			m.relaunchElevated()
+		})
+		relaunchButton.Importance = widget.HighImportance
+		buttons = append(buttons, relaunchButton)
+	} else {
+		continueButton.Importance = widget.HighImportance
+	}
+
+	elevationDialog = dialog.NewCustomWithoutButtons(i18n.T("elevation.title"), content, m.window)
+	elevationDialog.SetButtons(buttons)
+	elevationDialog.Resize(fyne.NewSize(560, 0))
+	elevationDialog.Show()
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// checkEnvConflicts 安装前检查已有的 ANTHROPIC_* 定义，有冲突时让用户选择先清理、忽略或取消
+// 没有冲突时直接调用 proceed
+func (m *Manager) checkEnvConflicts(provider installer.Provider, proceed func()) {
+	conflicts := m.installer.DetectEnvConflicts(provider)
+	if len(conflicts) == 0 {
+		proceed()
+		return
+	}
+
+	var lines []string
+	for _, conflict := range conflicts {
+		lines = append(lines, "• "+conflict.String())
+	}
+
+	hintLabel := widget.NewLabel(i18n.T("env_conflict.hint"))
+	hintLabel.Wrapping = fyne.TextWrapWord
+
+	listLabel := widget.NewLabel(strings.Join(lines, "\n"))
+	listLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	listScroll := container.NewScroll(listLabel)
+	listScroll.SetMinSize(fyne.NewSize(600, 160))
+
+	var conflictDialog *dialog.CustomDialog
+	cleanButton := widget.NewButton(i18n.T("env_conflict.clean"), func() {
+		conflictDialog.Hide()
+		if err := m.installer.ResolveEnvConflicts(conflicts); err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		proceed()
+	})
+	cleanButton.Importance = widget.HighImportance
+	ignoreButton := widget.NewButton(i18n.T("env_conflict.ignore"), func() {
+		conflictDialog.Hide()
+		proceed()
+	})
+	cancelButton := widget.NewButton(i18n.T("button.cancel"), func() {
+		conflictDialog.Hide()
+	})
+
+	content := container.NewBorder(hintLabel, nil, nil, nil, listScroll)
+	conflictDialog = dialog.NewCustomWithoutButtons(i18n.T("env_conflict.title"), content, m.window)
+	conflictDialog.SetButtons([]fyne.CanvasObject{cancelButton, ignoreButton, cleanButton})
+	conflictDialog.Resize(fyne.NewSize(680, 0))
+	conflictDialog.Show()
+}
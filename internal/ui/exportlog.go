@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// exportLogFileName 导出日志的默认文件名
+func exportLogFileName() string {
+	return fmt.Sprintf("claude-k2-install-%s.log", time.Now().Format("20060102-150405"))
+}
+
+// desktopDir 返回桌面目录，不存在时返回空字符串
+func desktopDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	desktop := filepath.Join(home, "Desktop")
+	if info, err := os.Stat(desktop); err != nil || !info.IsDir() {
+		return ""
+	}
+	return desktop
+}
+
+// exportLogs 让用户选择保存位置，导出环境信息和完整安装日志
+func (m *Manager) exportLogs() {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if writer == nil {
+			// 用户取消
+			return
+		}
+
+		// 生成报告需要执行命令检测版本，放到后台避免界面卡顿
+		go func() {
+			report := m.installer.DiagnosticReport()
+			_, writeErr := writer.Write([]byte(report))
+			if closeErr := writer.Close(); writeErr == nil {
+				writeErr = closeErr
+			}
+			// 有安装报告时以 JSON 保存在日志旁边
+			saved := writer.URI().Path()
+			if writeErr == nil {
+				if reportPath, err := m.exportInstallReport(saved); err != nil {
+					writeErr = err
+				} else if reportPath != "" {
+					saved += "\n" + reportPath
+				}
+			}
+
+			m.updateUI(func() {
+				if writeErr != nil {
+					dialog.ShowError(errors.New(i18n.T("error.export_log", writeErr)), m.window)
+					return
+				}
+				dialog.ShowInformation(i18n.T("export_log.done_title"),
+					i18n.T("export_log.done", saved),
+					m.window)
+			})
+		}()
+	}, m.window)
+
+	saveDialog.SetFileName(exportLogFileName())
+	if desktop := desktopDir(); desktop != "" {
+		if lister, err := storage.ListerForURI(storage.NewFileURI(desktop)); err == nil {
+			saveDialog.SetLocation(lister)
+		}
+	}
+	saveDialog.Show()
+}
+
+// exportInstallReport 把最近一次的安装报告以 JSON 保存在导出的日志旁边，返回保存的路径，
+// 还没有安装报告时返回空字符串
+func (m *Manager) exportInstallReport(logPath string) (string, error) {
+	report := m.installer.LastReport()
+	if report == nil {
+		return "", nil
+	}
+	data, err := report.JSON()
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSuffix(logPath, filepath.Ext(logPath)) + "-report.json"
+	return path, os.WriteFile(path, data, 0600)
+}
+
+// copyLogs 把带版本和系统信息的完整日志复制到剪贴板，方便通过微信等直接粘贴分享
+func (m *Manager) copyLogs() {
+	m.window.Clipboard().SetContent(m.installer.LogsWithHeader())
+	dialog.ShowInformation(i18n.T("log.copied_title"), i18n.T("log.copied"), m.window)
+}
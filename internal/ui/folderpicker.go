@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"os"
+
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// chooseFolder 选择目录并填入 entry，从已填写的目录开始浏览；选择后调用 onChosen（可为 nil）
+func (m *Manager) chooseFolder(entry *widget.Entry, onChosen func(dir string)) {
+	folderDialog := dialog.NewFolderOpen(func(dir fyne.ListableURI, err error) {
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if dir == nil {
+			// 用户取消
+			return
+		}
+		entry.SetText(dir.Path())
+		if onChosen != nil {
+			onChosen(dir.Path())
+		}
+	}, m.window)
+
+	if current := entry.Text; current != "" {
+		if info, err := os.Stat(current); err == nil && info.IsDir() {
+			if lister, err := storage.ListerForURI(storage.NewFileURI(current)); err == nil {
+				folderDialog.SetLocation(lister)
+			}
+		}
+	}
+	folderDialog.SetConfirmText(i18n.T("button.ok"))
+	folderDialog.Show()
+}
@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/png"
+
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// 图片查看器的缩放范围和每次缩放的倍数
+const (
+	minImageZoom  = 0.25
+	maxImageZoom  = 4
+	imageZoomStep = 1.25
+)
+
+// zoomableImage 可缩放和拖动平移的图片查看器：适应窗口时完整显示图片，
+// 否则按缩放比例显示在滚动区域中，拖动图片即可平移
+type zoomableImage struct {
+	width, height float32 // 100% 时的尺寸，图片的 1 像素对应 1 个界面单位
+	scale         float32
+	fit           bool
+
+	fitImage  *canvas.Image // 适应窗口时显示
+	zoomImage *canvas.Image // 缩放时显示在滚动区域中
+	scroll    *container.Scroll
+	body      *fyne.Container
+	zoomLabel *widget.Label
+	fitCheck  *widget.Check
+}
+
+// newZoomableImage 创建图片查看器，默认适应窗口，图片区域至少为 minSize
+func newZoomableImage(resource fyne.Resource, minSize fyne.Size) *zoomableImage {
+	z := &zoomableImage{scale: 1, fit: true}
+	if config, _, err := image.DecodeConfig(bytes.NewReader(resource.Content())); err == nil {
+		z.width, z.height = float32(config.Width), float32(config.Height)
+	}
+
+	z.fitImage = canvas.NewImageFromResource(resource)
+	z.fitImage.FillMode = canvas.ImageFillContain
+	z.fitImage.SetMinSize(minSize)
+
+	z.zoomImage = canvas.NewImageFromResource(resource)
+	z.zoomImage.FillMode = canvas.ImageFillContain
+	pannable := &pannableImage{image: z.zoomImage}
+	pannable.ExtendBaseWidget(pannable)
+	z.scroll = container.NewScroll(pannable)
+	z.scroll.SetMinSize(minSize)
+	pannable.scroll = z.scroll
+
+	z.body = container.NewStack(z.fitImage)
+	z.zoomLabel = widget.NewLabel("")
+	z.fitCheck = widget.NewCheck(i18n.T("tutorial.zoom_fit"), z.setFit)
+	z.fitCheck.SetChecked(true)
+	return z
+}
+
+// content 返回缩放按钮和图片区域
+func (z *zoomableImage) content() fyne.CanvasObject {
+	zoomOut := widget.NewButton("−", func() { z.zoomBy(1 / imageZoomStep) })
+	zoomIn := widget.NewButton("+", func() { z.zoomBy(imageZoomStep) })
+	actualSize := widget.NewButton("100%", func() { z.setScale(1) })
+	toolbar := container.NewCenter(container.NewHBox(zoomOut, z.zoomLabel, zoomIn, actualSize, z.fitCheck))
+	return container.NewBorder(toolbar, nil, nil, nil, z.body)
+}
+
+// zoomBy 按倍数缩放；适应窗口时从当前显示的比例开始缩放
+func (z *zoomableImage) zoomBy(factor float32) {
+	scale := z.scale
+	if z.fit {
+		scale = z.fitScale()
+	}
+	z.setScale(scale * factor)
+}
+
+// setScale 按指定比例显示图片，同时退出适应窗口
+func (z *zoomableImage) setScale(scale float32) {
+	if scale < minImageZoom {
+		scale = minImageZoom
+	}
+	if scale > maxImageZoom {
+		scale = maxImageZoom
+	}
+	z.scale = scale
+	if z.fit {
+		// 取消勾选会回调 setFit(false) 并刷新显示
+		z.fitCheck.SetChecked(false)
+		return
+	}
+	z.refresh()
+}
+
+// setFit 切换适应窗口
+func (z *zoomableImage) setFit(fit bool) {
+	z.fit = fit
+	z.refresh()
+}
+
+// fitScale 适应窗口时图片实际显示的比例
+func (z *zoomableImage) fitScale() float32 {
+	size := z.body.Size()
+	if z.width == 0 || z.height == 0 || size.Width == 0 || size.Height == 0 {
+		return 1
+	}
+	scale := size.Width / z.width
+	if s := size.Height / z.height; s < scale {
+		scale = s
+	}
+	return scale
+}
+
+// refresh 按当前模式和比例更新显示
+func (z *zoomableImage) refresh() {
+	if z.fit {
+		// 适应窗口时比例随窗口变化，由勾选框表示
+		z.zoomLabel.SetText("")
+		z.body.Objects = []fyne.CanvasObject{z.fitImage}
+		z.body.Refresh()
+		return
+	}
+
+	z.zoomLabel.SetText(fmt.Sprintf("%d%%", int(z.scale*100+0.5)))
+	z.zoomImage.SetMinSize(fyne.NewSize(z.width*z.scale, z.height*z.scale))
+	z.body.Objects = []fyne.CanvasObject{z.scroll}
+	z.body.Refresh()
+	z.scroll.Refresh()
+}
+
+// pannableImage 显示在滚动区域中的图片，拖动图片时平移滚动区域
+type pannableImage struct {
+	widget.BaseWidget
+	image  *canvas.Image
+	scroll *container.Scroll
+}
+
+func (p *pannableImage) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(p.image)
+}
+
+func (p *pannableImage) Dragged(event *fyne.DragEvent) {
+	// 超出范围的偏移由滚动区域限制
+	p.scroll.ScrollToOffset(p.scroll.Offset.Subtract(event.Dragged))
+}
+
+func (p *pannableImage) DragEnd() {}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+)
+
+// showInstalledState 上次已成功安装且组件仍在时，显示已安装状态和记录的版本，
+// 主按钮改为打开 Claude Code，并提供重新安装的选项，须在主线程中调用
+func (m *Manager) showInstalledState(envText string, record installer.InstallRecord) {
+	recorded := func(version string) string {
+		if version == "" {
+			return i18n.T("env.not_installed")
+		}
+		return version
+	}
+	m.envLabel.SetText(envText + "\n" + i18n.T("env.last_install",
+		record.CompletedAt.Local().Format("2006-01-02 15:04"), record.Provider,
+		recorded(record.NodeVersion), recorded(record.GitVersion), recorded(record.ClaudeVersion)))
+
+	m.installButton.Hide()
+	m.openButton.Show()
+	m.reinstallButton.Show()
+	m.statusLabel.SetText(i18n.T("status.installed"))
+	m.progressBar.SetValue(1)
+	for index := range displaySteps {
+		if m.stepStatuses[index] != stepSkipped {
+			m.setStepStatus(index, stepDone)
+		}
+	}
+}
+
+// reinstall 离开已安装状态，恢复为完整安装：已安装的组件仍会检测，Claude Code 和 API 配置重新安装
+func (m *Manager) reinstall() {
+	m.envReady = false
+	m.installButton.SetText(i18n.T("button.install"))
+	m.installButton.Show()
+	m.openButton.Hide()
+	m.reinstallButton.Hide()
+	m.progressBar.SetValue(0)
+	m.statusLabel.SetText("")
+	m.resetStepStatuses()
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// reportSummary 完成对话框中可展开的安装报告：各组件结果、写入的配置文件、设置的环境变量和警告
+func (m *Manager) reportSummary(report *installer.InstallReport) fyne.CanvasObject {
+	icons := map[string]string{
+		installer.ComponentOK:      "✅",
+		installer.ComponentFailed:  "❌",
+		installer.ComponentSkipped: "⏭️",
+	}
+
+	var lines []string
+	if report.Error != "" {
+		lines = append(lines, i18n.T("report.error", report.Error), "")
+	}
+	lines = append(lines, i18n.T("report.components"))
+	for _, component := range report.Components {
+		status := i18n.T("report." + component.Status)
+		lines = append(lines, fmt.Sprintf("  %s %s %s (%s)", icons[component.Status], component.Name, component.Version, status))
+	}
+
+	if len(report.ConfigFiles) > 0 {
+		lines = append(lines, "", i18n.T("report.config_files"))
+		for _, file := range report.ConfigFiles {
+			lines = append(lines, fmt.Sprintf("  %s: %s", file.Label, file.Path))
+		}
+	}
+
+	if len(report.EnvVars) > 0 {
+		scope := i18n.T("report.temporary")
+		if report.Permanent {
+			scope = i18n.T("report.permanent")
+		}
+		lines = append(lines, "", i18n.T("report.env_vars", scope))
+		names := make([]string, 0, len(report.EnvVars))
+		for name := range report.EnvVars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			lines = append(lines, fmt.Sprintf("  %s=%s", name, report.EnvVars[name]))
+		}
+	}
+
+	if len(report.Warnings) > 0 {
+		lines = append(lines, "", i18n.T("report.warnings", len(report.Warnings)))
+		for _, warning := range report.Warnings {
+			lines = append(lines, "  "+warning)
+		}
+	}
+
+	detail := widget.NewLabel(strings.Join(lines, "\n"))
+	detail.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(detail)
+	scroll.SetMinSize(fyne.NewSize(480, 180))
+	exportButton := widget.NewButton(i18n.T("report.export"), m.exportLogs)
+
+	return widget.NewAccordion(widget.NewAccordionItem(
+		i18n.T("report.title", report.Duration),
+		container.NewBorder(nil, container.NewHBox(exportButton), nil, nil, scroll),
+	))
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ApplyLocale 设置界面语言：环境变量 CLAUDE_K2_LANG 优先，其次是界面中保存的选择
+func ApplyLocale() i18n.Locale {
+	if locale, ok := i18n.FromEnv(); ok {
+		i18n.SetLocale(locale)
+		return locale
+	}
+	if config, err := LoadConfig(); err == nil {
+		if locale, ok := i18n.Parse(config.Language); ok {
+			i18n.SetLocale(locale)
+		}
+	}
+	return i18n.Current()
+}
+
+// createLanguageRow 创建语言选择行，切换后重新启动程序生效
+func (m *Manager) createLanguageRow() fyne.CanvasObject {
+	var names []string
+	for _, locale := range i18n.Locales {
+		names = append(names, locale.DisplayName())
+	}
+
+	languageSelect := widget.NewSelect(names, nil)
+	languageSelect.SetSelected(i18n.Current().DisplayName())
+	languageSelect.OnChanged = func(name string) {
+		for _, locale := range i18n.Locales {
+			if locale.DisplayName() != name {
+				continue
+			}
+			config := m.loadConfigOrDefault()
+			if config.Language == string(locale) {
+				return
+			}
+			config.Language = string(locale)
+			SaveConfig(config)
+			dialog.ShowInformation(name, i18n.T("language.restart"), m.window)
+			return
+		}
+	}
+
+	return container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.language")), nil, languageSelect)
+}
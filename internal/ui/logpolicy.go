@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// logRetentionOptions 日志留存选项，顺序即界面显示顺序；label 为翻译键
+var logRetentionOptions = []struct {
+	retention installer.LogRetention
+	label     string
+}{
+	{installer.LogRetentionOff, "log_policy.off"},
+	{installer.LogRetentionSession, "log_policy.session"},
+	{installer.LogRetentionDays, "log_policy.days"},
+	{installer.LogRetentionForever, "log_policy.forever"},
+}
+
+// applyLogPolicy 读取保存的日志策略并应用到安装器，首次运行时提示用户确认
+func (m *Manager) applyLogPolicy() {
+	config := m.loadConfigOrDefault()
+	if config.LogPolicy != nil {
+		m.installer.SetLogPolicy(*config.LogPolicy)
+		return
+	}
+
+	m.installer.SetLogPolicy(installer.DefaultLogPolicy())
+
+	// 等待主界面显示后再弹出首次运行提示
+	time.AfterFunc(300*time.Millisecond, func() {
+		m.updateUI(func() {
+			m.showLogPolicyDialog(true)
+		})
+	})
+}
+
+// saveLogPolicy 保存并应用日志策略
+func (m *Manager) saveLogPolicy(policy installer.LogPolicy) {
+	m.installer.SetLogPolicy(policy)
+
+	config := m.loadConfigOrDefault()
+	config.LogPolicy = &policy
+	SaveConfig(config)
+}
+
+// showLogPolicyDialog 显示日志留存与隐私设置；首次运行时关闭对话框即采用默认策略
+func (m *Manager) showLogPolicyDialog(firstRun bool) {
+	current := m.installer.LogPolicy()
+
+	var labels []string
+	for _, option := range logRetentionOptions {
+		labels = append(labels, i18n.T(option.label))
+	}
+	retentionRadio := widget.NewRadioGroup(labels, nil)
+
+	daysEntry := widget.NewEntry()
+	daysEntry.SetText(strconv.Itoa(installer.DefaultLogRetentionDays))
+	if current.Retention == installer.LogRetentionDays && current.Days > 0 {
+		daysEntry.SetText(strconv.Itoa(current.Days))
+	}
+
+	retentionRadio.OnChanged = func(selected string) {
+		if selected == i18n.T(logRetentionOptions[2].label) {
+			daysEntry.Enable()
+		} else {
+			daysEntry.Disable()
+		}
+	}
+	for _, option := range logRetentionOptions {
+		if option.retention == current.Retention {
+			retentionRadio.SetSelected(i18n.T(option.label))
+		}
+	}
+
+	fullArgsCheck := widget.NewCheck(i18n.T("log_policy.full_args"), nil)
+	fullArgsCheck.SetChecked(current.RecordFullArgs)
+
+	intro := i18n.T("log_policy.intro")
+	if firstRun {
+		intro = i18n.T("log_policy.first_run",
+			intro, installer.DefaultLogPolicy().Describe())
+	}
+	introLabel := widget.NewLabel(intro)
+	introLabel.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(
+		introLabel,
+		widget.NewSeparator(),
+		widget.NewLabel(i18n.T("log_policy.retention")),
+		retentionRadio,
+		container.NewBorder(nil, nil, widget.NewLabel(i18n.T("log_policy.days_label")), nil, daysEntry),
+		widget.NewSeparator(),
+		fullArgsCheck,
+	)
+
+	dismiss := i18n.T("button.cancel")
+	if firstRun {
+		dismiss = i18n.T("button.use_default")
+	}
+
+	policyDialog := dialog.NewCustomConfirm(i18n.T("button.log_policy"), i18n.T("button.save"), dismiss, content, func(confirmed bool) {
+		if !confirmed {
+			// 首次运行时记录用户已知悉默认策略，不再重复提示
+			if firstRun {
+				m.saveLogPolicy(installer.DefaultLogPolicy())
+			}
+			return
+		}
+
+		policy := installer.LogPolicy{RecordFullArgs: fullArgsCheck.Checked}
+		for _, option := range logRetentionOptions {
+			if i18n.T(option.label) == retentionRadio.Selected {
+				policy.Retention = option.retention
+			}
+		}
+		if policy.Retention == installer.LogRetentionDays {
+			days, err := strconv.Atoi(daysEntry.Text)
+			if err != nil || days <= 0 {
+				dialog.ShowError(errors.New(i18n.T("error.log_days")), m.window)
+				return
+			}
+			policy.Days = days
+		}
+
+		m.saveLogPolicy(policy)
+		m.addLog(i18n.T("log_policy.updated", policy.Describe()))
+	}, m.window)
+	policyDialog.Resize(fyne.NewSize(480, 420))
+	policyDialog.Show()
+}
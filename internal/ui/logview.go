@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+)
+
+// logBottomTolerance 距底部不超过该距离时视为停在底部，避免滚动取整误差导致停止跟随
+const logBottomTolerance = 4
+
+// newLogView 创建日志显示区的滚动容器
+//
+// 日志不折行、由外层滚动容器负责滚动，这样才能读取和控制滚动位置：
+// 停在底部时新日志到来自动滚动到最新一行，用户向上翻看时暂停跟随，滚回底部后恢复。
+func (m *Manager) newLogView() *container.Scroll {
+	m.logsDisplay.Wrapping = fyne.TextWrapOff
+	m.logsDisplay.Scroll = container.ScrollNone
+
+	scroll := container.NewScroll(m.logsDisplay)
+	m.logFollow = true
+	scroll.OnScrolled = func(fyne.Position) {
+		m.logFollow = logAtBottom(scroll)
+	}
+	return scroll
+}
+
+// logAtBottom 判断滚动容器是否停在底部，内容不足一屏时也视为在底部
+func logAtBottom(scroll *container.Scroll) bool {
+	maxOffset := scroll.Content.MinSize().Height - scroll.Size().Height
+	return scroll.Offset.Y >= maxOffset-logBottomTolerance
+}
+
+// followLogs 停在底部时滚动到最新一行，须在主线程中调用
+func (m *Manager) followLogs() {
+	if m.logScroll != nil && m.logFollow {
+		m.logScroll.ScrollToBottom()
+	}
+}
+
+// clearLogs 清空日志显示区，只显示之后产生的日志，并恢复跟随最新日志，须在主线程中调用
+func (m *Manager) clearLogs() {
+	m.logsDisplay.SetText("")
+	m.logSeq = m.installer.LogCount()
+	m.logLines = 0
+	m.logFollow = true
+}
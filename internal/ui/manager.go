@@ -1,15 +1,20 @@
 package ui
 
 import (
+	"claude-k2-installer/internal/i18n"
 	"claude-k2-installer/internal/installer"
+	"claude-k2-installer/internal/updater"
+	"errors"
 	"fmt"
 	"image/color"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -17,6 +22,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -25,57 +31,378 @@ type Manager struct {
 	installer *installer.Installer
 
 	// UI 组件
-	progressBar       *widget.ProgressBar
-	statusLabel       *widget.Label
-	logsDisplay       *widget.Entry
-	installButton     *widget.Button
-	apiKeyEntry       *widget.Entry
-	rpmEntry          *widget.Entry
-	tutorialButton    *widget.Button
-	openButton        *widget.Button
-	systemConfigCheck *widget.Check
+	progressBar        *widget.ProgressBar
+	statusLabel        *widget.Label
+	progressDetail     *widget.Label // 进度条下方的详情，如下载速度
+	logsDisplay        *widget.Entry
+	installButton      *widget.Button
+	apiKeyEntry        *widget.Entry
+	rpmEntry           *widget.Entry
+	rpmHint            *widget.Label // 速率限制对应的请求间隔和充值档位提醒
+	providerSelect     *widget.Select
+	profileSelect      *widget.Select
+	baseURLEntry       *widget.Entry
+	nodeVersionEntry   *widget.Entry
+	npmSudoCheck       *widget.Check
+	claudeVersionEntry *widget.Entry
+	offlineDirEntry    *widget.Entry
+	projectDirEntry    *widget.Entry
+	proxyEntry         *widget.Entry
+	slowNetworkCheck   *widget.Check  // 慢速网络模式
+	forceIPv4Check     *widget.Check  // 只使用 IPv4 连接
+	envOnlyCheck       *widget.Check  // 仅配置环境变量，不修改 ~/.claude.json
+	configModeSelect   *widget.Select // 永久环境变量写入 shell 配置文件、settings.json 或两者
+	cacheLabel         *widget.Label  // 下载缓存的大小
+	advancedOptions    *widget.Accordion
+	tutorialButton     *widget.Button
+	openButton         *widget.Button
+	reinstallButton    *widget.Button
+	updateClaudeButton *widget.Button
+	changeKeyButton    *widget.Button
+	testButton         *widget.Button
+	verifyButton       *widget.Button
+	systemConfigCheck  *widget.Check
+	dryRunCheck        *widget.Check
+	highContrastCheck  *widget.Check
+	themeSelect        *widget.Select
+	scaleSelect        *widget.Select
+	macTerminalSelect  *widget.Select
+	titleText          *canvas.Text
+
+	// 随窗口尺寸调整的布局，只在主线程中访问
+	leftPanel    *fyne.Container
+	rightPanel   *fyne.Container
+	panels       *fyne.Container // 左右分栏或上下排列的面板
+	buttonBar    *fyne.Container
+	logScroll    *container.Scroll
+	narrowLayout bool
+
+	// 步骤卡片中每一步的标签和状态，只在主线程中访问
+	stepLabels   []*widget.Label
+	stepStatuses []stepStatus
+	envLabel     *widget.Label // 已检测到的组件版本
+	envReady     bool          // 组件均已安装，主按钮只配置 API
+
+	// 步骤卡片中的跳过选项，勾选后安装时不检测也不安装该组件
+	skipNodeCheck *widget.Check
+	skipGitCheck  *widget.Check
+
+	// 配置完成后显示的配置文件路径
+	configPathsCard  *fyne.Container
+	configPathsLabel *widget.Label
+
+	// 窗口顶部的新版本提示，只在主线程中访问
+	updateBanner  *fyne.Container
+	updateLabel   *widget.Label
+	latestRelease *updater.Release // 发现的新版本，未发现时为 nil
+
+	// 连续安装失败的次数，达到 manualGuideAfterFailures 后建议手动安装，只在主线程中访问
+	installFailures int
+
+	// 日志显示区已显示到的日志序号和行数，只在主线程中访问
+	logSeq    int
+	logLines  int
+	logFollow bool // 日志区停在底部，新日志到来时自动滚动
+
+	// 窗口已关闭，之后后台协程的界面更新都被忽略，可在任意协程中访问
+	closed atomic.Bool
 }
 
 func NewManager(window fyne.Window, inst *installer.Installer) *Manager {
-	return &Manager{
+	m := &Manager{
 		window:    window,
 		installer: inst,
 	}
+	if window != nil {
+		window.SetOnClosed(func() { m.closed.Store(true) })
+	}
+	return m
 }
 
 // loadSavedConfig 加载已保存的配置
 func (m *Manager) loadSavedConfig() {
+	m.applyLogPolicy()
+
 	if config, err := LoadConfig(); err == nil {
+		// 先恢复服务商，切换服务商会重置默认 RPM
+		if m.baseURLEntry != nil && config.CustomBaseURL != "" {
+			m.baseURLEntry.SetText(config.CustomBaseURL)
+		}
+		if m.providerSelect != nil && config.Provider != "" {
+			m.providerSelect.SetSelected(config.Provider)
+		}
 		if m.apiKeyEntry != nil && config.APIKey != "" {
 			m.apiKeyEntry.SetText(config.APIKey)
 		}
 		if m.rpmEntry != nil && config.RPM != "" {
 			m.rpmEntry.SetText(config.RPM)
 		}
+		if m.highContrastCheck != nil {
+			m.highContrastCheck.SetChecked(config.HighContrast)
+		}
+		if m.scaleSelect != nil {
+			m.scaleSelect.SetSelected(scaleText(normalizeScale(config.UIScale)))
+		}
+		if m.themeSelect != nil {
+			for _, option := range themeModeOptions {
+				if string(option.mode) == config.ThemeMode {
+					m.themeSelect.SetSelected(i18n.T(option.text))
+				}
+			}
+		}
+		if m.nodeVersionEntry != nil && config.NodeVersion != "" {
+			m.nodeVersionEntry.SetText(config.NodeVersion)
+		}
+		if config.MinNodeVersion > 0 {
+			m.installer.MinNodeVersion = config.MinNodeVersion
+		}
+		if config.MinGitVersion != "" && installer.ValidateMinGitVersion(config.MinGitVersion) == nil {
+			m.installer.MinGitVersion = config.MinGitVersion
+		}
+		if config.GitVersion != "" && installer.ValidateGitTargetVersion(config.GitVersion, m.installer.MinGitVersion) == nil {
+			m.installer.GitVersion = config.GitVersion
+		}
+		if m.npmSudoCheck != nil {
+			m.npmSudoCheck.SetChecked(config.NPMStrategy == string(installer.NPMStrategySudo))
+		}
+		if m.claudeVersionEntry != nil && config.ClaudeVersion != "" {
+			m.claudeVersionEntry.SetText(config.ClaudeVersion)
+		}
+		if m.offlineDirEntry != nil && config.OfflineDir != "" {
+			m.offlineDirEntry.SetText(config.OfflineDir)
+		}
+		if m.proxyEntry != nil && config.Proxy != "" {
+			m.proxyEntry.SetText(config.Proxy)
+		}
+		if m.slowNetworkCheck != nil {
+			m.slowNetworkCheck.SetChecked(config.SlowNetwork)
+		}
+		if m.forceIPv4Check != nil {
+			m.forceIPv4Check.SetChecked(config.ForceIPv4)
+		}
+		if m.envOnlyCheck != nil {
+			m.envOnlyCheck.SetChecked(config.EnvOnly)
+		}
+		if m.configModeSelect != nil && installer.ValidateConfigStrategy(config.ConfigStrategy) == nil {
+			for _, option := range configStrategyOptions {
+				if string(option.strategy) == config.ConfigStrategy {
+					m.configModeSelect.SetSelected(i18n.T(option.text))
+				}
+			}
+		}
+		if m.skipNodeCheck != nil {
+			m.skipNodeCheck.SetChecked(config.SkipNode)
+		}
+		if m.skipGitCheck != nil {
+			m.skipGitCheck.SetChecked(config.SkipGit)
+		}
+		if m.projectDirEntry != nil && config.ProjectDir != "" {
+			m.projectDirEntry.SetText(config.ProjectDir)
+		}
+		if m.macTerminalSelect != nil && config.MacTerminal != macTerminalAuto {
+			m.macTerminalSelect.SetSelected(macTerminalLabel(config.MacTerminal))
+		}
+		m.refreshProfiles(config.LastUsed)
 	}
 }
 
 // saveCurrentConfig 保存当前配置
 func (m *Manager) saveCurrentConfig() {
 	if m.apiKeyEntry != nil && m.rpmEntry != nil {
-		SaveConfig(m.apiKeyEntry.Text, m.rpmEntry.Text)
+		config := m.loadConfigOrDefault()
+		config.APIKey = m.apiKeyEntry.Text
+		config.RPM = m.rpmEntry.Text
+		if m.providerSelect != nil {
+			config.Provider = m.providerSelect.Selected
+		}
+		if m.baseURLEntry != nil {
+			config.CustomBaseURL = m.baseURLEntry.Text
+		}
+		if m.nodeVersionEntry != nil {
+			config.NodeVersion = strings.TrimSpace(m.nodeVersionEntry.Text)
+		}
+		config.NPMStrategy = string(m.npmStrategy())
+		if m.claudeVersionEntry != nil {
+			config.ClaudeVersion = strings.TrimSpace(m.claudeVersionEntry.Text)
+		}
+		if m.offlineDirEntry != nil {
+			config.OfflineDir = strings.TrimSpace(m.offlineDirEntry.Text)
+		}
+		if m.proxyEntry != nil {
+			config.Proxy = strings.TrimSpace(m.proxyEntry.Text)
+		}
+		if m.slowNetworkCheck != nil {
+			config.SlowNetwork = m.slowNetworkCheck.Checked
+		}
+		if m.forceIPv4Check != nil {
+			config.ForceIPv4 = m.forceIPv4Check.Checked
+		}
+		if m.envOnlyCheck != nil {
+			config.EnvOnly = m.envOnlyCheck.Checked
+		}
+		config.ConfigStrategy = string(m.installer.ConfigStrategy)
+		if m.skipNodeCheck != nil {
+			config.SkipNode = m.skipNodeCheck.Checked
+		}
+		if m.skipGitCheck != nil {
+			config.SkipGit = m.skipGitCheck.Checked
+		}
+		if m.profileSelect != nil && m.profileSelect.Selected != "" {
+			config.LastUsed = m.profileSelect.Selected
+		}
+		SaveConfig(config)
+	}
+}
+
+// selectedProvider 返回当前选择的服务商
+func (m *Manager) selectedProvider() (installer.Provider, error) {
+	if m.providerSelect == nil {
+		return installer.DefaultProvider(), nil
+	}
+
+	name := m.providerSelect.Selected
+	if name == installer.CustomProviderName {
+		return installer.NewCustomProvider(m.baseURLEntry.Text)
+	}
+	if provider, ok := installer.FindProvider(name); ok {
+		return provider, nil
+	}
+	return installer.DefaultProvider(), nil
+}
+
+// npmStrategy 返回界面上选择的 npm 全局安装策略
+func (m *Manager) npmStrategy() installer.NPMStrategy {
+	if m.npmSudoCheck != nil && m.npmSudoCheck.Checked {
+		return installer.NPMStrategySudo
+	}
+	return installer.NPMStrategyUserPrefix
+}
+
+// applyNetworkSettings 按界面上的慢速网络模式和 IPv4 选项设置下载超时和连接方式
+func (m *Manager) applyNetworkSettings() {
+	m.installer.ForceIPv4 = m.forceIPv4Check != nil && m.forceIPv4Check.Checked
+	if m.slowNetworkCheck != nil && m.slowNetworkCheck.Checked {
+		m.installer.UseSlowNetwork()
+		return
+	}
+	m.installer.DownloadTimeout = installer.DefaultDownloadTimeout
+	m.installer.StallTimeout = installer.DefaultStallTimeout
+}
+
+// loadConfigOrDefault 读取已保存的配置，读取失败时返回空配置，避免覆盖其他设置
+func (m *Manager) loadConfigOrDefault() *AppConfig {
+	if config, err := LoadConfig(); err == nil {
+		return config
+	}
+	return &AppConfig{}
+}
+
+// setMacTerminal 保存打开 Claude Code 时使用的 macOS 终端
+func (m *Manager) setMacTerminal(terminal string) {
+	config := m.loadConfigOrDefault()
+	terminal = macTerminalFromLabel(terminal)
+	if config.MacTerminal == terminal {
+		return
+	}
+	config.MacTerminal = terminal
+	SaveConfig(config)
+}
+
+// setProjectDir 保存打开 Claude Code 时进入的项目目录
+func (m *Manager) setProjectDir(dir string) {
+	config := m.loadConfigOrDefault()
+	if config.ProjectDir == dir {
+		return
+	}
+	config.ProjectDir = dir
+	SaveConfig(config)
+}
+
+// themeModeOptions 外观选择框的选项及其消息 ID
+var themeModeOptions = []struct {
+	mode ThemeMode
+	text string
+}{
+	{ThemeModeSystem, "appearance.system"},
+	{ThemeModeLight, "appearance.light"},
+	{ThemeModeDark, "appearance.dark"},
+}
+
+// configStrategyOptions 配置方式下拉框的选项，按显示顺序排列
+var configStrategyOptions = []struct {
+	strategy installer.ConfigStrategy
+	text     string
+}{
+	{installer.ConfigStrategyShell, "config_strategy.shell"},
+	{installer.ConfigStrategySettings, "config_strategy.settings"},
+	{installer.ConfigStrategyBoth, "config_strategy.both"},
+}
+
+// applyTheme 按配置应用主题，标题文字颜色随主题更新
+func (m *Manager) applyTheme(config *AppConfig) {
+	fyne.CurrentApp().Settings().SetTheme(NewTheme(config.HighContrast, ThemeMode(config.ThemeMode), config.UIScale))
+	if m.titleText != nil {
+		m.titleText.Color = theme.Color(theme.ColorNameForeground)
+		m.titleText.Refresh()
+	}
+}
+
+// setHighContrast 切换高对比度主题并立即应用
+func (m *Manager) setHighContrast(enabled bool) {
+	config := m.loadConfigOrDefault()
+	config.HighContrast = enabled
+	SaveConfig(config)
+	m.applyTheme(config)
+}
+
+// scaleText 缩放比例在选择框中显示的文字
+func scaleText(scale float32) string {
+	return fmt.Sprintf("%d%%", int(scale*100+0.5))
+}
+
+// setUIScale 切换界面缩放比例，应用主题后界面立即按新尺寸刷新
+func (m *Manager) setUIScale(text string) {
+	for _, scale := range UIScales {
+		if scaleText(scale) != text {
+			continue
+		}
+		config := m.loadConfigOrDefault()
+		config.UIScale = scale
+		SaveConfig(config)
+		m.applyTheme(config)
+		return
+	}
+}
+
+// setThemeMode 切换浅色、深色或跟随系统并立即应用
+func (m *Manager) setThemeMode(text string) {
+	for _, option := range themeModeOptions {
+		if i18n.T(option.text) != text {
+			continue
+		}
+		config := m.loadConfigOrDefault()
+		config.ThemeMode = string(option.mode)
+		SaveConfig(config)
+		m.applyTheme(config)
+		return
 	}
 }
 
 func (m *Manager) CreateMainContent() fyne.CanvasObject {
 	// 创建标题 - 使用更鲜艳的颜色
-	title := canvas.NewText("Claude Code + K2 环境集成工具", color.RGBA{R: 30, G: 41, B: 59, A: 255})
+	title := canvas.NewText(i18n.T("app.title"), theme.Color(theme.ColorNameForeground))
+	m.titleText = title
 	title.TextSize = 24
 	title.TextStyle = fyne.TextStyle{Bold: true}
 	title.Alignment = fyne.TextAlignCenter
 
-	subtitle := canvas.NewText("一键安装配置 Claude Code 和 Kimi K2 开发环境", color.RGBA{R: 59, G: 130, B: 246, A: 255})
+	subtitle := canvas.NewText(i18n.T("app.subtitle"), color.RGBA{R: 59, G: 130, B: 246, A: 255})
 	subtitle.TextSize = 14
 	subtitle.TextStyle = fyne.TextStyle{Bold: true}
 	subtitle.Alignment = fyne.TextAlignCenter
 
 	// 添加作者信息 - 可点击复制的微信号
-	wechatBtn := widget.NewButton("🤖 加微信: ruan11223344 进群分享最新AI知识，一起学习进步 (点击复制)", func() {
+	wechatBtn := widget.NewButton(i18n.T("app.wechat"), func() {
 		m.window.Clipboard().SetContent("ruan11223344")
 		m.showQRCodeDialog()
 	})
@@ -86,43 +413,94 @@ func (m *Manager) CreateMainContent() fyne.CanvasObject {
 
 	// 组装完整界面
 	content := container.NewVBox(
-		container.NewPadded(container.NewVBox(title, subtitle)),
+		container.NewPadded(container.NewVBox(title, subtitle, m.activationStatus())),
 		container.NewPadded(wechatBtn),
 		widget.NewSeparator(),
 		mainContent,
 	)
 
-	return container.NewScroll(content)
+	// 新版本提示不随内容滚动，始终显示在顶部
+	updateBanner := m.createUpdateBanner()
+	go m.checkForUpdate()
+
+	bottomBar := container.NewVBox(widget.NewSeparator(), container.NewPadded(m.buttonBar))
+	return newResizeWatcher(
+		container.NewBorder(updateBanner, bottomBar, nil, nil, container.NewScroll(content)),
+		m.onWindowResize,
+	)
 }
 
 // createInstallerContent 创建安装界面
 func (m *Manager) createInstallerContent() fyne.CanvasObject {
 	// 创建进度条
 	m.progressBar = widget.NewProgressBar()
-	m.statusLabel = widget.NewLabel("准备就绪")
+	m.statusLabel = widget.NewLabel(i18n.T("status.ready"))
+	// 下载时显示速度和剩余时间
+	m.progressDetail = widget.NewLabel("")
+	m.progressDetail.Importance = widget.LowImportance
+	m.progressDetail.Hide()
 
 	// 创建日志显示区
 	m.logsDisplay = widget.NewMultiLineEntry()
 	m.logsDisplay.Disable()
-	m.logsDisplay.SetPlaceHolder("安装日志将显示在这里...")
+	m.logsDisplay.SetPlaceHolder(i18n.T("log.placeholder"))
 
-	logScroll := container.NewScroll(m.logsDisplay)
-	logScroll.SetMinSize(fyne.NewSize(0, 500))
+	m.logScroll = m.newLogView()
+	m.logScroll.SetMinSize(fyne.NewSize(0, logMinHeight))
+
+	// 服务商选择
+	providerNames := []string{}
+	for _, p := range installer.BuiltinProviders {
+		providerNames = append(providerNames, p.Name)
+	}
+	providerNames = append(providerNames, installer.CustomProviderName)
+
+	// 自定义网关地址（仅选择自定义网关时显示）
+	m.baseURLEntry = widget.NewEntry()
+	m.baseURLEntry.SetPlaceHolder("https://your-gateway.example.com/anthropic")
+	m.baseURLEntry.Hide()
+
+	m.providerSelect = widget.NewSelect(providerNames, func(name string) {
+		defer m.updateRPMHint()
+		if name == installer.CustomProviderName {
+			m.baseURLEntry.Show()
+			return
+		}
+		m.baseURLEntry.Hide()
+		if provider, ok := installer.FindProvider(name); ok && m.rpmEntry != nil {
+			m.rpmEntry.SetText(strconv.Itoa(provider.DefaultRPM))
+		}
+	})
+
+	providerContainer := container.NewVBox(
+		container.NewBorder(
+			nil, nil,
+			widget.NewLabel(i18n.T("label.provider")),
+			nil,
+			m.providerSelect,
+		),
+		m.baseURLEntry,
+	)
 
 	// API Key 输入
 	m.apiKeyEntry = widget.NewPasswordEntry()
-	m.apiKeyEntry.SetPlaceHolder("请输入API Key")
+	m.apiKeyEntry.SetPlaceHolder(i18n.T("apikey.placeholder"))
 	m.apiKeyEntry.Resize(fyne.NewSize(300, 36)) // 固定尺寸
 
 	// API Key 获取链接 - 可点击
-	apiKeyBtn := widget.NewButton("🔑 点击获取 API Key", func() {
-		urlStr := "https://platform.moonshot.cn/console/api-keys"
-		m.openURL(urlStr)
+	apiKeyBtn := widget.NewButton(i18n.T("button.get_api_key"), func() {
+		m.openURL(kimiAPIKeyURL)
 	})
 	apiKeyBtn.Importance = widget.MediumImportance
 
+	// 手机扫码获取 API Key
+	apiKeyQRBtn := widget.NewButton(i18n.T("button.scan"), func() {
+		m.showURLQRCodeDialog(i18n.T("qr.api_key_title"), kimiAPIKeyURL)
+	})
+	apiKeyQRBtn.Importance = widget.LowImportance
+
 	// 恢复按钮
-	restoreBtn := widget.NewButton("🔄 恢复Claude配置", func() {
+	restoreBtn := widget.NewButton(i18n.T("button.restore_config"), func() {
 		m.restoreClaudeConfig()
 	})
 	restoreBtn.Importance = widget.LowImportance
@@ -131,7 +509,7 @@ func (m *Manager) createInstallerContent() fyne.CanvasObject {
 		container.NewBorder(
 			nil, nil,
 			widget.NewLabel("API Key:"),
-			container.NewHBox(apiKeyBtn, restoreBtn),
+			container.NewHBox(apiKeyBtn, apiKeyQRBtn, restoreBtn),
 			m.apiKeyEntry,
 		),
 	)
@@ -142,57 +520,224 @@ func (m *Manager) createInstallerContent() fyne.CanvasObject {
 	m.rpmEntry.SetText("3")                  // 默认值（免费用户）
 	m.rpmEntry.Resize(fyne.NewSize(100, 36)) // 固定尺寸，比较小
 
+	// 输入时实时显示请求间隔，超过免费档位时提醒需要充值
+	m.rpmHint = widget.NewLabel("")
+	m.rpmHint.Wrapping = fyne.TextWrapWord
+	m.rpmEntry.OnChanged = func(string) { m.updateRPMHint() }
+
+	// 默认选择 Kimi K2
+	m.providerSelect.SetSelected(installer.DefaultProvider().Name)
+
 	// 速率限制说明
-	rpmInfo := widget.NewLabel("免费: 3 | ¥50: 200 | ¥100: 500 | ¥500+: 5000")
+	rpmInfo := widget.NewLabel(i18n.T("rpm.info"))
 	rpmInfo.TextStyle = fyne.TextStyle{Italic: true}
 
-	rpmDesc := widget.NewLabel("* 速率限制基于Kimi充值额度，实测最少充值50元才不会影响使用")
+	rpmDesc := widget.NewLabel(i18n.T("rpm.desc"))
 	rpmDesc.TextStyle = fyne.TextStyle{Italic: true, Bold: true}
 	rpmDesc.Alignment = fyne.TextAlignLeading
 
 	// 充值链接 - 可点击
-	chargeBtn := widget.NewButton("💳 打开Kimi充值链接", func() {
-		urlStr := "https://platform.moonshot.cn/console/pay"
-		m.openURL(urlStr)
+	chargeBtn := widget.NewButton(i18n.T("button.charge"), func() {
+		m.openURL(kimiChargeURL)
 	})
 	chargeBtn.Importance = widget.MediumImportance
 
+	// 手机扫码充值
+	chargeQRBtn := widget.NewButton(i18n.T("button.scan"), func() {
+		m.showURLQRCodeDialog(i18n.T("qr.charge_title"), kimiChargeURL)
+	})
+	chargeQRBtn.Importance = widget.LowImportance
+
 	rpmContainer := container.NewVBox(
 		container.NewBorder(
 			nil, nil,
-			widget.NewLabel("速率限制 (RPM):"),
-			chargeBtn,
+			widget.NewLabel(i18n.T("label.rpm")),
+			container.NewHBox(chargeBtn, chargeQRBtn),
 			m.rpmEntry,
 		),
+		m.rpmHint,
 		rpmInfo,
 		rpmDesc,
 	)
 
 	// 自动设置勾选框
-	m.systemConfigCheck = widget.NewCheck("永久设置K2环境变量（推荐 - 写入.bashrc/.zshrc/Windows环境变量）", nil)
+	m.systemConfigCheck = widget.NewCheck(i18n.T("check.system_config"), nil)
 	m.systemConfigCheck.SetChecked(true) // 默认勾选，永久设置
 
+	// 模拟运行：只报告将执行的操作，不修改系统
+	m.dryRunCheck = widget.NewCheck(i18n.T("check.dry_run"), nil)
+
 	// 添加说明文字
-	envVarHelp := widget.NewLabel("✓ 勾选：永久设置（写入配置文件）  ✗ 不勾选：仅当前进程")
+	envVarHelp := widget.NewLabel(i18n.T("help.env_var"))
 	envVarHelp.TextStyle = fyne.TextStyle{Italic: true}
 	envVarHelp.Alignment = fyne.TextAlignLeading
 
+	// 高级选项：需要安装 Node.js 时的目标版本
+	m.nodeVersionEntry = widget.NewEntry()
+	m.nodeVersionEntry.SetPlaceHolder(installer.DefaultNodeVersion)
+	nodeVersionHelp := widget.NewLabel(i18n.T("help.node_version"))
+	nodeVersionHelp.TextStyle = fyne.TextStyle{Italic: true}
+	nodeVersionHelp.Wrapping = fyne.TextWrapWord
+	// Claude Code 版本，默认 latest，可固定到已验证兼容的版本
+	m.claudeVersionEntry = widget.NewEntry()
+	m.claudeVersionEntry.SetPlaceHolder(installer.DefaultClaudeCodeVersion)
+
+	// 离线安装包目录：设置后从该目录安装，不联网下载
+	m.offlineDirEntry = widget.NewEntry()
+	m.offlineDirEntry.SetPlaceHolder(i18n.T("offline.placeholder"))
+	offlineDirButton := widget.NewButton(i18n.T("button.browse"), func() {
+		m.chooseFolder(m.offlineDirEntry, nil)
+	})
+	offlineDirHelp := widget.NewLabel(i18n.T("help.offline_dir"))
+	offlineDirHelp.TextStyle = fyne.TextStyle{Italic: true}
+	offlineDirHelp.Wrapping = fyne.TextWrapWord
+
+	// 项目目录：打开 Claude Code 时先进入该目录，选择后立即保存，之后一键打开
+	m.projectDirEntry = widget.NewEntry()
+	m.projectDirEntry.SetPlaceHolder(i18n.T("project.placeholder"))
+	projectDirButton := widget.NewButton(i18n.T("button.browse"), func() {
+		m.chooseFolder(m.projectDirEntry, m.setProjectDir)
+	})
+
+	// 下载和 npm 安装使用的 HTTP 代理，留空时沿用代理环境变量
+	m.proxyEntry = widget.NewEntry()
+	m.proxyEntry.SetPlaceHolder(i18n.T("proxy.placeholder"))
+
+	// 慢速网络模式：放宽下载总时间和停滞判断，避免大文件在慢速连接上超时
+	m.slowNetworkCheck = widget.NewCheck(i18n.T("check.slow_network"), nil)
+
+	// IPv6 不通的网络可以只使用 IPv4，默认两者并行尝试
+	m.forceIPv4Check = widget.NewCheck(i18n.T("check.force_ipv4"), nil)
+
+	// 只用环境变量运行 Claude Code 的用户可以不让本工具修改 ~/.claude.json，安装、更换 Key 和卸载时均生效
+	m.envOnlyCheck = widget.NewCheck(i18n.T("check.env_only"), func(checked bool) {
+		m.installer.WriteClaudeJSON = !checked
+	})
+
+	// 永久环境变量的写入位置：从 Dock、开始菜单等图形界面启动的 Claude Code 读不到 shell 配置文件，
+	// 可改为写入 Claude Code 自己读取的 ~/.claude/settings.json
+	var configStrategyNames []string
+	for _, option := range configStrategyOptions {
+		configStrategyNames = append(configStrategyNames, i18n.T(option.text))
+	}
+	m.configModeSelect = widget.NewSelect(configStrategyNames, func(name string) {
+		for _, option := range configStrategyOptions {
+			if i18n.T(option.text) == name {
+				m.installer.ConfigStrategy = option.strategy
+			}
+		}
+	})
+	m.configModeSelect.SetSelected(i18n.T("config_strategy.shell"))
+	configStrategyRow := container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.config_strategy")), nil, m.configModeSelect)
+
+	// npm 全局目录不可写时默认改用 ~/.npm-global，Linux 上可选择使用 sudo
+	m.npmSudoCheck = widget.NewCheck(i18n.T("check.npm_sudo"), nil)
+	if runtime.GOOS != "linux" {
+		m.npmSudoCheck.Hide()
+	}
+
+	// macOS 上打开 Claude Code 使用的终端，默认自动检测 iTerm2、Warp，最后回退到 Terminal.app
+	m.macTerminalSelect = widget.NewSelect(macTerminalOptions(), nil)
+	m.macTerminalSelect.SetSelected(macTerminalLabel(macTerminalAuto))
+	macTerminalRow := container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.terminal")), nil, m.macTerminalSelect)
+	if runtime.GOOS != "darwin" {
+		macTerminalRow.Hide()
+	}
+
+	m.advancedOptions = widget.NewAccordion(widget.NewAccordionItem(i18n.T("advanced.title"),
+		container.NewVBox(
+			container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.node_version")), nil, m.nodeVersionEntry),
+			nodeVersionHelp,
+			container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.claude_version")), nil, m.claudeVersionEntry),
+			container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.offline_dir")), offlineDirButton, m.offlineDirEntry),
+			offlineDirHelp,
+			container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.proxy")), nil, m.proxyEntry),
+			m.slowNetworkCheck,
+			m.forceIPv4Check,
+			container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.project_dir")), projectDirButton, m.projectDirEntry),
+			m.npmSudoCheck,
+			m.envOnlyCheck,
+			configStrategyRow,
+			macTerminalRow,
+			m.createCacheRow(),
+		),
+	))
+
+	// 高对比度模式（无障碍）
+	m.highContrastCheck = widget.NewCheck(i18n.T("check.high_contrast"), nil)
+
+	// 外观：跟随系统，或强制浅色、深色
+	var themeModeNames []string
+	for _, option := range themeModeOptions {
+		themeModeNames = append(themeModeNames, i18n.T(option.text))
+	}
+	m.themeSelect = widget.NewSelect(themeModeNames, nil)
+	m.themeSelect.SetSelected(i18n.T("appearance.system"))
+	themeRow := container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.appearance")), nil, m.themeSelect)
+
+	// 界面缩放：放大文字、间距和图标
+	var scaleNames []string
+	for _, scale := range UIScales {
+		scaleNames = append(scaleNames, scaleText(scale))
+	}
+	m.scaleSelect = widget.NewSelect(scaleNames, nil)
+	m.scaleSelect.SetSelected(scaleText(1))
+	scaleRow := container.NewBorder(nil, nil, widget.NewLabel(i18n.T("label.ui_scale")), nil, m.scaleSelect)
+
 	// 创建按钮
-	m.installButton = widget.NewButton("开始安装", m.onInstallClick)
+	m.installButton = widget.NewButton(i18n.T("button.install"), m.onInstallClick)
 	m.installButton.Importance = widget.HighImportance
 
-	m.tutorialButton = widget.NewButton("查看教程", m.showTutorial)
+	m.tutorialButton = widget.NewButton(i18n.T("button.tutorial"), m.showTutorial)
+
+	logPolicyButton := widget.NewButton(i18n.T("button.log_policy"), func() {
+		m.showLogPolicyDialog(false)
+	})
+	logPolicyButton.Importance = widget.LowImportance
+
+	uninstallButton := widget.NewButton(i18n.T("button.uninstall"), m.showUninstallDialog)
+	uninstallButton.Importance = widget.LowImportance
 
 	// 创建打开按钮（初始隐藏）
-	m.openButton = widget.NewButton("打开 Claude Code", m.openClaudeCode)
+	// 测试连接：用填写的 API Key 实际调用一次 claude
+	m.testButton = widget.NewButton(i18n.T("button.test_connection"), m.testConnection)
+
+	// 检测环境：不重新安装，只检查组件版本和新终端中的 K2 环境变量
+	m.verifyButton = widget.NewButton(i18n.T("button.verify"), m.verifyEnvironment)
+	m.verifyButton.Importance = widget.LowImportance
+
+	m.openButton = widget.NewButton(i18n.T("button.open_claude"), m.openClaudeCode)
 	m.openButton.Importance = widget.HighImportance
 	m.openButton.Hide()
 
-	buttonContainer := container.NewHBox(
+	// 重新安装：只在显示上次安装成功的状态时出现
+	m.reinstallButton = widget.NewButton(i18n.T("button.reinstall"), m.reinstall)
+	m.reinstallButton.Importance = widget.LowImportance
+	m.reinstallButton.Hide()
+
+	// 更新 Claude Code：检测到已安装的 Claude Code 时出现
+	m.updateClaudeButton = widget.NewButton(i18n.T("button.update_claude"), m.updateClaudeCode)
+	m.updateClaudeButton.Importance = widget.LowImportance
+	m.updateClaudeButton.Hide()
+
+	// 更换 API Key：已安装 Claude Code 时出现，只重新配置，不重新安装
+	m.changeKeyButton = widget.NewButton(i18n.T("button.change_key"), m.showChangeKeyDialog)
+	m.changeKeyButton.Importance = widget.LowImportance
+	m.changeKeyButton.Hide()
+
+	// 按钮固定在窗口底部，小屏幕上也不会被挤出可见区域
+	m.buttonBar = container.NewHBox(
 		layout.NewSpacer(),
+		logPolicyButton,
+		uninstallButton,
+		m.verifyButton,
 		m.tutorialButton,
 		m.installButton,
 		m.openButton,
+		m.reinstallButton,
+		m.updateClaudeButton,
+		m.changeKeyButton,
+		m.testButton,
 		layout.NewSpacer(),
 	)
 
@@ -200,74 +745,271 @@ func (m *Manager) createInstallerContent() fyne.CanvasObject {
 	stepsCard := m.createStepsCard()
 
 	// 组装安装界面 - 改为左右布局
-	leftPanel := container.NewVBox(
+	m.leftPanel = container.NewVBox(
 		stepsCard,
 		widget.NewSeparator(),
 		container.NewVBox(
-			widget.NewLabel("配置信息"),
+			widget.NewLabel(i18n.T("section.config")),
+			m.createProfileRow(),
+			providerContainer,
 			apiKeyContainer,
 			widget.NewSeparator(),
 			rpmContainer,
 			widget.NewSeparator(),
-			m.systemConfigCheck,
+			container.NewHBox(m.systemConfigCheck, m.dryRunCheck),
 			envVarHelp,
+			widget.NewSeparator(),
+			m.highContrastCheck,
+			themeRow,
+			scaleRow,
+			m.createLanguageRow(),
+			m.advancedOptions,
 		),
-		buttonContainer,
 	)
 
 	// 加载已保存的配置
 	m.loadSavedConfig()
 
-	rightPanel := container.NewVBox(
+	// 加载配置后再绑定回调，避免初始化时重复保存
+	m.highContrastCheck.OnChanged = m.setHighContrast
+	m.themeSelect.OnChanged = m.setThemeMode
+	m.scaleSelect.OnChanged = m.setUIScale
+	m.macTerminalSelect.OnChanged = m.setMacTerminal
+
+	// 后台检测已安装的组件，环境完整时只需配置 API
+	go m.checkEnvironment()
+
+	// 导出日志，方便用户反馈问题
+	exportLogButton := widget.NewButton(i18n.T("button.export_logs"), m.exportLogs)
+	exportLogButton.Importance = widget.LowImportance
+	// 复制日志，日志区不可选中文字，方便直接粘贴到聊天窗口
+	copyLogButton := widget.NewButton(i18n.T("button.copy_logs"), m.copyLogs)
+	copyLogButton.Importance = widget.LowImportance
+	// 生成诊断报告，包含完整的环境状态，密钥已隐藏
+	diagnosticsButton := widget.NewButton(i18n.T("button.diagnostics"), m.exportDiagnostics)
+	diagnosticsButton.Importance = widget.LowImportance
+	// 自动安装走不通时，生成适用于当前系统的手动安装步骤
+	manualGuideButton := widget.NewButton(i18n.T("button.manual_guide"), func() { m.showManualGuide("") })
+	manualGuideButton.Importance = widget.LowImportance
+	// 打开配置目录，方便检查写入的 .claude.json 和 shell 配置
+	configFolderButton := widget.NewButton(i18n.T("button.open_config_dir"), m.openConfigFolder)
+	configFolderButton.Importance = widget.LowImportance
+
+	m.rightPanel = container.NewVBox(
 		container.NewVBox(
-			widget.NewLabel("安装进度"),
+			widget.NewLabel(i18n.T("section.progress")),
 			m.progressBar,
+			m.progressDetail,
 			m.statusLabel,
 		),
+		m.createConfigPathsCard(),
 		widget.NewSeparator(),
 		container.NewVBox(
-			widget.NewLabel("安装日志"),
-			logScroll,
+			container.NewHBox(widget.NewLabel(i18n.T("section.logs")), layout.NewSpacer(), configFolderButton, copyLogButton, exportLogButton, diagnosticsButton, manualGuideButton),
+			m.logScroll,
 		),
 	)
 
-	// 左右分栏布局 - 左边60%，右边40%
-	split := container.NewHSplit(leftPanel, rightPanel)
-	split.SetOffset(0.65) // 左侧60%，右侧40%
-	return split
+	// 宽窗口左右分栏，窄窗口上下排列，由 onWindowResize 按窗口尺寸切换
+	m.panels = container.NewStack()
+	m.onWindowResize(DefaultWindowSize)
+	return m.panels
 }
 
-func (m *Manager) createStepsCard() fyne.CanvasObject {
-	steps := []string{
-		"1. 检查系统环境",
-		"2. 自动安装 Node.js (如未安装)",
-		"3. 自动安装 Git (如未安装)",
-		"4. 安装 Claude Code CLI 工具",
-		"5. 配置 Kimi K2 API",
-		"6. 验证环境配置",
+// stepStatus 安装步骤在步骤卡片中的状态
+type stepStatus int
+
+const (
+	stepPending stepStatus = iota
+	stepRunning
+	stepDone
+	stepFailed
+	stepSkipped
+)
+
+// icon 返回状态对应的图标
+func (s stepStatus) icon() string {
+	switch s {
+	case stepRunning:
+		return "🔄"
+	case stepDone:
+		return "✅"
+	case stepFailed:
+		return "❌"
+	case stepSkipped:
+		return "⏭️"
+	default:
+		return "⚪"
 	}
+}
+
+// displaySteps 步骤卡片中的步骤及其对应的安装器步骤名（ProgressUpdate.Step），均为消息 ID
+// 配置 API 不在安装器的步骤中，由界面在配置阶段单独更新
+var displaySteps = []struct {
+	text  string
+	steps []string
+}{
+	{"steps.check_system", []string{"step.check_system"}},
+	{"steps.install_node", []string{"step.check_node", "step.install_node"}},
+	{"steps.install_git", []string{"step.check_git", "step.install_git"}},
+	{"steps.install_claude", []string{"step.check_npm", "step.install_claude"}},
+	{"steps.configure_api", nil},
+	{"steps.verify", []string{"step.verify"}},
+}
 
+// configureStepIndex 配置 API 在步骤卡片中的位置
+const configureStepIndex = 4
+
+func (m *Manager) createStepsCard() fyne.CanvasObject {
 	var labels []fyne.CanvasObject
-	for _, step := range steps {
-		label := widget.NewLabel(step)
-		labels = append(labels, label)
+	m.stepLabels = nil
+	m.stepStatuses = make([]stepStatus, len(displaySteps))
+	for index, step := range displaySteps {
+		label := widget.NewLabel(stepPending.icon() + " " + i18n.T(step.text))
+		m.stepLabels = append(m.stepLabels, label)
+
+		// Node.js 和 Git 可以由用户自行管理，提供跳过选项
+		var skipCheck *widget.Check
+		switch step.text {
+		case "steps.install_node":
+			m.skipNodeCheck = m.newSkipCheck(index)
+			skipCheck = m.skipNodeCheck
+		case "steps.install_git":
+			m.skipGitCheck = m.newSkipCheck(index)
+			skipCheck = m.skipGitCheck
+		}
+		if skipCheck == nil {
+			labels = append(labels, label)
+			continue
+		}
+		labels = append(labels, container.NewBorder(nil, nil, nil, skipCheck, label))
 	}
 
+	// 启动时检测到的组件版本
+	m.envLabel = widget.NewLabel(i18n.T("env.detecting"))
+	m.envLabel.TextStyle = fyne.TextStyle{Italic: true}
+	m.envLabel.Wrapping = fyne.TextWrapWord
+	labels = append(labels, widget.NewSeparator(), m.envLabel)
+
 	stepsContainer := container.NewVBox(labels...)
 
-	card := widget.NewCard("安装步骤", "本工具将自动完成以下步骤：", stepsContainer)
+	card := widget.NewCard(i18n.T("steps.title"), i18n.T("steps.subtitle"), stepsContainer)
 
 	return card
 }
 
+// setStepStatus 更新步骤卡片中某一步的状态，须在主线程中调用
+func (m *Manager) setStepStatus(index int, status stepStatus) {
+	if index < 0 || index >= len(m.stepLabels) {
+		return
+	}
+	m.stepStatuses[index] = status
+	text := i18n.T(displaySteps[index].text)
+	if status == stepSkipped {
+		text = i18n.T("steps.skipped", text)
+	}
+	m.stepLabels[index].SetText(status.icon() + " " + text)
+}
+
+// newSkipCheck 创建步骤卡片中第 index 步的跳过选项，勾选后该步骤显示为已跳过
+func (m *Manager) newSkipCheck(index int) *widget.Check {
+	return widget.NewCheck(i18n.T("steps.skip"), func(checked bool) {
+		if checked {
+			m.setStepStatus(index, stepSkipped)
+		} else {
+			m.setStepStatus(index, stepPending)
+		}
+	})
+}
+
+// resetStepStatuses 把所有步骤恢复为未开始，用户选择跳过的步骤保持已跳过，须在主线程中调用
+func (m *Manager) resetStepStatuses() {
+	for index := range m.stepLabels {
+		if m.stepStatuses[index] != stepSkipped {
+			m.setStepStatus(index, stepPending)
+		}
+	}
+}
+
+// updateStepStatuses 根据安装进度更新步骤卡片（线程安全）
+//
+// 收到某一步的进度时，它之前的安装步骤都已结束；出错时正在执行的步骤标记为失败。
+func (m *Manager) updateStepStatuses(update installer.ProgressUpdate) {
+	m.updateUI(func() {
+		if update.Error != nil {
+			for index, status := range m.stepStatuses {
+				if status == stepRunning {
+					m.setStepStatus(index, stepFailed)
+				}
+			}
+			return
+		}
+
+		current := len(displaySteps)
+		if update.Step != "完成" {
+			current = -1
+			for index, step := range displaySteps {
+				if slices.ContainsFunc(step.steps, func(id string) bool { return i18n.T(id) == update.Step }) {
+					current = index
+					break
+				}
+			}
+			if current < 0 {
+				return
+			}
+		}
+
+		for index, step := range displaySteps {
+			if len(step.steps) == 0 {
+				continue
+			}
+			switch {
+			case m.stepStatuses[index] == stepSkipped:
+				continue
+			case index == current:
+				m.setStepStatus(index, stepRunning)
+			case index < current && m.stepStatuses[index] != stepDone:
+				m.setStepStatus(index, stepDone)
+			}
+		}
+	})
+}
+
 func (m *Manager) onInstallClick() {
-	// 检查 API Key
-	apiKey := m.apiKeyEntry.Text
+	// 检查 API Key，去掉粘贴时带上的空白和引号
+	apiKey := installer.NormalizeAPIKey(m.apiKeyEntry.Text)
 	if apiKey == "" {
-		dialog.ShowError(fmt.Errorf("请输入 Kimi K2 API Key"), m.window)
+		dialog.ShowError(errors.New(i18n.T("error.api_key_required")), m.window)
 		return
 	}
+	if apiKey != m.apiKeyEntry.Text {
+		m.apiKeyEntry.SetText(apiKey)
+	}
 
+	// 获取服务商
+	provider, err := m.selectedProvider()
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+
+	// 格式可疑时提醒用户确认，而不是直接写入可能无效的配置
+	if err := provider.CheckAPIKeyFormat(apiKey); err != nil {
+		dialog.ShowConfirm(i18n.T("confirm.api_key_title"),
+			i18n.T("confirm.api_key_format", err),
+			func(proceed bool) {
+				if proceed {
+					m.startInstall(provider, apiKey)
+				}
+			}, m.window)
+		return
+	}
+
+	m.startInstall(provider, apiKey)
+}
+
+// startInstall 校验其余选项后开始安装
+func (m *Manager) startInstall(provider installer.Provider, apiKey string) {
 	// 获取速率限制
 	rpm := m.rpmEntry.Text
 	if rpm == "" {
@@ -275,148 +1017,396 @@ func (m *Manager) onInstallClick() {
 	}
 	// 验证是否为数字
 	if _, err := strconv.Atoi(rpm); err != nil {
-		dialog.ShowError(fmt.Errorf("速率限制必须是数字"), m.window)
+		dialog.ShowError(errors.New(i18n.T("error.rpm_not_number")), m.window)
 		return
 	}
 
+	// 获取 Node.js 目标版本
+	nodeVersion := strings.TrimSpace(m.nodeVersionEntry.Text)
+	if nodeVersion == "" {
+		nodeVersion = installer.DefaultNodeVersion
+	}
+	if err := installer.ValidateNodeTargetVersion(nodeVersion, m.installer.MinNodeVersion); err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	m.installer.NodeVersion = nodeVersion
+	m.installer.NPMStrategy = m.npmStrategy()
+
+	// 获取 Claude Code 版本
+	claudeVersion := strings.TrimSpace(m.claudeVersionEntry.Text)
+	if claudeVersion == "" {
+		claudeVersion = installer.DefaultClaudeCodeVersion
+	}
+	if err := installer.ValidateClaudeCodeVersion(strings.TrimPrefix(claudeVersion, "v")); err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	m.installer.ClaudeCodeVersion = claudeVersion
+	m.installer.OfflineDir = strings.TrimSpace(m.offlineDirEntry.Text)
+	proxy := strings.TrimSpace(m.proxyEntry.Text)
+	if proxy != "" {
+		if err := installer.ValidateProxyURL(proxy); err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+	}
+	m.installer.Proxy = proxy
+	m.applyNetworkSettings()
+	m.installer.SkipNode = m.skipNodeCheck != nil && m.skipNodeCheck.Checked
+	m.installer.SkipGit = m.skipGitCheck != nil && m.skipGitCheck.Checked
+
 	// 保存当前配置
 	m.saveCurrentConfig()
 
+	// 模拟运行不修改任何配置，直接开始
+	if m.dryRunCheck.Checked {
+		m.runInstall(provider, apiKey, rpm)
+		return
+	}
+
+	// 依次检查 Node.js 版本、管理员权限和已有的 ANTHROPIC_* 定义，永久设置环境变量时再按配置方式预览对配置文件的修改
+	useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
+	m.checkNodeUpgrade(func() {
+		m.checkElevation(func() {
+			m.checkEnvConflicts(provider, func() {
+				if useSystemConfig {
+					m.confirmShellConfigChanges(provider, apiKey, rpm, func() {
+						m.runInstall(provider, apiKey, rpm)
+					})
+					return
+				}
+				m.runInstall(provider, apiKey, rpm)
+			})
+		})
+	})
+}
+
+// runInstall 开始安装并监控进度，环境已完整安装时只配置 API
+func (m *Manager) runInstall(provider installer.Provider, apiKey, rpm string) {
+	// 环境已完整安装时跳过安装流程
+	if m.envReady && !m.dryRunCheck.Checked {
+		m.configureOnly(provider, apiKey, rpm)
+		return
+	}
+
 	// 禁用安装按钮
 	m.installButton.Disable()
-	m.logsDisplay.SetText("")
+	m.clearLogs()
+	m.resetStepStatuses()
 
 	// 启动安装
-	go m.installer.Install()
+	m.installer.DryRun = m.dryRunCheck != nil && m.dryRunCheck.Checked
+	updates, err := m.installer.StartInstall()
+	if err != nil {
+		m.installButton.Enable()
+		dialog.ShowError(err, m.window)
+		return
+	}
 
 	// 启动进度监控协程
-	go func() {
-		// 添加 panic 恢复机制
-		defer func() {
-			if r := recover(); r != nil {
-				errMsg := fmt.Sprintf("安装过程中发生错误: %v", r)
-				fmt.Println(errMsg)
+	go m.monitorInstall(updates, provider, apiKey, rpm)
+}
+
+// monitorInstall 监控一次安装或重试的进度，channel 关闭后配置 API
+func (m *Manager) monitorInstall(updates <-chan installer.ProgressUpdate, provider installer.Provider, apiKey, rpm string) {
+	// 添加 panic 恢复机制
+	defer func() {
+		if r := recover(); r != nil {
+			errMsg := i18n.T("error.install_panic", r)
+			fmt.Println(errMsg)
+			m.updateUI(func() {
 				if m.statusLabel != nil {
-					m.statusLabel.SetText("安装失败")
+					m.statusLabel.SetText(i18n.T("status.install_failed"))
 				}
 				if m.installButton != nil {
 					m.installButton.Enable()
 				}
-				// 延迟显示错误对话框
-				time.AfterFunc(100*time.Millisecond, func() {
-					if m.window != nil {
-						dialog.ShowError(fmt.Errorf(errMsg), m.window)
-					}
-				})
-			}
-		}()
+				dialog.ShowError(errors.New(errMsg), m.window)
+			})
+		}
+	}()
 
-		// Install() 方法会关闭 channel，这里不需要再关闭
+	// 安装器在操作结束时关闭 channel，这里只读取
 
-		// 监控安装进度
-		for update := range m.installer.Progress {
-			if update.Error != nil {
-				// 更新 UI
+	// 监控安装进度
+	for update := range updates {
+		update := update // 界面更新在主线程中异步执行，复制本次的进度
+		if update.Error != nil {
+			m.updateStepStatuses(update)
+			m.updateUI(func() {
 				if m.statusLabel != nil {
-					m.statusLabel.SetText(fmt.Sprintf("错误: %v", update.Error))
+					m.statusLabel.SetText(i18n.T("status.error", update.Error))
+				}
+				if m.progressDetail != nil {
+					m.progressDetail.Hide()
 				}
 				if m.installButton != nil {
 					m.installButton.Enable()
 				}
-				// 延迟显示错误对话框
-				time.AfterFunc(100*time.Millisecond, func() {
-					if m.window != nil {
-						dialog.ShowError(update.Error, m.window)
-					}
+			})
+			// 延迟显示错误对话框
+			time.AfterFunc(100*time.Millisecond, func() {
+				m.updateUI(func() {
+					m.showInstallError(update.Error, provider, apiKey, rpm)
 				})
-				return
-			}
+			})
+			return
+		}
 
-			// 更新进度（只有百分比>=0时才更新进度条）
-			if update.Percent >= 0 && m.progressBar != nil {
-				m.progressBar.SetValue(update.Percent)
-			}
-			
-			// 更新状态标签（只有非日志消息才更新状态）
-			if update.Step != "日志" && m.statusLabel != nil {
-				m.statusLabel.SetText(update.Message)
-			}
+		if update.Step != "日志" {
+			m.updateUI(func() {
+				// 更新进度（只有百分比>=0时才更新进度条）
+				if update.Percent >= 0 && m.progressBar != nil {
+					m.progressBar.SetValue(update.Percent)
+				}
+				// 更新状态标签（只有非日志消息才更新状态）
+				if m.statusLabel != nil {
+					m.statusLabel.SetText(update.Message)
+				}
+				// 下载速度和剩余时间，其他进度更新时隐藏
+				if m.progressDetail != nil {
+					m.progressDetail.SetText(update.Detail)
+					if update.Detail == "" {
+						m.progressDetail.Hide()
+					} else {
+						m.progressDetail.Show()
+					}
+				}
+			})
+			// 更新步骤卡片
+			m.updateStepStatuses(update)
+		} else if update.Percent >= 0 {
+			m.updateUI(func() {
+				if m.progressBar != nil {
+					m.progressBar.SetValue(update.Percent)
+				}
+			})
+		}
 
-			// 实时更新日志显示
-			if m.logsDisplay != nil {
-				logs := m.installer.GetLogs()
-				logText := strings.Join(logs, "\n")
-				m.updateUI(func() {
-					m.logsDisplay.SetText(logText)
-					// 滚动到底部
-					m.logsDisplay.CursorRow = len(logs)
-				})
+		// 实时更新日志显示
+		m.syncLogs()
+	}
+
+	// 模拟运行：列出配置阶段的操作后直接汇总，不进入安装完成状态
+	if m.installer.DryRun {
+		useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
+		m.installer.ConfigureProviderAPI(provider, apiKey, rpm, useSystemConfig)
+		m.handleDryRunComplete()
+		return
+	}
+
+	// channel 已关闭，现在配置 API
+	// 先显示完成状态
+	m.handleInstallComplete()
+
+	// 然后配置 API
+	go func() {
+		// 配置 API Key 和速率限制
+		m.updateUI(func() {
+			if m.statusLabel != nil {
+				m.statusLabel.SetText(i18n.T("status.configuring"))
 			}
-		}
+			m.setStepStatus(configureStepIndex, stepRunning)
+		})
 
-		// channel 已关闭，现在配置 API
-		// 先显示完成状态
-		m.handleInstallComplete()
+		// 更新日志显示
+		m.addLog(i18n.T("log.configuring"))
 
-		// 然后配置 API
-		go func() {
-			// 配置 API Key 和速率限制
+		// 传递系统级配置选项，配置阶段的日志通过新的进度 channel 实时显示
+		useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
+		err := m.configure(provider, apiKey, rpm, useSystemConfig)
+		report := m.installer.BuildReport(provider, apiKey, rpm, useSystemConfig, err)
+		if err != nil {
+			// 不影响主流程，只是配置失败，报告中列出失败原因
+			m.updateUI(func() {
+				m.setStepStatus(configureStepIndex, stepFailed)
+				if m.statusLabel != nil {
+					m.statusLabel.SetText(i18n.T("status.install_ok_api_failed"))
+				}
+				m.showCompleteDialog(i18n.T("dialog.install_done_title"), i18n.T("dialog.install_done"), report)
+			})
+			return
+		}
+
+		// 显示最终日志
+		m.syncLogs()
+		m.updateUI(func() {
+			m.setStepStatus(configureStepIndex, stepDone)
 			if m.statusLabel != nil {
-				m.statusLabel.SetText("配置 K2 API...")
+				m.statusLabel.SetText(i18n.T("status.all_done"))
 			}
+			m.showCompleteDialog(i18n.T("dialog.install_done_title"), i18n.T("dialog.install_done"), report)
+		})
+	}()
+}
+
+// checkEnvironment 检测已安装的组件并显示版本，环境完整时把主按钮改为仅配置 API，
+// 上次已成功安装时显示已安装状态
+func (m *Manager) checkEnvironment() {
+	status := m.installer.CheckEnvironment()
+
+	// 上次安装成功的记录：记录的组件已被删除时清除，恢复为安装状态
+	record, _ := installer.LoadInstallRecord()
+	if record != nil && record.Stale(status) {
+		installer.ClearInstallRecord()
+		record = nil
+	}
 
-			// 更新日志显示
-			if m.logsDisplay != nil {
-				m.logsDisplay.SetText(m.logsDisplay.Text + "\n配置 K2 API...")
+	versionText := func(ok bool, version string) string {
+		if version == "" {
+			return i18n.T("env.not_installed")
+		}
+		if !ok {
+			return i18n.T("env.too_old", version)
+		}
+		return version
+	}
+	text := i18n.T("env.detected",
+		versionText(status.NodeOK, status.NodeVersion),
+		versionText(status.GitOK, status.GitVersion),
+		versionText(status.ClaudeOK, status.ClaudeVersion))
+
+	m.updateUI(func() {
+		m.envLabel.SetText(text)
+		m.envReady = false
+		if status.ClaudeOK {
+			m.updateClaudeButton.Show()
+			m.changeKeyButton.Show()
+		} else {
+			m.updateClaudeButton.Hide()
+			m.changeKeyButton.Hide()
+		}
+		if record != nil {
+			m.envReady = status.Ready()
+			m.showInstalledState(text, *record)
+			return
+		}
+		if !status.Ready() {
+			return
+		}
+
+		m.envReady = true
+		m.envLabel.SetText(text + "\n" + i18n.T("env.ready"))
+		m.installButton.SetText(i18n.T("button.configure_only"))
+		for index := range displaySteps {
+			if index != configureStepIndex {
+				m.setStepStatus(index, stepDone)
 			}
+		}
+	})
+}
 
-			// 传递系统级配置选项
-			useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
-			err := m.installer.ConfigureK2APIWithOptions(apiKey, rpm, useSystemConfig)
+// configure 在后台配置 API 并实时同步日志，等待配置结束后返回结果，不要在主线程中调用
+func (m *Manager) configure(provider installer.Provider, apiKey, rpm string, useSystemConfig bool) error {
+	updates, err := m.installer.StartConfigure(provider, apiKey, rpm, useSystemConfig)
+	if err != nil {
+		return err
+	}
+	for update := range updates {
+		if update.Error != nil {
+			err = update.Error
+		}
+		m.syncLogs()
+	}
+	m.syncLogs()
+	return err
+}
+
+// configureOnly 环境已完整安装时跳过安装流程，直接配置 API
+func (m *Manager) configureOnly(provider installer.Provider, apiKey, rpm string) {
+	m.installButton.Disable()
+	m.clearLogs()
+	m.setStepStatus(configureStepIndex, stepRunning)
+	if m.statusLabel != nil {
+		m.statusLabel.SetText(i18n.T("status.configuring"))
+	}
+
+	useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
+	go func() {
+		m.addLog(i18n.T("log.skip_install"))
+		err := m.configure(provider, apiKey, rpm, useSystemConfig)
+		report := m.installer.BuildReport(provider, apiKey, rpm, useSystemConfig, err)
+
+		m.updateUI(func() {
 			if err != nil {
-				// 不影响主流程，只是配置失败
-				fyne.Do(func() {
-					if m.statusLabel != nil {
-						m.statusLabel.SetText("⚠️ 安装完成，但 API 配置失败")
-					}
-				})
+				m.setStepStatus(configureStepIndex, stepFailed)
+				m.statusLabel.SetText(i18n.T("status.api_failed"))
+				m.installButton.Enable()
+				dialog.ShowError(errors.New(i18n.T("error.api_failed", err)), m.window)
 				return
 			}
 
-			// 监听配置阶段的日志更新
-			go func() {
-				for update := range m.installer.Progress {
-					if update.Step == "日志" && m.logsDisplay != nil {
-						logs := m.installer.GetLogs()
-						logText := strings.Join(logs, "\n")
-						m.updateUI(func() {
-							m.logsDisplay.SetText(logText)
-							// 滚动到底部
-							m.logsDisplay.CursorRow = len(logs)
-						})
-					}
-				}
-			}()
-
-			// 显示最终日志
-			fyne.Do(func() {
-				if m.logsDisplay != nil {
-					logs := m.installer.GetLogs()
-					logText := strings.Join(logs, "\n")
-					m.logsDisplay.SetText(logText)
-				}
-				if m.statusLabel != nil {
-					m.statusLabel.SetText("✅ 安装和配置全部完成！")
-				}
-			})
-		}()
+			m.setStepStatus(configureStepIndex, stepDone)
+			m.statusLabel.SetText(i18n.T("status.api_done"))
+			m.progressBar.SetValue(1)
+			m.installButton.Hide()
+			m.openButton.Show()
+			m.showCompleteDialog(i18n.T("dialog.api_done_title"), i18n.T("dialog.api_done"), report)
+		})
 	}()
 }
 
+// showInstallError 显示安装错误，步骤失败时提供从该步骤重试的选项
+func (m *Manager) showInstallError(err error, provider installer.Provider, apiKey, rpm string) {
+	if m.window == nil {
+		return
+	}
+
+	// 反复失败时建议改为手动安装，不再只提供重试
+	m.installFailures++
+	if m.installFailures >= manualGuideAfterFailures {
+		m.showRepeatedInstallFailure(err, provider, apiKey, rpm)
+		return
+	}
+
+	var stepErr *installer.StepError
+	if !errors.As(err, &stepErr) {
+		if !m.showErrorWithRemedy(i18n.T("dialog.install_failed_title"), err, "", nil) {
+			dialog.ShowError(err, m.window)
+		}
+		return
+	}
+
+	// 已知的错误类型给出对应的解决办法，同时保留从该步骤重试
+	retry := func() { m.retryInstall(stepErr.StepID, provider, apiKey, rpm) }
+	if m.showErrorWithRemedy(i18n.T("dialog.install_failed_title"), err, i18n.T("button.retry_step"), retry) {
+		return
+	}
+
+	retryDialog := dialog.NewConfirm(i18n.T("dialog.install_failed_title"),
+		i18n.T("dialog.retry", err, stepErr.Step),
+		func(retry bool) {
+			if retry {
+				m.retryInstall(stepErr.StepID, provider, apiKey, rpm)
+			}
+		}, m.window)
+	retryDialog.SetConfirmText(i18n.T("button.retry_step"))
+	retryDialog.SetDismissText(i18n.T("button.close"))
+	retryDialog.Show()
+}
+
+// retryInstall 从失败的步骤继续安装，stepID 为 StepError.StepID
+func (m *Manager) retryInstall(stepID string, provider installer.Provider, apiKey, rpm string) {
+	updates, err := m.installer.RetryFrom(stepID)
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+
+	if m.installButton != nil {
+		m.installButton.Disable()
+	}
+	if m.statusLabel != nil {
+		m.statusLabel.SetText(i18n.T("status.retrying", i18n.T(stepID)))
+	}
+	go m.monitorInstall(updates, provider, apiKey, rpm)
+}
+
 // handleInstallComplete 处理安装完成
 func (m *Manager) handleInstallComplete() {
 	// 确保 UI 更新在主线程中执行
-	fyne.Do(func() {
+	m.updateUI(func() {
 		if m.installButton != nil {
 			m.installButton.Hide()
 		}
@@ -424,24 +1414,90 @@ func (m *Manager) handleInstallComplete() {
 			m.openButton.Show()
 		}
 		if m.statusLabel != nil {
-			m.statusLabel.SetText("✅ 安装完成！")
+			m.statusLabel.SetText(i18n.T("status.install_done"))
 		}
+		m.installFailures = 0
+		go m.refreshCacheSize()
+		// 完成对话框在配置 API 结束后显示，附带安装报告
+	})
+}
 
-		// 延迟一点显示对话框，确保 UI 更新完成
-		time.AfterFunc(100*time.Millisecond, func() {
-			if m.window != nil {
-				completeDialog := dialog.NewInformation("安装完成",
-					"Claude Code + K2 环境已成功安装！\n\n"+
-						"点击「打开 Claude Code」按钮开始使用。",
-					m.window)
-				completeDialog.Show()
-			}
-		})
+// showCompleteDialog 显示完成对话框，附带在已打开的终端中启用 K2 的命令和可展开的安装报告，须在主线程中调用
+func (m *Manager) showCompleteDialog(title, message string, report *installer.InstallReport) {
+	if m.window == nil {
+		return
+	}
+
+	m.showConfigPaths()
+
+	useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
+	command := m.installer.GetActivationCommand(useSystemConfig)
+
+	hint := i18n.T("complete.hint_permanent")
+	switch {
+	case !useSystemConfig:
+		hint = i18n.T("complete.hint_temp")
+	case m.installer.ConfigStrategy == installer.ConfigStrategySettings:
+		hint = i18n.T("complete.hint_settings")
+	}
+	hintLabel := widget.NewLabel(hint)
+	hintLabel.Wrapping = fyne.TextWrapWord
+
+	commandLabel := widget.NewLabel(command)
+	commandLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	copyButton := widget.NewButton(i18n.T("button.copy"), func() {
+		m.window.Clipboard().SetContent(command)
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel(message),
+		widget.NewSeparator(),
+		hintLabel,
+		container.NewBorder(nil, nil, nil, copyButton, commandLabel),
+	)
+	if report != nil {
+		content.Add(m.reportSummary(report))
+	}
+	completeDialog := dialog.NewCustom(title, i18n.T("button.ok"), content, m.window)
+	completeDialog.Resize(fyne.NewSize(520, 0))
+	completeDialog.Show()
+}
+
+// handleDryRunComplete 显示模拟运行的汇总，恢复安装按钮
+func (m *Manager) handleDryRunComplete() {
+	summary := m.installer.DryRunSummary()
+	components := m.installer.PlannedComponents()
+
+	m.syncLogs()
+	m.updateUI(func() {
+		if m.statusLabel != nil {
+			m.statusLabel.SetText("🔍 " + summary)
+		}
+		if m.installButton != nil {
+			m.installButton.Enable()
+		}
+
+		detail := i18n.T("dryrun.nothing")
+		if len(components) > 0 {
+			detail = i18n.T("dryrun.components") + strings.Join(components, "\n• ")
+		}
+		dialog.ShowInformation(i18n.T("dryrun.title"),
+			detail+i18n.T("dryrun.footer"),
+			m.window)
 	})
 }
 
 func (m *Manager) showTutorial() {
 	tutorial := NewTutorialWithImages(m.window)
+	tutorial.FAQActions = map[string]func(){
+		faqActionRecharge: func() { m.openURL(kimiChargeURL) },
+		faqActionAPIKeys:  func() { m.openURL(kimiAPIKeyURL) },
+		faqActionVerify:   m.verifyEnvironment,
+		faqActionCopyCommand: func() {
+			useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
+			m.window.Clipboard().SetContent(m.installer.GetActivationCommand(useSystemConfig))
+		},
+	}
 	tutorial.Show()
 }
 
@@ -449,27 +1505,62 @@ func (m *Manager) showTutorial() {
 func (m *Manager) addLog(message string) {
 	// 将日志添加到日志显示区
 	m.updateUI(func() {
-		currentText := m.logsDisplay.Text
-		if currentText != "" {
-			currentText += "\n"
+		m.appendLogLines([]string{message})
+	})
+}
+
+// syncLogs 把安装器新增的日志追加到日志显示区（线程安全）
+func (m *Manager) syncLogs() {
+	m.updateUI(func() {
+		if m.logsDisplay == nil {
+			return
 		}
-		m.logsDisplay.SetText(currentText + message)
+		lines, next := m.installer.LogsSince(m.logSeq)
+		m.logSeq = next
+		m.appendLogLines(lines)
 	})
 }
 
-func (m *Manager) updateUI(fn func()) {
-	if fn == nil {
+// appendLogLines 追加日志行，停在底部时滚动到最新一行，须在主线程中调用
+//
+// 只追加新行，避免每条日志都重新拼接全部内容；显示的行数超过缓冲区两倍时
+// 用缓冲区中的日志重建显示内容，防止日志显示区无限增长。
+func (m *Manager) appendLogLines(lines []string) {
+	if m.logsDisplay == nil || len(lines) == 0 {
 		return
 	}
 
-	// 确保所有 UI 操作都在主线程中执行
-	if m.window == nil {
-		return
+	if m.logLines+len(lines) > 2*m.installer.MaxLogLines() {
+		logs, next := m.installer.LogsSince(m.logSeq - m.installer.MaxLogLines())
+		m.logSeq = next
+		m.logsDisplay.SetText(strings.Join(logs, "\n"))
+		m.logLines = len(logs)
+	} else {
+		text := strings.Join(lines, "\n")
+		if m.logsDisplay.Text != "" {
+			text = "\n" + text
+		}
+		m.logsDisplay.Append(text)
+		m.logLines += len(lines)
 	}
 
-	// 直接执行，让 Fyne 自己处理线程问题
-	// 因为我们已经在 goroutine 中了，所以直接调用即可
-	fn()
+	// 停在底部时滚动到最新一行，用户向上翻看时不打扰
+	m.followLogs()
+}
+
+// updateUI 在主线程中执行界面更新（线程安全），后台协程修改控件都要经过这里；
+// 窗口关闭后的更新直接忽略，避免访问已销毁的控件
+func (m *Manager) updateUI(fn func()) {
+	if fn == nil || m.window == nil || m.closed.Load() {
+		return
+	}
+	fyne.Do(func() {
+		// 排队期间窗口可能已关闭
+		if m.closed.Load() {
+			return
+		}
+		fn()
+	})
 }
 
 // openURL 打开网址
@@ -490,7 +1581,7 @@ func (m *Manager) openURL(urlStr string) {
 		if err != nil {
 			// 如果打开失败，显示链接让用户手动复制
 			m.window.Clipboard().SetContent(urlStr)
-			dialog.ShowInformation("链接已复制", fmt.Sprintf("无法自动打开浏览器，链接已复制到剪贴板:\n%s", urlStr), m.window)
+			dialog.ShowInformation(i18n.T("link.copied_title"), i18n.T("link.copied", urlStr), m.window)
 		}
 	}
 }
@@ -499,10 +1590,10 @@ func (m *Manager) openURL(urlStr string) {
 func (m *Manager) restoreClaudeConfig() {
 	err := m.installer.RestoreOriginalClaudeConfig()
 	if err != nil {
-		dialog.ShowError(fmt.Errorf("恢复配置失败: %v", err), m.window)
+		dialog.ShowError(errors.New(i18n.T("error.restore", err)), m.window)
 		return
 	}
-	dialog.ShowInformation("成功", "✅ Claude Code 配置已恢复到初始状态！", m.window)
+	dialog.ShowInformation(i18n.T("dialog.success"), i18n.T("restore.done"), m.window)
 }
 
 // openClaudeCode 打开 Claude Code
@@ -514,11 +1605,24 @@ func (m *Manager) openClaudeCode() {
 	// 检查是否勾选了永久设置
 	useSystemConfig := m.systemConfigCheck != nil && m.systemConfigCheck.Checked
 
+	// 选择了项目目录时，在该目录中启动 Claude Code
+	projectDir := ""
+	if m.projectDirEntry != nil {
+		projectDir = strings.TrimSpace(m.projectDirEntry.Text)
+	}
+	if projectDir != "" {
+		if info, err := os.Stat(projectDir); err != nil || !info.IsDir() {
+			dialog.ShowError(errors.New(i18n.T("error.project_dir", projectDir)), m.window)
+			return
+		}
+	}
+	m.setProjectDir(projectDir)
+
 	switch runtime.GOOS {
 	case "windows":
 		// Windows: 根据永久设置决定启动方式
 		tempDir := os.TempDir()
-		setupScript = filepath.Join(tempDir, "claude_k2_setup.bat")
+		setupScript = installer.SetupScriptPath()
 
 		if useSystemConfig {
 			// 勾选了永久设置：删除临时脚本，使用PowerShell重新加载环境变量
@@ -529,15 +1633,15 @@ func (m *Manager) openClaudeCode() {
 echo Starting Claude Code (Permanent Environment Variables Mode)...
 echo.
 rem Refresh environment variables from registry
-for /f "tokens=2*" %%A in ('reg query "HKCU\Environment" /v ANTHROPIC_API_KEY 2^>nul') do set "ANTHROPIC_API_KEY=%%B"
+set "{{CLEAR_ENV}}="
+for /f "tokens=2*" %%A in ('reg query "HKCU\Environment" /v {{KEY_ENV}} 2^>nul') do set "{{KEY_ENV}}=%%B"
 for /f "tokens=2*" %%A in ('reg query "HKCU\Environment" /v ANTHROPIC_BASE_URL 2^>nul') do set "ANTHROPIC_BASE_URL=%%B"
 for /f "tokens=2*" %%A in ('reg query "HKCU\Environment" /v CLAUDE_REQUEST_DELAY_MS 2^>nul') do set "CLAUDE_REQUEST_DELAY_MS=%%B"
 for /f "tokens=2*" %%A in ('reg query "HKCU\Environment" /v CLAUDE_MAX_CONCURRENT_REQUESTS 2^>nul') do set "CLAUDE_MAX_CONCURRENT_REQUESTS=%%B"
-set "ANTHROPIC_AUTH_TOKEN="
 
-if defined ANTHROPIC_API_KEY (
+if defined {{KEY_ENV}} (
     echo K2 Environment Variables Detected
-    echo    API Key: %ANTHROPIC_API_KEY:~0,10%...
+    echo    API Key: %{{KEY_ENV}}:~0,10%...
     echo    Base URL: %ANTHROPIC_BASE_URL%
 ) else (
     echo Warning: K2 environment variables not found
@@ -546,6 +1650,15 @@ echo.
 echo Launching Claude Code...
 claude
 `
+			// 按服务商替换认证变量名
+			provider, err := m.selectedProvider()
+			if err != nil {
+				provider = installer.DefaultProvider()
+			}
+			refreshContent = strings.NewReplacer(
+				"{{KEY_ENV}}", provider.EnvKeyName,
+				"{{CLEAR_ENV}}", provider.ConflictingEnvKey(),
+			).Replace(refreshContent)
 			os.WriteFile(refreshScript, []byte(refreshContent), 0755)
 			cmd = exec.Command("cmd", "/c", "start", "cmd", "/k", refreshScript)
 		} else {
@@ -569,70 +1682,86 @@ claude
 		}
 	case "darwin":
 		// macOS: 根据永久设置决定启动方式
-		setupScript = "/tmp/claude_k2_setup.sh"
+		setupScript = installer.SetupScriptPath()
 
-		var script string
+		shellCmd := withProjectDir(projectDir, "claude")
 		if useSystemConfig {
 			// 勾选了永久设置：删除临时脚本，使用永久环境变量
 			os.Remove(setupScript)
-			script = `tell application "Terminal"
-				do script "claude"
-				activate
-			end tell`
-		} else {
+		} else if _, err := os.Stat(setupScript); err == nil {
 			// 未勾选永久设置：使用临时脚本（如果存在）
-			if _, err := os.Stat(setupScript); err == nil {
-				script = fmt.Sprintf(`tell application "Terminal"
-				do script "source %s && claude"
-				activate
-			end tell`, setupScript)
-			} else {
-				script = `tell application "Terminal"
-				do script "claude"
-				activate
-			end tell`
-			}
+			shellCmd = withProjectDir(projectDir, fmt.Sprintf("source %s && claude", setupScript))
+		}
+
+		// 使用用户选择或检测到的终端，默认 Terminal.app
+		terminal := resolveMacTerminal(m.loadConfigOrDefault().MacTerminal)
+		var err error
+		cmd, err = macTerminalCommand(terminal, shellCmd)
+		if err != nil {
+			dialog.ShowError(errors.New(i18n.T("error.open_claude", err)), m.window)
+			return
+		}
+	case "linux":
+		// Linux: 在检测到的终端模拟器中启动，找不到终端时提示手动执行的命令
+		setupScript = installer.SetupScriptPath()
+		if useSystemConfig {
+			os.Remove(setupScript)
+		}
+
+		shellCmd := linuxClaudeShell(useSystemConfig, setupScript, projectDir)
+		cmd = linuxTerminalCommand(shellCmd...)
+		if cmd == nil {
+			m.showManualLaunchDialog(withProjectDir(projectDir, m.installer.GetActivationCommand(useSystemConfig)))
+			return
 		}
-		cmd = exec.Command("osascript", "-e", script)
 	}
 
 	if cmd != nil {
+		// Windows 的 start 在启动进程的工作目录中打开新窗口
+		if projectDir != "" {
+			cmd.Dir = projectDir
+		}
 		err := cmd.Start()
 		if err != nil {
-			dialog.ShowError(fmt.Errorf("无法打开 Claude Code: %v", err), m.window)
+			dialog.ShowError(errors.New(i18n.T("error.open_claude", err)), m.window)
 		} else {
 			// 成功启动，显示提示
-			dialog.ShowInformation("成功", "Claude Code 已启动！\n环境变量已自动设置为K2 API。", m.window)
+			dialog.ShowInformation(i18n.T("dialog.success"), i18n.T("open.started"), m.window)
 		}
 	} else {
 		// 这种情况不应该发生在Windows和Mac上
-		dialog.ShowError(fmt.Errorf("不支持的操作系统或无法启动终端"), m.window)
+		dialog.ShowError(errors.New(i18n.T("error.unsupported")), m.window)
+	}
+}
+
+// showManualLaunchDialog 找不到终端模拟器时，提示用户在自己的终端中执行命令
+func (m *Manager) showManualLaunchDialog(command string) {
+	if command != "claude" && !strings.HasSuffix(command, "&& claude") {
+		command += " && claude"
 	}
+
+	hintLabel := widget.NewLabel(i18n.T("launch.no_terminal"))
+	hintLabel.Wrapping = fyne.TextWrapWord
+	commandLabel := widget.NewLabel(command)
+	commandLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	copyButton := widget.NewButton(i18n.T("button.copy"), func() {
+		m.window.Clipboard().SetContent(command)
+	})
+
+	content := container.NewVBox(hintLabel, container.NewBorder(nil, nil, nil, copyButton, commandLabel))
+	launchDialog := dialog.NewCustom(i18n.T("button.open_claude"), i18n.T("button.ok"), content, m.window)
+	launchDialog.Resize(fyne.NewSize(520, 0))
+	launchDialog.Show()
 }
 
 // showQRCodeDialog 显示包含二维码的对话框
 func (m *Manager) showQRCodeDialog() {
-	// 使用嵌入的二维码图片资源
-	qrImage := canvas.NewImageFromResource(QRCodeResource)
-	qrImage.FillMode = canvas.ImageFillContain
-	qrImage.SetMinSize(fyne.NewSize(200, 200))
-
-	// 创建文本内容
-	title := widget.NewRichTextFromMarkdown("## 微信号已复制到剪贴板\n")
-	title.Wrapping = fyne.TextWrapWord
-
-	content := widget.NewRichTextFromMarkdown("**微信号**: ruan11223344\n\n可以扫描二维码直接进群，或搜索微信号添加好友\n进群分享最新AI知识，一起学习进步！")
-	content.Wrapping = fyne.TextWrapWord
-
-	// 创建垂直布局容器 - 标题、二维码、文字内容
-	contentContainer := container.NewVBox(
-		title,
-		qrImage,
-		content,
-	)
+	showQRDialog(m.window, i18n.T("wechat.title"), QRCodeResource,
+		i18n.T("wechat.header"),
+		i18n.T("wechat.body"))
+}
 
-	// 显示自定义对话框
-	customDialog := dialog.NewCustom("加微信进群", "关闭", contentContainer, m.window)
-	customDialog.Resize(fyne.NewSize(300, 400))
-	customDialog.Show()
+// showURLQRCodeDialog 将链接生成二维码并显示，方便用户在手机上打开
+func (m *Manager) showURLQRCodeDialog(dialogTitle, urlStr string) {
+	showURLQRCodeDialog(m.window, dialogTitle, urlStr)
 }
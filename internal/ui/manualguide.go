@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// manualGuideAfterFailures 自动安装连续失败多少次后，在错误对话框中建议改为手动安装
+const manualGuideAfterFailures = 2
+
+// showRepeatedInstallFailure 自动安装连续失败后显示错误和已知的解决办法，并提供生成手动安装指南；
+// 步骤失败时仍可从该步骤重试
+func (m *Manager) showRepeatedInstallFailure(err error, provider installer.Provider, apiKey, rpm string) {
+	remedy, _ := m.remedyFor(err)
+	message := remedy.message
+	if message == "" {
+		message = err.Error()
+	}
+	errLabel := widget.NewLabel(message)
+	errLabel.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(errLabel, widget.NewSeparator())
+	if remedy.hint != "" {
+		hintLabel := widget.NewLabel(remedy.hint)
+		hintLabel.Wrapping = fyne.TextWrapWord
+		content.Add(hintLabel)
+	}
+	suggestLabel := widget.NewLabel(i18n.T("manual_guide.suggest", m.installFailures))
+	suggestLabel.Wrapping = fyne.TextWrapWord
+	content.Add(suggestLabel)
+
+	var failedStep string
+	var stepErr *installer.StepError
+	if errors.As(err, &stepErr) {
+		failedStep = stepErr.Step
+	}
+
+	var errDialog *dialog.CustomDialog
+	buttons := []fyne.CanvasObject{widget.NewButton(i18n.T("button.close"), func() { errDialog.Hide() })}
+	if stepErr != nil {
+		buttons = append(buttons, widget.NewButton(i18n.T("button.retry_step"), func() {
+			errDialog.Hide()
+			m.retryInstall(stepErr.StepID, provider, apiKey, rpm)
+		}))
+	}
+	guideButton := widget.NewButton(i18n.T("button.manual_guide"), func() {
+		errDialog.Hide()
+		m.showManualGuide(failedStep)
+	})
+	guideButton.Importance = widget.HighImportance
+	buttons = append(buttons, guideButton)
+
+	errDialog = dialog.NewCustomWithoutButtons(i18n.T("dialog.install_failed_title"), content, m.window)
+	errDialog.SetButtons(buttons)
+	errDialog.Resize(fyne.NewSize(520, 0))
+	errDialog.Show()
+}
+
+// showManualGuide 按当前系统和失败的步骤生成手动安装指南并显示，failedStep 可为空
+// 生成时需要检测已安装的组件和 npm 镜像，放到后台执行
+func (m *Manager) showManualGuide(failedStep string) {
+	provider, err := m.selectedProvider()
+	if err != nil {
+		provider = installer.DefaultProvider()
+	}
+	var apiKey, rpm string
+	if m.apiKeyEntry != nil {
+		apiKey = installer.NormalizeAPIKey(m.apiKeyEntry.Text)
+	}
+	if m.rpmEntry != nil {
+		rpm = m.rpmEntry.Text
+	}
+
+	progress := dialog.NewCustomWithoutButtons(i18n.T("manual_guide.title"),
+		container.NewVBox(widget.NewLabel(i18n.T("manual_guide.generating")), widget.NewProgressBarInfinite()),
+		m.window)
+	progress.Show()
+
+	go func() {
+		guide := m.installer.ManualInstallGuide(provider, apiKey, rpm, failedStep)
+		m.updateUI(func() {
+			progress.Hide()
+			m.showManualGuideDialog(guide)
+		})
+	}()
+}
+
+// showManualGuideDialog 显示手动安装指南，可复制到剪贴板或导出为 Markdown 文件
+func (m *Manager) showManualGuideDialog(guide string) {
+	hintLabel := widget.NewLabel(i18n.T("manual_guide.hint"))
+	hintLabel.Wrapping = fyne.TextWrapWord
+
+	guideLabel := widget.NewLabel(guide)
+	guideLabel.Wrapping = fyne.TextWrapWord
+	guideScroll := container.NewVScroll(guideLabel)
+	guideScroll.SetMinSize(fyne.NewSize(620, 380))
+
+	var guideDialog *dialog.CustomDialog
+	copyButton := widget.NewButton(i18n.T("manual_guide.copy"), func() {
+		m.window.Clipboard().SetContent(guide)
+		dialog.ShowInformation(i18n.T("manual_guide.title"), i18n.T("manual_guide.copied"), m.window)
+	})
+	exportButton := widget.NewButton(i18n.T("manual_guide.export"), func() { m.exportManualGuide(guide) })
+	exportButton.Importance = widget.HighImportance
+
+	guideDialog = dialog.NewCustomWithoutButtons(i18n.T("manual_guide.title"),
+		container.NewBorder(hintLabel, nil, nil, nil, guideScroll), m.window)
+	guideDialog.SetButtons([]fyne.CanvasObject{
+		widget.NewButton(i18n.T("button.close"), func() { guideDialog.Hide() }),
+		copyButton,
+		exportButton,
+	})
+	guideDialog.Resize(fyne.NewSize(700, 0))
+	guideDialog.Show()
+}
+
+// exportManualGuide 让用户选择保存位置，把手动安装指南保存为 Markdown 文件，默认保存到桌面
+func (m *Manager) exportManualGuide(guide string) {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, m.window)
+			return
+		}
+		if writer == nil {
+			// 用户取消
+			return
+		}
+
+		_, writeErr := writer.Write([]byte(guide))
+		if closeErr := writer.Close(); writeErr == nil {
+			writeErr = closeErr
+		}
+		if writeErr != nil {
+			dialog.ShowError(errors.New(i18n.T("error.manual_guide", writeErr)), m.window)
+			return
+		}
+		dialog.ShowInformation(i18n.T("manual_guide.title"), i18n.T("manual_guide.saved", writer.URI().Path()), m.window)
+	}, m.window)
+
+	saveDialog.SetFileName(fmt.Sprintf("claude-k2-manual-install-%s.md", time.Now().Format("20060102-150405")))
+	if desktop := desktopDir(); desktop != "" {
+		if lister, err := storage.ListerForURI(storage.NewFileURI(desktop)); err == nil {
+			saveDialog.SetLocation(lister)
+		}
+	}
+	saveDialog.Show()
+}
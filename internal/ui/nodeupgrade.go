@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// checkNodeUpgrade 安装前检查已安装的 Node.js 是否低于要求的最低版本，低于时提示将升级；
+// 勾选了跳过 Node.js 时询问是否改为升级，否则安装后 Claude Code 无法运行。版本满足要求时直接调用 proceed
+// 检测环境需要执行命令，放到后台避免界面卡顿，检测期间禁用安装按钮防止重复点击
+func (m *Manager) checkNodeUpgrade(proceed func()) {
+	m.installButton.Disable()
+	go func() {
+		status := m.installer.CheckEnvironment()
+
+		m.updateUI(func() {
+			m.installButton.Enable()
+			if status.NodeVersion == "" || status.NodeOK {
+				proceed()
+				return
+			}
+			m.showNodeUpgradeDialog(status, proceed)
+		})
+	}()
+}
+
+// showNodeUpgradeDialog 提示已安装的 Node.js 版本过低，由用户选择升级、保留或取消
+func (m *Manager) showNodeUpgradeDialog(status installer.EnvironmentStatus, proceed func()) {
+	skipping := m.installer.SkipNode
+	target := strings.TrimPrefix(m.installer.NodeVersion, "v")
+	message := i18n.T("node_upgrade.message", status.NodeVersion, status.MinNodeVersion, target)
+	if skipping {
+		message = i18n.T("node_upgrade.message_skipped", status.NodeVersion, status.MinNodeVersion, target)
+	}
+	messageLabel := widget.NewLabel(message)
+	messageLabel.Wrapping = fyne.TextWrapWord
+
+	var upgradeDialog *dialog.CustomDialog
+	upgradeButton := widget.NewButton(i18n.T("node_upgrade.upgrade"), func() {
+		upgradeDialog.Hide()
+		if skipping {
+			m.installer.SkipNode = false
+			if m.skipNodeCheck != nil {
+				m.skipNodeCheck.SetChecked(false)
+			}
+			m.saveCurrentConfig()
+		}
+		proceed()
+	})
+	upgradeButton.Importance = widget.HighImportance
+	cancelButton := widget.NewButton(i18n.T("button.cancel"), func() {
+		upgradeDialog.Hide()
+	})
+	buttons := []fyne.CanvasObject{cancelButton}
+	if skipping {
+		buttons = append(buttons, widget.NewButton(i18n.T("node_upgrade.keep"), func() {
+			upgradeDialog.Hide()
+			proceed()
+		}))
+	}
+	buttons = append(buttons, upgradeButton)
+
+	upgradeDialog = dialog.NewCustomWithoutButtons(i18n.T("node_upgrade.title"), messageLabel, m.window)
+	upgradeDialog.SetButtons(buttons)
+	upgradeDialog.Resize(fyne.NewSize(520, 0))
+	upgradeDialog.Show()
+}
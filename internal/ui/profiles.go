@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createProfileRow 创建配置档选择行：选择后填充服务商、API Key 和速率限制
+func (m *Manager) createProfileRow() fyne.CanvasObject {
+	m.profileSelect = widget.NewSelect(nil, m.applyProfile)
+	m.profileSelect.PlaceHolder = i18n.T("profile.placeholder")
+
+	saveButton := widget.NewButton(i18n.T("profile.save"), m.saveAsProfile)
+	saveButton.Importance = widget.LowImportance
+	deleteButton := widget.NewButton(i18n.T("button.delete"), m.deleteSelectedProfile)
+	deleteButton.Importance = widget.LowImportance
+
+	return container.NewBorder(
+		nil, nil,
+		widget.NewLabel(i18n.T("profile.label")),
+		container.NewHBox(saveButton, deleteButton),
+		m.profileSelect,
+	)
+}
+
+// refreshProfiles 重新读取配置档列表，只更新选中项而不覆盖当前输入
+func (m *Manager) refreshProfiles(selected string) {
+	if m.profileSelect == nil {
+		return
+	}
+
+	profiles, _, _ := LoadProfiles()
+	names := make([]string, 0, len(profiles))
+	found := false
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+		found = found || profile.Name == selected
+	}
+	if !found {
+		selected = ""
+	}
+
+	m.profileSelect.Options = names
+	m.profileSelect.Selected = selected
+	m.profileSelect.Refresh()
+}
+
+// applyProfile 用选中的配置档填充输入框
+func (m *Manager) applyProfile(name string) {
+	profiles, _, err := LoadProfiles()
+	if err != nil {
+		return
+	}
+
+	for _, profile := range profiles {
+		if profile.Name != name {
+			continue
+		}
+		// 先切换服务商，切换时会重置默认 RPM
+		if profile.CustomBaseURL != "" {
+			m.baseURLEntry.SetText(profile.CustomBaseURL)
+		}
+		if profile.Provider != "" {
+			m.providerSelect.SetSelected(profile.Provider)
+		}
+		m.apiKeyEntry.SetText(profile.APIKey)
+		if profile.RPM != "" {
+			m.rpmEntry.SetText(profile.RPM)
+		}
+
+		config := m.loadConfigOrDefault()
+		config.LastUsed = name
+		SaveConfig(config)
+		return
+	}
+}
+
+// saveAsProfile 把当前输入保存为命名配置档，默认使用当前选中的名称
+func (m *Manager) saveAsProfile() {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder(i18n.T("profile.name_placeholder"))
+	nameEntry.SetText(m.profileSelect.Selected)
+
+	items := []*widget.FormItem{widget.NewFormItem(i18n.T("profile.name"), nameEntry)}
+	dialog.ShowForm(i18n.T("profile.save_title"), i18n.T("button.save"), i18n.T("button.cancel"), items, func(ok bool) {
+		if !ok {
+			return
+		}
+		name := strings.TrimSpace(nameEntry.Text)
+		if name == "" {
+			dialog.ShowError(errors.New(i18n.T("error.profile_name")), m.window)
+			return
+		}
+
+		profile := Profile{
+			Name:          name,
+			APIKey:        m.apiKeyEntry.Text,
+			RPM:           m.rpmEntry.Text,
+			Provider:      m.providerSelect.Selected,
+			CustomBaseURL: m.baseURLEntry.Text,
+		}
+		if err := SaveProfile(profile); err != nil {
+			dialog.ShowError(errors.New(i18n.T("error.profile_save", err)), m.window)
+			return
+		}
+		m.refreshProfiles(name)
+	}, m.window)
+}
+
+// deleteSelectedProfile 确认后删除当前选中的配置档
+func (m *Manager) deleteSelectedProfile() {
+	name := m.profileSelect.Selected
+	if name == "" {
+		dialog.ShowInformation(i18n.T("profile.delete_title"), i18n.T("profile.select_first"), m.window)
+		return
+	}
+
+	dialog.ShowConfirm(i18n.T("profile.delete_title"), i18n.T("profile.delete_confirm", name), func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := DeleteProfile(name); err != nil {
+			dialog.ShowError(errors.New(i18n.T("error.profile_delete", err)), m.window)
+			return
+		}
+		m.refreshProfiles("")
+	}, m.window)
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"errors"
+
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrCodeImageSize 动态生成的二维码图片边长（像素）
+const qrCodeImageSize = 256
+
+// NewURLQRCodeResource 将 URL 编码为二维码图片资源，供手机扫码打开
+func NewURLQRCodeResource(urlStr string) (fyne.Resource, error) {
+	png, err := qrcode.Encode(urlStr, qrcode.Medium, qrCodeImageSize)
+	if err != nil {
+		return nil, errors.New(i18n.T("error.qr_code", err))
+	}
+
+	return fyne.NewStaticResource("url_qr.png", png), nil
+}
+
+// showURLQRCodeDialog 将链接生成二维码并显示，方便用户在手机上打开
+func showURLQRCodeDialog(parent fyne.Window, dialogTitle, urlStr string) {
+	qrResource, err := NewURLQRCodeResource(urlStr)
+	if err != nil {
+		dialog.ShowError(err, parent)
+		return
+	}
+
+	showQRDialog(parent, dialogTitle, qrResource,
+		i18n.T("qr.scan_heading"),
+		i18n.T("qr.scan_body", urlStr))
+}
+
+// showQRDialog 显示二维码对话框 - 标题、二维码、文字内容
+func showQRDialog(parent fyne.Window, dialogTitle string, qrResource fyne.Resource, heading, body string) {
+	qrImage := canvas.NewImageFromResource(qrResource)
+	qrImage.FillMode = canvas.ImageFillContain
+	qrImage.SetMinSize(fyne.NewSize(200, 200))
+
+	// 创建文本内容
+	title := widget.NewRichTextFromMarkdown(heading)
+	title.Wrapping = fyne.TextWrapWord
+
+	content := widget.NewRichTextFromMarkdown(body)
+	content.Wrapping = fyne.TextWrapWord
+
+	// 创建垂直布局容器 - 标题、二维码、文字内容
+	contentContainer := container.NewVBox(
+		title,
+		qrImage,
+		content,
+	)
+
+	// 显示自定义对话框
+	customDialog := dialog.NewCustom(dialogTitle, i18n.T("button.close"), contentContainer, parent)
+	customDialog.Resize(fyne.NewSize(300, 400))
+	customDialog.Show()
+}
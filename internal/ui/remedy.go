@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"errors"
+	"runtime"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// errorRemedy 已知错误类型的原因说明和处理按钮
+type errorRemedy struct {
+	message    string // 代替 err.Error() 显示的错误信息，为空时显示原错误
+	hint       string // 原因和解决办法
+	action     string // 处理按钮的文字，为空时只显示说明
+	fix        func() // 点击处理按钮后执行
+	retryAfter bool   // 执行 fix 后立即重试
+}
+
+// remedyFor 返回安装器错误对应的解决办法，不是已知的错误类型时 ok 为 false
+func (m *Manager) remedyFor(err error) (remedy errorRemedy, ok bool) {
+	switch {
+	case errors.Is(err, installer.ErrNoNetwork),
+		errors.Is(err, installer.ErrNodeDownloadFailed),
+		errors.Is(err, installer.ErrGitDownloadFailed),
+		errors.Is(err, installer.ErrNpmRegistryUnreachable):
+		return errorRemedy{hint: i18n.T("remedy.network"), action: i18n.T("remedy.open_proxy"), fix: m.openProxySettings}, true
+	case errors.Is(err, installer.ErrNodeTooOld):
+		return errorRemedy{hint: i18n.T("remedy.node_too_old")}, true
+	case errors.Is(err, installer.ErrGitTooOld):
+		return errorRemedy{hint: i18n.T("remedy.git_too_old")}, true
+	case errors.Is(err, installer.ErrUntrustedInstaller):
+		return errorRemedy{hint: i18n.T("remedy.untrusted"), action: i18n.T("remedy.open_proxy"), fix: m.openProxySettings}, true
+	case errors.Is(err, installer.ErrBlockedByAntivirus):
+		return errorRemedy{hint: i18n.T("remedy.antivirus"), action: i18n.T("remedy.open_protection_history"), fix: func() { m.openURL("windowsdefender://threat") }}, true
+	case errors.Is(err, installer.ErrUserCancelled):
+		return errorRemedy{message: i18n.T("error.user_cancelled"), hint: i18n.T("remedy.user_cancelled")}, true
+	case errors.Is(err, installer.ErrNeedsElevation):
+		if installer.CanRelaunchElevated() {
+			return errorRemedy{hint: i18n.T("remedy.elevation_win"), action: i18n.T("elevation.relaunch"), fix: m.relaunchElevated}, true
+		}
+		return errorRemedy{hint: i18n.T("remedy.elevation")}, true
+	case errors.Is(err, installer.ErrNpmPermission):
+		if runtime.GOOS == "linux" {
+			return errorRemedy{
+				hint:       i18n.T("remedy.npm_sudo"),
+				action:     i18n.T("remedy.retry_sudo"),
+				fix:        func() { m.npmSudoCheck.SetChecked(true); m.installer.NPMStrategy = installer.NPMStrategySudo },
+				retryAfter: true,
+			}, true
+		}
+		if installer.CanRelaunchElevated() {
+			return errorRemedy{hint: i18n.T("remedy.npm_win"), action: i18n.T("elevation.relaunch"), fix: m.relaunchElevated}, true
+		}
+		return errorRemedy{hint: i18n.T("remedy.npm")}, true
+	case errors.Is(err, installer.ErrAPIKeyInvalid):
+		return errorRemedy{hint: i18n.T("remedy.api_key"), action: i18n.T("button.get_api_key"), fix: m.openAPIKeyPage}, true
+	case errors.Is(err, installer.ErrInsufficientBalance):
+		return errorRemedy{hint: i18n.T("remedy.balance"), action: i18n.T("remedy.recharge"), fix: func() { m.openURL(kimiChargeURL) }}, true
+	case errors.Is(err, installer.ErrRateLimited):
+		return errorRemedy{hint: i18n.T("remedy.rate_limited"), action: i18n.T("remedy.recharge"), fix: func() { m.openURL(kimiChargeURL) }}, true
+	}
+	return errorRemedy{}, false
+}
+
+// showErrorWithRemedy 显示错误、原因说明和处理按钮；retry 不为 nil 时同时提供重试按钮
+// 不是已知的错误类型时返回 false，由调用方按原来的方式显示，须在主线程中调用
+func (m *Manager) showErrorWithRemedy(title string, err error, retryText string, retry func()) bool {
+	remedy, ok := m.remedyFor(err)
+	if !ok || m.window == nil {
+		return false
+	}
+
+	message := remedy.message
+	if message == "" {
+		message = err.Error()
+	}
+	errLabel := widget.NewLabel(message)
+	errLabel.Wrapping = fyne.TextWrapWord
+	hintLabel := widget.NewLabel(remedy.hint)
+	hintLabel.Wrapping = fyne.TextWrapWord
+	content := container.NewVBox(errLabel, widget.NewSeparator(), hintLabel)
+
+	var errDialog *dialog.CustomDialog
+	buttons := []fyne.CanvasObject{widget.NewButton(i18n.T("button.close"), func() { errDialog.Hide() })}
+	if retry != nil && !remedy.retryAfter {
+		buttons = append(buttons, widget.NewButton(retryText, func() {
+			errDialog.Hide()
+			retry()
+		}))
+	}
+	if remedy.action != "" && (retry != nil || !remedy.retryAfter) {
+		actionButton := widget.NewButton(remedy.action, func() {
+			errDialog.Hide()
+			remedy.fix()
+			if remedy.retryAfter {
+				retry()
+			}
+		})
+		actionButton.Importance = widget.HighImportance
+		buttons = append(buttons, actionButton)
+	}
+
+	errDialog = dialog.NewCustomWithoutButtons(title, content, m.window)
+	errDialog.SetButtons(buttons)
+	errDialog.Resize(fyne.NewSize(520, 0))
+	errDialog.Show()
+	return true
+}
+
+// openProxySettings 展开高级选项并聚焦代理地址输入框
+func (m *Manager) openProxySettings() {
+	if m.advancedOptions == nil || m.proxyEntry == nil {
+		return
+	}
+	m.advancedOptions.Open(0)
+	m.window.Canvas().Focus(m.proxyEntry)
+}
+
+// openAPIKeyPage 打开 Kimi 的 API Key 管理页面，其他服务商聚焦 API Key 输入框以便重新填写
+func (m *Manager) openAPIKeyPage() {
+	if provider, err := m.selectedProvider(); err == nil && provider.Name == installer.DefaultProvider().Name {
+		m.openURL(kimiAPIKeyURL)
+	}
+	m.window.Canvas().Focus(m.apiKeyEntry)
+}
+
+// relaunchElevated 以管理员身份重新启动程序并退出当前进程
+func (m *Manager) relaunchElevated() {
+	if err := installer.RelaunchElevated(); err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	fyne.CurrentApp().Quit()
+}
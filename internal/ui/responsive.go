@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// narrowLayoutWidth 窗口宽度小于该值时左右面板改为上下排列
+	narrowLayoutWidth = 1100
+	// shortLayoutHeight 窗口高度小于该值时（如 1366x768、1280x720 的笔记本）缩小日志区
+	shortLayoutHeight = 820
+
+	logMinHeight      = 500
+	logMinHeightShort = 240
+
+	// screenQueryTimeout 查询屏幕尺寸的超时时间
+	screenQueryTimeout = 3 * time.Second
+)
+
+// resizeWatcher 包裹窗口内容，尺寸变化时回调，用于按窗口大小调整布局
+type resizeWatcher struct {
+	widget.BaseWidget
+	content  fyne.CanvasObject
+	onResize func(fyne.Size)
+	last     fyne.Size
+}
+
+func newResizeWatcher(content fyne.CanvasObject, onResize func(fyne.Size)) *resizeWatcher {
+	w := &resizeWatcher{content: content, onResize: onResize}
+	w.ExtendBaseWidget(w)
+	return w
+}
+
+func (w *resizeWatcher) Resize(size fyne.Size) {
+	if size != w.last {
+		w.last = size
+		w.onResize(size)
+	}
+	w.BaseWidget.Resize(size)
+}
+
+func (w *resizeWatcher) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(w.content)
+}
+
+// onWindowResize 窄窗口上下排列面板，矮窗口缩小日志区，在主线程中调用
+func (m *Manager) onWindowResize(size fyne.Size) {
+	narrow := size.Width < narrowLayoutWidth
+	if m.panels == nil || (len(m.panels.Objects) > 0 && narrow == m.narrowLayout) {
+		m.setLogMinHeight(size.Height < shortLayoutHeight)
+		return
+	}
+
+	m.narrowLayout = narrow
+	if narrow {
+		m.panels.Objects = []fyne.CanvasObject{container.NewVBox(m.leftPanel, m.rightPanel)}
+	} else {
+		// 左右分栏布局 - 左边65%，右边35%
+		split := container.NewHSplit(m.leftPanel, m.rightPanel)
+		split.SetOffset(0.65)
+		m.panels.Objects = []fyne.CanvasObject{split}
+	}
+	m.setLogMinHeight(size.Height < shortLayoutHeight)
+	m.panels.Refresh()
+}
+
+// setLogMinHeight 按窗口高度设置日志区的最小高度
+func (m *Manager) setLogMinHeight(short bool) {
+	if m.logScroll == nil {
+		return
+	}
+	height := float32(logMinHeight)
+	if short {
+		height = logMinHeightShort
+	}
+	if m.logScroll.MinSize().Height != height {
+		m.logScroll.SetMinSize(fyne.NewSize(0, height))
+		m.logScroll.Refresh()
+	}
+}
+
+// FitWindowToScreen 在后台查询屏幕可用尺寸，窗口超出时缩小并居中
+func FitWindowToScreen(window fyne.Window, size fyne.Size) {
+	go func() {
+		width, height, err := screenSize()
+		if err != nil {
+			return
+		}
+
+		fitted := fyne.NewSize(
+			min(size.Width, float32(width)*0.95),
+			min(size.Height, float32(height)*0.9),
+		)
+		if fitted == size {
+			return
+		}
+		fyne.Do(func() {
+			window.Resize(fitted)
+			window.CenterOnScreen()
+		})
+	}()
+}
+
+// screenSize 返回主屏幕的可用尺寸（Windows 不含任务栏）
+func screenSize() (int, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), screenQueryTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command",
+			`Add-Type -AssemblyName System.Windows.Forms; $a = [System.Windows.Forms.Screen]::PrimaryScreen.WorkingArea; "$($a.Width) $($a.Height)"`)
+	case "darwin":
+		// 返回 "0, 0, 宽, 高"
+		cmd = exec.CommandContext(ctx, "osascript", "-e", `tell application "Finder" to get bounds of window of desktop`)
+	default:
+		// 输出中包含 "current 1920 x 1080"
+		cmd = exec.CommandContext(ctx, "xrandr", "--current")
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseScreenSize(runtime.GOOS, string(output))
+}
+
+var xrandrCurrentRe = regexp.MustCompile(`current (\d+) x (\d+)`)
+
+// parseScreenSize 解析各平台查询屏幕尺寸命令的输出
+func parseScreenSize(goos, output string) (int, int, error) {
+	var fields []string
+	switch goos {
+	case "darwin":
+		parts := strings.Split(strings.TrimSpace(output), ",")
+		if len(parts) == 4 {
+			fields = parts[2:]
+		}
+	case "windows":
+		fields = strings.Fields(output)
+	default:
+		if match := xrandrCurrentRe.FindStringSubmatch(output); match != nil {
+			fields = match[1:]
+		}
+	}
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("无法解析屏幕尺寸: %q", strings.TrimSpace(output))
+	}
+
+	width, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("无法解析屏幕尺寸: %q", strings.TrimSpace(output))
+	}
+	height, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("无法解析屏幕尺寸: %q", strings.TrimSpace(output))
+	}
+	return width, height, nil
+}
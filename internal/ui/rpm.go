@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+)
+
+// rpmHintText 返回速率限制的说明：实际写入的请求间隔，以及超过免费档位时需要的充值档位
+func rpmHintText(provider installer.Provider, rpmText string) string {
+	delay, note := provider.RequestDelay(rpmText)
+	lines := []string{i18n.T("rpm.delay", delay)}
+	if note != "" {
+		lines = append(lines, "⚠️ "+note)
+	}
+
+	rpm, err := strconv.Atoi(strings.TrimSpace(rpmText))
+	if err != nil {
+		return strings.Join(lines, "\n")
+	}
+	freeRPM, ok := provider.FreeRPM()
+	if !ok || rpm <= freeRPM {
+		return strings.Join(lines, "\n")
+	}
+
+	tier, _ := provider.RequiredTier(rpm)
+	if rpm > tier.RPM {
+		lines = append(lines, i18n.T("rpm.above_max", rpm, provider.Name, tier.Label, tier.RPM))
+	} else {
+		lines = append(lines, i18n.T("rpm.paid_tier", rpm, freeRPM, tier.Label))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// updateRPMHint 按当前服务商和输入的速率限制刷新说明，只在主线程中调用
+func (m *Manager) updateRPMHint() {
+	if m.rpmHint == nil || m.rpmEntry == nil {
+		return
+	}
+	provider, err := m.selectedProvider()
+	if err != nil {
+		// 自定义网关地址尚未填写完整时，按自定义网关的默认值计算
+		provider = installer.Provider{DefaultRPM: 60}
+	}
+	m.rpmHint.SetText(rpmHintText(provider, m.rpmEntry.Text))
+}
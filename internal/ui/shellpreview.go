@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// confirmShellConfigChanges 永久设置环境变量前按配置方式预览将对 shell 配置文件和 settings.json 做的修改，
+// 确认后调用 proceed；没有需要修改的文件时（如 Windows 上只写入系统环境变量）直接继续
+func (m *Manager) confirmShellConfigChanges(provider installer.Provider, apiKey, rpm string, proceed func()) {
+	changes, err := m.installer.PlanShellConfigChanges(provider, apiKey, rpm)
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+	if len(changes) == 0 {
+		proceed()
+		return
+	}
+
+	var sections []string
+	for _, change := range changes {
+		var header string
+		switch {
+		case change.Err != nil:
+			header = i18n.T("shell_preview.read_failed", change.Path, change.Err)
+		case change.Created:
+			header = i18n.T("shell_preview.create", change.Path)
+		case change.Replaced:
+			header = i18n.T("shell_preview.replace", change.Path)
+		default:
+			header = i18n.T("shell_preview.append", change.Path)
+		}
+		sections = append(sections, header+"\n\n"+change.Diff())
+	}
+
+	hintLabel := widget.NewLabel(i18n.T("shell_preview.hint"))
+	hintLabel.Wrapping = fyne.TextWrapWord
+
+	diffLabel := widget.NewLabel(strings.Join(sections, "\n"))
+	diffLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	diffScroll := container.NewScroll(diffLabel)
+	diffScroll.SetMinSize(fyne.NewSize(600, 260))
+
+	content := container.NewBorder(hintLabel, nil, nil, nil, diffScroll)
+	confirmDialog := dialog.NewCustomConfirm(i18n.T("shell_preview.title"), i18n.T("shell_preview.confirm"), i18n.T("button.cancel"), content,
+		func(ok bool) {
+			if ok {
+				proceed()
+			}
+		}, m.window)
+	confirmDialog.Resize(fyne.NewSize(680, 0))
+	confirmDialog.Show()
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+)
+
+// linuxTerminals 常见的 Linux 终端模拟器及其执行命令的参数，按优先级排列
+var linuxTerminals = []struct {
+	name string
+	args []string // 放在要执行的命令之前的参数
+}{
+	{"x-terminal-emulator", []string{"-e"}},
+	{"gnome-terminal", []string{"--"}},
+	{"konsole", []string{"-e"}},
+	{"xfce4-terminal", []string{"-x"}},
+	{"xterm", []string{"-e"}},
+}
+
+// linuxTerminalCommand 在找到的第一个终端模拟器中执行 argv，找不到终端时返回 nil
+func linuxTerminalCommand(argv ...string) *exec.Cmd {
+	for _, terminal := range linuxTerminals {
+		path, err := exec.LookPath(terminal.name)
+		if err != nil {
+			continue
+		}
+		args := append(append([]string{}, terminal.args...), argv...)
+		return exec.Command(path, args...)
+	}
+	return nil
+}
+
+// linuxClaudeShell 返回在新终端中启动 Claude Code 的 shell 命令
+// 永久设置时用用户的交互式 shell 加载 rc 文件中的 K2 配置；否则先加载临时脚本
+// projectDir 不为空时先进入该目录
+func linuxClaudeShell(useSystemConfig bool, setupScript, projectDir string) []string {
+	if !useSystemConfig {
+		if _, err := os.Stat(setupScript); err == nil {
+			return []string{"bash", "-c", withProjectDir(projectDir, "source "+setupScript+"; claude; exec bash")}
+		}
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "bash"
+	}
+	return []string{shell, "-ic", withProjectDir(projectDir, "claude; exec "+filepath.Base(shell))}
+}
+
+// withProjectDir 在 shell 命令前加上进入项目目录，目录为空时原样返回
+func withProjectDir(projectDir, shellCmd string) string {
+	if projectDir == "" {
+		return shellCmd
+	}
+	return "cd " + installer.ShellQuote(projectDir) + " && " + shellCmd
+}
+
+// macTerminalAuto 配置中表示自动选择 macOS 终端：优先使用已安装的第三方终端，最后回退到 Terminal.app
+const macTerminalAuto = ""
+
+// macTerminalLabel 返回终端在选择框中显示的名称，自动检测随界面语言显示
+func macTerminalLabel(terminal string) string {
+	if terminal == macTerminalAuto {
+		return i18n.T("terminal.auto")
+	}
+	return terminal
+}
+
+// macTerminalFromLabel 把选择框中显示的名称转换为配置中保存的值
+func macTerminalFromLabel(label string) string {
+	if label == i18n.T("terminal.auto") {
+		return macTerminalAuto
+	}
+	return label
+}
+
+// macTerminals 支持的 macOS 终端应用，按自动检测的优先级排列
+var macTerminals = []struct {
+	name string // 界面显示和配置中保存的名称
+	app  string // /Applications 下的应用名
+}{
+	{"iTerm2", "iTerm.app"},
+	{"Warp", "Warp.app"},
+	{"Terminal", "Terminal.app"},
+}
+
+// macTerminalInstalled 检查终端应用是否安装在 /Applications 或 ~/Applications 中
+func macTerminalInstalled(app string) bool {
+	dirs := []string{"/Applications", "/System/Applications/Utilities", "/Applications/Utilities"}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, "Applications"))
+	}
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, app)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// macTerminalOptions 返回终端选择框的选项：自动检测加上已安装的终端
+func macTerminalOptions() []string {
+	options := []string{macTerminalLabel(macTerminalAuto)}
+	for _, terminal := range macTerminals {
+		if terminal.name == "Terminal" || macTerminalInstalled(terminal.app) {
+			options = append(options, terminal.name)
+		}
+	}
+	return options
+}
+
+// resolveMacTerminal 返回实际使用的终端，偏好的终端未安装时自动检测
+func resolveMacTerminal(preferred string) string {
+	for _, terminal := range macTerminals {
+		if terminal.name == preferred && macTerminalInstalled(terminal.app) {
+			return terminal.name
+		}
+	}
+	for _, terminal := range macTerminals {
+		if macTerminalInstalled(terminal.app) {
+			return terminal.name
+		}
+	}
+	return "Terminal"
+}
+
+// appleScriptQuote 转义 AppleScript 字符串中的反斜杠和双引号
+func appleScriptQuote(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+}
+
+// macTerminalCommand 在指定的 macOS 终端中执行 shell 命令
+// Terminal 和 iTerm2 通过 AppleScript 控制；Warp 不支持 AppleScript，改为打开 .command 脚本
+func macTerminalCommand(terminal, shellCmd string) (*exec.Cmd, error) {
+	switch terminal {
+	case "iTerm2":
+		script := fmt.Sprintf(`tell application "iTerm"
+				activate
+				set newWindow to (create window with default profile)
+				tell current session of newWindow to write text "%s"
+			end tell`, appleScriptQuote(shellCmd))
+		return exec.Command("osascript", "-e", script), nil
+	case "Warp":
+		launcher := filepath.Join(os.TempDir(), "claude_k2_launch.command")
+		content := "#!/bin/bash\n" + shellCmd + "\nexec \"${SHELL:-/bin/zsh}\" -l\n"
+		if err := os.WriteFile(launcher, []byte(content), 0755); err != nil {
+			return nil, errors.New(i18n.T("error.launcher_script", err))
+		}
+		return exec.Command("open", "-a", "Warp", launcher), nil
+	default:
+		script := fmt.Sprintf(`tell application "Terminal"
+				do script "%s"
+				activate
+			end tell`, appleScriptQuote(shellCmd))
+		return exec.Command("osascript", "-e", script), nil
+	}
+}
@@ -7,9 +7,48 @@ import (
 	"fyne.io/fyne/v2/theme"
 )
 
-type CustomTheme struct{}
+// ThemeMode 外观模式，为空时跟随系统设置
+type ThemeMode string
+
+const (
+	ThemeModeSystem ThemeMode = ""
+	ThemeModeLight  ThemeMode = "light"
+	ThemeModeDark   ThemeMode = "dark"
+)
+
+// UIScales 界面可选的缩放比例，用于高分屏或低视力用户放大文字和间距
+var UIScales = []float32{1.0, 1.25, 1.5}
+
+// normalizeScale 未设置或超出范围时使用 1.0
+func normalizeScale(scale float32) float32 {
+	if scale < 1 || scale > 2 {
+		return 1
+	}
+	return scale
+}
+
+type CustomTheme struct {
+	Mode  ThemeMode // 强制浅色或深色，为空时使用系统传入的 variant
+	Scale float32   // 文字、间距和图标的缩放比例，0 表示 1.0
+}
+
+// variant 返回实际使用的明暗模式
+func (m *CustomTheme) variant(variant fyne.ThemeVariant) fyne.ThemeVariant {
+	switch m.Mode {
+	case ThemeModeLight:
+		return theme.VariantLight
+	case ThemeModeDark:
+		return theme.VariantDark
+	}
+	return variant
+}
 
 func (m *CustomTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	variant = m.variant(variant)
+	if variant == theme.VariantDark {
+		return m.darkColor(name)
+	}
+
 	switch name {
 	case theme.ColorNamePrimary:
 		return color.RGBA{R: 0, G: 122, B: 255, A: 255} // iOS 蓝色
@@ -49,6 +88,44 @@ func (m *CustomTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant)
 	return theme.DefaultTheme().Color(name, variant)
 }
 
+// darkColor 深色模式配色：深蓝灰背景 + 浅色文字，保留 iOS 蓝色强调色
+func (m *CustomTheme) darkColor(name fyne.ThemeColorName) color.Color {
+	switch name {
+	case theme.ColorNamePrimary:
+		return color.RGBA{R: 0, G: 122, B: 255, A: 255} // iOS 蓝色
+	case theme.ColorNameButton:
+		return color.RGBA{R: 0, G: 122, B: 255, A: 255}
+	case theme.ColorNameBackground:
+		return color.RGBA{R: 15, G: 23, B: 42, A: 255} // 深蓝灰色背景
+	case theme.ColorNameForeground:
+		return color.RGBA{R: 226, G: 232, B: 240, A: 255} // 浅灰色文字
+	case theme.ColorNameDisabled:
+		return color.RGBA{R: 100, G: 116, B: 139, A: 255} // 柔和的灰色
+	case theme.ColorNamePlaceHolder:
+		return color.RGBA{R: 148, G: 163, B: 184, A: 255} // 中等灰色
+	case theme.ColorNamePressed:
+		return color.RGBA{R: 59, G: 130, B: 246, A: 255} // 按下时的蓝色
+	case theme.ColorNameHover:
+		return color.RGBA{R: 51, G: 65, B: 85, A: 255} // 悬停时的浅一级背景
+	case theme.ColorNameFocus:
+		return color.RGBA{R: 129, G: 140, B: 248, A: 255} // 聚焦时的紫蓝色
+	case theme.ColorNameSelection:
+		return color.RGBA{R: 30, G: 64, B: 175, A: 255} // 选择时的深蓝色
+	case theme.ColorNameSeparator:
+		return color.RGBA{R: 51, G: 65, B: 85, A: 255} // 分隔线颜色
+	case theme.ColorNameInputBackground, theme.ColorNameMenuBackground,
+		theme.ColorNameOverlayBackground, theme.ColorNameHeaderBackground:
+		return color.RGBA{R: 30, G: 41, B: 59, A: 255} // 输入框、菜单和弹窗背景
+	case theme.ColorNameError:
+		return color.RGBA{R: 248, G: 113, B: 113, A: 255} // 红色错误
+	case theme.ColorNameSuccess:
+		return color.RGBA{R: 74, G: 222, B: 128, A: 255} // 绿色成功
+	case theme.ColorNameWarning:
+		return color.RGBA{R: 251, G: 191, B: 36, A: 255} // 橙色警告
+	}
+	return theme.DefaultTheme().Color(name, theme.VariantDark)
+}
+
 func (m *CustomTheme) Font(style fyne.TextStyle) fyne.Resource {
 	// 使用默认主题的字体，Fyne 2.6+ 会自动处理中文
 	return theme.DefaultTheme().Font(style)
@@ -59,19 +136,94 @@ func (m *CustomTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
 }
 
 func (m *CustomTheme) Size(name fyne.ThemeSizeName) float32 {
+	scale := normalizeScale(m.Scale)
 	switch name {
 	case theme.SizeNamePadding:
-		return 8
+		return 8 * scale
 	case theme.SizeNameInlineIcon:
-		return 20
+		return 20 * scale
 	case theme.SizeNameScrollBar:
 		return 16
 	case theme.SizeNameText:
-		return 15
+		return 15 * scale
 	}
 	return theme.DefaultTheme().Size(name)
 }
 
+// HighContrastTheme 高对比度主题：纯黑白配色 + 深色强调色，加粗边框
+// 所有文字与其背景的组合均满足 WCAG AA（>= 4.5:1），除焦点框外均达到 AAA（>= 7:1）
+type HighContrastTheme struct {
+	CustomTheme
+}
+
+func (m *HighContrastTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	switch name {
+	case theme.ColorNamePrimary, theme.ColorNameHyperlink:
+		return color.RGBA{R: 0, G: 0, B: 204, A: 255} // 深蓝，与白色文字/白底对比度 11.2:1
+	case theme.ColorNameButton:
+		// 普通按钮的文字使用前景色（黑色），背景用浅灰，黑字对比度 14.9:1
+		return color.RGBA{R: 217, G: 217, B: 217, A: 255}
+	case theme.ColorNameForegroundOnPrimary, theme.ColorNameForegroundOnError,
+		theme.ColorNameForegroundOnSuccess, theme.ColorNameForegroundOnWarning:
+		return color.White
+	case theme.ColorNameBackground, theme.ColorNameInputBackground,
+		theme.ColorNameMenuBackground, theme.ColorNameOverlayBackground,
+		theme.ColorNameHeaderBackground:
+		return color.White // 纯白背景
+	case theme.ColorNameForeground, theme.ColorNameSeparator, theme.ColorNameInputBorder:
+		return color.Black // 纯黑文字和边框，对比度 21:1
+	case theme.ColorNameDisabled, theme.ColorNamePlaceHolder:
+		return color.RGBA{R: 89, G: 89, B: 89, A: 255} // 深灰，白底对比度 7:1
+	case theme.ColorNameDisabledButton:
+		return color.RGBA{R: 230, G: 230, B: 230, A: 255} // 与禁用文字的深灰对比度 5.6:1
+	case theme.ColorNamePressed:
+		return color.RGBA{R: 0, G: 0, B: 0, A: 102} // 按下时加深
+	case theme.ColorNameHover:
+		return color.RGBA{R: 0, G: 0, B: 0, A: 51} // 悬停时加深
+	case theme.ColorNameFocus:
+		return color.RGBA{R: 204, G: 0, B: 0, A: 255} // 高饱和红色焦点框，白底对比度 5.9:1
+	case theme.ColorNameSelection:
+		return color.RGBA{R: 255, G: 255, B: 0, A: 255} // 黄色选中，黑字对比度 19.6:1
+	case theme.ColorNameScrollBar:
+		return color.RGBA{R: 0, G: 0, B: 0, A: 200}
+	case theme.ColorNameError:
+		return color.RGBA{R: 176, G: 0, B: 32, A: 255} // 深红，白底对比度 7.3:1
+	case theme.ColorNameSuccess:
+		return color.RGBA{R: 0, G: 100, B: 0, A: 255} // 深绿，白底对比度 7.4:1
+	case theme.ColorNameWarning:
+		return color.RGBA{R: 122, G: 74, B: 0, A: 255} // 深琥珀色，白底/白字对比度 7.5:1
+	}
+	return m.CustomTheme.Color(name, variant)
+}
+
+func (m *HighContrastTheme) Size(name fyne.ThemeSizeName) float32 {
+	switch name {
+	case theme.SizeNameInputBorder:
+		return 3 // 加粗输入框边框
+	case theme.SizeNameSeparatorThickness:
+		return 2 // 加粗分隔线
+	}
+	return m.CustomTheme.Size(name)
+}
+
+// NewTheme 根据是否启用高对比度、外观模式和缩放比例返回对应主题
+// 高对比度主题固定使用黑白配色，不区分深浅色
+func NewTheme(highContrast bool, mode ThemeMode, scale float32) fyne.Theme {
+	if highContrast {
+		return &HighContrastTheme{CustomTheme{Scale: scale}}
+	}
+	return &CustomTheme{Mode: mode, Scale: scale}
+}
+
+// LoadTheme 根据已保存的配置返回主题
+func LoadTheme() fyne.Theme {
+	config, err := LoadConfig()
+	if err != nil {
+		return NewTheme(false, ThemeModeSystem, 1)
+	}
+	return NewTheme(config.HighContrast, ThemeMode(config.ThemeMode), config.UIScale)
+}
+
 var (
 	DefaultWindowSize = fyne.NewSize(1440, 1000) // 宽度增加20%，从1200到1440
 	SuccessColor     = color.RGBA{R: 52, G: 199, B: 89, A: 255}
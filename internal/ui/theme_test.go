@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// relativeLuminance WCAG 2.x 定义的相对亮度
+func relativeLuminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	channel := func(v uint32) float64 {
+		s := float64(v) / 0xffff
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(r) + 0.7152*channel(g) + 0.0722*channel(b)
+}
+
+func contrastRatio(a, b color.Color) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+func TestHighContrastThemeMeetsWCAGAA(t *testing.T) {
+	th := &HighContrastTheme{}
+	c := func(name fyne.ThemeColorName) color.Color { return th.Color(name, theme.VariantLight) }
+
+	pairs := []struct {
+		text, background fyne.ThemeColorName
+	}{
+		{theme.ColorNameForeground, theme.ColorNameBackground},
+		{theme.ColorNameForeground, theme.ColorNameButton},
+		{theme.ColorNameForegroundOnPrimary, theme.ColorNamePrimary},
+		{theme.ColorNameForegroundOnError, theme.ColorNameError},
+		{theme.ColorNameForegroundOnSuccess, theme.ColorNameSuccess},
+		{theme.ColorNameForegroundOnWarning, theme.ColorNameWarning},
+		{theme.ColorNameHyperlink, theme.ColorNameBackground},
+		{theme.ColorNameWarning, theme.ColorNameBackground},
+		{theme.ColorNameError, theme.ColorNameBackground},
+		{theme.ColorNameSuccess, theme.ColorNameBackground},
+		{theme.ColorNameDisabled, theme.ColorNameBackground},
+		{theme.ColorNameDisabled, theme.ColorNameDisabledButton},
+		{theme.ColorNameForeground, theme.ColorNameSelection},
+	}
+	for _, p := range pairs {
+		if ratio := contrastRatio(c(p.text), c(p.background)); ratio < 4.5 {
+			t.Errorf("%s on %s has contrast %.2f:1, want at least 4.5:1", p.text, p.background, ratio)
+		}
+	}
+}
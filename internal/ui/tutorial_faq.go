@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createFAQ 创建常见问题列表：点击问题展开答案和对应的操作按钮，输入关键字只显示匹配的问题
+func (t *TutorialWithImages) createFAQ() fyne.CanvasObject {
+	items := make([]*widget.AccordionItem, 0, len(faqEntries))
+	searchTexts := make([]string, 0, len(faqEntries))
+	for _, entry := range faqEntries {
+		question := i18n.T("faq." + entry.ID)
+		answer := i18n.T("faq." + entry.ID + "_answer")
+
+		answerLabel := widget.NewLabel(answer)
+		answerLabel.Wrapping = fyne.TextWrapWord
+		var detail fyne.CanvasObject = answerLabel
+		if action := t.FAQActions[entry.Action]; action != nil {
+			button := widget.NewButton(i18n.T("faq.do_"+entry.Action), action)
+			detail = container.NewVBox(answerLabel, container.NewHBox(button))
+		}
+
+		items = append(items, widget.NewAccordionItem(question, detail))
+		searchTexts = append(searchTexts, strings.ToLower(question+"\n"+answer))
+	}
+
+	accordion := widget.NewAccordion(items...)
+	noMatchLabel := widget.NewLabel(i18n.T("faq.no_match"))
+	noMatchLabel.Wrapping = fyne.TextWrapWord
+	noMatchLabel.Hide()
+
+	searchEntry := widget.NewEntry()
+	searchEntry.SetPlaceHolder(i18n.T("faq.search"))
+	searchEntry.OnChanged = func(query string) {
+		query = strings.ToLower(strings.TrimSpace(query))
+		var matched []*widget.AccordionItem
+		for n, text := range searchTexts {
+			if query == "" || strings.Contains(text, query) {
+				matched = append(matched, items[n])
+			}
+		}
+		accordion.Items = matched
+		accordion.Refresh()
+		if len(matched) == 0 {
+			noMatchLabel.Show()
+		} else {
+			noMatchLabel.Hide()
+		}
+	}
+
+	return container.NewVBox(searchEntry, accordion, noMatchLabel)
+}
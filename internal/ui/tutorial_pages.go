@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"claude-k2-installer/assets"
+	"claude-k2-installer/internal/i18n"
+)
+
+// tutorialPageSpec 教程页面的定义，文字按 ID 从 i18n 中取出：
+// 标题为 tutorial.<ID>，正文为 tutorial.<ID>_body，按钮文字为 tutorial.<ID>_button
+type tutorialPageSpec struct {
+	ID        string
+	Image     string // tutorialImages 中的配图键，为空时没有配图
+	ButtonURL string // 不为空时显示打开该链接的按钮
+	FAQ       bool   // 正文下方显示常见问题列表
+}
+
+// tutorialPageSpecs 全部教程页面，两种教程按 ID 选用
+var tutorialPageSpecs = map[string]tutorialPageSpec{
+	"welcome":     {ID: "welcome"},
+	"claude_code": {ID: "claude_code"},
+	"k2":          {ID: "k2"},
+	"get_key":     {ID: "get_key"},
+	"charge":      {ID: "charge", ButtonURL: kimiChargeURL},
+	"key_page":    {ID: "key_page", Image: "api-key-page", ButtonURL: kimiAPIKeyURL},
+	"create_key":  {ID: "create_key", Image: "create-api-key"},
+	"save_key":    {ID: "save_key", Image: "api-key-created"},
+	"first_run":   {ID: "first_run", Image: "select-key"},
+	"usage":       {ID: "usage"},
+	"faq":         {ID: "faq", FAQ: true},
+}
+
+// textTutorialPages 纯文字教程的页面顺序
+var textTutorialPages = []string{"welcome", "claude_code", "k2", "get_key", "usage"}
+
+// imageTutorialPages 图文教程的页面顺序
+var imageTutorialPages = []string{"welcome", "charge", "key_page", "create_key", "save_key", "first_run", "usage", "faq"}
+
+// tutorialImages 教程配图，键与 assets/images 下的文件名对应
+var tutorialImages = map[string][]byte{
+	"api-key-page":    assets.APIKeyPageImage,
+	"create-api-key":  assets.CreateAPIKeyImage,
+	"api-key-created": assets.APIKeyCreatedImage,
+	"select-key":      assets.ClaudeFirstRunImage,
+}
+
+// buildTutorialPages 按页面顺序和当前语言生成教程页面
+func buildTutorialPages(ids []string) []TutorialPageWithImage {
+	pages := make([]TutorialPageWithImage, 0, len(ids))
+	for _, id := range ids {
+		spec := tutorialPageSpecs[id]
+		page := TutorialPageWithImage{
+			Title:   i18n.T("tutorial." + id),
+			Content: i18n.T("tutorial." + id + "_body"),
+			Image:   spec.Image,
+			FAQ:     spec.FAQ,
+		}
+		if spec.ButtonURL != "" {
+			page.ShowButton = true
+			page.ButtonText = i18n.T("tutorial." + id + "_button")
+			page.ButtonURL = spec.ButtonURL
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// faqEntry 常见问题，问题为 faq.<ID>，答案为 faq.<ID>_answer，操作按钮文字为 faq.do_<Action>
+type faqEntry struct {
+	ID     string
+	Action string // TutorialWithImages.FAQActions 中的操作键，为空时没有操作按钮
+}
+
+// 常见问题的操作，由打开教程的界面提供
+const (
+	faqActionRecharge    = "recharge"
+	faqActionAPIKeys     = "api_keys"
+	faqActionVerify      = "verify"
+	faqActionCopyCommand = "copy_command"
+)
+
+// faqEntries 教程中的常见问题，按出现频率排列
+var faqEntries = []faqEntry{
+	{ID: "429", Action: faqActionRecharge},
+	{ID: "not_found", Action: faqActionVerify},
+	{ID: "not_applied", Action: faqActionCopyCommand},
+	{ID: "change_key"},
+	{ID: "401", Action: faqActionAPIKeys},
+	{ID: "balance", Action: faqActionRecharge},
+}
@@ -1,7 +1,7 @@
 package ui
 
 import (
-	"claude-k2-installer/assets"
+	"claude-k2-installer/internal/i18n"
 	"fmt"
 	"image/color"
 	"net/url"
@@ -17,6 +17,9 @@ type TutorialWithImages struct {
 	parent  fyne.Window
 	current int
 	pages   []TutorialPageWithImage
+
+	// FAQActions 常见问题的操作按钮，键为 faqAction* 常量，没有提供的操作不显示按钮
+	FAQActions map[string]func()
 }
 
 // ImageClickable 可点击的图片组件
@@ -106,116 +109,25 @@ func (r *tappableRenderer) Destroy() {}
 type TutorialPageWithImage struct {
 	Title      string
 	Content    string
-	ImageData  []byte
+	Image      string // tutorialImages 中的配图键，为空时没有配图
 	ShowButton bool
 	ButtonText string
 	ButtonURL  string
+	FAQ        bool // 正文下方显示常见问题列表
 }
 
 func NewTutorialWithImages(parent fyne.Window) *TutorialWithImages {
 	return &TutorialWithImages{
 		parent:  parent,
 		current: 0,
-		pages: []TutorialPageWithImage{
-			{
-				Title: "欢迎使用 Claude Code + K2 集成工具",
-				Content: `本工具将帮助你一键安装和配置 Claude Code 与 Kimi K2 大模型环境。
-
-主要功能：
-• 自动检测并安装必要的依赖（Node.js、Git）
-• 一键安装 Claude Code CLI 工具
-• 自动配置 Kimi K2 API
-• 无需手动输入复杂命令
-
-点击"下一步"继续了解详细信息。`,
-			},
-			{
-				Title: "重要提醒：请先充值",
-				Content: `⚠️ 重要提醒：使用前请先充值！
-
-免费账户限制：
-• RPM（每分钟请求数）仅为 3 次
-• 无法满足 Claude Code 正常使用需求
-• 会频繁出现 429 错误
-
-建议操作：
-• 实测最少充值 50 元才不会影响使用
-• 充值后 RPM 限制将提升至 200
-• 确保工具能够正常使用`,
-				ShowButton: true,
-				ButtonText: "前往充值",
-				ButtonURL:  "https://platform.moonshot.cn/console/pay",
-			},
-			{
-				Title: "步骤1：进入 API Key 管理页面",
-				Content: `登录 Kimi 平台后，点击左侧菜单的"API Key 管理"。
-
-在页面右上角，点击"新建 API Key"按钮（如下图红色箭头所示）。`,
-				ImageData:  assets.APIKeyPageImage,
-				ShowButton: true,
-				ButtonText: "打开 API Key 管理页面",
-				ButtonURL:  "https://platform.moonshot.cn/console/api-keys",
-			},
-			{
-				Title: "步骤2：创建新的 API Key",
-				Content: `在弹出的对话框中：
-
-1. 输入 API Key 名称（如：这里使用默认）
-2. 选择项目（默认为 default）
-3. 点击"确定"按钮创建
-
-注意：创建前请确保已经充值，否则无法正常使用。`,
-				ImageData: assets.CreateAPIKeyImage,
-			},
-			{
-				Title: "步骤3：保存你的 API Key",
-				Content: `⚠️ 重要：请立即复制并保存你的 API Key！
-
-• 密钥只会显示一次
-• 关闭对话框后将无法再次查看
-• 请将密钥保存在安全的地方
-
-复制 sk- 开头的完整密钥，然后将其粘贴到本工具的 API Key 输入框中。`,
-				ImageData: assets.APIKeyCreatedImage,
-			},
-			{
-				Title: "步骤4：首次运行 Claude Code",
-				Content: `打开 Claude Code 后，会检测到你的自定义 API Key：
-
-1. 系统会显示检测到的 API Key（如图所示）
-2. 询问是否使用此 API Key
-3. 这里要按↑键盘，选择 "1. Yes"
-4. 然后按回车键继续
-
-⚠️ 重要提醒：
-• 一定要选择 "Yes" 使用检测到的 K2 API
-• 系统已经为你配置好了 Kimi K2 的 API
-• 如果选择 No，将无法使用配置好的 K2 服务`,
-				ImageData: assets.ClaudeFirstRunImage,
-			},
-			{
-				Title: "安装完成后的使用",
-				Content: `安装完成后，你可以：
-
-1. 在终端运行 'claude' 命令启动 Claude Code
-2. 使用 Claude Code 进行 AI 辅助编程
-3. 享受 K2 模型带来的高性价比体验
-
-常用命令：
-• claude - 启动交互模式
-• claude --help - 查看帮助信息
-• claude --version - 查看版本
-
-祝你使用愉快！`,
-			},
-		},
+		pages:   buildTutorialPages(imageTutorialPages),
 	}
 }
 
 func (t *TutorialWithImages) Show() {
 	content := t.createContent()
 
-	d := dialog.NewCustom("使用教程", "关闭", content, t.parent)
+	d := dialog.NewCustom(i18n.T("tutorial.title"), i18n.T("button.close"), content, t.parent)
 	d.Resize(fyne.NewSize(800, 600))
 	d.Show()
 }
@@ -233,8 +145,8 @@ func (t *TutorialWithImages) createContent() fyne.CanvasObject {
 	var mainContent fyne.CanvasObject
 
 	// 如果当前页有图片，显示图片
-	if t.pages[t.current].ImageData != nil {
-		imageResource := fyne.NewStaticResource("tutorial-image", t.pages[t.current].ImageData)
+	if imageData := tutorialImages[t.pages[t.current].Image]; imageData != nil {
+		imageResource := fyne.NewStaticResource("tutorial-"+t.pages[t.current].Image, imageData)
 		image := canvas.NewImageFromResource(imageResource)
 		image.FillMode = canvas.ImageFillContain
 		image.SetMinSize(fyne.NewSize(600, 400))
@@ -257,7 +169,7 @@ func (t *TutorialWithImages) createContent() fyne.CanvasObject {
 		clickableImage := container.NewStack(image, clickContainer)
 
 		// 添加提示文字
-		tipLabel := widget.NewLabel("💡 点击图片可放大查看")
+		tipLabel := widget.NewLabel(i18n.T("tutorial.zoom_tip"))
 		tipLabel.TextStyle = fyne.TextStyle{Italic: true}
 		tipLabel.Alignment = fyne.TextAlignCenter
 
@@ -267,6 +179,8 @@ func (t *TutorialWithImages) createContent() fyne.CanvasObject {
 			container.NewCenter(clickableImage),
 			tipLabel,
 		)
+	} else if t.pages[t.current].FAQ {
+		mainContent = container.NewVBox(contentLabel, t.createFAQ())
 	} else {
 		mainContent = contentLabel
 	}
@@ -281,9 +195,14 @@ func (t *TutorialWithImages) createContent() fyne.CanvasObject {
 		})
 		button.Importance = widget.HighImportance
 
+		// 手机扫码打开同一链接
+		qrButton := widget.NewButton(i18n.T("tutorial.scan_open"), func() {
+			showURLQRCodeDialog(t.parent, t.pages[t.current].ButtonText, t.pages[t.current].ButtonURL)
+		})
+
 		mainContent = container.NewVBox(
 			mainContent,
-			container.NewCenter(button),
+			container.NewCenter(container.NewHBox(button, qrButton)),
 		)
 	}
 
@@ -297,7 +216,7 @@ func (t *TutorialWithImages) createContent() fyne.CanvasObject {
 	var prevButton, nextButton *widget.Button
 
 	// 创建导航按钮
-	prevButton = widget.NewButton("上一步", func() {
+	prevButton = widget.NewButton(i18n.T("tutorial.prev"), func() {
 		if t.current > 0 {
 			t.current--
 			t.updateContent(titleLabel, contentLabel, contentScroll)
@@ -306,7 +225,7 @@ func (t *TutorialWithImages) createContent() fyne.CanvasObject {
 		}
 	})
 
-	nextButton = widget.NewButton("下一步", func() {
+	nextButton = widget.NewButton(i18n.T("tutorial.next"), func() {
 		if t.current < len(t.pages)-1 {
 			t.current++
 			t.updateContent(titleLabel, contentLabel, contentScroll)
@@ -340,8 +259,8 @@ func (t *TutorialWithImages) updateContent(title, content *widget.Label, scroll
 	var mainContent fyne.CanvasObject
 
 	// 如果当前页有图片，显示图片
-	if t.pages[t.current].ImageData != nil {
-		imageResource := fyne.NewStaticResource("tutorial-image", t.pages[t.current].ImageData)
+	if imageData := tutorialImages[t.pages[t.current].Image]; imageData != nil {
+		imageResource := fyne.NewStaticResource("tutorial-"+t.pages[t.current].Image, imageData)
 		image := canvas.NewImageFromResource(imageResource)
 		image.FillMode = canvas.ImageFillContain
 		image.SetMinSize(fyne.NewSize(600, 400))
@@ -364,7 +283,7 @@ func (t *TutorialWithImages) updateContent(title, content *widget.Label, scroll
 		clickableImage := container.NewStack(image, clickContainer)
 
 		// 添加提示文字
-		tipLabel := widget.NewLabel("💡 点击图片可放大查看")
+		tipLabel := widget.NewLabel(i18n.T("tutorial.zoom_tip"))
 		tipLabel.TextStyle = fyne.TextStyle{Italic: true}
 		tipLabel.Alignment = fyne.TextAlignCenter
 
@@ -374,6 +293,8 @@ func (t *TutorialWithImages) updateContent(title, content *widget.Label, scroll
 			container.NewCenter(clickableImage),
 			tipLabel,
 		)
+	} else if t.pages[t.current].FAQ {
+		mainContent = container.NewVBox(content, t.createFAQ())
 	} else {
 		mainContent = content
 	}
@@ -388,9 +309,14 @@ func (t *TutorialWithImages) updateContent(title, content *widget.Label, scroll
 		})
 		button.Importance = widget.HighImportance
 
+		// 手机扫码打开同一链接
+		qrButton := widget.NewButton(i18n.T("tutorial.scan_open"), func() {
+			showURLQRCodeDialog(t.parent, t.pages[t.current].ButtonText, t.pages[t.current].ButtonURL)
+		})
+
 		mainContent = container.NewVBox(
 			mainContent,
-			container.NewCenter(button),
+			container.NewCenter(container.NewHBox(button, qrButton)),
 		)
 	}
 
@@ -417,16 +343,12 @@ func (t *TutorialWithImages) updatePageLabel(label *widget.Label) {
 
 // showLargeImage 显示放大的图片
 func (t *TutorialWithImages) showLargeImage(imageResource fyne.Resource) {
-	// 创建放大的图片
-	largeImage := canvas.NewImageFromResource(imageResource)
-	largeImage.FillMode = canvas.ImageFillOriginal // 改为原始尺寸
-
-	// 创建滚动容器以防图片太大
-	imageScroll := container.NewScroll(largeImage)
-	imageScroll.SetMinSize(fyne.NewSize(800, 500))
+	// 可缩放的图片：默认适应窗口，放大后可拖动平移
+	viewer := newZoomableImage(imageResource, fyne.NewSize(800, 500))
+	viewerContent := viewer.content()
 
 	// 创建关闭按钮
-	closeBtn := widget.NewButton("关闭", nil)
+	closeBtn := widget.NewButton(i18n.T("button.close"), nil)
 	closeBtn.Importance = widget.HighImportance
 
 	// 使用 Border 布局，确保图片占据主要空间
@@ -434,11 +356,11 @@ func (t *TutorialWithImages) showLargeImage(imageResource fyne.Resource) {
 		nil,                           // top
 		container.NewCenter(closeBtn), // bottom
 		nil, nil,                      // left, right
-		imageScroll, // center
+		viewerContent,                 // center
 	)
 
 	// 使用 NewCustomConfirm 并只显示确认按钮
-	imageDialog := dialog.NewCustomConfirm("图片预览", "关闭", "", content, func(bool) {}, t.parent)
+	imageDialog := dialog.NewCustomConfirm(i18n.T("tutorial.preview"), i18n.T("button.close"), "", content, func(bool) {}, t.parent)
 
 	// 设置关闭按钮的动作
 	closeBtn.OnTapped = func() {
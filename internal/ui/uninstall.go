@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showUninstallDialog 列出将删除的内容，确认后卸载 Claude Code 并清除 K2 配置
+func (m *Manager) showUninstallDialog() {
+	planLabel := widget.NewLabel("")
+	planLabel.Wrapping = fyne.TextWrapWord
+
+	updatePlan := func(removeData bool) {
+		plan := installer.UninstallPlan(installer.UninstallOptions{RemoveData: removeData})
+		planLabel.SetText(i18n.T("uninstall.plan", strings.Join(plan, "\n• ")))
+	}
+	updatePlan(false)
+
+	removeDataCheck := widget.NewCheck(i18n.T("uninstall.remove_data"), updatePlan)
+
+	content := container.NewVBox(planLabel, removeDataCheck)
+	confirm := dialog.NewCustomConfirm(i18n.T("uninstall.title"), i18n.T("button.uninstall"), i18n.T("button.cancel"), content, func(ok bool) {
+		if ok {
+			m.uninstall(installer.UninstallOptions{RemoveData: removeDataCheck.Checked})
+		}
+	}, m.window)
+	confirm.Resize(fyne.NewSize(520, 0))
+	confirm.Show()
+}
+
+// uninstall 在后台执行卸载，完成后恢复安装按钮
+func (m *Manager) uninstall(opts installer.UninstallOptions) {
+	m.installButton.Disable()
+	m.clearLogs()
+	m.statusLabel.SetText(i18n.T("uninstall.running"))
+
+	go func() {
+		err := m.installer.Uninstall(opts)
+		m.syncLogs()
+
+		m.updateUI(func() {
+			m.installButton.Enable()
+			if err != nil {
+				m.statusLabel.SetText(i18n.T("uninstall.incomplete"))
+				dialog.ShowError(errors.New(i18n.T("error.uninstall", err)), m.window)
+				return
+			}
+
+			// 环境已不完整，恢复为完整安装
+			m.envReady = false
+			m.installButton.SetText(i18n.T("button.install"))
+			m.installButton.Show()
+			m.openButton.Hide()
+			m.reinstallButton.Hide()
+			m.progressBar.SetValue(0)
+			m.resetStepStatuses()
+			m.statusLabel.SetText(i18n.T("uninstall.done"))
+			dialog.ShowInformation(i18n.T("uninstall.done_title"), i18n.T("uninstall.done_message"), m.window)
+			go m.checkEnvironment()
+		})
+	}()
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"claude-k2-installer/internal/i18n"
+	"claude-k2-installer/internal/installer"
+	"claude-k2-installer/internal/updater"
+	"claude-k2-installer/internal/version"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// createUpdateBanner 创建窗口顶部的新版本提示条，发现新版本前隐藏
+func (m *Manager) createUpdateBanner() fyne.CanvasObject {
+	m.updateLabel = widget.NewLabel("")
+	m.updateLabel.Importance = widget.HighImportance
+
+	updateButton := widget.NewButton(i18n.T("update.install"), m.installUpdate)
+	updateButton.Importance = widget.HighImportance
+	notesButton := widget.NewButton(i18n.T("update.notes"), func() {
+		if m.latestRelease != nil {
+			m.openURL(m.latestRelease.PageURL)
+		}
+	})
+	notesButton.Importance = widget.LowImportance
+	closeButton := widget.NewButton("✕", func() {
+		m.updateBanner.Hide()
+	})
+	closeButton.Importance = widget.LowImportance
+
+	m.updateBanner = container.NewVBox(
+		container.NewHBox(m.updateLabel, layout.NewSpacer(), notesButton, updateButton, closeButton),
+		widget.NewSeparator(),
+	)
+	m.updateBanner.Hide()
+	return m.updateBanner
+}
+
+// checkForUpdate 在后台检查新版本，有新版本时显示提示条；检查失败不打扰用户
+func (m *Manager) checkForUpdate() {
+	release, err := updater.CheckForUpdate(m.updateProxy())
+	if err != nil {
+		// 只记录到日志，导出日志时可以看到
+		m.installer.AddLog(fmt.Sprintf("⚠️ %v", err))
+		return
+	}
+	if release == nil {
+		return
+	}
+
+	m.updateUI(func() {
+		m.latestRelease = release
+		m.updateLabel.SetText(i18n.T("update.available", release.Version, version.Version))
+		m.updateBanner.Show()
+	})
+}
+
+// installUpdate 下载新版本替换当前程序，完成后询问是否立即重新启动
+// 没有适用于当前系统的安装包时打开发布页面
+func (m *Manager) installUpdate() {
+	release := m.latestRelease
+	if release == nil {
+		return
+	}
+	if release.AssetURL == "" {
+		m.openURL(release.PageURL)
+		return
+	}
+
+	progress := dialog.NewCustomWithoutButtons(i18n.T("update.downloading_title"),
+		container.NewVBox(widget.NewLabel(i18n.T("update.downloading", release.AssetName)), widget.NewProgressBarInfinite()),
+		m.window)
+	progress.Show()
+
+	proxy := m.updateProxy()
+	go func() {
+		err := updater.Apply(release, proxy)
+		m.updateUI(func() {
+			progress.Hide()
+			if err != nil {
+				dialog.ShowError(err, m.window)
+				return
+			}
+			m.updateBanner.Hide()
+			dialog.ShowConfirm(i18n.T("update.done_title"), i18n.T("update.done", release.Version), func(restart bool) {
+				if !restart {
+					return
+				}
+				if err := updater.Restart(); err != nil {
+					dialog.ShowError(err, m.window)
+					return
+				}
+				fyne.CurrentApp().Quit()
+			}, m.window)
+		})
+	}()
+}
+
+// updateProxy 返回检查和下载更新使用的代理：使用界面中保存的代理设置，未设置或无效时为空（读取代理环境变量）
+func (m *Manager) updateProxy() string {
+	proxy := strings.TrimSpace(m.loadConfigOrDefault().Proxy)
+	if proxy == "" || installer.ValidateProxyURL(proxy) != nil {
+		return ""
+	}
+	return proxy
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"testing"
+
+	"claude-k2-installer/internal/installer"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestUpdateUISkipsWithoutWindow(t *testing.T) {
+	m := NewManager(nil, installer.New())
+	called := false
+	m.updateUI(func() { called = true })
+	if called {
+		t.Error("updateUI should not run updates without a window")
+	}
+	m.updateUI(nil)
+}
+
+func TestUpdateUIRunsUntilWindowClosed(t *testing.T) {
+	app := test.NewApp()
+	defer app.Quit()
+	window := app.NewWindow("test")
+	m := NewManager(window, installer.New())
+
+	calls := 0
+	m.updateUI(func() { calls++ })
+	if calls != 1 {
+		t.Fatalf("updateUI ran the update %d times, want 1", calls)
+	}
+
+	window.Close()
+	m.updateUI(func() { calls++ })
+	if calls != 1 {
+		t.Error("updateUI should ignore updates after the window is closed")
+	}
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+
+	"claude-k2-installer/internal/i18n"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// verifyEnvironment 不重新安装，检测组件版本和新终端中的 K2 环境变量，以检查清单显示结果
+func (m *Manager) verifyEnvironment() {
+	provider, err := m.selectedProvider()
+	if err != nil {
+		dialog.ShowError(err, m.window)
+		return
+	}
+
+	m.verifyButton.Disable()
+	m.statusLabel.SetText(i18n.T("verify.running"))
+
+	// 检测需要执行命令并启动 shell，放到后台避免界面卡顿
+	go func() {
+		report := m.installer.Verify(provider)
+		m.syncLogs()
+
+		m.updateUI(func() {
+			m.verifyButton.Enable()
+
+			summary := i18n.T("verify.healthy")
+			if !report.Healthy() {
+				summary = i18n.T("verify.unhealthy")
+			}
+			m.statusLabel.SetText(summary)
+
+			summaryLabel := widget.NewLabel(summary)
+			summaryLabel.Wrapping = fyne.TextWrapWord
+			items := container.NewVBox()
+			for _, check := range report.Checks() {
+				icon := "✅"
+				if !check.OK {
+					icon = "❌"
+				}
+				item := widget.NewLabel(fmt.Sprintf("%s %s: %s", icon, check.Name, check.Detail))
+				item.Wrapping = fyne.TextWrapWord
+				items.Add(item)
+			}
+			sourceLabel := widget.NewLabel(fmt.Sprintf(i18n.T("verify.source"), report.EnvSource))
+			sourceLabel.TextStyle = fyne.TextStyle{Italic: true}
+
+			content := container.NewVBox(summaryLabel, widget.NewSeparator(), items, sourceLabel)
+			verifyDialog := dialog.NewCustom(i18n.T("verify.title"), i18n.T("button.close"), content, m.window)
+			verifyDialog.Resize(fyne.NewSize(520, 0))
+			verifyDialog.Show()
+		})
+	}()
+}
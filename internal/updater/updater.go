@@ -0,0 +1,401 @@
+// Package updater 检查 GitHub Releases 上的新版本并替换正在运行的安装器
+package updater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"claude-k2-installer/internal/version"
+)
+
+// Repo 发布新版本的 GitHub 仓库
+const Repo = "ruan11223344/claude-k2-installer"
+
+// MirrorEnvVar 下载 GitHub 文件时使用的镜像前缀，如 https://ghproxy.net/，国内网络无法直连 GitHub 时设置
+// 只用于下载发布包，校验文件始终直接从 api.github.com 获取
+const MirrorEnvVar = "CLAUDE_K2_GITHUB_MIRROR"
+
+// checksumsAssetName 发布中的校验文件，每行为 "SHA256  文件名"，由 build.sh 生成
+const checksumsAssetName = "SHA256SUMS"
+
+// binaryName 发布包中可执行文件的名称，与 build.sh 中的 APP_NAME 一致
+const binaryName = "ClaudeK2Installer"
+
+// Release GitHub 上的一个发布版本
+type Release struct {
+	Version   string // 去掉 v 前缀的版本号
+	PageURL   string // 发布页面
+	Notes     string // 更新说明
+	AssetName string // 适用于当前系统的发布包，为空时只能手动下载
+	AssetURL  string
+
+	// 校验文件的 API 地址（api.github.com），为空时拒绝自动更新
+	ChecksumsURL string
+}
+
+// githubRelease GitHub releases API 返回的字段
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+	Body    string `json:"body"`
+	Assets  []struct {
+		Name   string `json:"name"`
+		URL    string `json:"browser_download_url"`
+		APIURL string `json:"url"`
+	} `json:"assets"`
+}
+
+// newHTTPClient 返回检查和下载更新使用的客户端：设置了 proxy 时固定使用该代理，否则读取代理环境变量
+func newHTTPClient(proxy string, timeout time.Duration) (*http.Client, error) {
+	proxyFunc := http.ProxyFromEnvironment
+	if proxy = strings.TrimSpace(proxy); proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("代理地址无效: %v", err)
+		}
+		proxyFunc = http.ProxyURL(proxyURL)
+	}
+	return &http.Client{Timeout: timeout, Transport: &http.Transport{Proxy: proxyFunc}}, nil
+}
+
+// latestReleaseURL 查询最新发布版本的接口，测试时替换
+var latestReleaseURL = "https://api.github.com/repos/" + Repo + "/releases/latest"
+
+// CheckForUpdate 查询 GitHub 上的最新版本，比当前版本新时返回该版本，否则返回 nil；
+// proxy 为界面中设置的代理，为空时读取代理环境变量。开发构建（如 dev）不检查更新
+func CheckForUpdate(proxy string) (*Release, error) {
+	if !isReleaseVersion(version.Version) {
+		return nil, nil
+	}
+	req, err := http.NewRequest("GET", latestReleaseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	client, err := newHTTPClient(proxy, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("检查更新失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("检查更新失败，HTTP状态码: %d", resp.StatusCode)
+	}
+
+	var latest githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return nil, fmt.Errorf("解析版本信息失败: %v", err)
+	}
+	latestVersion := strings.TrimPrefix(latest.TagName, "v")
+	if !newerVersion(latestVersion, version.Version) {
+		return nil, nil
+	}
+
+	release := &Release{Version: latestVersion, PageURL: latest.HTMLURL, Notes: latest.Body}
+	for _, asset := range latest.Assets {
+		switch {
+		case asset.Name == checksumsAssetName:
+			release.ChecksumsURL = asset.APIURL
+		case release.AssetURL == "" && matchesPlatform(asset.Name, runtime.GOOS, runtime.GOARCH):
+			release.AssetName = asset.Name
+			release.AssetURL = asset.URL
+		}
+	}
+	return release, nil
+}
+
+// isReleaseVersion 判断是否为发布构建的版本号（如 1.2.0），dev 等开发构建的版本不参与自动更新
+func isReleaseVersion(v string) bool {
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// newerVersion 按数字逐段比较版本号，latest 比 current 新时返回 true
+func newerVersion(latest, current string) bool {
+	a, b := strings.Split(latest, "."), strings.Split(strings.TrimPrefix(current, "v"), ".")
+	for n := 0; n < len(a) || n < len(b); n++ {
+		var x, y int
+		if n < len(a) {
+			x, _ = strconv.Atoi(a[n])
+		}
+		if n < len(b) {
+			y, _ = strconv.Atoi(b[n])
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+// matchesPlatform 判断发布包是否适用于指定平台，命名规则见 build.sh：
+// ClaudeK2Installer-版本-windows.zip、-macos-架构.zip、-linux-架构.tar.gz
+func matchesPlatform(name, goos, goarch string) bool {
+	name = strings.ToLower(name)
+	archNames := map[string][]string{
+		"amd64": {"x86_64", "amd64", "x64"},
+		"arm64": {"arm64", "aarch64"},
+	}[goarch]
+	hasArch := func() bool {
+		for _, arch := range archNames {
+			if strings.Contains(name, arch) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch goos {
+	case "windows":
+		return strings.Contains(name, "windows") && strings.HasSuffix(name, ".zip")
+	case "darwin":
+		return strings.Contains(name, "macos") && strings.HasSuffix(name, ".zip") && hasArch()
+	case "linux":
+		return strings.Contains(name, "linux") && strings.HasSuffix(name, ".tar.gz") && hasArch()
+	}
+	return false
+}
+
+// mirrorURL 设置了 MirrorEnvVar 时通过镜像下载
+func mirrorURL(url string) string {
+	if mirror := strings.TrimSpace(os.Getenv(MirrorEnvVar)); mirror != "" {
+		return strings.TrimSuffix(mirror, "/") + "/" + url
+	}
+	return url
+}
+
+// Apply 下载发布包并校验 SHA256，取出其中的可执行文件替换当前正在运行的程序，重新启动后生效；
+// proxy 为界面中设置的代理，为空时读取代理环境变量
+//
+// 新文件先写到程序旁边再改名替换；Windows 不能覆盖运行中的 exe，先把旧文件改名为 .old，
+// 下次启动时由 CleanupOldBinary 删除。
+func Apply(release *Release, proxy string) error {
+	if release.AssetURL == "" {
+		return fmt.Errorf("没有适用于当前系统的安装包，请到发布页面手动下载: %s", release.PageURL)
+	}
+	client, err := newHTTPClient(proxy, 5*time.Minute)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("获取程序路径失败: %v", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("获取程序路径失败: %v", err)
+	}
+
+	archive, err := os.CreateTemp("", "claude-k2-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	// 校验通过前不改动当前程序
+	if err := downloadVerified(client, release, archive); err != nil {
+		return err
+	}
+
+	newPath := exe + ".new"
+	if err := extractBinary(archive.Name(), release.AssetName, newPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		oldPath := exe + ".old"
+		os.Remove(oldPath)
+		if err := os.Rename(exe, oldPath); err != nil {
+			os.Remove(newPath)
+			return fmt.Errorf("替换程序失败: %v", err)
+		}
+	}
+	if err := os.Rename(newPath, exe); err != nil {
+		os.Remove(newPath)
+		return fmt.Errorf("替换程序失败: %v", err)
+	}
+	return nil
+}
+
+// downloadVerified 直接从 api.github.com 获取校验文件，再下载发布包（可经过镜像）写入 dest，
+// 发布包的 SHA256 与校验文件不一致时返回错误
+func downloadVerified(client *http.Client, release *Release, dest io.Writer) error {
+	if release.ChecksumsURL == "" {
+		return fmt.Errorf("该版本没有发布 %s 校验文件，无法确认安装包未被篡改，请到发布页面手动下载: %s", checksumsAssetName, release.PageURL)
+	}
+	want, err := fetchChecksum(client, release.ChecksumsURL, release.AssetName)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Get(mirrorURL(release.AssetURL))
+	if err != nil {
+		return fmt.Errorf("下载新版本失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("下载新版本失败，HTTP状态码: %d", resp.StatusCode)
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(dest, hash), resp.Body); err != nil {
+		return fmt.Errorf("下载新版本失败: %v", err)
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("新版本安装包校验失败（SHA256 为 %s，应为 %s），可能被镜像或网络篡改，已停止更新", got, want)
+	}
+	return nil
+}
+
+// fetchChecksum 通过 GitHub API 下载校验文件，返回 assetName 的 SHA256；不经过镜像，避免镜像同时篡改安装包和校验值
+func fetchChecksum(client *http.Client, checksumsURL, assetName string) (string, error) {
+	req, err := http.NewRequest("GET", checksumsURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("下载校验文件失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("下载校验文件失败，HTTP状态码: %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("下载校验文件失败: %v", err)
+	}
+	return parseChecksum(data, assetName)
+}
+
+// parseChecksum 从 sha256sum 格式的校验文件中找出 assetName 的 SHA256
+func parseChecksum(data []byte, assetName string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// sha256sum 的二进制模式在文件名前加 *
+		if strings.TrimPrefix(fields[1], "*") != assetName {
+			continue
+		}
+		sum := strings.ToLower(fields[0])
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+			return "", fmt.Errorf("校验文件中 %s 的 SHA256 格式不正确", assetName)
+		}
+		return sum, nil
+	}
+	return "", fmt.Errorf("校验文件中没有 %s 的 SHA256，无法确认安装包未被篡改", assetName)
+}
+
+// extractBinary 从 zip 或 tar.gz 发布包中取出可执行文件写到 dest
+func extractBinary(archivePath, assetName, dest string) error {
+	want := binaryName
+	if runtime.GOOS == "windows" {
+		want += ".exe"
+	}
+
+	if strings.HasSuffix(assetName, ".tar.gz") {
+		f, err := os.Open(archivePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("解压新版本失败: %v", err)
+		}
+		tr := tar.NewReader(gz)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("解压新版本失败: %v", err)
+			}
+			if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == want {
+				return writeExecutable(dest, tr)
+			}
+		}
+		return fmt.Errorf("安装包中没有找到 %s", want)
+	}
+
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return fmt.Errorf("解压新版本失败: %v", err)
+	}
+	defer zr.Close()
+	for _, file := range zr.File {
+		// macOS 的 zip 中是 .app 包，可执行文件在 Contents/MacOS 下
+		if file.FileInfo().IsDir() || filepath.Base(file.Name) != want {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("解压新版本失败: %v", err)
+		}
+		defer rc.Close()
+		return writeExecutable(dest, rc)
+	}
+	return fmt.Errorf("安装包中没有找到 %s", want)
+}
+
+// writeExecutable 把 r 的内容写成可执行文件
+func writeExecutable(dest string, r io.Reader) error {
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return fmt.Errorf("写入新版本失败: %v", err)
+	}
+	return out.Close()
+}
+
+// CleanupOldBinary 删除上次在 Windows 上更新时留下的旧程序
+func CleanupOldBinary() {
+	if exe, err := os.Executable(); err == nil {
+		os.Remove(exe + ".old")
+	}
+}
+
+// Restart 启动更新后的程序，调用方随后应退出当前进程
+func Restart() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Start()
+}
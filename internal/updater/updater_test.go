@@ -0,0 +1,192 @@
+package updater
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"claude-k2-installer/internal/version"
+)
+
+func TestNewerVersion(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"1.0.1", "1.0.0", true},
+		{"1.10.0", "1.9.3", true},
+		{"1.0", "1.0.0", false},
+		{"1.0.0", "v1.0.0", false},
+		{"0.9.9", "1.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := newerVersion(tt.latest, tt.current); got != tt.want {
+			t.Errorf("newerVersion(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesPlatform(t *testing.T) {
+	if !matchesPlatform("ClaudeK2Installer-1.1.0-windows.zip", "windows", "amd64") {
+		t.Error("windows zip should match windows")
+	}
+	if !matchesPlatform("ClaudeK2Installer-1.1.0-macos-arm64.zip", "darwin", "arm64") {
+		t.Error("arm64 macOS zip should match darwin/arm64")
+	}
+	if matchesPlatform("ClaudeK2Installer-1.1.0-macos-arm64.zip", "darwin", "amd64") {
+		t.Error("arm64 macOS zip should not match darwin/amd64")
+	}
+	if !matchesPlatform("ClaudeK2Installer-1.1.0-linux-x86_64.tar.gz", "linux", "amd64") {
+		t.Error("x86_64 linux tarball should match linux/amd64")
+	}
+}
+
+func TestCheckForUpdate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name":"v9.0.0","html_url":"https://example.com/release","body":"notes","assets":[]}`))
+	}))
+	defer server.Close()
+
+	defer func(url string) { latestReleaseURL = url }(latestReleaseURL)
+	latestReleaseURL = server.URL
+
+	release, err := CheckForUpdate("")
+	if err != nil {
+		t.Fatalf("CheckForUpdate: %v", err)
+	}
+	if release == nil || release.Version != "9.0.0" || release.PageURL != "https://example.com/release" {
+		t.Errorf("unexpected release %+v", release)
+	}
+
+	defer func(v string) { version.Version = v }(version.Version)
+	version.Version = "9.0.0"
+	if release, err := CheckForUpdate(""); err != nil || release != nil {
+		t.Errorf("expected no update for the same version, got %+v, %v", release, err)
+	}
+
+	version.Version = "dev"
+	if release, err := CheckForUpdate(""); err != nil || release != nil {
+		t.Errorf("dev builds should not be offered updates, got %+v, %v", release, err)
+	}
+}
+
+func TestCheckForUpdateFindsChecksums(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name":"v9.0.0","assets":[` +
+			`{"name":"SHA256SUMS","browser_download_url":"https://github.com/dl/SHA256SUMS","url":"https://api.github.com/assets/1"}]}`))
+	}))
+	defer server.Close()
+
+	defer func(url string) { latestReleaseURL = url }(latestReleaseURL)
+	latestReleaseURL = server.URL
+	defer func(v string) { version.Version = v }(version.Version)
+	version.Version = "1.0.0"
+
+	release, err := CheckForUpdate("")
+	if err != nil || release == nil {
+		t.Fatalf("CheckForUpdate: %+v, %v", release, err)
+	}
+	if release.ChecksumsURL != "https://api.github.com/assets/1" {
+		t.Errorf("checksums should use the API asset URL, got %q", release.ChecksumsURL)
+	}
+	if _, err := CheckForUpdate("://bad proxy"); err == nil {
+		t.Error("an invalid proxy should be rejected")
+	}
+}
+
+func TestDownloadVerified(t *testing.T) {
+	archive := []byte("release archive")
+	sum := sha256.Sum256(archive)
+	const assetName = "ClaudeK2Installer-9.0.0-linux-x86_64.tar.gz"
+
+	var checksumsAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sums":
+			checksumsAccept = r.Header.Get("Accept")
+			w.Write([]byte(hex.EncodeToString(sum[:]) + "  " + assetName + "\n"))
+		case "/bad-sums":
+			w.Write([]byte(strings.Repeat("0", 64) + " *" + assetName + "\n"))
+		case "/asset":
+			w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	release := &Release{AssetName: assetName, AssetURL: server.URL + "/asset", ChecksumsURL: server.URL + "/sums"}
+	var buf bytes.Buffer
+	if err := downloadVerified(server.Client(), release, &buf); err != nil {
+		t.Fatalf("downloadVerified: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), archive) {
+		t.Error("downloaded archive does not match")
+	}
+	if checksumsAccept != "application/octet-stream" {
+		t.Errorf("checksums should be requested as a raw asset, got Accept %q", checksumsAccept)
+	}
+
+	release.ChecksumsURL = server.URL + "/bad-sums"
+	if err := downloadVerified(server.Client(), release, &bytes.Buffer{}); err == nil {
+		t.Error("a checksum mismatch should be rejected")
+	}
+
+	release.ChecksumsURL = ""
+	if err := downloadVerified(server.Client(), release, &bytes.Buffer{}); err == nil {
+		t.Error("a release without SHA256SUMS should be rejected")
+	}
+}
+
+func TestDownloadVerifiedChecksumsBypassMirror(t *testing.T) {
+	archive := []byte("release archive")
+	sum := sha256.Sum256(archive)
+	const assetName = "ClaudeK2Installer-9.0.0-windows.zip"
+
+	// 镜像返回被篡改的安装包，校验文件直接从源站获取
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(hex.EncodeToString(sum[:]) + "  " + assetName + "\n"))
+	}))
+	defer origin.Close()
+	var mirrorPaths []string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorPaths = append(mirrorPaths, r.URL.Path)
+		w.Write([]byte("tampered archive"))
+	}))
+	defer mirror.Close()
+	t.Setenv(MirrorEnvVar, mirror.URL)
+
+	release := &Release{AssetName: assetName, AssetURL: "https://github.com/dl/" + assetName, ChecksumsURL: origin.URL + "/sums"}
+	if err := downloadVerified(http.DefaultClient, release, &bytes.Buffer{}); err == nil {
+		t.Error("a tampered archive from the mirror should be rejected")
+	}
+	for _, path := range mirrorPaths {
+		if strings.Contains(path, "sums") {
+			t.Errorf("checksums must not be fetched through the mirror: %s", path)
+		}
+	}
+	if len(mirrorPaths) != 1 {
+		t.Errorf("expected only the archive to go through the mirror, got %v", mirrorPaths)
+	}
+}
+
+func TestParseChecksum(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	data := []byte(sum + "  a.zip\n" + strings.Repeat("cd", 32) + " *b.tar.gz\n")
+	if got, err := parseChecksum(data, "a.zip"); err != nil || got != sum {
+		t.Errorf("parseChecksum(a.zip) = %q, %v", got, err)
+	}
+	if got, err := parseChecksum(data, "b.tar.gz"); err != nil || got != strings.Repeat("cd", 32) {
+		t.Errorf("parseChecksum(b.tar.gz) = %q, %v", got, err)
+	}
+	if _, err := parseChecksum(data, "c.zip"); err == nil {
+		t.Error("a missing entry should be an error")
+	}
+	if _, err := parseChecksum([]byte("xyz  a.zip\n"), "a.zip"); err == nil {
+		t.Error("a malformed checksum should be an error")
+	}
+}
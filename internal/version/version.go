@@ -0,0 +1,16 @@
+// Package version 记录安装器的构建版本，便于把问题反馈和具体构建对应起来
+package version
+
+// Version 安装器版本，发布构建时通过 -ldflags "-X claude-k2-installer/internal/version.Version=x.y.z" 写入
+var Version = "1.0.0"
+
+// Commit 构建时的 git 提交，通过 -ldflags "-X claude-k2-installer/internal/version.Commit=..." 写入，未设置时为空
+var Commit = ""
+
+// String 返回用于显示的版本，如 v1.0.0 (a1b2c3d)
+func String() string {
+	if Commit == "" {
+		return "v" + Version
+	}
+	return "v" + Version + " (" + Commit + ")"
+}
@@ -1,33 +1,162 @@
 package main
 
 import (
+	"claude-k2-installer/internal/activation"
+	"claude-k2-installer/internal/cli"
+	"claude-k2-installer/internal/i18n"
 	"claude-k2-installer/internal/installer"
 	"claude-k2-installer/internal/ui"
+	"claude-k2-installer/internal/updater"
+	"claude-k2-installer/internal/version"
+	"errors"
+	"flag"
+	"fmt"
 	"os"
+	"strings"
 
 	"fyne.io/fyne/v2/app"
 )
 
 func main() {
-	// 设置环境变量以支持中文
-	os.Setenv("LANG", "zh_CN.UTF-8")
+	headless := flag.Bool("headless", false, "无界面模式：不启动窗口，直接安装并配置（适用于服务器和 CI）")
+	apiKey := flag.String("api-key", "", "API Key（无界面模式）")
+	rpm := flag.String("rpm", "3", "速率限制 RPM（无界面模式）")
+	provider := flag.String("provider", "", "服务商名称，默认 Kimi K2（无界面模式）")
+	permanent := flag.Bool("permanent", true, "永久设置环境变量（无界面模式）")
+	jsonProgress := flag.Bool("json", false, "以每行一个 JSON 对象的格式输出进度事件（无界面模式）")
+	logRetention := flag.String("log-retention", string(installer.LogRetentionDays), "日志留存: off 不落盘 / session 仅本次会话 / days 留存 N 天 / forever 永久（无界面模式）")
+	logDays := flag.Int("log-days", installer.DefaultLogRetentionDays, "日志留存天数，log-retention 为 days 时生效（无界面模式）")
+	logFullArgs := flag.Bool("log-full-args", false, "日志中记录命令完整参数，可能包含 API Key（无界面模式）")
+	nodeVersion := flag.String("node-version", installer.DefaultNodeVersion, "需要安装 Node.js 时安装的版本（无界面模式）")
+	minNodeVersion := flag.Int("min-node-version", installer.DefaultMinNodeVersion, "要求的最低 Node.js 主版本，已安装的版本更低时升级（无界面模式）")
+	gitVersion := flag.String("git-version", installer.DefaultGitVersion, "Windows 上需要安装 Git 时安装的 Git for Windows 版本（无界面模式）")
+	minGitVersion := flag.String("min-git-version", installer.DefaultMinGitVersion, "要求的最低 Git 版本，已安装的版本更低时重新安装（无界面模式）")
+	claudeVersion := flag.String("claude-version", installer.DefaultClaudeCodeVersion, "安装的 Claude Code 版本，如 1.0.51 或 latest（无界面模式）")
+	npmSudo := flag.Bool("npm-sudo", false, "npm 全局目录无写权限时使用 sudo 安装，默认改用 ~/.npm-global（仅 Linux，无界面模式）")
+	downloadRetries := flag.Int("download-retries", installer.DefaultDownloadRetries, "每个镜像下载失败后的重试次数，超时和 5xx 按指数退避重试，404 不重试（无界面模式）")
+	slowNetwork := flag.Bool("slow-network", false, "慢速网络模式：单个文件下载最长 60 分钟，2 分钟没有新数据才判定停滞（无界面模式）")
+	forceIPv4 := flag.Bool("force-ipv4", false, "只使用 IPv4 连接，IPv6 网络不通时使用（无界面模式）")
+	offlineDir := flag.String("offline-dir", "", "离线安装包目录：从该目录安装 Node.js、Git 和 Claude Code，不联网下载（无界面模式）")
+	nodeMirror := flag.String("node-mirror", "", "自定义 Node.js 下载镜像，如 https://npmmirror.com/mirrors/node，优先于内置镜像（无界面模式）")
+	npmRegistry := flag.String("npm-registry", "", "安装 Claude Code 使用的 npm 镜像，默认 https://registry.npmmirror.com（无界面模式）")
+	proxy := flag.String("proxy", "", "下载和 npm 安装使用的 HTTP 代理，如 http://127.0.0.1:7890（无界面模式）")
+	configFile := flag.String("config", "", "从 JSON 或 YAML 配置文件读取安装参数，命令行显式指定的参数优先；指定后以无界面模式运行")
+	envOnly := flag.Bool("env-only", false, "仅配置环境变量，不修改 ~/.claude.json（无界面模式）")
+	configStrategy := flag.String("config-strategy", string(installer.ConfigStrategyShell), "永久环境变量的写入位置: shell（shell 配置文件/Windows 环境变量）、settings（~/.claude/settings.json）或 both（无界面模式）")
+	skipNode := flag.Bool("skip-node", false, "跳过检测和安装 Node.js，使用自行管理的 Node.js（无界面模式）")
+	skipGit := flag.Bool("skip-git", false, "跳过检测和安装 Git（无界面模式）")
+	dryRun := flag.Bool("dry-run", false, "模拟运行：只报告将安装的组件，不修改系统（无界面模式）")
+	logLines := flag.Int("log-lines", installer.DefaultMaxLogLines, "日志区最多保留的行数，超出后丢弃最旧的日志")
+	showVersion := flag.Bool("version", false, "显示版本号并退出")
+	activationCode := flag.String("activation-code", "", "激活码，本机尚未激活时用于激活（无界面模式）")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println("Claude Code K2 Installer " + version.String())
+		return
+	}
+
+	// 删除上次自动更新时留下的旧程序（仅 Windows 会留下）
+	updater.CleanupOldBinary()
+
+	// 界面语言：环境变量 CLAUDE_K2_LANG 优先，其次是界面中保存的选择
+	// 使用中文时设置 LANG 以支持中文输出
+	if ui.ApplyLocale() == i18n.ZhCN {
+		os.Setenv("LANG", "zh_CN.UTF-8")
+	}
+
+	if *headless || *configFile != "" {
+		// 无界面模式同样需要激活
+		if !activation.IsActivated() {
+			if err := activateHeadless(*activationCode); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+				os.Exit(2)
+			}
+		}
+
+		opts := cli.Options{
+			APIKey:          *apiKey,
+			RPM:             *rpm,
+			Provider:        *provider,
+			UseSystemConfig: *permanent,
+			JSON:            *jsonProgress,
+			DryRun:          *dryRun,
+			NodeVersion:     *nodeVersion,
+			MinNodeVersion:  *minNodeVersion,
+			GitVersion:      *gitVersion,
+			MinGitVersion:   *minGitVersion,
+			NPMSudo:         *npmSudo,
+			ClaudeVersion:   *claudeVersion,
+			DownloadRetries: *downloadRetries,
+			OfflineDir:      *offlineDir,
+			EnvOnly:         *envOnly,
+			ConfigStrategy:  *configStrategy,
+			SkipNode:        *skipNode,
+			SkipGit:         *skipGit,
+			NodeMirror:      *nodeMirror,
+			NPMRegistry:     *npmRegistry,
+			Proxy:           *proxy,
+			SlowNetwork:     *slowNetwork,
+			ForceIPv4:       *forceIPv4,
+			LogPolicy: installer.LogPolicy{
+				Retention:      installer.LogRetention(*logRetention),
+				Days:           *logDays,
+				RecordFullArgs: *logFullArgs,
+			},
+		}
+		if *configFile != "" {
+			// 命令行显式指定的参数优先于配置文件
+			explicit := make(map[string]bool)
+			flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+			if err := opts.LoadConfigFile(*configFile, explicit); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+				os.Exit(2)
+			}
+		}
+		os.Exit(cli.Run(opts))
+	}
 
 	myApp := app.New()
-	myApp.Settings().SetTheme(&ui.CustomTheme{})
+	myApp.Settings().SetTheme(ui.LoadTheme())
 
-	mainWindow := myApp.NewWindow("Claude Code + K2 环境集成工具")
+	mainWindow := myApp.NewWindow(i18n.T("app.title") + " " + version.String())
 	mainWindow.Resize(ui.DefaultWindowSize)
 	mainWindow.CenterOnScreen()
+	// 小屏幕（如 1366x768、1280x720）上缩小到屏幕可用范围内
+	ui.FitWindowToScreen(mainWindow, ui.DefaultWindowSize)
 
 	// 创建安装器实例
 	inst := installer.New()
+	inst.SetMaxLogLines(*logLines)
 
 	// 创建UI管理器
 	uiManager := ui.NewManager(mainWindow, inst)
 
-	// 直接显示主界面（包含激活状态）
-	mainWindow.SetContent(uiManager.CreateMainContent())
+	// 未激活时先显示激活界面，激活成功后进入主界面（主界面中显示激活状态）
+	if activation.IsActivated() {
+		mainWindow.SetContent(uiManager.CreateMainContent())
+	} else {
+		mainWindow.SetContent(uiManager.CreateActivationContent())
+	}
 
 	mainWindow.ShowAndRun()
+
+	// 按日志策略关闭并清理本次会话的日志
+	inst.CloseLog()
 }
 
+// activateHeadless 无界面模式下使用 --activation-code 激活本机，提示输出到 stderr，不影响 --json 的输出
+func activateHeadless(code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return errors.New("本机尚未激活，请先在图形界面中激活，或使用 --activation-code 指定激活码")
+	}
+	if !activation.Validate(code) {
+		return errors.New("激活码无效，请检查是否完整输入")
+	}
+	if err := activation.SaveActivation(code); err != nil {
+		return fmt.Errorf("保存激活信息失败: %v", err)
+	}
+	fmt.Fprintln(os.Stderr, "✅ 已激活")
+	return nil
+}